@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/config"
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+)
+
+var errInvalidAccountSetting = errors.New("invalid account setting, expected key=value")
+
+// init initializes the account command and its subcommands, adding them to
+// the root command.
+func init() {
+	rootCmd.AddCommand(accountCmd)
+	accountCmd.AddCommand(accountAddCmd)
+	accountCmd.AddCommand(accountListCmd)
+	accountCmd.AddCommand(accountRemoveCmd)
+}
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Manage accounts, e.g. of several institutions",
+	Long: "Define accounts with their own access token and defaults in the configuration\n" +
+		"file. Select one with --account, or set 'account' in the config file to the\n" +
+		"account used by default.",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+var accountAddCmd = &cobra.Command{
+	Use:   "add <name> [key=value]...",
+	Short: "Add an account and store its access token",
+	Long: "Add an account to the configuration file and store its access token. The\n" +
+		"settings are defaults of the account, named after the flags they provide\n" +
+		"defaults for, e.g. 'account add zhaw output=~/Videos/ZHAW'. Adding an existing\n" +
+		"account updates its settings.",
+	Example: "  switchtube-downloader account add eth output=~/Videos/ETH\n" +
+		"  switchtube-downloader account add test base-url=https://test.tube.switch.ch",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := cfg.AddAccount(name); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		for _, setting := range args[1:] {
+			key, value, found := strings.Cut(setting, "=")
+			if !found {
+				return fmt.Errorf("%w: %s", errInvalidAccountSetting, setting)
+			}
+
+			key = currentFlagName(key)
+
+			flag := lookupConfigFlag(key)
+			if flag == nil || key == "account" {
+				return fmt.Errorf("%w: %s", errUnknownConfigKey, key)
+			}
+
+			if err := cfg.SetAccountValue(name, key, value, flag.Value.Type()); err != nil {
+				return fmt.Errorf("%w", err)
+			}
+		}
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		fmt.Printf("Added account %s in %s\n", name, cfg.Path())
+
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		err = tokenMgr.WithAccount(name).Set()
+		if err != nil && !errors.Is(err, token.ErrTokenAlreadyExists) {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+var accountListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all accounts",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		defaultAccount, _, _ := cfg.Get("account")
+
+		accounts := make([]models.AccountConfig, 0, len(cfg.Accounts()))
+		for _, name := range cfg.Accounts() {
+			settings, _, err := cfg.AccountValues(name)
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+
+			accounts = append(accounts, models.AccountConfig{
+				Name:     name,
+				Default:  len(defaultAccount) > 0 && defaultAccount[0] == name,
+				Settings: settings,
+			})
+		}
+
+		if asJSON {
+			return printJSON(accounts)
+		}
+
+		if len(accounts) == 0 {
+			fmt.Println("No accounts defined")
+
+			return nil
+		}
+
+		ui.PrintAccounts(accounts)
+
+		return nil
+	},
+}
+
+var accountRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an account and its access token",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !cfg.RemoveAccount(name) {
+			return fmt.Errorf("%w: %s", config.ErrUnknownAccount, name)
+		}
+
+		if defaultAccount, _, _ := cfg.Get("account"); len(defaultAccount) > 0 && defaultAccount[0] == name {
+			cfg.Unset("account")
+		}
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		fmt.Printf("Removed account %s from %s\n", name, cfg.Path())
+
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		err = tokenMgr.WithAccount(name).Delete()
+		if err != nil && !errors.Is(err, token.ErrNoTokenStored) {
+			slog.Warn("failed to delete the token of the account", "account", name, "error", err)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// aliasAddArgs is the number of arguments of the alias add command.
+	aliasAddArgs = 2
+
+	// mediaArgsAnnotation marks commands whose arguments are ids or links of
+	// videos, channels or profiles, which may be given as aliases.
+	mediaArgsAnnotation = "mediaArgs"
+)
+
+var errUnknownAlias = errors.New("unknown alias")
+
+// init initializes the alias command and its subcommands, adding them to the
+// root command.
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasAddCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage aliases for channels and videos",
+	Long: "Define short names for the ids or links of videos, channels and profiles in the\n" +
+		"configuration file. An alias can be given anywhere an id or link is accepted.",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:   "add <name> <id|url>",
+	Short: "Add or replace an alias",
+	Args:  cobra.ExactArgs(aliasAddArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := cfg.SetAlias(args[0], args[1]); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		fmt.Printf("Added alias %s in %s\n", args[0], cfg.Path())
+
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all aliases",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		aliases := make([]models.Alias, 0, len(cfg.Aliases()))
+		for name, target := range cfg.Aliases() {
+			aliases = append(aliases, models.Alias{Name: name, Target: target})
+		}
+
+		slices.SortFunc(aliases, func(a, b models.Alias) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+
+		if asJSON {
+			return printJSON(aliases)
+		}
+
+		if len(aliases) == 0 {
+			fmt.Println("No aliases defined")
+
+			return nil
+		}
+
+		ui.PrintAliases(aliases)
+
+		return nil
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an alias",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !cfg.RemoveAlias(args[0]) {
+			return fmt.Errorf("%w: %s", errUnknownAlias, args[0])
+		}
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		fmt.Printf("Removed alias %s from %s\n", args[0], cfg.Path())
+
+		return nil
+	},
+}
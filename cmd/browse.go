@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/models"
+)
+
+// init initializes the browse command and adds it to the root command with
+// its flags.
+func init() {
+	rootCmd.AddCommand(browseCmd)
+	browseCmd.Flags().
+		BoolP("episode", "e", false, "Prefixes the video with episode-number e.g. 01_OR_Mapping.mp4")
+	addExistingFileFlags(browseCmd)
+	browseCmd.Flags().StringP("output", "o", "", "Output directory for downloaded files")
+	addProgressFlag(browseCmd)
+	addFilenameFlags(browseCmd)
+	addPostProcessFlags(browseCmd)
+	addSizeLimitFlags(browseCmd)
+	addVariantFlags(browseCmd)
+	addExternalDownloaderFlag(browseCmd)
+	addExecBeforeFlag(browseCmd)
+	addTimeoutFlags(browseCmd)
+	addWaitLockFlag(browseCmd)
+	addRetryFlags(browseCmd)
+}
+
+var browseCmd = &cobra.Command{
+	Use:   "browse <id|url>",
+	Short: "Browse a channel or profile interactively",
+	Long: "Navigate the channels of a profile or the videos of a channel in the terminal,\n" +
+		"mark videos with space and press d to download them into their channel folders\n" +
+		"as given by --output-template.",
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{mediaArgsAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := downloadConfig(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		profile, channels, err := download.BrowseChannels(cmd.Context(), client, args[0])
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		selections, err := ui.Browse(channels, func(channelID string) ([]models.Video, error) {
+			return download.ChannelVideos(cmd.Context(), client, channelID)
+		})
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if len(selections) == 0 {
+			fmt.Fprintln(os.Stderr, "No videos selected for download")
+
+			return nil
+		}
+
+		for i := range selections {
+			selections[i].Profile = profile
+		}
+
+		// The run timeout only starts once the videos are picked
+		ctx, cancel := download.WithRunTimeout(cmd.Context(), config)
+		defer cancel()
+
+		return downloadSelections(ctx, client, config, selections)
+	},
+}
+
+// downloadSelections downloads the videos marked in the browser channel by
+// channel.
+func downloadSelections(
+	ctx context.Context,
+	client *download.Client,
+	config models.DownloadConfig,
+	selections []models.ChannelSelection,
+) error {
+	failed := 0
+
+	for _, selection := range selections {
+		fmt.Fprintf(os.Stderr, "\nChannel: %s\n", selection.Channel.Name)
+
+		if err := download.DownloadSelection(ctx, client, config, selection); err != nil {
+			slog.Error("failed to download channel", "channel", selection.Channel.Name, "error", err)
+
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", download.ErrPartialFailure, failed, len(selections))
+	}
+
+	return nil
+}
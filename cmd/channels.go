@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/helper/ui"
+)
+
+// init initializes the channels command and adds it to the root command.
+func init() {
+	rootCmd.AddCommand(channelsCmd)
+}
+
+var channelsCmd = &cobra.Command{
+	Use:   "channels",
+	Short: "List the channels you have access to",
+	Long: "List the id and name of every channel the access token gives access to, e.g. the\n" +
+		"channels of your organization, to find the id to download.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		channels, err := download.Channels(cmd.Context(), client)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if asJSON {
+			return printJSON(channels)
+		}
+
+		if len(channels) == 0 {
+			fmt.Println("No channels found")
+
+			return nil
+		}
+
+		ui.PrintChannels(channels)
+
+		return nil
+	},
+}
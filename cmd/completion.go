@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"slices"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"switchtube-downloader/internal/token"
+)
+
+// flagValues are the values offered by shell completion for flags that only
+// accept a fixed set of values.
+var flagValues = map[string][]string{
+	"audio-format":        audioFormats,
+	"external-downloader": externalDownloaders,
+	"on-conflict":         conflictPolicies,
+	"order":               videoOrders,
+	"progress":            progressFormats,
+	"progress-style":      progressStyles,
+	"remux":               remuxContainers,
+	"keyring-backend":     token.Stores,
+}
+
+// init registers the completion functions of the config keys.
+func init() {
+	configGetCmd.ValidArgsFunction = completeConfigKey
+	configSetCmd.ValidArgsFunction = completeConfigKeyValue
+}
+
+// registerFlagCompletions registers the value completion of all flags in
+// flagValues for cmd and its subcommands. It must run after all commands have
+// been added.
+func registerFlagCompletions(cmd *cobra.Command) {
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if _, ok := flagValues[flag.Name]; ok {
+			cobra.CheckErr(cmd.RegisterFlagCompletionFunc(flag.Name, completeFlagValue(flag)))
+		}
+	})
+
+	for _, sub := range cmd.Commands() {
+		registerFlagCompletions(sub)
+	}
+}
+
+// completeFlagValue returns a completion function offering the known values
+// of flag.
+func completeFlagValue(flag *pflag.Flag) cobra.CompletionFunc {
+	return func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return flagValueCompletions(flag), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// flagValueCompletions returns the values offered for flag, or nil if any
+// value is accepted.
+func flagValueCompletions(flag *pflag.Flag) []string {
+	if values, ok := flagValues[flag.Name]; ok {
+		return values
+	}
+
+	if flag.Value.Type() == "bool" {
+		return []string{"true", "false"}
+	}
+
+	return nil
+}
+
+// completeConfigKey completes the key argument of config get.
+func completeConfigKey(
+	_ *cobra.Command,
+	args []string,
+	_ string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return configKeys(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigKeyValue completes the key and value arguments of config set.
+func completeConfigKeyValue(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 1 {
+		return completeConfigKey(cmd, args, toComplete)
+	}
+
+	flag := lookupConfigFlag(args[0])
+	if flag == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if values := flagValueCompletions(flag); values != nil {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Free-form values such as the output directory are often paths
+	return nil, cobra.ShellCompDirectiveDefault
+}
+
+// configKeys returns the sorted names of all flags that can be set in the
+// config file.
+func configKeys() []string {
+	var keys []string
+
+	collectFlagNames(rootCmd, &keys)
+	slices.Sort(keys)
+
+	return slices.DeleteFunc(slices.Compact(keys), func(key string) bool {
+		return lookupConfigFlag(key) == nil
+	})
+}
+
+// collectFlagNames appends the names of the local flags of cmd and its
+// subcommands to names.
+func collectFlagNames(cmd *cobra.Command, names *[]string) {
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		*names = append(*names, flag.Name)
+	})
+
+	for _, sub := range configurableCommands(cmd) {
+		collectFlagNames(sub, names)
+	}
+}
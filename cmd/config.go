@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"switchtube-downloader/internal/config"
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/token"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// configSetArgs is the number of arguments of the config set command.
+	configSetArgs = 2
+
+	// bestQuality is the answer of the config init wizard keeping the default
+	// variant selection.
+	bestQuality = "best"
+)
+
+var errUnknownConfigKey = errors.New("unknown config key")
+
+// init initializes the config command and its subcommands, adding them to the
+// root command.
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configInitCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration file",
+	Long:  "Read and write the default flag values stored in the configuration file",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a config key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		key := currentFlagName(args[0])
+
+		values, ok, err := cfg.Get(key)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		} else if !ok {
+			fmt.Printf("%s is not set\n", key)
+
+			return nil
+		}
+
+		fmt.Println(strings.Join(values, ","))
+
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set the value of a config key",
+	Long: "Set the default value of a flag in the configuration file.\n" +
+		"The key is the long name of any flag, e.g. 'config set output ~/Videos'.",
+	Args: cobra.ExactArgs(configSetArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := currentFlagName(args[0]), args[1]
+
+		flag := lookupConfigFlag(key)
+		if flag == nil {
+			return fmt.Errorf("%w: %s", errUnknownConfigKey, key)
+		}
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := cfg.Set(key, value, flag.Value.Type()); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		fmt.Printf("Set %s in %s\n", key, cfg.Path())
+
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the configuration file",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("# %s\n", cfg.Path())
+
+		if err := cfg.Encode(os.Stdout); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create the configuration file interactively",
+	Long: "Ask for the most common settings and write them to the configuration file.\n" +
+		"Existing values are kept if the answer is left empty.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if !ui.InputEnabled() {
+			return fmt.Errorf("%w: use 'config set' instead", ui.ErrInputDisabled)
+		}
+
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := promptOutput(cfg); err != nil {
+			return err
+		}
+
+		if err := promptQuality(cfg); err != nil {
+			return err
+		}
+
+		if err := promptWebhook(cfg); err != nil {
+			return err
+		}
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		fmt.Printf("Saved configuration to %s\n", cfg.Path())
+
+		if !ui.Confirm("Store your SwitchTube access token now?") {
+			return nil
+		}
+
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := tokenMgr.Set(); err != nil && !errors.Is(err, token.ErrTokenAlreadyExists) {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+// promptValue asks for a value of key, showing its current value from cfg,
+// which is kept if the answer is empty.
+func promptValue(cfg *config.Config, label, key string) string {
+	current := ""
+	if values, ok, err := cfg.Get(key); err == nil && ok {
+		current = strings.Join(values, ",")
+	}
+
+	if current != "" {
+		label = fmt.Sprintf("%s [%s]", label, current)
+	}
+
+	if answer := ui.Input(label + ": "); answer != "" {
+		return answer
+	}
+
+	return current
+}
+
+// promptOutput asks for the default output directory.
+func promptOutput(cfg *config.Config) error {
+	output := promptValue(cfg, "Default output directory (empty for the current directory)", "output")
+	if output == "" {
+		return nil
+	}
+
+	return setConfigValue(cfg, "output", output, "string")
+}
+
+// promptQuality asks which variant to download by default, which is either
+// the best one, the smallest or largest one, or the best one up to a maximum
+// height, and replaces any previous choice.
+func promptQuality(cfg *config.Config) error {
+	for {
+		answer := ui.Input(
+			"Quality (best, smallest, largest or a maximum height such as 720) [best]: ",
+		)
+
+		answer = strings.ToLower(answer)
+		if answer == "" {
+			return nil
+		}
+
+		height, err := strconv.Atoi(answer)
+		if answer != bestQuality && answer != "smallest" && answer != "largest" &&
+			(err != nil || height <= 0) {
+			fmt.Fprintf(os.Stderr, "Invalid quality: %s\n", answer)
+
+			continue
+		}
+
+		for _, key := range []string{"smallest", "largest", "max-height"} {
+			cfg.Unset(key)
+		}
+
+		switch answer {
+		case bestQuality:
+			return nil
+		case "smallest", "largest":
+			return setConfigValue(cfg, answer, "true", "bool")
+		default:
+			return setConfigValue(cfg, "max-height", answer, "int")
+		}
+	}
+}
+
+// promptWebhook asks for the URL notified after each download.
+func promptWebhook(cfg *config.Config) error {
+	for {
+		webhook := promptValue(cfg, "Webhook URL to notify after downloads (empty for none)",
+			"notify-webhook")
+		if webhook == "" {
+			return nil
+		}
+
+		if validWebhookURL(webhook) {
+			return setConfigValue(cfg, "notify-webhook", webhook, "string")
+		}
+
+		fmt.Fprintf(os.Stderr, "%s: %s\n", errInvalidWebhookURL, webhook)
+	}
+}
+
+// setConfigValue stores value for key in cfg.
+func setConfigValue(cfg *config.Config, key, value, flagType string) error {
+	if err := cfg.Set(key, value, flagType); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// lookupConfigFlag returns the flag of any command that can be configured
+// with key or nil if there is none.
+func lookupConfigFlag(key string) *pflag.Flag {
+	if key == "config" || key == "help" {
+		return nil
+	}
+
+	return lookupFlag(rootCmd, key)
+}
+
+// lookupFlag searches cmd and all its subcommands for a flag named name.
+func lookupFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	if flag := cmd.LocalFlags().Lookup(name); flag != nil {
+		return flag
+	}
+
+	for _, sub := range configurableCommands(cmd) {
+		if flag := lookupFlag(sub, name); flag != nil {
+			return flag
+		}
+	}
+
+	return nil
+}
+
+// configurableCommands returns the subcommands of cmd whose flags can be set
+// in the config file, which excludes hidden commands and the generated
+// completion command.
+func configurableCommands(cmd *cobra.Command) []*cobra.Command {
+	var commands []*cobra.Command
+
+	for _, sub := range cmd.Commands() {
+		if !sub.Hidden && sub.Name() != "completion" {
+			commands = append(commands, sub)
+		}
+	}
+
+	return commands
+}
@@ -1,83 +1,300 @@
 package cmd
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/helper/ui"
 	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/schedule"
 )
 
+var (
+	errPrintURLsWatch    = errors.New("--print-urls can't be combined with --watch or --schedule")
+	errScheduleInterval  = errors.New("--schedule and --interval cannot be used together")
+	errStdoutSingleVideo = errors.New(
+		"-o - writes a single video and can't be used with several inputs, --watch or --schedule",
+	)
+	errWatchSingleChannel = errors.New("--watch and --schedule support a single channel")
+)
+
+// defaultWatchInterval is the default time between two checks in watch mode.
+const defaultWatchInterval = 30 * time.Minute
+
 // init initializes the download command and adds it to the root command with
 // its flags.
 func init() {
 	rootCmd.AddCommand(downloadCmd)
 	downloadCmd.Flags().
 		BoolP("episode", "e", false, "Prefixes the video with episode-number e.g. 01_OR_Mapping.mp4")
-	downloadCmd.Flags().BoolP("skip", "s", false, "Skip video if it already exists")
-	downloadCmd.Flags().BoolP("force", "f", false, "Force overwrite if file already exist")
+	addExistingFileFlags(downloadCmd)
 	downloadCmd.Flags().BoolP("all", "a", false, "Download the whole content of a channel")
-	downloadCmd.Flags().StringP("output", "o", "", "Output directory for downloaded files")
+	downloadCmd.Flags().
+		Bool("no-size", false, "Don't fetch the size of every video for the selection list (faster)")
+	downloadCmd.Flags().
+		StringP("output", "o", "", "Output directory for downloaded files, or - to write a video to stdout")
+	downloadCmd.Flags().
+		BoolP("watch", "w", false, "Keep running and download new videos of a channel periodically")
+	downloadCmd.Flags().
+		Duration("interval", defaultWatchInterval, "Time between two checks in watch mode")
+	addScheduleFlag(downloadCmd)
+	downloadCmd.Flags().
+		Bool("new-only", false, "Only download the videos of a channel published since the last run")
+	downloadCmd.Flags().
+		Bool("mirror", false, "Download all new videos of a channel and list local files no longer in it")
+	downloadCmd.Flags().
+		Bool("prune", false, "With --mirror, delete the files no longer in the channel after confirming")
+	downloadCmd.Flags().
+		String("prune-to", "", "With --mirror, move the files no longer in the channel to this folder")
+	downloadCmd.Flags().
+		Bool("print-urls", false, "Print the media URLs and the required header instead of downloading")
+	addOrderFlags(downloadCmd)
+	addProgressFlag(downloadCmd)
+	addFilenameFlags(downloadCmd)
+	addPostProcessFlags(downloadCmd)
+	addSizeLimitFlags(downloadCmd)
+	addVariantFlags(downloadCmd)
+	addExternalDownloaderFlag(downloadCmd)
+	addExecBeforeFlag(downloadCmd)
+	addTimeoutFlags(downloadCmd)
+	addWaitLockFlag(downloadCmd)
+	addRetryFlags(downloadCmd)
 }
 
 var downloadCmd = &cobra.Command{
-	Use:   "download <id|url>",
+	Use:   "download <id|url>...",
 	Short: "Download a video or channel",
 	Long: "Download a video or channel. Automatically detects if input is a video or channel.\n" +
-		"You can also pass the whole URL instead of the ID for convenience.",
-	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		episode, err := cmd.Flags().GetBool("episode")
+		"You can also pass the whole URL instead of the ID for convenience, and several\n" +
+		"videos or channels at once.\n" +
+		"With --watch, a channel is checked every --interval and new videos are downloaded,\n" +
+		"with --schedule whenever the cron expression is due.",
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{mediaArgsAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := downloadConfig(cmd, args[0])
 		if err != nil {
-			fmt.Printf("Error getting episode flag: %v", err)
+			return err
+		}
 
-			return
+		watch, err := cmd.Flags().GetBool("watch")
+		if err != nil {
+			return fmt.Errorf("%w: watch: %w", errFailedToGetFlag, err)
 		}
 
-		skip, err := cmd.Flags().GetBool("skip")
+		interval, err := cmd.Flags().GetDuration("interval")
 		if err != nil {
-			fmt.Printf("Error getting skip flag: %v", err)
+			return fmt.Errorf("%w: interval: %w", errFailedToGetFlag, err)
+		}
 
-			return
+		sched, err := scheduleFlag(cmd)
+		if err != nil {
+			return err
+		} else if sched != nil && cmd.Flags().Changed("interval") {
+			return errScheduleInterval
 		}
 
-		force, err := cmd.Flags().GetBool("force")
+		watch = watch || sched != nil
+
+		printURLs, err := cmd.Flags().GetBool("print-urls")
 		if err != nil {
-			fmt.Printf("Error getting force flag: %v", err)
+			return fmt.Errorf("%w: print-urls: %w", errFailedToGetFlag, err)
+		}
 
-			return
+		if config.Output == models.OutputStdout && (watch || len(args) > 1) {
+			return errStdoutSingleVideo
 		}
 
-		all, err := cmd.Flags().GetBool("all")
+		client, err := newClient(cmd)
 		if err != nil {
-			fmt.Printf("Error getting all flag: %v", err)
+			return err
+		}
 
-			return
+		if printURLs {
+			if watch {
+				return errPrintURLsWatch
+			}
+
+			return printMediaURLs(cmd.Context(), client, config, args)
 		}
 
-		output, err := cmd.Flags().GetString("output")
-		if err != nil {
-			fmt.Printf("Error getting output flag: %v", err)
+		if watch {
+			if len(args) > 1 {
+				return errWatchSingleChannel
+			}
 
-			return
+			return runWatch(client, config, interval, sched)
 		}
 
-		config := models.DownloadConfig{
-			Media:      args[0],
-			UseEpisode: episode,
-			Skip:       skip,
-			Force:      force,
-			All:        all,
-			Output:     strings.TrimSpace(output),
+		return downloadMedia(cmd, client, config, args)
+	},
+}
+
+// downloadMedia downloads every video or channel in media one after the other
+// within one run timeout, each with the overrides of its channel. A failed
+// download doesn't stop the others; the error is ErrPartialFailure if some of
+// them failed, or the first error if all did.
+func downloadMedia(
+	cmd *cobra.Command,
+	client *download.Client,
+	config models.DownloadConfig,
+	media []string,
+) error {
+	if len(media) == 1 {
+		if err := download.Download(cmd.Context(), client, config); err != nil {
+			return fmt.Errorf("%w", err)
 		}
 
-		err = download.Download(config)
-		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+		return nil
+	}
+
+	ctx, cancel := download.WithRunTimeout(cmd.Context(), config)
+	defer cancel()
+
+	var firstErr error
+
+	failed := 0
+
+	for i, item := range media {
+		fmt.Fprintf(os.Stderr, "\n[%d/%d] %s\n", i+1, len(media), item)
 
-			return
+		if err := downloadItem(ctx, cmd, client, item); err != nil {
+			slog.Error("failed to download", "media", item, "error", err)
+
+			firstErr = cmp.Or(firstErr, err)
+			failed++
 		}
-	},
+	}
+
+	// With --json, stdout only holds the JSON summary of every download
+	if !config.JSON {
+		complete := fmt.Sprintf("Download complete! %d/%d videos and channels successful",
+			len(media)-failed, len(media))
+		fmt.Printf("\n%s\n", ui.Outcome(complete, failed == 0))
+	}
+
+	switch failed {
+	case 0:
+		return nil
+	case len(media):
+		return fmt.Errorf("%w", firstErr)
+	default:
+		return fmt.Errorf("%w: %d of %d", download.ErrPartialFailure, failed, len(media))
+	}
+}
+
+// downloadItem downloads the video or channel media of a run for several of
+// them, with the overrides of its channel.
+func downloadItem(
+	ctx context.Context,
+	cmd *cobra.Command,
+	client *download.Client,
+	media string,
+) error {
+	if err := reapplyConfig(cmd, media); err != nil {
+		return err
+	}
+
+	config, err := downloadConfig(cmd, media)
+	if err != nil {
+		return err
+	}
+
+	if err := download.Download(ctx, client, config); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// printMediaURLs prints the media URLs of every video or channel in media, one
+// per line, and the header they have to be requested with, or all of them as
+// JSON.
+func printMediaURLs(
+	ctx context.Context,
+	client *download.Client,
+	config models.DownloadConfig,
+	media []string,
+) error {
+	urls := make([]models.MediaURL, 0, len(media))
+
+	for _, item := range media {
+		config.Media = item
+
+		resolved, err := download.MediaURLs(ctx, client, config)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		urls = append(urls, resolved...)
+	}
+
+	if config.JSON {
+		return printJSON(urls)
+	}
+
+	if len(urls) == 0 {
+		return nil
+	}
+
+	// The header is the same for every URL and isn't part of the data
+	for key, value := range urls[0].Header {
+		fmt.Fprintf(os.Stderr, "Request the URLs with the header: %s: %s\n", key, value)
+	}
+
+	for _, mediaURL := range urls {
+		fmt.Println(mediaURL.URL)
+	}
+
+	return nil
+}
+
+// runWatch runs the watch mode, syncing on sched if it isn't nil and every
+// interval otherwise, until the process receives SIGINT or SIGTERM, in which
+// case errInterrupted is returned. A second signal aborts immediately.
+func runWatch(
+	client *download.Client,
+	config models.DownloadConfig,
+	interval time.Duration,
+	sched *schedule.Schedule,
+) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-signals
+		// Restore the default behavior so a second signal terminates immediately
+		signal.Stop(signals)
+		fmt.Fprintln(os.Stderr,
+			"\nStopping watch mode after the current run (press Ctrl+C again to abort)")
+		cancel()
+	}()
+
+	watch := func() error { return download.Watch(ctx, client, config, interval) }
+	if sched != nil {
+		watch = func() error { return download.WatchSchedule(ctx, client, config, sched) }
+	}
+
+	if err := watch(); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if ctx.Err() != nil {
+		return errInterrupted
+	}
+
+	return nil
 }
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/token"
+)
+
+// Exit codes of the application, so that scripts can react to the kind of
+// failure.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitAuth        = 2
+	exitNotFound    = 3
+	exitPartial     = 4
+	exitInterrupted = 130
+)
+
+var errInterrupted = errors.New("interrupted")
+
+// exitCode returns the exit code for the error returned by a command.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errInterrupted):
+		return exitInterrupted
+	case errors.Is(err, download.ErrUnauthorized), errors.Is(err, token.ErrNoTokenFound):
+		return exitAuth
+	case errors.Is(err, download.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, download.ErrPartialFailure):
+		return exitPartial
+	default:
+		return exitError
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/token"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: exitOK},
+		{name: "interrupted", err: errInterrupted, want: exitInterrupted},
+		{
+			name: "unauthorized",
+			err:  fmt.Errorf("failed to download channel: %w", download.ErrUnauthorized),
+			want: exitAuth,
+		},
+		{
+			name: "no token",
+			err:  fmt.Errorf("failed to get token: %w", token.ErrNoTokenFound),
+			want: exitAuth,
+		},
+		{
+			name: "not found",
+			err:  fmt.Errorf("failed to download video: %w", download.ErrNotFound),
+			want: exitNotFound,
+		},
+		{
+			// All videos of the channel failed, so the cause is returned
+			name: "every video unauthorized",
+			err:  fmt.Errorf("failed to download channel: failed to download video: %w", download.ErrUnauthorized),
+			want: exitAuth,
+		},
+		{
+			name: "partial failure",
+			err:  fmt.Errorf("%w: 1 of 3", download.ErrPartialFailure),
+			want: exitPartial,
+		},
+		{name: "other error", err: errors.New("disk full"), want: exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
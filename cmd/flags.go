@@ -0,0 +1,623 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/failures"
+	"switchtube-downloader/internal/helper/dir"
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/history"
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/postprocess"
+	"switchtube-downloader/internal/schedule"
+)
+
+var (
+	errFailedToGetFlag       = errors.New("failed to get flag")
+	errInvalidFlag           = errors.New("invalid flag value")
+	errInvalidMaxHeight      = errors.New("invalid --max-height, it must not be negative")
+	errInvalidAudioFormat    = errors.New("invalid audio format")
+	errInvalidConflictPolicy = errors.New("invalid conflict policy")
+	errInvalidDownloader     = errors.New("invalid external downloader")
+	errInvalidOrder          = errors.New("invalid order")
+	errInvalidProgressFormat = errors.New("invalid progress format")
+	errInvalidProgressStyle  = errors.New("invalid progress style")
+	errInvalidRemuxContainer = errors.New("invalid remux container")
+	errInvalidSizeLimits     = errors.New("--min-filesize is larger than --max-filesize")
+	errInvalidWebhookURL     = errors.New("invalid webhook url, it must start with http:// or https://")
+	errSkipAndForce          = errors.New("--skip-existing and --force cannot be used together")
+	errIPv4AndIPv6           = errors.New("--force-ipv4 and --force-ipv6 cannot be used together")
+	errSmallestAndLargest    = errors.New("--smallest and --largest cannot be used together")
+	errPruneWithoutMirror    = errors.New("--prune and --prune-to require --mirror")
+	errStdoutOutput          = errors.New(
+		"-o - writes the video to stdout and can't be combined with --json, --progress json, " +
+			"post-processing, --external-downloader, --write-nfo or --playlist",
+	)
+)
+
+// progressFormats are the valid values of the --progress flag.
+var progressFormats = []string{models.ProgressFormatBar, models.ProgressFormatJSON}
+
+// progressStyles are the valid values of the --progress-style flag.
+var progressStyles = []string{
+	models.ProgressStyleDefault,
+	models.ProgressStyleASCII,
+	models.ProgressStyleUnicode,
+	models.ProgressStyleBraille,
+	models.ProgressStyleMinimal,
+}
+
+// conflictPolicies are the valid values of the --on-conflict flag.
+var conflictPolicies = []string{
+	models.ConflictPrompt,
+	models.ConflictSkip,
+	models.ConflictOverwrite,
+	models.ConflictRename,
+}
+
+// videoOrders are the valid values of the --order flag.
+var videoOrders = []string{models.OrderAPI, models.OrderEpisode, models.OrderDate, models.OrderTitle}
+
+// remuxContainers are the valid values of the --remux flag.
+var remuxContainers = []string{models.RemuxMKV, models.RemuxMP4}
+
+// audioFormats are the valid values of the --audio-format flag.
+var audioFormats = []string{models.AudioMP3, models.AudioM4A}
+
+// externalDownloaders are the valid values of the --external-downloader flag.
+var externalDownloaders = []string{models.ExternalAria2c, models.ExternalCurl}
+
+// addExistingFileFlags adds the flags deciding what happens to existing files
+// to cmd. --skip is a hidden alias of --skip-existing that keeps command lines,
+// config files and environment variables using the former name working.
+func addExistingFileFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("skip-existing", "s", false,
+		"Skip videos that already exist without prompting (cannot be combined with --force)")
+	cmd.Flags().BoolP("force", "f", false, "Force overwrite if file already exist")
+	cmd.Flags().AddFlag(&pflag.Flag{
+		Name:        "skip",
+		Usage:       "Alias of --skip-existing",
+		Value:       cmd.Flags().Lookup("skip-existing").Value,
+		DefValue:    "false",
+		NoOptDefVal: "true",
+		Hidden:      true,
+	})
+	addConflictFlag(cmd)
+	addIfChangedFlag(cmd)
+}
+
+// addIfChangedFlag adds the --if-changed flag to cmd.
+func addIfChangedFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("if-changed", false,
+		"Download existing videos again only if their size or publication date changed")
+}
+
+// checkExclusiveFlags returns an error if both --skip-existing and --force,
+// or both --force-ipv4 and --force-ipv6, are given on the command line.
+// Values from the config file don't count, so --force still overrides
+// skip = true there, like clearOpposingFlags does for the network.
+func checkExclusiveFlags(cmd *cobra.Command) error {
+	skip := cmd.Flags().Changed("skip-existing") || cmd.Flags().Changed("skip")
+	if skip && cmd.Flags().Changed("force") {
+		return errSkipAndForce
+	}
+
+	if cmd.Flags().Changed("force-ipv4") && cmd.Flags().Changed("force-ipv6") {
+		return errIPv4AndIPv6
+	}
+
+	return nil
+}
+
+// opposingFlags maps boolean flags to the flag they rule out.
+var opposingFlags = map[string]string{
+	"force-ipv4": "force-ipv6",
+	"force-ipv6": "force-ipv4",
+}
+
+// clearOpposingFlags turns the flags ruled out by flags given on the command
+// line off, so that their values from the config file or the environment,
+// which are applied afterwards, don't override the command line, e.g.
+// force-ipv4 = true in the config file when --force-ipv6 is given.
+func clearOpposingFlags(cmd *cobra.Command) error {
+	for _, name := range slices.Sorted(maps.Keys(opposingFlags)) {
+		opposing := cmd.Flags().Lookup(opposingFlags[name])
+		if !cmd.Flags().Changed(name) || opposing == nil || opposing.Changed {
+			continue
+		}
+
+		if err := cmd.Flags().Set(opposing.Name, "false"); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+
+	return nil
+}
+
+// addConflictFlag adds the --on-conflict flag to cmd.
+func addConflictFlag(cmd *cobra.Command) {
+	cmd.Flags().String("on-conflict", models.ConflictPrompt,
+		"What to do with existing files: prompt, skip, overwrite or rename (append a counter)")
+}
+
+// addFilenameFlags adds the flags controlling file and folder names to cmd.
+// --windows-safe is enabled by default on Windows.
+func addFilenameFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("windows-safe", runtime.GOOS == "windows",
+		"Make file and folder names valid on Windows (reserved names, trailing dots)")
+	cmd.Flags().Bool("slug", false,
+		"Use portable ASCII file and folder names (ö becomes oe, é becomes e)")
+	cmd.Flags().String("output-template", models.DefaultOutputTemplate,
+		"Folders of a channel inside the output directory: {profile}, {channel} or {channel_id}")
+	cmd.Flags().Bool("long-paths", false,
+		"Allow paths longer than 260 characters on Windows instead of shortening titles")
+}
+
+// addPostProcessFlags adds the flags of the steps that run after videos have
+// been downloaded to cmd.
+func addPostProcessFlags(cmd *cobra.Command) {
+	cmd.Flags().String("remux", "",
+		"Remux downloaded videos losslessly to "+strings.Join(remuxContainers, " or ")+
+			" (requires ffmpeg)")
+	cmd.Flags().Bool("extract-audio", false,
+		"Extract the audio of downloaded videos and remove the videos (requires ffmpeg)")
+	cmd.Flags().String("audio-format", models.AudioMP3,
+		"Format of extracted audio: "+strings.Join(audioFormats, " or "))
+	cmd.Flags().Bool("keep-video", false, "Keep videos after extracting their audio")
+	cmd.Flags().Bool("playlist", false,
+		"Write playlist.m3u8 with the videos of a channel in episode order")
+	cmd.Flags().Bool("write-nfo", false,
+		"Write tvshow.nfo for a channel and an NFO file for every video for Kodi and Jellyfin")
+	cmd.Flags().Bool("no-history", false, "Don't record the downloads in the download history")
+	cmd.Flags().String("notify-webhook", "",
+		"URL to POST a JSON summary to when the download of a channel completes")
+}
+
+// addTimeoutFlags adds the flags limiting how long downloads may take to cmd.
+func addTimeoutFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("video-timeout", 0,
+		"Abort the download of a video after this long, e.g. 30m (0 for no limit)")
+	cmd.Flags().Duration("run-timeout", 0,
+		"Abort the whole run after this long, e.g. 6h (0 for no limit)")
+}
+
+// addScheduleFlag adds the --schedule flag, which keeps cmd running and syncs
+// a channel according to a cron expression, to cmd.
+func addScheduleFlag(cmd *cobra.Command) {
+	cmd.Flags().String("schedule", "",
+		"Keep running and sync the channel on a cron schedule, e.g. \"0 3 * * *\" for 3:00 daily")
+}
+
+// scheduleFlag returns the schedule given by --schedule, or nil if there is
+// none.
+func scheduleFlag(cmd *cobra.Command) (*schedule.Schedule, error) {
+	expression, err := stringFlag(cmd, "schedule")
+	if err != nil || expression == "" {
+		return nil, err
+	}
+
+	sched, err := schedule.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: schedule: %w", errInvalidFlag, err)
+	}
+
+	return sched, nil
+}
+
+// addWaitLockFlag adds the --wait-lock flag to cmd.
+func addWaitLockFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("wait-lock", false,
+		"Wait for another instance downloading into the same folder instead of failing")
+}
+
+// addRetryFlags adds the flags controlling how failed downloads are retried to
+// cmd.
+func addRetryFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("retry-passes", 1,
+		"Number of times the failed videos of a channel are retried at the end (0 to disable)")
+	cmd.Flags().String("failures-file", "",
+		"File failed downloads are recorded in for the retry command (default is "+
+			"$HOME/.config/switchtube-dl/failures.jsonl)")
+}
+
+// addExternalDownloaderFlag adds the --external-downloader flag to cmd.
+func addExternalDownloaderFlag(cmd *cobra.Command) {
+	cmd.Flags().String("external-downloader", "",
+		"Download videos with "+strings.Join(externalDownloaders, " or ")+
+			" instead of the built-in downloader, e.g. for segmented downloads")
+}
+
+// addExecBeforeFlag adds the --exec-before flag to cmd.
+func addExecBeforeFlag(cmd *cobra.Command) {
+	cmd.Flags().String("exec-before", "",
+		"Shell command run before every video with its metadata as JSON on stdin, "+
+			"skipping the video if it exits non-zero")
+}
+
+// addVariantFlags adds the flags choosing the variant of a video that is
+// downloaded by size or resolution to cmd.
+func addVariantFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("smallest", false,
+		"Download the smallest variant of every video by file size, e.g. to preview it")
+	cmd.Flags().Bool("largest", false,
+		"Download the largest variant of every video by file size, e.g. to archive it")
+	cmd.Flags().Int("max-height", 0,
+		"Download the variant with the highest resolution up to this height, e.g. 720 (0 for any)")
+}
+
+// addSizeLimitFlags adds the flags skipping videos by their size to cmd.
+func addSizeLimitFlags(cmd *cobra.Command) {
+	cmd.Flags().String("min-filesize", "",
+		"Skip videos smaller than this size, e.g. 10M (K, M, G and T are binary units)")
+	cmd.Flags().String("max-filesize", "",
+		"Skip videos larger than this size, e.g. 1.5G, to save a metered connection")
+}
+
+// addOrderFlags adds the flags controlling the order of the videos of a
+// channel to cmd.
+func addOrderFlags(cmd *cobra.Command) {
+	cmd.Flags().String("order", models.OrderAPI,
+		"Order of the videos of a channel: "+strings.Join(videoOrders, ", ")+
+			" (api keeps the order of SwitchTube)")
+	cmd.Flags().Bool("reverse", false, "Reverse the order of the videos of a channel")
+}
+
+// addProgressFlag adds the --progress and --progress-style flags to cmd.
+func addProgressFlag(cmd *cobra.Command) {
+	cmd.Flags().String("progress", models.ProgressFormatBar,
+		"Progress output: "+strings.Join(progressFormats, " or ")+" (newline-delimited JSON events)")
+	cmd.Flags().String("progress-style", models.ProgressStyleDefault,
+		"Style of the progress bar: "+strings.Join(progressStyles, ", ")+
+			" (percentage and speed only)")
+}
+
+// downloadConfig creates the download configuration for media from the flags
+// of cmd. Flags the command doesn't have keep their zero value.
+func downloadConfig(cmd *cobra.Command, media string) (models.DownloadConfig, error) {
+	var config models.DownloadConfig
+
+	config.Media = media
+
+	var err error
+
+	for _, flag := range []struct {
+		name   string
+		target *bool
+	}{
+		{name: "episode", target: &config.UseEpisode},
+		{name: "skip-existing", target: &config.Skip},
+		{name: "force", target: &config.Force},
+		{name: "all", target: &config.All},
+		{name: "if-changed", target: &config.IfChanged},
+		{name: "mirror", target: &config.Mirror},
+		{name: "reverse", target: &config.Reverse},
+		{name: "new-only", target: &config.NewOnly},
+		{name: "prune", target: &config.Prune},
+		{name: "json", target: &config.JSON},
+		{name: "no-size", target: &config.NoSize},
+		{name: "windows-safe", target: &config.WindowsSafe},
+		{name: "slug", target: &config.Slug},
+		{name: "long-paths", target: &config.LongPaths},
+		{name: "extract-audio", target: &config.ExtractAudio},
+		{name: "keep-video", target: &config.KeepVideo},
+		{name: "playlist", target: &config.Playlist},
+		{name: "write-nfo", target: &config.WriteNFO},
+		{name: "smallest", target: &config.Smallest},
+		{name: "largest", target: &config.Largest},
+		{name: "wait-lock", target: &config.WaitLock},
+	} {
+		if *flag.target, err = boolFlag(cmd, flag.name); err != nil {
+			return config, err
+		}
+	}
+
+	for _, flag := range []struct {
+		name   string
+		target *string
+	}{
+		{name: "output", target: &config.Output},
+		{name: "output-template", target: &config.OutputTemplate},
+		{name: "progress", target: &config.ProgressFormat},
+		{name: "progress-style", target: &config.ProgressStyle},
+		{name: "on-conflict", target: &config.OnConflict},
+		{name: "remux", target: &config.Remux},
+		{name: "audio-format", target: &config.AudioFormat},
+		{name: "notify-webhook", target: &config.NotifyWebhook},
+		{name: "prune-to", target: &config.PruneTo},
+		{name: "order", target: &config.Order},
+		{name: "external-downloader", target: &config.ExternalDownloader},
+		{name: "exec-before", target: &config.ExecBefore},
+	} {
+		if *flag.target, err = stringFlag(cmd, flag.name); err != nil {
+			return config, err
+		}
+	}
+
+	for _, flag := range []struct {
+		name   string
+		target *time.Duration
+	}{
+		{name: "video-timeout", target: &config.VideoTimeout},
+		{name: "run-timeout", target: &config.RunTimeout},
+	} {
+		if *flag.target, err = durationFlag(cmd, flag.name); err != nil {
+			return config, err
+		}
+	}
+
+	if config.RetryPasses, err = intFlag(cmd, "retry-passes"); err != nil {
+		return config, err
+	}
+
+	if config.MaxHeight, err = intFlag(cmd, "max-height"); err != nil {
+		return config, err
+	}
+
+	if config.MinFilesize, config.MaxFilesize, err = sizeLimits(cmd); err != nil {
+		return config, err
+	}
+
+	config.Output = strings.TrimSpace(config.Output)
+
+	if config.History, err = historyPath(cmd); err != nil {
+		return config, err
+	}
+
+	if config.Failures, err = failuresPath(cmd); err != nil {
+		return config, err
+	}
+
+	return config, checkDownloadConfig(cmd, config)
+}
+
+// checkDownloadConfig returns an error if config, read from the flags of cmd,
+// has an unsupported value or hands downloads to an external downloader that
+// can't apply the network settings of the client.
+func checkDownloadConfig(cmd *cobra.Command, config models.DownloadConfig) error {
+	if err := validateDownloadConfig(config); err != nil || config.ExternalDownloader == "" {
+		return err
+	}
+
+	network, err := clientConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := download.CheckExternalNetwork(config.ExternalDownloader, network); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// historyPath returns the path of the history file downloads of cmd are
+// recorded in, or an empty string if cmd doesn't download or --no-history is
+// set.
+func historyPath(cmd *cobra.Command) (string, error) {
+	if cmd.Flags().Lookup("no-history") == nil {
+		return "", nil
+	}
+
+	noHistory, err := boolFlag(cmd, "no-history")
+	if err != nil || noHistory {
+		return "", err
+	}
+
+	path, err := history.DefaultPath()
+	if err != nil {
+		slog.Warn("download history is disabled", "error", err)
+
+		return "", nil
+	}
+
+	return path, nil
+}
+
+// failuresPath returns the path of the file failed downloads of cmd are
+// recorded in, or an empty string if cmd doesn't download.
+func failuresPath(cmd *cobra.Command) (string, error) {
+	path, err := stringFlag(cmd, "failures-file")
+	if err != nil || path != "" || cmd.Flags().Lookup("failures-file") == nil {
+		return path, err
+	}
+
+	if path, err = failures.DefaultPath(); err != nil {
+		slog.Warn("recording failed downloads is disabled", "error", err)
+
+		return "", nil
+	}
+
+	return path, nil
+}
+
+// validateDownloadConfig returns an error if a flag of config has a value
+// that isn't supported.
+func validateDownloadConfig(config models.DownloadConfig) error {
+	for _, flag := range []struct {
+		value   string
+		allowed []string
+		err     error
+	}{
+		{value: config.ProgressFormat, allowed: progressFormats, err: errInvalidProgressFormat},
+		{value: config.ProgressStyle, allowed: progressStyles, err: errInvalidProgressStyle},
+		{value: config.OnConflict, allowed: conflictPolicies, err: errInvalidConflictPolicy},
+		{value: config.Remux, allowed: remuxContainers, err: errInvalidRemuxContainer},
+		{value: config.AudioFormat, allowed: audioFormats, err: errInvalidAudioFormat},
+		{value: config.Order, allowed: videoOrders, err: errInvalidOrder},
+		{value: config.ExternalDownloader, allowed: externalDownloaders, err: errInvalidDownloader},
+	} {
+		if flag.value != "" && !slices.Contains(flag.allowed, flag.value) {
+			return fmt.Errorf("%w: %s", flag.err, flag.value)
+		}
+	}
+
+	if config.NotifyWebhook != "" && !validWebhookURL(config.NotifyWebhook) {
+		return fmt.Errorf("%w: %s", errInvalidWebhookURL, config.NotifyWebhook)
+	}
+
+	if err := validateCombinations(config); err != nil {
+		return err
+	}
+
+	if err := dir.ValidateOutputTemplate(config.OutputTemplate); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return checkTools(config)
+}
+
+// checkTools returns an error if a tool config requires isn't installed.
+func checkTools(config models.DownloadConfig) error {
+	if err := postprocess.CheckFFmpeg(config); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if err := download.CheckExternalDownloader(config); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// validWebhookURL reports whether rawURL is an http or https URL.
+func validWebhookURL(rawURL string) bool {
+	webhook, err := url.Parse(rawURL)
+
+	return err == nil && (webhook.Scheme == "http" || webhook.Scheme == "https")
+}
+
+// validateCombinations returns an error if files are pruned outside of mirror
+// mode, if the variant to download is requested inconsistently or if a video
+// written to stdout would be mixed with other output or needs a file.
+func validateCombinations(config models.DownloadConfig) error {
+	if (config.Prune || config.PruneTo != "") && !config.Mirror {
+		return errPruneWithoutMirror
+	}
+
+	if config.Smallest && config.Largest {
+		return errSmallestAndLargest
+	}
+
+	if config.MaxHeight < 0 {
+		return fmt.Errorf("%w: %d", errInvalidMaxHeight, config.MaxHeight)
+	}
+
+	if config.Output != models.OutputStdout {
+		return nil
+	}
+
+	if config.JSON || config.ProgressFormat == models.ProgressFormatJSON ||
+		postprocess.Enabled(config) || config.ExternalDownloader != "" ||
+		config.WriteNFO || config.Playlist {
+		return errStdoutOutput
+	}
+
+	return nil
+}
+
+// boolFlag returns the value of the bool flag name or false if cmd doesn't
+// have it.
+func boolFlag(cmd *cobra.Command, name string) (bool, error) {
+	if cmd.Flags().Lookup(name) == nil {
+		return false, nil
+	}
+
+	value, err := cmd.Flags().GetBool(name)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s: %w", errFailedToGetFlag, name, err)
+	}
+
+	return value, nil
+}
+
+// stringFlag returns the value of the string flag name or an empty string if
+// cmd doesn't have it.
+func stringFlag(cmd *cobra.Command, name string) (string, error) {
+	if cmd.Flags().Lookup(name) == nil {
+		return "", nil
+	}
+
+	value, err := cmd.Flags().GetString(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", errFailedToGetFlag, name, err)
+	}
+
+	return value, nil
+}
+
+// durationFlag returns the value of the duration flag name or zero if cmd
+// doesn't have it.
+func durationFlag(cmd *cobra.Command, name string) (time.Duration, error) {
+	if cmd.Flags().Lookup(name) == nil {
+		return 0, nil
+	}
+
+	value, err := cmd.Flags().GetDuration(name)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", errFailedToGetFlag, name, err)
+	}
+
+	return value, nil
+}
+
+// intFlag returns the value of the int flag name or zero if cmd doesn't have
+// it.
+func intFlag(cmd *cobra.Command, name string) (int, error) {
+	if cmd.Flags().Lookup(name) == nil {
+		return 0, nil
+	}
+
+	value, err := cmd.Flags().GetInt(name)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", errFailedToGetFlag, name, err)
+	}
+
+	return value, nil
+}
+
+// sizeLimits returns the sizes given by --min-filesize and --max-filesize.
+func sizeLimits(cmd *cobra.Command) (int64, int64, error) {
+	minSize, err := sizeFlag(cmd, "min-filesize")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	maxSize, err := sizeFlag(cmd, "max-filesize")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if maxSize > 0 && minSize > maxSize {
+		return 0, 0, errInvalidSizeLimits
+	}
+
+	return minSize, maxSize, nil
+}
+
+// sizeFlag returns the size in bytes given by the flag name, or zero if cmd
+// doesn't have it or it is empty.
+func sizeFlag(cmd *cobra.Command, name string) (int64, error) {
+	value, err := stringFlag(cmd, name)
+	if err != nil || value == "" {
+		return 0, err
+	}
+
+	size, err := ui.ParseSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", errInvalidFlag, name, err)
+	}
+
+	return size, nil
+}
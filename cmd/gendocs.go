@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// docsDirPermissions are the permissions of the generated docs directories.
+const docsDirPermissions = 0o755
+
+var errFailedToGenerateDocs = errors.New("failed to generate docs")
+
+// init initializes the hidden gen-docs command and adds it to the root
+// command with its flags.
+func init() {
+	rootCmd.AddCommand(genDocsCmd)
+	genDocsCmd.Flags().String("dir", "docs", "Directory the man and markdown folders are written to")
+}
+
+var genDocsCmd = &cobra.Command{
+	Use:   "gen-docs",
+	Short: "Generate man pages and markdown docs for all commands",
+	Long: "Write man pages to <dir>/man and a markdown reference to <dir>/markdown,\n" +
+		"one file per command, e.g. for packagers.",
+	Args:   cobra.NoArgs,
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		dir, err := cmd.Flags().GetString("dir")
+		if err != nil {
+			return fmt.Errorf("%w: dir: %w", errFailedToGetFlag, err)
+		}
+
+		if err := generateDocs(dir); err != nil {
+			return fmt.Errorf("%w: %w", errFailedToGenerateDocs, err)
+		}
+
+		fmt.Printf("Docs written to %s\n", dir)
+
+		return nil
+	},
+}
+
+// generateDocs writes the man pages and markdown files of all commands to
+// subdirectories of dir.
+func generateDocs(dir string) error {
+	manDir := filepath.Join(dir, "man")
+	markdownDir := filepath.Join(dir, "markdown")
+
+	for _, path := range []string{manDir, markdownDir} {
+		if err := os.MkdirAll(path, docsDirPermissions); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+	}
+
+	// Timestamps would make the output differ between otherwise identical builds
+	rootCmd.DisableAutoGenTag = true
+
+	header := &doc.GenManHeader{
+		Title:   "SWITCHTUBE-DOWNLOADER",
+		Section: "1",
+		Date:    nil,
+		Source:  "SwitchTube-Downloader " + version,
+		Manual:  "SwitchTube-Downloader Manual",
+	}
+
+	if err := doc.GenManTree(rootCmd, header, manDir); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if err := doc.GenMarkdownTree(rootCmd, markdownDir); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
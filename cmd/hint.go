@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"slices"
+	"syscall"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/token"
+)
+
+// errorHint is a hint on what the user can do about a common failure.
+type errorHint struct {
+	matches func(err error) bool
+	hint    func(err error) string
+}
+
+// errorHints are the failures printed with a hint, in the order they are
+// matched.
+var errorHints = []errorHint{
+	{
+		matches: isError(token.ErrNoTokenFound),
+		hint:    fixedHint("run 'token set', or pass the token in the " + token.EnvVar + " environment variable"),
+	},
+	{
+		matches: isError(download.ErrUnauthorized),
+		hint:    fixedHint("run 'token set' if the token has expired, or check your channel permissions"),
+	},
+	{
+		matches: isError(download.ErrAccountUnavailable),
+		hint:    fixedHint("downloads don't need it, this SwitchTube instance just doesn't report it"),
+	},
+	{
+		matches: isError(download.ErrNotFound),
+		hint:    fixedHint("check the ID or your channel permissions"),
+	},
+	{
+		matches: isDiskFull,
+		hint:    fixedHint("free disk space or choose another output directory with -o"),
+	},
+	{
+		matches: isError(fs.ErrPermission),
+		hint:    permissionHint,
+	},
+	{
+		matches: isErrorType[x509.UnknownAuthorityError],
+		hint:    fixedHint("pass the CA certificate of your network, e.g. of a TLS-inspecting proxy, with --ca-cert"),
+	},
+	{
+		matches: isErrorType[*net.DNSError],
+		hint:    fixedHint("check your network connection and --proxy"),
+	},
+	{
+		matches: isError(syscall.ECONNREFUSED),
+		hint:    fixedHint("check --base-url and --proxy"),
+	},
+	{
+		matches: isTimeout,
+		hint:    fixedHint("check your network connection, or raise --api-timeout or --read-timeout"),
+	},
+}
+
+// printError prints err to stderr, followed by a hint on what to do about it
+// if it is a common failure.
+func printError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+
+	if hint := describeError(err); hint != "" {
+		fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+	}
+}
+
+// describeError returns the hint for err if it is a common failure, and an
+// empty string otherwise. Partial failures are summaries of several errors
+// and get no hint, like timeouts the user asked for.
+func describeError(err error) string {
+	if errors.Is(err, download.ErrPartialFailure) || errors.Is(err, errInterrupted) ||
+		download.IsTimeoutLimit(err) {
+		return ""
+	}
+
+	for _, known := range errorHints {
+		if known.matches(err) {
+			return known.hint(err)
+		}
+	}
+
+	return ""
+}
+
+// fixedHint returns a hint that is the same for every error.
+func fixedHint(hint string) func(error) string {
+	return func(error) string {
+		return hint
+	}
+}
+
+// permissionHint returns the hint for a permission error, naming the file or
+// directory it occurred on if known, since it may be any of the output
+// directory, the config directory or the token file.
+func permissionHint(err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return fmt.Sprintf("check that you may access %s", pathErr.Path)
+	}
+
+	return "check the permissions of the file or directory"
+}
+
+// isDiskFull reports whether err is caused by a full disk.
+func isDiskFull(err error) bool {
+	return slices.ContainsFunc(diskFullErrors, func(target error) bool {
+		return errors.Is(err, target)
+	})
+}
+
+// isError returns a matcher for errors wrapping target.
+func isError(target error) func(error) bool {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// isErrorType reports whether err wraps an error of type T.
+func isErrorType[T error](err error) bool {
+	var target T
+
+	return errors.As(err, &target)
+}
+
+// isTimeout reports whether err is a network timeout.
+func isTimeout(err error) bool {
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
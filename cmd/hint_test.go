@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"syscall"
+	"testing"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/token"
+)
+
+// timeoutError is a network error that timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestDescribeError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "no token",
+			err:  fmt.Errorf("failed to get token: %w", token.ErrNoTokenFound),
+			want: "run 'token set', or pass the token in the " + token.EnvVar + " environment variable",
+		},
+		{
+			name: "unauthorized",
+			err:  fmt.Errorf("failed to download channel: %w", download.ErrUnauthorized),
+			want: "run 'token set' if the token has expired, or check your channel permissions",
+		},
+		{
+			name: "account unavailable",
+			err:  fmt.Errorf("%w: api/v1/browse/profiles/me", download.ErrAccountUnavailable),
+			want: "downloads don't need it, this SwitchTube instance just doesn't report it",
+		},
+		{
+			name: "not found",
+			err:  download.ErrNotFound,
+			want: "check the ID or your channel permissions",
+		},
+		{
+			name: "disk full",
+			err:  &fs.PathError{Op: "write", Path: "videos/Intro.mp4", Err: syscall.ENOSPC},
+			want: "free disk space or choose another output directory with -o",
+		},
+		{
+			name: "permission denied on a file",
+			err:  &fs.PathError{Op: "open", Path: "tokens.json", Err: fs.ErrPermission},
+			want: "check that you may access tokens.json",
+		},
+		{
+			name: "permission denied",
+			err:  fs.ErrPermission,
+			want: "check the permissions of the file or directory",
+		},
+		{
+			name: "unknown certificate authority",
+			err:  fmt.Errorf("request failed: %w", x509.UnknownAuthorityError{}),
+			want: "pass the CA certificate of your network, e.g. of a TLS-inspecting proxy, with --ca-cert",
+		},
+		{
+			name: "unknown host",
+			err:  &net.DNSError{Err: "no such host", Name: "tube.switch.ch"},
+			want: "check your network connection and --proxy",
+		},
+		{
+			name: "connection refused",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			want: "check --base-url and --proxy",
+		},
+		{
+			name: "network timeout",
+			err:  &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}},
+			want: "check your network connection, or raise --api-timeout or --read-timeout",
+		},
+		{
+			name: "partial failure",
+			err:  fmt.Errorf("%w: 1 of 3: %w", download.ErrPartialFailure, download.ErrNotFound),
+			want: "",
+		},
+		{
+			name: "interrupted",
+			err:  fmt.Errorf("%w: %w", errInterrupted, context.Canceled),
+			want: "",
+		},
+		{
+			name: "other error",
+			err:  errors.New("something else"),
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeError(tt.err); got != tt.want {
+				t.Errorf("describeError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package cmd
+
+import "syscall"
+
+// diskFullErrors are the errors reporting a full disk.
+var diskFullErrors = []error{syscall.ENOSPC}
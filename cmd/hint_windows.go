@@ -0,0 +1,11 @@
+package cmd
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// diskFullErrors are the errors reporting a full disk. Windows reports it
+// with its own error codes rather than ENOSPC.
+var diskFullErrors = []error{syscall.ENOSPC, windows.ERROR_DISK_FULL, windows.ERROR_HANDLE_DISK_FULL}
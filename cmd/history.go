@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/history"
+	"switchtube-downloader/internal/models"
+)
+
+// init initializes the history command and its subcommands, adding them to
+// the root command.
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historySearchCmd)
+
+	historyListCmd.Flags().String("channel", "", "Only list downloads of channels containing this text")
+	historyListCmd.Flags().IntP("limit", "n", 0, "Only list the most recent downloads")
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the download history",
+	Long: "List and search the completed downloads recorded in the history file,\n" +
+		"e.g. to find where a video was saved. Pass --no-history to a download to\n" +
+		"leave it out of the history.",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List completed downloads, most recent first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		entries, err := loadHistory()
+		if err != nil {
+			return err
+		}
+
+		channel, err := cmd.Flags().GetString("channel")
+		if err != nil {
+			return fmt.Errorf("%w: channel: %w", errFailedToGetFlag, err)
+		}
+
+		limit, err := cmd.Flags().GetInt("limit")
+		if err != nil {
+			return fmt.Errorf("%w: limit: %w", errFailedToGetFlag, err)
+		}
+
+		if channel != "" {
+			entries = slices.DeleteFunc(entries, func(entry models.HistoryEntry) bool {
+				return !strings.Contains(strings.ToLower(entry.Channel), strings.ToLower(channel))
+			})
+		}
+
+		if limit > 0 && len(entries) > limit {
+			entries = entries[:limit]
+		}
+
+		return printHistory(cmd, entries)
+	},
+}
+
+var historySearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search completed downloads by id, title, channel or path",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := loadHistory()
+		if err != nil {
+			return err
+		}
+
+		return printHistory(cmd, history.Search(entries, args[0]))
+	},
+}
+
+// loadHistory loads the download history, most recent download first.
+func loadHistory() ([]models.HistoryEntry, error) {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	entries, err := history.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	slices.Reverse(entries)
+
+	return entries, nil
+}
+
+// printHistory prints entries as a table or, if the --json flag of cmd is
+// set, as JSON.
+func printHistory(cmd *cobra.Command, entries []models.HistoryEntry) error {
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+	}
+
+	if asJSON {
+		return printJSON(append([]models.HistoryEntry{}, entries...))
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No downloads found")
+
+		return nil
+	}
+
+	ui.PrintHistory(entries)
+
+	return nil
+}
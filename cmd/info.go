@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/helper/ui"
+)
+
+// init initializes the info command and adds it to the root command.
+func init() {
+	rootCmd.AddCommand(infoCmd)
+}
+
+var infoCmd = &cobra.Command{
+	Use:         "info <id|url>",
+	Short:       "Show the metadata of a video",
+	Long:        "Show title, episode, duration and the downloadable variants of a video without downloading it",
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{mediaArgsAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		details, err := download.VideoInfo(cmd.Context(), client, args[0])
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if !asJSON {
+			ui.PrintVideoDetails(details)
+
+			return nil
+		}
+
+		return printJSON(details)
+	},
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/helper/ui"
+)
+
+// init initializes the list command and adds it to the root command.
+func init() {
+	rootCmd.AddCommand(listCmd)
+}
+
+var listCmd = &cobra.Command{
+	Use:         "list <id|url>",
+	Short:       "List the videos of a channel",
+	Long:        "List index, episode, title, duration and size of every video in a channel without downloading",
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{mediaArgsAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		listing, err := download.ListChannel(cmd.Context(), client, args[0])
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if !asJSON {
+			ui.PrintChannelListing(listing)
+
+			return nil
+		}
+
+		return printJSON(listing)
+	},
+}
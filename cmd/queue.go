@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/queue"
+)
+
+var errNotQueued = errors.New("not in the queue")
+
+// init initializes the queue command and its subcommands, adding them to the
+// root command with their flags.
+func init() {
+	rootCmd.AddCommand(queueCmd)
+	queueCmd.AddCommand(queueAddCmd)
+	queueCmd.AddCommand(queueListCmd)
+	queueCmd.AddCommand(queueRemoveCmd)
+	queueCmd.AddCommand(queueRunCmd)
+
+	queueRunCmd.Flags().
+		BoolP("episode", "e", false, "Prefixes the video with episode-number e.g. 01_OR_Mapping.mp4")
+	queueRunCmd.Flags().StringP("output", "o", "", "Output directory for downloaded files")
+	addOrderFlags(queueRunCmd)
+	addProgressFlag(queueRunCmd)
+	addFilenameFlags(queueRunCmd)
+	addPostProcessFlags(queueRunCmd)
+	addSizeLimitFlags(queueRunCmd)
+	addVariantFlags(queueRunCmd)
+	addExternalDownloaderFlag(queueRunCmd)
+	addExecBeforeFlag(queueRunCmd)
+	addTimeoutFlags(queueRunCmd)
+	addWaitLockFlag(queueRunCmd)
+	addRetryFlags(queueRunCmd)
+}
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Collect videos and channels to download later",
+	Long: "Add links to the download queue during the day and download all of them in one\n" +
+		"go with 'queue run', e.g. overnight. The queue is stored in\n" +
+		"$HOME/.config/switchtube-dl/queue.jsonl.",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if err := cmd.Help(); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+var queueAddCmd = &cobra.Command{
+	Use:         "add <id|url>...",
+	Short:       "Add videos or channels to the queue",
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{mediaArgsAnnotation: "true"},
+	RunE: func(_ *cobra.Command, args []string) error {
+		path, err := queue.DefaultPath()
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		added, err := queue.Add(path, args...)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		fmt.Printf("Added %d of %d to the queue\n", added, len(args))
+
+		return nil
+	},
+}
+
+var queueListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the queued videos and channels",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		_, entries, err := loadQueue()
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			return printJSON(append([]models.QueueEntry{}, entries...))
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("The queue is empty")
+
+			return nil
+		}
+
+		ui.PrintQueue(entries)
+
+		return nil
+	},
+}
+
+var queueRemoveCmd = &cobra.Command{
+	Use:   "remove <number|id|url>",
+	Short: "Remove an entry from the queue",
+	Long:  "Remove an entry from the queue by its number in 'queue list' or by the id or link it was added with",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		path, err := queue.DefaultPath()
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		var removed bool
+
+		if err := queue.Update(path, func(entries []models.QueueEntry) []models.QueueEntry {
+			entries, removed = queue.Remove(entries, args[0])
+
+			return entries
+		}); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if !removed {
+			return fmt.Errorf("%w: %s", errNotQueued, args[0])
+		}
+
+		fmt.Printf("Removed %s from the queue\n", args[0])
+
+		return nil
+	},
+}
+
+var queueRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Download everything in the queue",
+	Long: "Download all queued videos and whole channels one after the other without\n" +
+		"prompting, skipping existing files. Downloaded entries are removed from the\n" +
+		"queue, failed ones stay for the next run.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		path, entries, err := loadQueue()
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("The queue is empty")
+
+			return nil
+		}
+
+		config, err := downloadConfig(cmd, "")
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		done, remaining := runQueue(cmd, client, config, entries)
+		if err := queue.Complete(path, done); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		complete := fmt.Sprintf("Queue complete! %d/%d downloads successful",
+			len(entries)-len(remaining), len(entries))
+		fmt.Printf("\n%s\n", ui.Outcome(complete, len(remaining) == 0))
+
+		if len(remaining) > 0 {
+			return fmt.Errorf("%w: %d of %d", download.ErrPartialFailure, len(remaining), len(entries))
+		}
+
+		return nil
+	},
+}
+
+// loadQueue returns the path of the queue file and its entries.
+func loadQueue() (string, []models.QueueEntry, error) {
+	path, err := queue.DefaultPath()
+	if err != nil {
+		return "", nil, fmt.Errorf("%w", err)
+	}
+
+	entries, err := queue.Load(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w", err)
+	}
+
+	return path, entries, nil
+}
+
+// runQueue downloads the media of entries within the run timeout of config,
+// each with the overrides of its channel, and returns the entries that were
+// downloaded and the ones that failed.
+func runQueue(
+	cmd *cobra.Command,
+	client *download.Client,
+	config models.DownloadConfig,
+	entries []models.QueueEntry,
+) ([]models.QueueEntry, []models.QueueEntry) {
+	ctx, cancel := download.WithRunTimeout(cmd.Context(), config)
+	defer cancel()
+
+	var done, remaining []models.QueueEntry
+
+	for i, entry := range entries {
+		fmt.Fprintf(os.Stderr, "\n[%d/%d] %s\n", i+1, len(entries), entry.Media)
+
+		if err := downloadQueued(ctx, cmd, client, entry.Media); err != nil {
+			slog.Error("failed to download queued media", "media", entry.Media, "error", err)
+
+			remaining = append(remaining, entry)
+
+			continue
+		}
+
+		done = append(done, entry)
+	}
+
+	return done, remaining
+}
+
+// downloadQueued downloads the queued media with the overrides of its
+// channel. Nobody is around to answer prompts, so all videos are selected and
+// existing files are skipped.
+func downloadQueued(
+	ctx context.Context,
+	cmd *cobra.Command,
+	client *download.Client,
+	media string,
+) error {
+	if err := reapplyConfig(cmd, media); err != nil {
+		return err
+	}
+
+	config, err := downloadConfig(cmd, media)
+	if err != nil {
+		return err
+	}
+
+	config.All = true
+	config.Skip = true
+
+	if err := download.Download(ctx, client, config); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/failures"
+	"switchtube-downloader/internal/models"
+)
+
+// init initializes the retry command and adds it to the root command.
+func init() {
+	rootCmd.AddCommand(retryCmd)
+	addProgressFlag(retryCmd)
+	retryCmd.Flags().Bool("no-history", false, "Don't record the downloads in the download history")
+}
+
+var retryCmd = &cobra.Command{
+	Use:   "retry [file]",
+	Short: "Retry failed downloads",
+	Long: "Download the videos recorded in a failures file again with the options of the\n" +
+		"download they failed in, such as the output folder and the file names. Commands\n" +
+		"and webhooks of that download aren't run again. Videos that fail again stay in\n" +
+		"the file, the others are removed. The file defaults to\n" +
+		"$HOME/.config/switchtube-dl/failures.jsonl.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := retryFile(args)
+		if err != nil {
+			return err
+		}
+
+		entries, err := failures.Load(path)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No failed downloads to retry")
+
+			return nil
+		}
+
+		base, err := downloadConfig(cmd, "")
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		remaining := retryDownloads(cmd.Context(), client, entries, base)
+		if err := failures.Save(path, remaining); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		fmt.Printf("\nRetry complete! %d/%d downloads successful\n",
+			len(entries)-len(remaining), len(entries))
+
+		if len(remaining) > 0 {
+			return fmt.Errorf("%w: %d of %d", download.ErrPartialFailure, len(remaining), len(entries))
+		}
+
+		return nil
+	},
+}
+
+// retryFile returns the failures file given in args or the default one.
+func retryFile(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	path, err := failures.DefaultPath()
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	return path, nil
+}
+
+// retryDownloads downloads the videos of entries again with the options of
+// base, the retry run, and returns the entries of the ones that failed again.
+func retryDownloads(
+	ctx context.Context,
+	client *download.Client,
+	entries []models.FailedDownload,
+	base models.DownloadConfig,
+) []models.FailedDownload {
+	var remaining []models.FailedDownload
+
+	for i, entry := range entries {
+		fmt.Fprintf(os.Stderr, "\n[%d/%d] Retrying %s\n", i+1, len(entries), retryName(entry))
+
+		// The options come from a file, so they are checked like flags
+		config := download.RetryConfig(entry, base)
+
+		err := validateDownloadConfig(config)
+		if err == nil {
+			err = download.Retry(ctx, client, entry, config)
+		}
+
+		if err != nil {
+			slog.Error("failed to retry download", "id", entry.ID, "error", err)
+
+			entry.Error = err.Error()
+			entry.FailedAt = time.Now()
+			remaining = append(remaining, entry)
+		}
+	}
+
+	return remaining
+}
+
+// retryName returns the title of the video of entry, or its id if the title
+// isn't known.
+func retryName(entry models.FailedDownload) string {
+	if entry.Title == "" {
+		return entry.ID
+	}
+
+	return entry.Title
+}
@@ -2,24 +2,642 @@
 package cmd
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"switchtube-downloader/internal/config"
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/helper/logging"
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+)
+
+const (
+	// defaultRateLimit is the default maximum number of API requests per
+	// second.
+	defaultRateLimit = 10
+
+	// commandLineAnnotation marks the flags set on the command line.
+	commandLineAnnotation = "commandLine"
 )
 
+var (
+	errFailedToCreateClient = errors.New("failed to create client")
+	errFailedToLoadConfig   = errors.New("failed to load config")
+)
+
+// init initializes the persistent flags shared by all commands.
+func init() {
+	rootCmd.SetGlobalNormalizationFunc(normalizeFlagName)
+
+	rootCmd.PersistentFlags().
+		String("config", "", "Path to the config file (default is $HOME/.config/switchtube-dl/config.toml)")
+	rootCmd.PersistentFlags().
+		String("account", "", "Account to use, as added with 'account add' (default from the config file)")
+	rootCmd.PersistentFlags().
+		String("base-url", "", "URL of the SwitchTube instance (default https://tube.switch.ch)")
+	rootCmd.PersistentFlags().String("keyring-backend", token.StoreAuto,
+		"Where the access token is stored: "+strings.Join(token.Stores, ", "))
+	rootCmd.PersistentFlags().
+		Bool("encrypt-token-file", false, "Encrypt the token file with a passphrase asked for on use")
+	rootCmd.PersistentFlags().
+		Duration("token-cache", 0, "Keep the decrypted token for this long, to ask for the passphrase once per session")
+	rootCmd.PersistentFlags().
+		String("proxy", "", "Proxy URL, e.g. socks5://host:port (default from HTTP_PROXY/HTTPS_PROXY)")
+	rootCmd.PersistentFlags().
+		String("ca-cert", "", "PEM file with additional CA certificates to trust")
+	rootCmd.PersistentFlags().
+		Bool("insecure", false, "Disable TLS certificate verification (dangerous)")
+	rootCmd.PersistentFlags().
+		Int("max-idle-conns", download.DefaultMaxIdleConns, "Maximum number of idle connections kept open for reuse")
+	rootCmd.PersistentFlags().
+		Bool("no-http2", false, "Only use HTTP/1.1, e.g. for VPNs that break HTTP/2 connections")
+	rootCmd.PersistentFlags().
+		Duration("tcp-keepalive", download.DefaultKeepAlive, "Interval of TCP keep-alive probes, negative to disable them")
+	rootCmd.PersistentFlags().
+		Duration("dial-timeout", download.DefaultDialTimeout, "Maximum time to establish a connection")
+	rootCmd.PersistentFlags().
+		Duration("api-timeout", download.DefaultAPITimeout, "Maximum duration of a metadata request to the API")
+	rootCmd.PersistentFlags().
+		Duration("read-timeout", download.DefaultReadTimeout, "Abort a video download that receives no data for this long")
+	rootCmd.PersistentFlags().
+		BoolP("force-ipv4", "4", false, "Only connect over IPv4, e.g. if IPv6 is broken on a VPN")
+	rootCmd.PersistentFlags().BoolP("force-ipv6", "6", false, "Only connect over IPv6")
+	rootCmd.PersistentFlags().
+		String("user-agent", "", "User-Agent sent with every request (default switchtube-downloader/<version>)")
+	rootCmd.PersistentFlags().
+		StringArray("header", nil, `Extra header sent with every request to SwitchTube, e.g. "X-Institution: ZHAW" (repeatable)`)
+	rootCmd.PersistentFlags().
+		StringSlice("forward-auth-to", nil, "Hosts the access token is still sent to on redirects, e.g. a CDN")
+	rootCmd.PersistentFlags().
+		Float64("rate-limit", defaultRateLimit, "Maximum number of API requests per second, 0 for no limit")
+	rootCmd.PersistentFlags().
+		Bool("no-cache", false, "Don't cache channel and video metadata between runs")
+	rootCmd.PersistentFlags().
+		CountP("verbose", "v", "Log download milestones, repeat (-vv) to log every HTTP request")
+	rootCmd.PersistentFlags().
+		String("log-file", "", "Append all log output including debug details to this file")
+	rootCmd.PersistentFlags().Bool("json", false, "Print results as JSON for scripting")
+	rootCmd.PersistentFlags().
+		Bool("no-color", false, "Disable colored output (also disabled by NO_COLOR)")
+	rootCmd.PersistentFlags().
+		Bool("no-input", false, "Never prompt: answer no to confirmations and fail where an answer is required")
+	rootCmd.PersistentFlags().
+		BoolP("yes", "y", false, "Answer yes to all confirmations, e.g. to overwrite files or replace the token")
+}
+
 var rootCmd = &cobra.Command{
 	Use:   filepath.Base(os.Args[0]),
 	Short: "A CLI downloader for SwitchTube videos",
 
-	CompletionOptions: cobra.CompletionOptions{
-		DisableDefaultCmd: true,
+	// Errors are printed by Execute, which also picks the exit code
+	SilenceErrors: true,
+
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkExclusiveFlags(cmd); err != nil {
+			return err
+		}
+
+		// Errors in the config file are not usage errors
+		cmd.SilenceUsage = true
+
+		if err := markCommandLineFlags(cmd); err != nil {
+			return err
+		}
+
+		if err := applyConfig(cmd, args); err != nil {
+			return err
+		}
+
+		if err := setupColor(cmd); err != nil {
+			return err
+		}
+
+		if err := setupInput(cmd); err != nil {
+			return err
+		}
+
+		if err := setupLogging(cmd); err != nil {
+			return err
+		}
+
+		warnRenamedEnv()
+
+		return nil
 	},
 }
 
-// Execute runs the root command and handles any errors.
+// Execute runs the root command and exits with a code that reflects the kind
+// of error, if any.
 func Execute() {
+	registerFlagCompletions(rootCmd)
+
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		printError(err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// applyConfig resolves the defaults of all flags of cmd that were not set on
+// the command line, first from the config file and then from SWITCHTUBE_*
+// environment variables. Aliases among the arguments of commands taking ids
+// or links are replaced in place with what they stand for. The defaults of
+// the selected account take precedence over the top-level values, and if cmd
+// is run for a single channel, the overrides of that channel take precedence
+// over both. Commands run for several channels apply the overrides of each
+// one with reapplyConfig.
+func applyConfig(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	account, err := configuredValue(cmd, cfg, "account")
+	if err != nil {
+		return err
+	}
+
+	if account != "" {
+		if cfg, err = cfg.ForAccount(account); err != nil {
+			return fmt.Errorf("%w (add it with 'account add %s')", err, account)
+		}
+	}
+
+	if cmd.Annotations[mediaArgsAnnotation] != "" {
+		for i, arg := range args {
+			args[i] = cfg.ResolveAlias(arg)
+		}
+	}
+
+	if len(args) == 1 {
+		base, err := configuredValue(cmd, cfg, "base-url")
+		if err != nil {
+			return err
+		}
+
+		cfg = cfg.ForChannel(channelNames(cfg, args[0], base)...)
+	}
+
+	if err := clearOpposingFlags(cmd); err != nil {
+		return err
+	}
+
+	if err := cfg.ApplyToFlags(cmd.Flags()); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToLoadConfig, err)
+	}
+
+	if err := config.ApplyEnv(cmd.Flags(), renamedFlags); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToLoadConfig, err)
+	}
+
+	return nil
+}
+
+// reapplyConfig resolves the flags of cmd again for the single channel or
+// video media, so that the overrides of each channel apply when cmd is run for
+// several of them. Flags not set on the command line are reset to their
+// defaults first, dropping the overrides of the previous channel.
+func reapplyConfig(cmd *cobra.Command, media string) error {
+	var err error
+
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || !flag.Changed || flag.Annotations[commandLineAnnotation] != nil {
+			return
+		}
+
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			err = slice.Replace(nil)
+		} else {
+			err = flag.Value.Set(flag.DefValue)
+		}
+
+		flag.Changed = false
+	})
+
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return applyConfig(cmd, []string{media})
+}
+
+// markCommandLineFlags annotates the flags of cmd set on the command line, so
+// that reapplyConfig keeps them.
+func markCommandLineFlags(cmd *cobra.Command) error {
+	var err error
+
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if err == nil {
+			err = cmd.Flags().SetAnnotation(flag.Name, commandLineAnnotation, []string{"true"})
+		}
+	})
+
+	if err != nil {
+		return fmt.Errorf("%w", err)
 	}
+
+	return nil
+}
+
+// channelNames returns the names the [channels.<name>] tables of the channel
+// media may have in cfg: the aliases standing for it, then its id, so that the
+// table of the id takes precedence.
+func channelNames(cfg *config.Config, media, base string) []string {
+	id := download.MediaID(media, base)
+	aliases := cfg.Aliases()
+
+	var names []string
+
+	for _, alias := range slices.Sorted(maps.Keys(aliases)) {
+		if download.MediaID(aliases[alias], base) == id {
+			names = append(names, alias)
+		}
+	}
+
+	return append(names, id)
+}
+
+// configuredValue returns the value of the string flag of cmd with the given
+// name from the command line, or else from cfg or the SWITCHTUBE_*
+// environment variable, in the precedence applyConfig uses for all flags. It
+// is meant for flags applyConfig needs before applying them, e.g. --account.
+func configuredValue(cmd *cobra.Command, cfg *config.Config, name string) (string, error) {
+	if cmd.Flags().Changed(name) {
+		value, err := cmd.Flags().GetString(name)
+		if err != nil {
+			return "", fmt.Errorf("%w", err)
+		}
+
+		return value, nil
+	}
+
+	values, ok, err := cfg.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToLoadConfig, err)
+	}
+
+	if ok && len(values) > 0 {
+		return values[0], nil
+	}
+
+	return os.Getenv(config.EnvName(name)), nil
+}
+
+// setupColor turns colored output off if --no-color is set.
+func setupColor(cmd *cobra.Command) error {
+	noColor, err := cmd.Flags().GetBool("no-color")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	ui.SetColor(!noColor)
+
+	return nil
+}
+
+// setupInput turns off all prompts if --no-input is set and answers all
+// confirmations if --yes is set.
+func setupInput(cmd *cobra.Command) error {
+	noInput, err := cmd.Flags().GetBool("no-input")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	ui.SetInput(!noInput)
+	ui.SetAssumeYes(yes)
+
+	return nil
+}
+
+// setupLogging configures the logger according to the --verbose and
+// --log-file flags.
+func setupLogging(cmd *cobra.Command) error {
+	verbosity, err := cmd.Flags().GetCount("verbose")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	logFile, err := cmd.Flags().GetString("log-file")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if err := logging.Setup(verbosity, logFile); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// printJSON prints v as JSON to stdout, like all JSON output.
+func printJSON(v any) error {
+	if err := ui.PrintJSON(v); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// loadConfig loads the config file given by the --config flag, the
+// SWITCHTUBE_CONFIG environment variable or the default location.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToLoadConfig, err)
+	}
+
+	if !cmd.Flags().Changed("config") {
+		path = os.Getenv(config.EnvName("config"))
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToLoadConfig, err)
+	}
+
+	if err := migrateRenamedKeys(cmd.Root(), cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// renamedFlags maps the former names of flags to their current ones, so that
+// scripts, config files and environment variables using them keep working.
+var renamedFlags = map[string]string{
+	"token-store": "keyring-backend",
+}
+
+// normalizeFlagName returns the current name of the flag with the given name.
+func normalizeFlagName(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+	return pflag.NormalizedName(currentFlagName(name))
+}
+
+// currentFlagName returns the current name of a flag that may have been
+// renamed.
+func currentFlagName(name string) string {
+	if current, ok := renamedFlags[name]; ok {
+		return current
+	}
+
+	return name
+}
+
+// warnRenamedEnv warns about environment variables of renamed flags that are
+// set, which still work but are deprecated.
+func warnRenamedEnv() {
+	for _, former := range slices.Sorted(maps.Keys(renamedFlags)) {
+		if _, ok := os.LookupEnv(config.EnvName(former)); ok {
+			slog.Warn("environment variable is deprecated",
+				"variable", config.EnvName(former),
+				"use", config.EnvName(renamedFlags[former]))
+		}
+	}
+}
+
+// migrateRenamedKeys moves the values of renamed flags of root in cfg to
+// their current keys, unless these are set as well. The file itself is only
+// rewritten when it is saved.
+func migrateRenamedKeys(root *cobra.Command, cfg *config.Config) error {
+	for former, current := range renamedFlags {
+		values, ok, err := cfg.Get(former)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errFailedToLoadConfig, err)
+		} else if !ok {
+			continue
+		}
+
+		cfg.Unset(former)
+
+		if _, ok, _ := cfg.Get(current); ok {
+			continue
+		}
+
+		flag := lookupFlag(root, current)
+		if err := cfg.Set(current, strings.Join(values, ","), flag.Value.Type()); err != nil {
+			return fmt.Errorf("%w: %w", errFailedToLoadConfig, err)
+		}
+	}
+
+	return nil
+}
+
+// newTokenManager creates a token manager for the token of the account
+// selected by --account, using the store selected by the --keyring-backend
+// flag and the token file options from the global flags.
+func newTokenManager(cmd *cobra.Command) (*token.Manager, error) {
+	store, err := cmd.Flags().GetString("keyring-backend")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToCreateClient, err)
+	}
+
+	var options token.FileOptions
+
+	if options.Encrypt, err = cmd.Flags().GetBool("encrypt-token-file"); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToCreateClient, err)
+	}
+
+	if options.CacheFor, err = cmd.Flags().GetDuration("token-cache"); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToCreateClient, err)
+	}
+
+	tokenMgr, err := token.NewTokenManagerWithFile(store, options)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToCreateClient, err)
+	}
+
+	account, err := cmd.Flags().GetString("account")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToCreateClient, err)
+	}
+
+	return tokenMgr.WithAccount(account), nil
+}
+
+// newClient creates an API client configured by the global flags.
+func newClient(cmd *cobra.Command) (*download.Client, error) {
+	tokenMgr, err := newTokenManager(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := clientConfig(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToCreateClient, err)
+	}
+
+	client, err := download.NewClient(tokenMgr, config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToCreateClient, err)
+	}
+
+	warnTokenExpiry(tokenMgr)
+
+	return client, nil
+}
+
+// clientConfig reads the HTTP client options from the global flags.
+func clientConfig(cmd *cobra.Command) (models.ClientConfig, error) {
+	var config models.ClientConfig
+
+	var err error
+
+	if config.BaseURL, err = cmd.Flags().GetString("base-url"); err != nil {
+		return config, fmt.Errorf("%w", err)
+	}
+
+	if config.Proxy, err = cmd.Flags().GetString("proxy"); err != nil {
+		return config, fmt.Errorf("%w", err)
+	}
+
+	if config.CACert, err = cmd.Flags().GetString("ca-cert"); err != nil {
+		return config, fmt.Errorf("%w", err)
+	}
+
+	if config.Insecure, err = cmd.Flags().GetBool("insecure"); err != nil {
+		return config, fmt.Errorf("%w", err)
+	}
+
+	if config.RateLimit, err = cmd.Flags().GetFloat64("rate-limit"); err != nil {
+		return config, fmt.Errorf("%w", err)
+	}
+
+	if err = readConnectionFlags(cmd, &config); err != nil {
+		return config, err
+	}
+
+	if err = readTimeoutFlags(cmd, &config); err != nil {
+		return config, err
+	}
+
+	if err = readHeaderFlags(cmd, &config); err != nil {
+		return config, err
+	}
+
+	noCache, err := cmd.Flags().GetBool("no-cache")
+	if err != nil {
+		return config, fmt.Errorf("%w", err)
+	}
+
+	if !noCache {
+		config.CacheDir = responseCacheDir()
+	}
+
+	return config, nil
+}
+
+// readConnectionFlags sets the settings of the connections of config from
+// the global flags.
+func readConnectionFlags(cmd *cobra.Command, config *models.ClientConfig) error {
+	var err error
+
+	if config.MaxIdleConns, err = cmd.Flags().GetInt("max-idle-conns"); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if config.DisableHTTP2, err = cmd.Flags().GetBool("no-http2"); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if config.KeepAlive, err = cmd.Flags().GetDuration("tcp-keepalive"); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if config.Network, err = forcedNetwork(cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// forcedNetwork returns the network selected by --force-ipv4 or --force-ipv6,
+// or an empty string if neither is set.
+func forcedNetwork(cmd *cobra.Command) (string, error) {
+	for _, flag := range []struct {
+		name    string
+		network string
+	}{
+		{name: "force-ipv4", network: models.NetworkIPv4},
+		{name: "force-ipv6", network: models.NetworkIPv6},
+	} {
+		forced, err := cmd.Flags().GetBool(flag.name)
+		if err != nil {
+			return "", fmt.Errorf("%w", err)
+		} else if forced {
+			return flag.network, nil
+		}
+	}
+
+	return "", nil
+}
+
+// readTimeoutFlags sets the timeouts of connections, metadata requests and
+// video streams of config from the global flags.
+func readTimeoutFlags(cmd *cobra.Command, config *models.ClientConfig) error {
+	var err error
+
+	if config.DialTimeout, err = cmd.Flags().GetDuration("dial-timeout"); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if config.APITimeout, err = cmd.Flags().GetDuration("api-timeout"); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if config.ReadTimeout, err = cmd.Flags().GetDuration("read-timeout"); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// readHeaderFlags sets the User-Agent, which defaults to the name and version
+// of the tool, the extra headers and the hosts the access token is forwarded
+// to on redirects of config from the global flags.
+func readHeaderFlags(cmd *cobra.Command, config *models.ClientConfig) error {
+	userAgent, err := cmd.Flags().GetString("user-agent")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	config.UserAgent = cmp.Or(userAgent, "switchtube-downloader/"+version)
+
+	if config.Headers, err = cmd.Flags().GetStringArray("header"); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if config.AuthRedirectHosts, err = cmd.Flags().GetStringSlice("forward-auth-to"); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// responseCacheDir returns the directory API responses are cached in, or an
+// empty string, which disables the cache, if there is no user cache dir.
+func responseCacheDir() string {
+	dir, err := config.CacheDir()
+	if err != nil {
+		slog.Warn("response cache is disabled", "error", err)
+
+		return ""
+	}
+
+	return filepath.Join(dir, "responses")
 }
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/models"
+)
+
+// init initializes the search command and adds it to the root command with
+// its flags.
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().BoolP("download", "d", false, "Select results and download them right away")
+	searchCmd.Flags().
+		BoolP("episode", "e", false, "Prefixes the video with episode-number e.g. 01_OR_Mapping.mp4")
+	searchCmd.Flags().StringP("output", "o", "", "Output directory for downloaded files")
+	addProgressFlag(searchCmd)
+	addFilenameFlags(searchCmd)
+	addPostProcessFlags(searchCmd)
+	addSizeLimitFlags(searchCmd)
+	addVariantFlags(searchCmd)
+	addExternalDownloaderFlag(searchCmd)
+	addExecBeforeFlag(searchCmd)
+	addTimeoutFlags(searchCmd)
+	addWaitLockFlag(searchCmd)
+	addRetryFlags(searchCmd)
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search for videos and channels",
+	Long: "Search SwitchTube for videos and channels matching the query and list them with their IDs.\n" +
+		"With --download, the results can be selected and downloaded right away.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		downloadResults, err := cmd.Flags().GetBool("download")
+		if err != nil {
+			return fmt.Errorf("%w: download: %w", errFailedToGetFlag, err)
+		}
+
+		config, err := downloadConfig(cmd, "")
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		result, err := download.Search(cmd.Context(), client, strings.Join(args, " "))
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		items, urls := searchItems(client, result)
+		if len(items) == 0 {
+			fmt.Println("No results found")
+
+			return nil
+		}
+
+		if !downloadResults {
+			ui.PrintList("results", items)
+
+			return nil
+		}
+
+		selectedIndices, err := ui.Select("results", items, false)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		// One deadline covers the downloads of all selected results
+		ctx, cancel := download.WithRunTimeout(cmd.Context(), config)
+		defer cancel()
+
+		failed := 0
+
+		for _, idx := range selectedIndices {
+			config.Media = urls[idx]
+			if err := download.Download(ctx, client, config); err != nil {
+				slog.Error("download failed", "media", config.Media, "error", err)
+
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%w: %d of %d", download.ErrPartialFailure, failed, len(selectedIndices))
+		}
+
+		return nil
+	},
+}
+
+// searchItems returns the display labels and URLs of all search results on
+// the SwitchTube instance of client.
+func searchItems(client *download.Client, result *models.SearchResult) ([]string, []string) {
+	var items, urls []string
+
+	for _, video := range result.Videos {
+		items = append(items, fmt.Sprintf("[video] %s (%s)", video.Title, video.ID))
+		urls = append(urls, client.VideoURL(video.ID))
+	}
+
+	for _, channel := range result.Channels {
+		items = append(items, fmt.Sprintf("[channel] %s (%s)", channel.Name, channel.ID))
+		urls = append(urls, client.ChannelURL(channel.ID))
+	}
+
+	return items, urls
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/history"
+)
+
+// init initializes the stats command and adds it to the root command.
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().Bool("json", false, "Print the statistics as JSON")
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show download statistics",
+	Long: "Show the number of videos and bytes downloaded and the average speed,\n" +
+		"in total, per channel and per month, based on the download history",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		entries, err := loadHistory()
+		if err != nil {
+			return err
+		}
+
+		stats := history.Summarize(entries)
+		if asJSON {
+			return printJSON(stats)
+		}
+
+		if stats.Total.Videos == 0 {
+			fmt.Println("No downloads recorded yet")
+
+			return nil
+		}
+
+		ui.PrintHistoryStats(stats)
+
+		return nil
+	},
+}
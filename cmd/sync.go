@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/config"
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/service"
+	"switchtube-downloader/internal/token"
+)
+
+var errScheduleService = errors.New(
+	"--schedule and --install-service cannot be used together, the timer schedules the sync",
+)
+
+// secretEnvVars are the environment variables holding secrets, which
+// installSyncService doesn't write to the service in plaintext.
+var secretEnvVars = []string{token.EnvVar, token.PassphraseEnvVar, config.EnvName("notify-webhook")}
+
+// init initializes the sync command and adds it to the root command with its
+// flags.
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().
+		BoolP("episode", "e", false, "Prefixes the video with episode-number e.g. 01_OR_Mapping.mp4")
+	syncCmd.Flags().StringP("output", "o", "", "Output directory for downloaded files")
+	addScheduleFlag(syncCmd)
+	syncCmd.Flags().String("install-service", "",
+		"Install a systemd user service and a timer running this sync on a systemd calendar expression")
+	syncCmd.Flags().Lookup("install-service").NoOptDefVal = service.DefaultCalendar
+	addIfChangedFlag(syncCmd)
+	addOrderFlags(syncCmd)
+	addProgressFlag(syncCmd)
+	addFilenameFlags(syncCmd)
+	addPostProcessFlags(syncCmd)
+	addSizeLimitFlags(syncCmd)
+	addVariantFlags(syncCmd)
+	addExternalDownloaderFlag(syncCmd)
+	addExecBeforeFlag(syncCmd)
+	addTimeoutFlags(syncCmd)
+	addWaitLockFlag(syncCmd)
+	addRetryFlags(syncCmd)
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync <id|url>",
+	Short: "Download new videos of a channel",
+	Long: "Download all videos of a channel that have not been downloaded yet.\n" +
+		"Synced videos are tracked in a state file inside the channel folder and existing\n" +
+		"files are skipped, so the command never prompts and can be run repeatedly (e.g. cron).\n" +
+		"With --schedule, it keeps running and syncs whenever the cron expression is due.\n" +
+		"With --install-service, a systemd timer runs the sync instead.",
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{mediaArgsAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := downloadConfig(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		sched, err := scheduleFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		if calendar, _ := cmd.Flags().GetString("install-service"); calendar != "" {
+			if sched != nil {
+				return errScheduleService
+			}
+
+			return installSyncService(cmd, args[0], calendar)
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		if sched != nil {
+			return runWatch(client, config, 0, sched)
+		}
+
+		if err = download.Sync(cmd.Context(), client, config); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+// installSyncService installs a systemd user service running the sync command
+// with the arguments and SWITCHTUBE_* environment variables it was invoked
+// with, except --install-service and secrets, and a timer starting it on
+// calendar. The
+// service waits for the locks of other syncs instead of failing, since timers
+// of several channels may fire at the same time.
+func installSyncService(cmd *cobra.Command, channel, calendar string) error {
+	command, err := syncServiceCommand()
+	if err != nil {
+		return err
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	base, err := cmd.Flags().GetString("base-url")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	id := download.MediaID(channel, base)
+	name := service.SyncName(id)
+	unit := service.Unit{
+		Name:             name,
+		Description:      "Sync SwitchTube channel " + id,
+		Command:          command,
+		WorkingDirectory: workingDir,
+		Calendar:         calendar,
+		Environment:      serviceEnvironment(name),
+	}
+
+	dir, err := service.Dir()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	timer, err := unit.Write(dir)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	fmt.Printf("Wrote %s and %s.service\n", timer, unit.Name)
+
+	enabled, err := unit.Enable()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	} else if !enabled {
+		fmt.Printf("Enable it with: systemctl --user enable --now %s.timer\n", unit.Name)
+
+		return nil
+	}
+
+	fmt.Printf("Enabled %s.timer, check it with: systemctl --user list-timers\n", unit.Name)
+
+	return nil
+}
+
+// serviceEnvironment returns the SWITCHTUBE_* environment variables passed on
+// to the service with the given name. Secrets are left out with a warning,
+// since the unit would hold them in plaintext; they can be passed on with
+// EnvironmentFile= or systemd-creds in a drop-in of the service instead.
+func serviceEnvironment(name string) []string {
+	var environment []string
+
+	for _, variable := range config.Environ() {
+		key, _, _ := strings.Cut(variable, "=")
+		if !slices.Contains(secretEnvVars, key) {
+			environment = append(environment, variable)
+
+			continue
+		}
+
+		slog.Warn("secret environment variable isn't passed on to the service",
+			"variable", key,
+			"hint", "set it with EnvironmentFile= or systemd-creds in: systemctl --user edit "+
+				name+".service")
+	}
+
+	return environment
+}
+
+// syncServiceCommand returns the command the service of installSyncService
+// runs: the sync command with the arguments it was invoked with, except
+// --install-service, waiting for locks unless --wait-lock is given.
+func syncServiceCommand() ([]string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	command := []string{executable}
+	waitLock := false
+
+	for _, arg := range os.Args[1:] {
+		if arg != "--install-service" && !strings.HasPrefix(arg, "--install-service=") {
+			command = append(command, arg)
+		}
+
+		waitLock = waitLock || arg == "--wait-lock" || strings.HasPrefix(arg, "--wait-lock=")
+	}
+
+	if !waitLock {
+		command = append(command, "--wait-lock")
+	}
+
+	return command, nil
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestServiceEnvironment(t *testing.T) {
+	t.Setenv("SWITCHTUBE_OUTPUT", "videos")
+	t.Setenv("SWITCHTUBE_TOKEN", "secret")
+	t.Setenv("SWITCHTUBE_TOKEN_PASSPHRASE", "passphrase")
+	t.Setenv("SWITCHTUBE_NOTIFY_WEBHOOK", "https://hooks.example.com/secret")
+
+	got := serviceEnvironment("switchtube-sync-os")
+	if want := []string{"SWITCHTUBE_OUTPUT=videos"}; !slices.Equal(got, want) {
+		t.Errorf("serviceEnvironment() = %v, want %v", got, want)
+	}
+}
@@ -3,7 +3,15 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
 
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/models"
 	"switchtube-downloader/internal/token"
 
 	"github.com/spf13/cobra"
@@ -16,71 +24,359 @@ func init() {
 	tokenCmd.AddCommand(tokenGetCmd)
 	tokenCmd.AddCommand(tokenSetCmd)
 	tokenCmd.AddCommand(tokenDeleteCmd)
+	tokenCmd.AddCommand(tokenExportCmd)
+	tokenCmd.AddCommand(tokenImportCmd)
+	tokenCmd.AddCommand(tokenPurgeCmd)
+	tokenCmd.AddCommand(tokenStatusCmd)
+	tokenExportCmd.Flags().StringP("output", "o", "", "Write the token to a file instead of stdout")
 }
 
+// tokenFilePermissions restricts exported token files to the current user.
+const tokenFilePermissions = 0o600
+
+var (
+	errFailedToReadToken      = errors.New("failed to read token")
+	errFailedToWriteTokenFile = errors.New("failed to write token file")
+)
+
 var tokenCmd = &cobra.Command{
 	Use:   "token",
 	Short: "Manage the SwitchTube access token",
-	Long:  "Manage the SwitchTube access token stored in the system keyring",
-	Run: func(cmd *cobra.Command, _ []string) {
+	Long:  "Manage the SwitchTube access token stored in the system keyring or the token file",
+	RunE: func(cmd *cobra.Command, _ []string) error {
 		if err := cmd.Help(); err != nil {
-			fmt.Printf("Error displaying help: %v\n", err)
-
-			return
+			return fmt.Errorf("%w", err)
 		}
+
+		return nil
 	},
 }
 
 var tokenGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get the current access token",
-	Long:  "Checks if an access token is currently stored in the system keyring and returns it if there is one",
-	Run: func(_ *cobra.Command, _ []string) {
-		tokenMgr := token.NewTokenManager()
+	Long:  "Checks if an access token is currently stored and returns it if there is one",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
 
 		token, err := tokenMgr.Get()
 		if err != nil {
-			fmt.Printf("Error getting token: %v\n", err)
+			return fmt.Errorf("%w", err)
+		}
 
-			return
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		if !asJSON {
+			fmt.Printf("Token: %s\n", token)
+
+			return nil
 		}
 
-		fmt.Printf("Token: %s\n", token)
+		return printJSON(map[string]string{"token": token})
 	},
 }
 
 var tokenSetCmd = &cobra.Command{
 	Use:   "set",
 	Short: "Set a new access token",
-	Long:  "Create and store a new SwitchTube access token in the system keyring",
-	Run: func(_ *cobra.Command, _ []string) {
-		tokenMgr := token.NewTokenManager()
+	Long:  "Create and store a new SwitchTube access token in the token store",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
 
 		if err := tokenMgr.Set(); errors.Is(err, token.ErrTokenAlreadyExists) {
-			return
+			return nil
 		} else if err != nil {
-			fmt.Printf("Error setting token: %v\n", err)
-
-			return
+			return fmt.Errorf("%w", err)
 		}
 
 		fmt.Println("Token successfully stored")
+
+		return nil
 	},
 }
 
 var tokenDeleteCmd = &cobra.Command{
 	Use:   "delete",
-	Short: "Delete access token from the keyring",
-	Long:  "Delete the SwitchTube access token stored the system keyring",
-	Run: func(_ *cobra.Command, _ []string) {
-		tokenMgr := token.NewTokenManager()
+	Short: "Delete access token from the token store",
+	Long:  "Delete the SwitchTube access token stored in the token store",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
 
 		if err := tokenMgr.Delete(); err != nil {
-			fmt.Printf("Error deleting token: %v\n", err)
-
-			return
+			return fmt.Errorf("%w", err)
 		}
 
 		fmt.Println("Token successfully deleted")
+
+		return nil
+	},
+}
+
+var tokenExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the stored access token",
+	Long: "Print the stored access token or write it to a file, e.g. to migrate it to another machine.\n" +
+		"The token is printed in plain text, so an explicit confirmation is required.",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return fmt.Errorf("%w: output: %w", errFailedToGetFlag, err)
+		}
+
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		token, err := tokenMgr.Export()
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		if !ui.Confirm("This reveals your access token in plain text. Continue?") {
+			fmt.Fprintln(os.Stderr, "Operation cancelled")
+
+			return nil
+		}
+
+		if output == "" {
+			fmt.Println(token)
+
+			return nil
+		}
+
+		if err := os.WriteFile(output, []byte(token+"\n"), tokenFilePermissions); err != nil {
+			return fmt.Errorf("%w: %w", errFailedToWriteTokenFile, err)
+		}
+
+		fmt.Printf("Token written to %s\n", output)
+
+		return nil
+	},
+}
+
+var tokenImportCmd = &cobra.Command{
+	Use:   "import <file|->",
+	Short: "Import an access token from a file or stdin",
+	Long: "Store the access token read from a file, or from stdin if the argument is '-'.\n" +
+		"An existing token is replaced without prompting, which allows provisioning from a secrets manager.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		data, err := readTokenInput(args[0])
+		if err != nil {
+			return fmt.Errorf("%w: %w", errFailedToReadToken, err)
+		}
+
+		if err := tokenMgr.Import(string(data)); err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		fmt.Println("Token successfully imported")
+
+		return nil
 	},
 }
+
+// readTokenInput reads the token from the file at path or from stdin if path
+// is "-".
+func readTokenInput(path string) ([]byte, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		return data, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return data, nil
+}
+
+var tokenPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete all stored access tokens",
+	Long: "Delete the access tokens of all accounts from the keyring, pass and the token file,\n" +
+		"e.g. before decommissioning a machine. The token stores are cleared regardless of\n" +
+		"--keyring-backend, so an explicit confirmation is required.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !ui.Confirm("This deletes the access tokens of all accounts. Continue?") {
+			fmt.Fprintln(os.Stderr, "Operation cancelled")
+
+			return nil
+		}
+
+		deleted, err := tokenMgr.Purge(cfg.Accounts())
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		fmt.Printf("Deleted %d stored tokens\n", deleted)
+
+		return nil
+	},
+}
+
+var tokenStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the details of the access token",
+	Long: "Show where the access token comes from, when it was stored and when it expires,\n" +
+		"and check with SwitchTube that it is still accepted. The expiry is only known if\n" +
+		"SwitchTube reports it, and is then remembered to warn about it at the start of\n" +
+		"later runs.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		info, err := tokenMgr.Info()
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		status := models.TokenStatus{
+			Source:  "token store",
+			Valid:   false,
+			Checked: true,
+			Account: nil,
+			Created: info.Created,
+			Expires: info.Expires,
+		}
+		if strings.TrimSpace(os.Getenv(token.EnvVar)) != "" {
+			status.Source = token.EnvVar
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		account, err := download.WhoAmI(cmd.Context(), client)
+		if errors.Is(err, download.ErrAccountUnavailable) {
+			status.Checked = false
+			err = nil
+		} else if err != nil && !errors.Is(err, download.ErrUnauthorized) {
+			return fmt.Errorf("%w", err)
+		}
+
+		if account != nil {
+			status.Valid = true
+			status.Account = account
+			status.Expires = recordTokenExpiry(tokenMgr, account, status.Expires)
+		}
+
+		if asJSON {
+			if err := printJSON(status); err != nil {
+				return err
+			}
+		} else {
+			printTokenStatus(status)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		return nil
+	},
+}
+
+// printTokenStatus prints the details of the access token.
+func printTokenStatus(status models.TokenStatus) {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "unknown"
+		}
+
+		return t.Local().Format(time.DateTime)
+	}
+
+	fmt.Printf("Source:  %s\n", status.Source)
+
+	switch {
+	case status.Account != nil:
+		fmt.Printf("Account: %s (%s)\n", status.Account.Name, status.Account.ID)
+		fmt.Printf("Valid:   %s\n", ui.Success("yes"))
+	case !status.Checked:
+		fmt.Println("Valid:   unknown, SwitchTube doesn't report the account of the token")
+	default:
+		fmt.Printf("Valid:   %s\n", ui.Failure("no, rejected by SwitchTube"))
+	}
+
+	fmt.Printf("Created: %s\n", formatTime(status.Created))
+	fmt.Printf("Expires: %s\n", formatTime(status.Expires))
+
+	info := token.Info{Created: status.Created, Expires: status.Expires}
+	if warning := info.ExpiryWarning(time.Now()); warning != "" {
+		fmt.Printf("         %s\n", ui.Failure(warning))
+	}
+}
+
+// recordTokenExpiry remembers the expiry of the access token SwitchTube
+// reports with account, so that later runs can warn about it, and returns the
+// expiry known afterwards.
+func recordTokenExpiry(tokenMgr *token.Manager, account *models.Account, known time.Time) time.Time {
+	if account.ExpiresAt.IsZero() || account.ExpiresAt.Equal(known) {
+		return known
+	}
+
+	if err := tokenMgr.SetExpiry(account.ExpiresAt); err != nil {
+		slog.Warn("failed to record the expiry of the token", "error", err)
+	}
+
+	return account.ExpiresAt
+}
+
+// warnTokenExpiry warns if the access token of tokenMgr expires soon or has
+// expired, as far as its expiry is known.
+func warnTokenExpiry(tokenMgr *token.Manager) {
+	info, err := tokenMgr.Info()
+	if err != nil {
+		slog.Debug("failed to read the details of the token", "error", err)
+
+		return
+	}
+
+	if warning := info.ExpiryWarning(time.Now()); warning != "" {
+		slog.Warn(warning, "expires", info.Expires.Local().Format(time.DateTime))
+	}
+}
@@ -2,12 +2,31 @@ package cmd
 
 import (
 	"fmt"
+	"runtime"
+	"runtime/debug"
 
 	"github.com/spf13/cobra"
 )
 
-// version is set at build time using ldflags.
-var version = "unknown"
+// unknown is reported for build details that are not available.
+const unknown = "unknown"
+
+// version, commit and date are set at build time using ldflags.
+var (
+	version = unknown
+	commit  = unknown
+	date    = unknown
+)
+
+// buildInfo describes the running binary.
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+}
 
 // init initializes the version command and adds it to the root command.
 func init() {
@@ -17,7 +36,72 @@ func init() {
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of the SwitchTube downloader",
-	Run: func(_ *cobra.Command, _ []string) {
-		fmt.Println(version)
+	Long: "Print the version, commit and build date of the SwitchTube downloader.\n" +
+		"Details missing from the release ldflags are taken from the Go build info.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		info := readBuildInfo()
+
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		if asJSON {
+			return printJSON(info)
+		}
+
+		modified := ""
+		if info.Modified {
+			modified = " (modified)"
+		}
+
+		fmt.Println(info.Version)
+		fmt.Printf("Commit:   %s%s\n", info.Commit, modified)
+		fmt.Printf("Built:    %s\n", info.Date)
+		fmt.Printf("Go:       %s %s\n", info.GoVersion, info.Platform)
+
+		return nil
 	},
 }
+
+// readBuildInfo returns the build details set by ldflags, falling back to the
+// module version and VCS settings embedded by the Go toolchain.
+func readBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		Modified:  false,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	// "go install module@version" sets the module version, local builds report
+	// "(devel)"
+	if info.Version == unknown && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == unknown {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == unknown {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+}
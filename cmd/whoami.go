@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"switchtube-downloader/internal/download"
+)
+
+// init initializes the whoami command and adds it to the root command.
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the account of the access token",
+	Long: "Show the name of the SwitchTube account the stored access token belongs to and\n" +
+		"the scopes of the token, to confirm which account downloads are made with.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		asJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return fmt.Errorf("%w: json: %w", errFailedToGetFlag, err)
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		account, err := download.WhoAmI(cmd.Context(), client)
+		if err != nil {
+			return fmt.Errorf("%w", err)
+		}
+
+		tokenMgr, err := newTokenManager(cmd)
+		if err != nil {
+			return err
+		}
+
+		info, err := tokenMgr.Info()
+		if err == nil {
+			recordTokenExpiry(tokenMgr, account, info.Expires)
+		}
+
+		if asJSON {
+			return printJSON(account)
+		}
+
+		scopes := "unknown"
+		if len(account.Scopes) > 0 {
+			scopes = strings.Join(account.Scopes, ", ")
+		}
+
+		fmt.Printf("Account: %s (%s)\n", account.Name, account.ID)
+		fmt.Printf("Scopes:  %s\n", scopes)
+
+		return nil
+	},
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// accountsTable is the table whose sub-tables, keyed by account name, hold
+// the defaults of each account.
+const accountsTable = "accounts"
+
+var (
+	// ErrUnknownAccount is returned for an account that isn't in the
+	// [accounts] table.
+	ErrUnknownAccount = errors.New("unknown account")
+
+	errInvalidAccountName = errors.New(
+		"invalid account name, use letters, digits, '.', '_' and '-' only",
+	)
+)
+
+// Accounts returns the names of all accounts of the [accounts] table in
+// alphabetical order.
+func (c *Config) Accounts() []string {
+	table, _ := c.values[accountsTable].(map[string]any)
+
+	return slices.Sorted(maps.Keys(table))
+}
+
+// AccountValues returns the values of the account with the given name
+// formatted as flag values, with arrays joined by commas, and reports whether
+// the account exists.
+func (c *Config) AccountValues(name string) (map[string]string, bool, error) {
+	overrides, ok := c.account(name)
+	if !ok {
+		return nil, false, nil
+	}
+
+	account := &Config{path: c.path, values: overrides}
+	values := make(map[string]string, len(overrides))
+
+	for key := range overrides {
+		formatted, _, err := account.Get(key)
+		if err != nil {
+			return nil, true, err
+		}
+
+		values[key] = strings.Join(formatted, ",")
+	}
+
+	return values, true, nil
+}
+
+// AddAccount adds the account with the given name, keeping the values of an
+// existing account of the same name.
+func (c *Config) AddAccount(name string) error {
+	if !aliasName.MatchString(name) {
+		return fmt.Errorf("%w: %s", errInvalidAccountName, name)
+	}
+
+	table, ok := c.values[accountsTable].(map[string]any)
+	if !ok {
+		table = make(map[string]any)
+		c.values[accountsTable] = table
+	}
+
+	if _, ok := table[name].(map[string]any); !ok {
+		table[name] = make(map[string]any)
+	}
+
+	return nil
+}
+
+// SetAccountValue stores value for key in the account with the given name,
+// which has to exist. The value is converted as by Set.
+func (c *Config) SetAccountValue(name, key, value, flagType string) error {
+	overrides, ok := c.account(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownAccount, name)
+	}
+
+	return (&Config{path: c.path, values: overrides}).Set(key, value, flagType)
+}
+
+// RemoveAccount removes the account with the given name and reports whether
+// it existed.
+func (c *Config) RemoveAccount(name string) bool {
+	if _, ok := c.account(name); !ok {
+		return false
+	}
+
+	table, _ := c.values[accountsTable].(map[string]any)
+	delete(table, name)
+
+	if len(table) == 0 {
+		delete(c.values, accountsTable)
+	}
+
+	return true
+}
+
+// ForAccount returns the configuration with the values of the
+// [accounts.<name>] table of the account with the given name taking
+// precedence over the top-level ones. Unlike ForChannel, it fails with
+// ErrUnknownAccount if there is no such account.
+func (c *Config) ForAccount(name string) (*Config, error) {
+	overrides, ok := c.account(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAccount, name)
+	}
+
+	values := maps.Clone(c.values)
+	maps.Copy(values, overrides)
+
+	return &Config{path: c.path, values: values}, nil
+}
+
+// account returns the table of the account with the given name.
+func (c *Config) account(name string) (map[string]any, bool) {
+	table, _ := c.values[accountsTable].(map[string]any)
+	overrides, ok := table[name].(map[string]any)
+
+	return overrides, ok
+}
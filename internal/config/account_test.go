@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAccounts(t *testing.T) {
+	path := writeConfig(t, `
+output = "~/Videos"
+quality = "best"
+
+[accounts.zhaw]
+output = "/srv/zhaw"
+tags = ["a", "b"]
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := cfg.AddAccount("eth"); err != nil {
+		t.Fatalf("AddAccount() error = %v", err)
+	}
+
+	if err := cfg.SetAccountValue("eth", "base-url", "https://test.tube.switch.ch", "string"); err != nil {
+		t.Fatalf("SetAccountValue() error = %v", err)
+	}
+
+	if err := cfg.AddAccount("eth"); err != nil {
+		t.Fatalf("AddAccount() of existing account error = %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got, want := loaded.Accounts(), []string{"eth", "zhaw"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Accounts() = %v, want %v", got, want)
+	}
+
+	values, ok, err := loaded.AccountValues("eth")
+	if err != nil || !ok || values["base-url"] != "https://test.tube.switch.ch" {
+		t.Errorf("AccountValues(eth) = %v, %v, %v, want the base url kept by AddAccount", values, ok, err)
+	}
+
+	values, _, _ = loaded.AccountValues("zhaw")
+	if want := map[string]string{"output": "/srv/zhaw", "tags": "a,b"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("AccountValues(zhaw) = %v, want %v", values, want)
+	}
+
+	account, err := loaded.ForAccount("zhaw")
+	if err != nil {
+		t.Fatalf("ForAccount() error = %v", err)
+	}
+
+	for key, want := range map[string]string{"output": "/srv/zhaw", "quality": "best"} {
+		if got, _, _ := account.Get(key); len(got) != 1 || got[0] != want {
+			t.Errorf("ForAccount(zhaw).Get(%q) = %v, want %q", key, got, want)
+		}
+	}
+
+	if !loaded.RemoveAccount("zhaw") || loaded.RemoveAccount("zhaw") {
+		t.Error("RemoveAccount() didn't report whether the account existed")
+	}
+
+	if _, err := loaded.ForAccount("zhaw"); !errors.Is(err, ErrUnknownAccount) {
+		t.Errorf("ForAccount() of removed account error = %v, want %v", err, ErrUnknownAccount)
+	}
+}
+
+func TestAccountErrors(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := cfg.AddAccount("https://tube.switch.ch"); !errors.Is(err, errInvalidAccountName) {
+		t.Errorf("AddAccount() error = %v, want %v", err, errInvalidAccountName)
+	}
+
+	if err := cfg.SetAccountValue("eth", "output", "/srv", "string"); !errors.Is(err, ErrUnknownAccount) {
+		t.Errorf("SetAccountValue() error = %v, want %v", err, ErrUnknownAccount)
+	}
+}
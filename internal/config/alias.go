@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// aliasesTable is the table mapping alias names to the ids or links they
+// stand for.
+const aliasesTable = "aliases"
+
+var (
+	errEmptyAliasTarget = errors.New("alias target must not be empty")
+	errInvalidAliasName = errors.New(
+		"invalid alias name, use letters, digits, '.', '_' and '-' only",
+	)
+)
+
+// aliasName matches valid alias names, which can't be mistaken for a link.
+var aliasName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Aliases returns all aliases of the [aliases] table with the id or link
+// they stand for.
+func (c *Config) Aliases() map[string]string {
+	table, _ := c.values[aliasesTable].(map[string]any)
+
+	aliases := make(map[string]string, len(table))
+
+	for name, target := range table {
+		if target, ok := target.(string); ok {
+			aliases[name] = target
+		}
+	}
+
+	return aliases
+}
+
+// SetAlias defines name as an alias of target, replacing an existing alias of
+// the same name.
+func (c *Config) SetAlias(name, target string) error {
+	if !aliasName.MatchString(name) {
+		return fmt.Errorf("%w: %s", errInvalidAliasName, name)
+	}
+
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return errEmptyAliasTarget
+	}
+
+	table, ok := c.values[aliasesTable].(map[string]any)
+	if !ok {
+		table = make(map[string]any)
+		c.values[aliasesTable] = table
+	}
+
+	table[name] = target
+
+	return nil
+}
+
+// RemoveAlias removes the alias name and reports whether it existed.
+func (c *Config) RemoveAlias(name string) bool {
+	table, _ := c.values[aliasesTable].(map[string]any)
+	if _, ok := table[name]; !ok {
+		return false
+	}
+
+	delete(table, name)
+
+	if len(table) == 0 {
+		delete(c.values, aliasesTable)
+	}
+
+	return true
+}
+
+// ResolveAlias returns the id or link media stands for if it is an alias, and
+// media itself otherwise.
+func (c *Config) ResolveAlias(media string) string {
+	if target, ok := c.Aliases()[strings.TrimSpace(media)]; ok {
+		return target
+	}
+
+	return media
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	path := writeConfig(t, `
+[aliases]
+os-course = "https://tube.switch.ch/channels/abc123"
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := cfg.SetAlias("math", "def456"); err != nil {
+		t.Fatalf("SetAlias() error = %v", err)
+	}
+
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		media string
+		want  string
+	}{
+		{media: "os-course", want: "https://tube.switch.ch/channels/abc123"},
+		{media: " math ", want: "def456"},
+		{media: "xyz789", want: "xyz789"},
+	}
+
+	for _, tt := range tests {
+		if got := loaded.ResolveAlias(tt.media); got != tt.want {
+			t.Errorf("ResolveAlias(%q) = %q, want %q", tt.media, got, tt.want)
+		}
+	}
+
+	if !loaded.RemoveAlias("math") || loaded.RemoveAlias("math") {
+		t.Error("RemoveAlias() should only report an existing alias as removed")
+	}
+
+	if got := len(loaded.Aliases()); got != 1 {
+		t.Errorf("len(Aliases()) = %d, want 1", got)
+	}
+
+	if values, ok, _ := loaded.Get(aliasesTable); ok {
+		t.Errorf("Get(%q) = %v, want no flag value", aliasesTable, values)
+	}
+}
+
+func TestSetAliasInvalid(t *testing.T) {
+	cfg, err := Load(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for _, name := range []string{"", "tube.switch.ch/channels/abc", "-flag", "two words"} {
+		if err := cfg.SetAlias(name, "abc123"); !errors.Is(err, errInvalidAliasName) {
+			t.Errorf("SetAlias(%q) error = %v, want %v", name, err, errInvalidAliasName)
+		}
+	}
+
+	if err := cfg.SetAlias("course", " "); !errors.Is(err, errEmptyAliasTarget) {
+		t.Errorf("SetAlias() error = %v, want %v", err, errEmptyAliasTarget)
+	}
+}
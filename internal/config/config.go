@@ -0,0 +1,422 @@
+// Package config provides loading of the configuration file, which stores
+// default values for command-line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// dirName is the name of the application folder in the user config dir.
+	dirName = "switchtube-dl"
+
+	// fileName is the name of the configuration file.
+	fileName = "config.toml"
+
+	// envPrefix is the prefix of environment variables overriding flags.
+	envPrefix = "SWITCHTUBE_"
+
+	// channelsTable is the table whose sub-tables, keyed by channel id,
+	// override values for a single channel.
+	channelsTable = "channels"
+
+	// File and directory permissions.
+	dirPermissions  = 0o750
+	filePermissions = 0o600
+)
+
+var (
+	errFailedToApplyValue  = errors.New("invalid config value")
+	errFailedToCreateDir   = errors.New("failed to create config directory")
+	errFailedToDecode      = errors.New("failed to decode config file")
+	errFailedToEncode      = errors.New("failed to encode config file")
+	errFailedToExpandHome  = errors.New("failed to expand home directory")
+	errFailedToGetCacheDir = errors.New("failed to get user cache directory")
+	errFailedToGetDir      = errors.New("failed to get user config directory")
+	errFailedToRead        = errors.New("failed to read config file")
+	errFailedToResolvePath = errors.New("failed to resolve config path")
+	errFailedToWrite       = errors.New("failed to write config file")
+	errInvalidValue        = errors.New("invalid value")
+	errUnsupportedValue    = errors.New("unsupported config value type")
+)
+
+// Config represents the contents of the configuration file. Top-level keys
+// are named after the command-line flags they provide defaults for.
+type Config struct {
+	path   string
+	values map[string]any
+}
+
+// Dir returns the application folder inside the user config dir, e.g.
+// ~/.config/switchtube-dl on Linux.
+func Dir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToGetDir, err)
+	}
+
+	return filepath.Join(configDir, dirName), nil
+}
+
+// CacheDir returns the application folder inside the user cache dir, e.g.
+// ~/.cache/switchtube-dl on Linux.
+func CacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToGetCacheDir, err)
+	}
+
+	return filepath.Join(cacheDir, dirName), nil
+}
+
+// DefaultPath returns the default location of the configuration file, e.g.
+// ~/.config/switchtube-dl/config.toml on Linux.
+func DefaultPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, fileName), nil
+}
+
+// Load reads the configuration file at path. If path is empty the default
+// location is used. A missing file results in an empty configuration.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		defaultPath, err := DefaultPath()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errFailedToResolvePath, err)
+		}
+
+		path = defaultPath
+	}
+
+	config := &Config{
+		path:   path,
+		values: make(map[string]any),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToRead, err)
+	}
+
+	if _, err := toml.Decode(string(data), &config.values); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errFailedToDecode, path, err)
+	}
+
+	return config, nil
+}
+
+// Get returns the value of key formatted as flag values. Arrays result in one
+// value per element. Tables are not flag values and are never returned.
+func (c *Config) Get(key string) ([]string, bool, error) {
+	value, ok := c.values[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if _, isTable := value.(map[string]any); isTable {
+		return nil, false, nil
+	}
+
+	elements, isArray := value.([]any)
+	if !isArray {
+		elements = []any{value}
+	}
+
+	result := make([]string, 0, len(elements))
+
+	for _, element := range elements {
+		formatted, err := formatValue(element)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: %s", err, key)
+		}
+
+		result = append(result, formatted)
+	}
+
+	return result, true, nil
+}
+
+// ForChannel returns the configuration with the values of the
+// [channels.<name>] tables of the given names taking precedence over the
+// top-level ones, later names over earlier ones. A channel may have a table
+// under its id and under each of its aliases. Without such tables, c itself
+// is returned.
+func (c *Config) ForChannel(names ...string) *Config {
+	channels, _ := c.values[channelsTable].(map[string]any)
+
+	var values map[string]any
+
+	for _, name := range names {
+		overrides, ok := channels[name].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if values == nil {
+			values = maps.Clone(c.values)
+		}
+
+		maps.Copy(values, overrides)
+	}
+
+	if values == nil {
+		return c
+	}
+
+	return &Config{path: c.path, values: values}
+}
+
+// Path returns the location of the configuration file.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Set stores value for key. The value is converted according to flagType,
+// the pflag type name of the flag the key belongs to (e.g. "bool").
+func (c *Config) Set(key, value, flagType string) error {
+	parsed, err := parseValue(value, flagType)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	c.values[key] = parsed
+
+	return nil
+}
+
+// Unset removes key from the configuration, so that the flag it belongs to
+// falls back to its default.
+func (c *Config) Unset(key string) {
+	delete(c.values, key)
+}
+
+// Save writes the configuration to its file, creating the directory if
+// necessary.
+func (c *Config) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), dirPermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToCreateDir, err)
+	}
+
+	file, err := os.OpenFile(c.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filePermissions)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWrite, err)
+	}
+
+	if err := c.Encode(file); err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWrite, err)
+	}
+
+	return nil
+}
+
+// Encode writes the configuration in TOML format to w.
+func (c *Config) Encode(w io.Writer) error {
+	if err := toml.NewEncoder(w).Encode(c.values); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToEncode, err)
+	}
+
+	return nil
+}
+
+// ApplyToFlags sets every flag that wasn't given on the command line to its
+// value from the configuration, so flags always take precedence.
+func (c *Config) ApplyToFlags(flags *pflag.FlagSet) error {
+	var applyErr error
+
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if applyErr != nil || flag.Changed {
+			return
+		}
+
+		values, ok, err := c.Get(flag.Name)
+		if err != nil {
+			applyErr = err
+
+			return
+		} else if !ok {
+			return
+		}
+
+		for _, value := range values {
+			if err := flags.Set(flag.Name, value); err != nil {
+				applyErr = fmt.Errorf("%w: %s: %w", errFailedToApplyValue, flag.Name, err)
+
+				return
+			}
+		}
+	})
+
+	return applyErr
+}
+
+// EnvName returns the name of the environment variable providing the default
+// of the flag with the given name, e.g. SWITCHTUBE_OUTPUT for "output".
+func EnvName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Environ returns the SWITCHTUBE_* environment variables in the form
+// "NAME=value", sorted by name, e.g. to pass them on to a service.
+func Environ() []string {
+	var environ []string
+
+	for _, variable := range os.Environ() {
+		if strings.HasPrefix(variable, envPrefix) {
+			environ = append(environ, variable)
+		}
+	}
+
+	slices.Sort(environ)
+
+	return environ
+}
+
+// ApplyEnv sets every flag that is still unset to the value of its
+// environment variable. It is meant to run after ApplyToFlags, resulting in
+// the precedence environment < config file < command line. renamed maps the
+// former names of flags to their current ones, whose environment variables
+// are still read if the current one isn't set.
+func ApplyEnv(flags *pflag.FlagSet, renamed map[string]string) error {
+	var applyErr error
+
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if applyErr != nil || flag.Changed {
+			return
+		}
+
+		name, value, ok := lookupEnv(flag.Name, renamed)
+		if !ok {
+			return
+		}
+
+		if err := flags.Set(flag.Name, value); err != nil {
+			applyErr = fmt.Errorf("%w: %s: %w", errFailedToApplyValue, name, err)
+		}
+	})
+
+	return applyErr
+}
+
+// lookupEnv returns the name and value of the environment variable of the
+// flag with the given name, falling back to the ones of its former names in
+// renamed.
+func lookupEnv(flagName string, renamed map[string]string) (string, string, bool) {
+	name := EnvName(flagName)
+	if value, ok := os.LookupEnv(name); ok {
+		return name, value, true
+	}
+
+	for _, former := range slices.Sorted(maps.Keys(renamed)) {
+		if renamed[former] != flagName {
+			continue
+		}
+
+		formerName := EnvName(former)
+		if value, ok := os.LookupEnv(formerName); ok {
+			return formerName, value, true
+		}
+	}
+
+	return "", "", false
+}
+
+// parseValue converts a command-line value to the TOML representation
+// matching flagType, validating it on the way.
+func parseValue(value, flagType string) (any, error) {
+	switch flagType {
+	case "bool":
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidValue, err)
+		}
+
+		return parsed, nil
+	case "int", "int64", "count":
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidValue, err)
+		}
+
+		return parsed, nil
+	case "float64":
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidValue, err)
+		}
+
+		return parsed, nil
+	case "duration":
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidValue, err)
+		}
+
+		return value, nil
+	case "stringSlice", "stringArray":
+		parts := strings.Split(value, ",")
+
+		elements := make([]any, len(parts))
+		for i, part := range parts {
+			elements[i] = strings.TrimSpace(part)
+		}
+
+		return elements, nil
+	default:
+		return value, nil
+	}
+}
+
+// formatValue formats a single TOML value as flag value. Strings starting with
+// "~/" are expanded to the home directory.
+func formatValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return expandHome(v)
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("%w: %T", errUnsupportedValue, value)
+	}
+}
+
+// expandHome replaces a leading "~/" with the home directory of the user.
+func expandHome(path string) (string, error) {
+	rest, found := strings.CutPrefix(path, "~/")
+	if !found {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToExpandHome, err)
+	}
+
+	return filepath.Join(home, rest), nil
+}
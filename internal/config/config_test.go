@@ -0,0 +1,392 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// writeConfig writes content to a config file in a temporary directory and
+// returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	return path
+}
+
+// newFlagSet creates a flag set resembling the one of the download command.
+func newFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringP("output", "o", "", "")
+	flags.BoolP("episode", "e", false, "")
+	flags.Int("retries", 0, "")
+	flags.StringSlice("tags", nil, "")
+
+	return flags
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v, want nil", err)
+	}
+
+	if _, ok, _ := cfg.Get("output"); ok {
+		t.Error("Get() on empty config returned a value")
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := writeConfig(t, "output = \n")
+
+	if _, err := Load(path); !errors.Is(err, errFailedToDecode) {
+		t.Errorf("Load() error = %v, want %v", err, errFailedToDecode)
+	}
+}
+
+func TestApplyToFlags(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("Failed to get home directory: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		args    []string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name:    "values from config",
+			content: "output = \"videos\"\nepisode = true\nretries = 3\n",
+			args:    nil,
+			want:    map[string]string{"output": "videos", "episode": "true", "retries": "3"},
+		},
+		{
+			name:    "flags take precedence",
+			content: "output = \"videos\"\nepisode = true\n",
+			args:    []string{"-o", "cli", "--episode=false"},
+			want:    map[string]string{"output": "cli", "episode": "false"},
+		},
+		{
+			name:    "home directory is expanded",
+			content: "output = \"~/Videos\"\n",
+			args:    nil,
+			want:    map[string]string{"output": filepath.Join(home, "Videos")},
+		},
+		{
+			name:    "arrays set every element",
+			content: "tags = [\"a\", \"b\"]\n",
+			args:    nil,
+			want:    map[string]string{"tags": "[a,b]"},
+		},
+		{
+			name:    "tables and unknown keys are ignored",
+			content: "unknown = 1\n[section]\noutput = \"nested\"\n",
+			args:    nil,
+			want:    map[string]string{"output": ""},
+		},
+		{
+			name:    "invalid value",
+			content: "retries = \"many\"\n",
+			args:    nil,
+			wantErr: errFailedToApplyValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.content))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			flags := newFlagSet()
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+
+			err = cfg.ApplyToFlags(flags)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ApplyToFlags() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ApplyToFlags() error = %v", err)
+			}
+
+			for name, want := range tt.want {
+				if got := flags.Lookup(name).Value.String(); got != want {
+					t.Errorf("flag %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestForChannel(t *testing.T) {
+	content := "output = \"videos\"\nretries = 2\n" +
+		"[channels.dh0sX6Fj1I]\noutput = \"os\"\nepisode = true\n" +
+		"[channels.os]\noutput = \"alias\"\nretries = 5\n"
+
+	cfg, err := Load(writeConfig(t, content))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		channels []string
+		args     []string
+		want     map[string]string
+	}{
+		{
+			name:     "overrides of the channel",
+			channels: []string{"dh0sX6Fj1I"},
+			want:     map[string]string{"output": "os", "episode": "true", "retries": "2"},
+		},
+		{
+			name:     "flags take precedence",
+			channels: []string{"dh0sX6Fj1I"},
+			args:     []string{"-o", "cli"},
+			want:     map[string]string{"output": "cli", "episode": "true"},
+		},
+		{
+			name:     "alias and id",
+			channels: []string{"os", "dh0sX6Fj1I"},
+			want:     map[string]string{"output": "os", "episode": "true", "retries": "5"},
+		},
+		{
+			name:     "other channel",
+			channels: []string{"a1B2c3"},
+			want:     map[string]string{"output": "videos", "episode": "false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := newFlagSet()
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+
+			if err := cfg.ForChannel(tt.channels...).ApplyToFlags(flags); err != nil {
+				t.Fatalf("ApplyToFlags() error = %v", err)
+			}
+
+			for name, want := range tt.want {
+				if got := flags.Lookup(name).Value.String(); got != want {
+					t.Errorf("flag %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+
+	// The overrides don't leak into the configuration of other channels
+	if values, _, _ := cfg.Get("output"); len(values) != 1 || values[0] != "videos" {
+		t.Errorf("Get(output) = %v after ForChannel(), want [videos]", values)
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	t.Setenv("SWITCHTUBE_OUTPUT", "videos")
+	t.Setenv("SWITCHTUBE_TOKEN", "secret")
+	t.Setenv("OTHER_OUTPUT", "other")
+
+	got := Environ()
+	want := []string{"SWITCHTUBE_OUTPUT=videos", "SWITCHTUBE_TOKEN=secret"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Environ() = %v, want %v", got, want)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"output":        "SWITCHTUBE_OUTPUT",
+		"force":         "SWITCHTUBE_FORCE",
+		"video-timeout": "SWITCHTUBE_VIDEO_TIMEOUT",
+	}
+
+	for flagName, want := range tests {
+		if got := EnvName(flagName); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", flagName, got, want)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("SWITCHTUBE_OUTPUT", "from-env")
+	t.Setenv("SWITCHTUBE_EPISODE", "true")
+	t.Setenv("SWITCHTUBE_RETRIES", "5")
+
+	cfg, err := Load(writeConfig(t, "retries = 2\n"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	flags := newFlagSet()
+	if err := flags.Parse([]string{"--episode=false"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	if err := cfg.ApplyToFlags(flags); err != nil {
+		t.Fatalf("ApplyToFlags() error = %v", err)
+	}
+
+	if err := ApplyEnv(flags, nil); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+
+	want := map[string]string{
+		"output":  "from-env", // only set in the environment
+		"episode": "false",    // command line beats environment
+		"retries": "2",        // config file beats environment
+	}
+
+	for name, value := range want {
+		if got := flags.Lookup(name).Value.String(); got != value {
+			t.Errorf("flag %s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestApplyEnvRenamed(t *testing.T) {
+	renamed := map[string]string{"folder": "output", "repeat": "retries"}
+
+	t.Setenv("SWITCHTUBE_FOLDER", "from-former")
+	t.Setenv("SWITCHTUBE_RETRIES", "3")
+	t.Setenv("SWITCHTUBE_REPEAT", "5")
+
+	flags := newFlagSet()
+	if err := ApplyEnv(flags, renamed); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+
+	want := map[string]string{
+		"output":  "from-former", // only the former name is set
+		"retries": "3",           // the current name beats the former one
+	}
+
+	for name, value := range want {
+		if got := flags.Lookup(name).Value.String(); got != value {
+			t.Errorf("flag %s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestApplyEnvInvalidValue(t *testing.T) {
+	t.Setenv("SWITCHTUBE_EPISODE", "maybe")
+
+	if err := ApplyEnv(newFlagSet(), nil); !errors.Is(err, errFailedToApplyValue) {
+		t.Errorf("ApplyEnv() error = %v, want %v", err, errFailedToApplyValue)
+	}
+}
+
+func TestSetAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", fileName)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		key      string
+		value    string
+		flagType string
+		wantErr  bool
+	}{
+		{key: "output", value: "videos", flagType: "string"},
+		{key: "episode", value: "true", flagType: "bool"},
+		{key: "retries", value: "3", flagType: "int"},
+		{key: "interval", value: "1h", flagType: "duration"},
+		{key: "tags", value: "a, b", flagType: "stringSlice"},
+		{key: "episode", value: "yes please", flagType: "bool", wantErr: true},
+		{key: "retries", value: "many", flagType: "int", wantErr: true},
+		{key: "interval", value: "soon", flagType: "duration", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := cfg.Set(tt.key, tt.value, tt.flagType)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Set(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+		}
+
+		if tt.wantErr && !errors.Is(err, errInvalidValue) {
+			t.Errorf("Set(%q, %q) error = %v, want %v", tt.key, tt.value, err, errInvalidValue)
+		}
+	}
+
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := map[string]string{
+		"output":   "videos",
+		"episode":  "true",
+		"retries":  "3",
+		"interval": "1h",
+		"tags":     "a,b",
+	}
+
+	for key, value := range want {
+		values, ok, err := loaded.Get(key)
+		if err != nil || !ok {
+			t.Fatalf("Get(%q) = %v, %v, %v", key, values, ok, err)
+		}
+
+		if got := strings.Join(values, ","); got != value {
+			t.Errorf("Get(%q) = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestUnset(t *testing.T) {
+	path := writeConfig(t, "smallest = true\nmax-height = 720\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	cfg.Unset("smallest")
+	cfg.Unset("missing")
+
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if values, ok, _ := loaded.Get("smallest"); ok {
+		t.Errorf("Get(smallest) = %v, want unset", values)
+	}
+
+	if values, ok, _ := loaded.Get("max-height"); !ok || values[0] != "720" {
+		t.Errorf("Get(max-height) = %v, %v, want 720", values, ok)
+	}
+}
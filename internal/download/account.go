@@ -0,0 +1,48 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"switchtube-downloader/internal/models"
+)
+
+var (
+	// ErrAccountUnavailable is returned by WhoAmI if SwitchTube doesn't
+	// report the account of the access token.
+	ErrAccountUnavailable = errors.New("the account of the access token isn't available")
+
+	errFailedToGetAccount = errors.New("failed to get account")
+)
+
+// WhoAmI returns the account the access token of client belongs to. The
+// account endpoint isn't part of the documented API, so an instance that
+// doesn't have it results in ErrAccountUnavailable rather than ErrNotFound.
+func WhoAmI(ctx context.Context, client *Client) (*models.Account, error) {
+	fullURL, err := url.JoinPath(client.baseURL(), accountAPI)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	return client.account(ctx, fullURL)
+}
+
+// account retrieves the account the access token belongs to from fullURL.
+func (c *Client) account(ctx context.Context, fullURL string) (*models.Account, error) {
+	var account models.Account
+
+	err := c.makeJSONRequest(ctx, fullURL, &account)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrAccountUnavailable, fullURL)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToGetAccount, err)
+	}
+
+	if account.Scopes == nil {
+		account.Scopes = []string{}
+	}
+
+	return &account, nil
+}
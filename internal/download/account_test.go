@@ -0,0 +1,82 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+)
+
+func TestAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    models.Account
+		wantErr error
+	}{
+		{
+			name:   "with scopes",
+			status: http.StatusOK,
+			body:   `{"id":"42","name":"Ada Lovelace","scopes":["browse"]}`,
+			want:   models.Account{ID: "42", Name: "Ada Lovelace", Scopes: []string{"browse"}},
+		},
+		{
+			name:   "without scopes",
+			status: http.StatusOK,
+			body:   `{"id":"42","name":"Ada Lovelace"}`,
+			want:   models.Account{ID: "42", Name: "Ada Lovelace", Scopes: []string{}},
+		},
+		{
+			name:   "with expiry",
+			status: http.StatusOK,
+			body:   `{"id":"42","name":"Ada Lovelace","expires_at":"2027-01-01T00:00:00Z"}`,
+			want: models.Account{
+				ID: "42", Name: "Ada Lovelace", Scopes: []string{},
+				ExpiresAt: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{name: "invalid token", status: http.StatusUnauthorized, wantErr: ErrUnauthorized},
+		{name: "no account endpoint", status: http.StatusNotFound, wantErr: ErrAccountUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get(headerAuthorization) != "Token secret" {
+					t.Errorf("Authorization = %q", r.Header.Get(headerAuthorization))
+				}
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(token.NewTokenManagerWithToken("secret"), models.ClientConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			account, err := client.account(context.Background(), server.URL)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("account() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if account.ID != tt.want.ID || account.Name != tt.want.Name ||
+				!slices.Equal(account.Scopes, tt.want.Scopes) || account.Scopes == nil ||
+				!account.ExpiresAt.Equal(tt.want.ExpiresAt) {
+				t.Errorf("account() = %+v, want %+v", account, tt.want)
+			}
+		})
+	}
+}
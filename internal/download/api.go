@@ -0,0 +1,124 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"switchtube-downloader/internal/models"
+)
+
+// headerRange requests the rest of a video when resuming a download.
+const headerRange = "Range"
+
+// API is the part of the SwitchTube API the downloaders use. Client
+// implements it by talking to SwitchTube. Tests and programs embedding the
+// downloader can replace it with WithAPI, e.g. to use a mock or an httptest
+// server.
+type API interface {
+	// GetVideo returns the metadata of a video.
+	GetVideo(ctx context.Context, videoID string) (*models.Video, error)
+
+	// GetVariants returns the downloadable variants of a video, the first of
+	// which is downloaded. A variant has size -1 if it is unknown.
+	GetVariants(ctx context.Context, videoID string) ([]models.Variant, error)
+
+	// GetChannelVideos returns all videos of a channel.
+	GetChannelVideos(ctx context.Context, channelID string) ([]models.Video, error)
+
+	// Stream requests the media at the path of a variant starting at byte
+	// offset, which is only non-zero when resuming an incomplete download.
+	// The caller closes the body of the response.
+	Stream(ctx context.Context, path string, offset int64) (*http.Response, error)
+}
+
+// WithAPI returns a copy of c whose downloaders use api instead of c for the
+// requests of API.
+func (c *Client) WithAPI(api API) *Client {
+	clone := *c
+	clone.api = api
+
+	return &clone
+}
+
+// currentAPI returns the API the downloaders of c use, which is nil if c is.
+func (c *Client) currentAPI() API {
+	if c == nil {
+		return nil
+	}
+
+	return c.api
+}
+
+// GetVideo retrieves the metadata of a video.
+func (c *Client) GetVideo(ctx context.Context, videoID string) (*models.Video, error) {
+	fullURL, err := url.JoinPath(c.baseURL(), videoAPI, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	var videoData models.Video
+	if err := c.makeJSONRequest(ctx, fullURL, &videoData); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToDecodeVideoMeta, err)
+	}
+
+	return &videoData, nil
+}
+
+// GetVariants retrieves the available variants of a video. Their sizes are
+// unknown, since SwitchTube only reports them for the media itself.
+func (c *Client) GetVariants(ctx context.Context, videoID string) ([]models.Variant, error) {
+	fullURL, err := url.JoinPath(c.baseURL(), videoAPI, videoID, "video_variants")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	var variants []models.Variant
+	if err := c.makeJSONRequest(ctx, fullURL, &variants); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToDecodeVariants, err)
+	}
+
+	for i := range variants {
+		variants[i].Size = unknownSize
+	}
+
+	return variants, nil
+}
+
+// GetChannelVideos retrieves all videos of a channel, following pagination.
+func (c *Client) GetChannelVideos(
+	ctx context.Context,
+	channelID string,
+) ([]models.Video, error) {
+	fullURL, err := url.JoinPath(c.baseURL(), channelAPI, channelID, "videos")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	videos, err := fetchAllPages[models.Video](ctx, c, fullURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToDecodeChannelVideos, err)
+	}
+
+	slog.Info("fetched channel videos", "id", channelID, "videos", len(videos))
+
+	return videos, nil
+}
+
+// Stream requests the media at path for downloading, starting at offset with
+// a range request if it isn't zero.
+func (c *Client) Stream(ctx context.Context, path string, offset int64) (*http.Response, error) {
+	fullURL, err := url.JoinPath(c.baseURL(), path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	var header http.Header
+	if offset > 0 {
+		header = http.Header{headerRange: {fmt.Sprintf("bytes=%d-", offset)}}
+	}
+
+	return c.makeStreamRequest(ctx, fullURL, header)
+}
@@ -0,0 +1,83 @@
+package download
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+)
+
+// mockAPI serves a single video from memory.
+type mockAPI struct {
+	video    models.Video
+	variants []models.Variant
+	data     string
+	streamed []string
+}
+
+func (m *mockAPI) GetVideo(context.Context, string) (*models.Video, error) {
+	return &m.video, nil
+}
+
+func (m *mockAPI) GetVariants(context.Context, string) ([]models.Variant, error) {
+	return m.variants, nil
+}
+
+func (m *mockAPI) GetChannelVideos(context.Context, string) ([]models.Video, error) {
+	return []models.Video{m.video}, nil
+}
+
+func (m *mockAPI) Stream(_ context.Context, path string, _ int64) (*http.Response, error) {
+	m.streamed = append(m.streamed, path)
+
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		ContentLength: int64(len(m.data)),
+		Body:          io.NopCloser(strings.NewReader(m.data)),
+	}, nil
+}
+
+type discardProgress struct{}
+
+func (discardProgress) Report(models.ProgressEvent) {}
+
+func TestDownloadVideoWithAPI(t *testing.T) {
+	api := &mockAPI{
+		video: models.Video{ID: "abc", Title: "Paging"},
+		variants: []models.Variant{
+			{MediaType: "video/mp4", Path: "media/abc.mp4", Size: 10},
+		},
+		data: "video data",
+	}
+
+	output := t.TempDir()
+	config := models.DownloadConfig{Output: output, Reporter: discardProgress{}}
+	client := (&Client{}).WithAPI(api)
+
+	downloader := newVideoDownloader(config, models.ProgressInfo{}, client)
+	if err := downloader.downloadVideo(context.Background(), "abc", true); err != nil {
+		t.Fatalf("downloadVideo() error = %v", err)
+	}
+
+	if len(api.streamed) != 1 || api.streamed[0] != "media/abc.mp4" {
+		t.Errorf("streamed = %v, want [media/abc.mp4]", api.streamed)
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "Paging.mp4"))
+	if err != nil {
+		t.Fatalf("failed to read downloaded video: %v", err)
+	}
+
+	if string(data) != api.data {
+		t.Errorf("downloaded video = %q, want %q", data, api.data)
+	}
+
+	if size := client.variantSize(context.Background(), api.variants[0]); size != 10 {
+		t.Errorf("variantSize() = %d, want the size reported by the API", size)
+	}
+}
@@ -0,0 +1,136 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"switchtube-downloader/internal/models"
+)
+
+var (
+	errChannelOrProfileRequired = errors.New("a channel or profile id or url is required")
+	errFailedToBrowse           = errors.New("failed to browse")
+)
+
+// BrowseChannels returns the channels to browse for media, which is either a
+// single channel or all channels of a profile, and the name of the profile,
+// which is empty for a single channel.
+func BrowseChannels(
+	ctx context.Context,
+	client *Client,
+	media string,
+) (string, []models.Channel, error) {
+	id, downloadType, err := extractIDAndType(media, client.baseURL())
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", errFailedToExtractType, err)
+	}
+
+	var config models.DownloadConfig
+
+	switch downloadType {
+	case videoType:
+		return "", nil, errChannelOrProfileRequired
+	case profileType:
+		return profileChannels(ctx, client, config, id)
+	case channelType, unknownType:
+	}
+
+	channel, err := newChannelDownloader(config, client).getMetadata(ctx, id)
+	if err == nil {
+		channel.ID = id
+
+		return "", []models.Channel{*channel}, nil
+	}
+
+	// Like in Download, a bare id that is no channel may still be a profile
+	if downloadType == unknownType && errors.Is(err, ErrNotFound) {
+		return profileChannels(ctx, client, config, id)
+	}
+
+	return "", nil, fmt.Errorf("%w: %w", errFailedToBrowse, err)
+}
+
+// profileChannels returns the name and the channels of the profile with the
+// given id.
+func profileChannels(
+	ctx context.Context,
+	client *Client,
+	config models.DownloadConfig,
+	profileID string,
+) (string, []models.Channel, error) {
+	downloader := newProfileDownloader(config, client)
+
+	profile, err := downloader.getMetadata(ctx, profileID)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", errFailedToBrowse, err)
+	}
+
+	channels, err := downloader.getChannels(ctx, profileID)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", errFailedToBrowse, err)
+	}
+
+	return profile.Name, channels, nil
+}
+
+// ChannelVideos returns all videos of the channel with the given id.
+func ChannelVideos(
+	ctx context.Context,
+	client *Client,
+	channelID string,
+) ([]models.Video, error) {
+	videos, err := client.api.GetChannelVideos(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToGetChannelVideos, err)
+	}
+
+	return videos, nil
+}
+
+// DownloadSelection downloads the selected videos of a channel into the
+// folder given by the output template, as if they had been picked in the
+// numeric selection. The output directory is locked while downloading.
+func DownloadSelection(
+	ctx context.Context,
+	client *Client,
+	config models.DownloadConfig,
+	selection models.ChannelSelection,
+) error {
+	videos, err := ChannelVideos(ctx, client, selection.Channel.ID)
+	if err != nil {
+		return err
+	}
+
+	var indices []int
+
+	for i, video := range videos {
+		if slices.ContainsFunc(selection.Videos, func(selected models.Video) bool {
+			return selected.ID == video.ID
+		}) {
+			indices = append(indices, i)
+		}
+	}
+
+	if len(indices) == 0 {
+		fmt.Fprintln(os.Stderr, "No videos selected for download")
+
+		return nil
+	}
+
+	downloader := newChannelDownloader(config, client)
+	downloader.profile = selection.Profile
+
+	folderName, unlock, err := downloader.createFolder(selection.Channel.ID, selection.Channel.Name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	downloader.config.Output = folderName
+	fmt.Fprintf(os.Stderr, "Downloading to folder: %s\n", folderName)
+
+	return downloader.downloadSelectedVideos(ctx, selection.Channel, videos, indices)
+}
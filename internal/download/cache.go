@@ -0,0 +1,128 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfNoneMatch = "If-None-Match"
+
+	// File and directory permissions of the response cache, which may
+	// contain private channels.
+	cacheDirPermissions  = 0o700
+	cacheFilePermissions = 0o600
+)
+
+// responseCache stores metadata responses with an ETag on disk, one file per
+// URL. A nil cache stores nothing.
+type responseCache struct {
+	dir string
+}
+
+// cachedResponse is a response stored in the cache. Link keeps the
+// pagination links of a cached page.
+type cachedResponse struct {
+	URL  string          `json:"url"`
+	ETag string          `json:"etag"`
+	Link []string        `json:"link"`
+	Body json.RawMessage `json:"body"`
+}
+
+// newResponseCache returns a cache in dir or nil if dir is empty.
+func newResponseCache(dir string) *responseCache {
+	if dir == "" {
+		return nil
+	}
+
+	return &responseCache{dir: dir}
+}
+
+// path returns the file the response of url is stored in.
+func (rc *responseCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+
+	return filepath.Join(rc.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load returns the cached response of url or nil if there is none.
+func (rc *responseCache) load(url string) *cachedResponse {
+	if rc == nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(rc.path(url))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to read cached response", "url", url, "error", err)
+		}
+
+		return nil
+	}
+
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil || cached.URL != url || cached.ETag == "" {
+		slog.Debug("ignoring invalid cached response", "url", url)
+
+		return nil
+	}
+
+	return &cached
+}
+
+// store caches body as the response of url if header has an ETag.
+func (rc *responseCache) store(url string, header http.Header, body []byte) {
+	etag := header.Get(headerETag)
+	if rc == nil || etag == "" {
+		return
+	}
+
+	data, err := json.Marshal(cachedResponse{
+		URL:  url,
+		ETag: etag,
+		Link: header.Values(headerLink),
+		Body: body,
+	})
+	if err != nil {
+		slog.Warn("failed to encode response for the cache", "url", url, "error", err)
+
+		return
+	}
+
+	if err := os.MkdirAll(rc.dir, cacheDirPermissions); err != nil {
+		slog.Warn("failed to create cache directory", "dir", rc.dir, "error", err)
+
+		return
+	}
+
+	if err := os.WriteFile(rc.path(url), data, cacheFilePermissions); err != nil {
+		slog.Warn("failed to cache response", "url", url, "error", err)
+	}
+}
+
+// requestHeader returns the header making a request conditional on the
+// cached response having changed, or nil if there is no cached response.
+func (cr *cachedResponse) requestHeader() http.Header {
+	if cr == nil {
+		return nil
+	}
+
+	return http.Header{headerIfNoneMatch: {cr.ETag}}
+}
+
+// header returns the response header of the cached response.
+func (cr *cachedResponse) header() http.Header {
+	header := http.Header{headerETag: {cr.ETag}}
+	for _, link := range cr.Link {
+		header.Add(headerLink, link)
+	}
+
+	return header
+}
@@ -0,0 +1,78 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+)
+
+func TestCachedJSONRequest(t *testing.T) {
+	const etag = `"v1"`
+
+	var requests, notModified int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		w.Header().Set(headerETag, etag)
+		w.Header().Set(headerLink, `</page2>; rel="next"`)
+
+		if r.Header.Get(headerIfNoneMatch) == etag {
+			notModified++
+
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Write([]byte(`{"id":"abc","name":"Operating Systems"}`))
+	}))
+	defer server.Close()
+
+	tm := token.NewTokenManagerWithToken("secret")
+
+	client, err := NewClient(tm, models.ClientConfig{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	for i := range 2 {
+		var channel models.Channel
+
+		header, err := client.makeJSONRequestWithHeader(context.Background(), server.URL, &channel)
+		if err != nil {
+			t.Fatalf("request %d: makeJSONRequestWithHeader() error = %v", i, err)
+		}
+
+		if channel.Name != "Operating Systems" {
+			t.Errorf("request %d: channel = %+v, want Operating Systems", i, channel)
+		}
+
+		if header.Get(headerLink) != `</page2>; rel="next"` {
+			t.Errorf("request %d: Link = %q, want the next page", i, header.Get(headerLink))
+		}
+	}
+
+	if requests != 2 || notModified != 1 {
+		t.Errorf("requests = %d, not modified = %d, want 2 requests, 1 not modified",
+			requests, notModified)
+	}
+}
+
+func TestResponseCacheDisabled(t *testing.T) {
+	var cache *responseCache
+
+	cache.store("https://example.com", http.Header{headerETag: {`"v1"`}}, []byte("{}"))
+
+	if cached := cache.load("https://example.com"); cached != nil {
+		t.Errorf("load() = %+v, want nil without a cache", cached)
+	}
+
+	if newResponseCache("") != nil {
+		t.Error("newResponseCache(\"\") != nil, want a disabled cache")
+	}
+}
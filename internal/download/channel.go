@@ -1,20 +1,23 @@
 package download
 
 import (
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/url"
+	"os"
+	"slices"
+	"sync"
+	"time"
 
 	"switchtube-downloader/internal/helper/dir"
 	"switchtube-downloader/internal/helper/ui"
 	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/postprocess"
 )
 
-// channelMetadata represents channel metadata.
-type channelMetadata struct {
-	Name string `json:"name"`
-}
-
 var (
 	errFailedToCreateChannelFolder = errors.New("failed to create channel folder")
 	errFailedToDecodeChannelMeta   = errors.New("failed to decode channel metadata")
@@ -24,176 +27,559 @@ var (
 	errFailedToSelectVideos        = errors.New("failed to select videos")
 )
 
+// retryPassDelay is the time to wait before retrying the failed videos of a
+// channel.
+const retryPassDelay = 5 * time.Second
+
+// prefetchWorkers limits the number of concurrent requests for the variants
+// of the selected videos of a channel.
+const prefetchWorkers = 8
+
+// prefetchedVariants are the variants of a selected video and the size of the
+// one that is downloaded.
+type prefetchedVariants struct {
+	variants []models.Variant
+	size     int64
+	err      error
+}
+
+// queuedVideo is a selected video that needs to be downloaded. title is the
+// title used in its file name.
+type queuedVideo struct {
+	index  int
+	size   int64
+	result int
+	title  string
+}
+
 // channelDownloader handles the downloading of channels.
 type channelDownloader struct {
 	config models.DownloadConfig
 	client *Client
+	api    API
+
+	// profile is the name of the profile the channel is downloaded with, if
+	// any, which the output template may nest the channel folder in.
+	profile string
+
+	// results are the results of the videos of the current run, in
+	// selection order.
+	results []models.VideoResult
+
+	// firstErr is the first error of a video that failed in the current run.
+	firstErr error
 }
 
 // newChannelDownloader creates a new instance of channelDownloader.
 func newChannelDownloader(config models.DownloadConfig, client *Client) *channelDownloader {
 	return &channelDownloader{
-		config: config,
-		client: client,
+		config:   config,
+		client:   client,
+		api:      client.currentAPI(),
+		profile:  "",
+		results:  nil,
+		firstErr: nil,
 	}
 }
 
-// downloadChannel downloads selected videos from a channel.
-func (cd *channelDownloader) downloadChannel(channelID string) error {
-	channelInfo, err := cd.getMetadata(channelID)
+// downloadChannel downloads selected videos from a channel. In mirror mode,
+// all videos that don't exist yet are downloaded and the files that no longer
+// belong to the channel are pruned afterwards.
+func (cd *channelDownloader) downloadChannel(ctx context.Context, channelID string) error {
+	if cd.config.Output == models.OutputStdout {
+		return errStdoutVideoOnly
+	}
+
+	if cd.config.Mirror {
+		cd.config.All = true
+		cd.config.Skip = true
+	}
+
+	channelInfo, err := cd.getMetadata(ctx, channelID)
 	if err != nil {
 		return fmt.Errorf("%w: %w", errFailedToGetChannelInfo, err)
 	}
 
-	videos, err := cd.getVideos(channelID)
+	videos, err := cd.api.GetChannelVideos(ctx, channelID)
 	if err != nil {
 		return fmt.Errorf("%w: %w", errFailedToGetChannelVideos, err)
 	}
 
 	if len(videos) == 0 {
-		fmt.Println("No videos found in this channel")
+		fmt.Fprintln(os.Stderr, "No videos found in this channel")
 
-		return nil
+		return cd.mirrorEmpty(channelID, channelInfo.Name)
+	}
+
+	videos = sortVideos(videos, cd.config)
+	channel := models.Channel{ID: channelID, Name: channelInfo.Name}
+
+	if cd.config.NewOnly {
+		return cd.downloadNewVideos(ctx, channel, videos)
 	}
 
-	fmt.Printf("Found %d videos in channel: %s\n", len(videos), channelInfo.Name)
+	fmt.Fprintf(os.Stderr, "Found %d videos in channel: %s\n", len(videos), channelInfo.Name)
 
-	selectedIndices, err := ui.SelectVideos(videos, cd.config.All)
+	selectedIndices, err := ui.SelectVideos(videos, cd.selectionSizes(ctx, videos), cd.config.All)
 	if err != nil {
 		return fmt.Errorf("%w: %w", errFailedToSelectVideos, err)
 	}
 
 	if len(selectedIndices) == 0 {
-		fmt.Println("No videos selected for download")
+		fmt.Fprintln(os.Stderr, "No videos selected for download")
 
 		return nil
 	}
 
-	folderName, err := dir.CreateChannelFolder(channelInfo.Name, cd.config)
+	folderName, unlock, err := cd.createFolder(channelID, channelInfo.Name)
 	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToCreateChannelFolder, err)
+		return err
 	}
+	defer unlock()
 
 	cd.config.Output = folderName
-	fmt.Printf("Downloading to folder: %s\n", folderName)
-	cd.downloadSelectedVideos(videos, selectedIndices)
+	fmt.Fprintf(os.Stderr, "Downloading to folder: %s\n", folderName)
 
-	return nil
+	err = cd.downloadSelectedVideos(ctx, channel, videos, selectedIndices)
+
+	if cd.config.Mirror {
+		cd.mirror(videos)
+	}
+
+	return err
+}
+
+// createFolder creates the folder of the channel according to the output
+// template and locks it against other instances until the returned function
+// is called. Channels can't be written to standard output.
+func (cd *channelDownloader) createFolder(channelID, name string) (string, func(), error) {
+	if cd.config.Output == models.OutputStdout {
+		return "", nil, errStdoutVideoOnly
+	}
+
+	folderName, err := dir.CreateFolder(dir.FolderFields{
+		Profile:   cd.profile,
+		Channel:   name,
+		ChannelID: channelID,
+	}, cd.config)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", errFailedToCreateChannelFolder, err)
+	}
+
+	unlock, err := lockFolder(folderName, cd.config.WaitLock)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return folderName, unlock, nil
+}
+
+// selectionSizes returns the size of every video for the selection list, or
+// nil if no list is shown or fetching the sizes is disabled.
+func (cd *channelDownloader) selectionSizes(ctx context.Context, videos []models.Video) []int64 {
+	if cd.config.All || cd.config.NoSize {
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, "Fetching video sizes (use --no-size to skip)...")
+
+	sizes := make([]int64, len(videos))
+	for i, video := range videos {
+		sizes[i] = cd.videoSize(ctx, video.ID)
+	}
+
+	return sizes
 }
 
 // getMetadata retrieves channel metadata from the API.
-func (cd *channelDownloader) getMetadata(channelID string) (*channelMetadata, error) {
-	fullURL, err := url.JoinPath(baseURL, channelAPI, channelID)
+func (cd *channelDownloader) getMetadata(
+	ctx context.Context,
+	channelID string,
+) (*models.Channel, error) {
+	fullURL, err := url.JoinPath(cd.client.baseURL(), channelAPI, channelID)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
 	}
 
-	var data channelMetadata
-	if err := cd.client.makeJSONRequest(fullURL, &data); err != nil {
+	var data models.Channel
+	if err := cd.client.makeJSONRequest(ctx, fullURL, &data); err != nil {
 		return nil, fmt.Errorf("%w: %w", errFailedToDecodeChannelMeta, err)
 	}
 
 	return &data, nil
 }
 
-// getVideos retrieves all videos from a channel.
-func (cd *channelDownloader) getVideos(channelID string) ([]models.Video, error) {
-	fullURL, err := url.JoinPath(baseURL, channelAPI, channelID, "videos")
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+// downloadSelectedVideos downloads the selected videos and reports results.
+// If any video failed, ErrPartialFailure is returned, or the error of the
+// first failed video if all of them did.
+func (cd *channelDownloader) downloadSelectedVideos(
+	ctx context.Context,
+	channel models.Channel,
+	videos []models.Video,
+	selectedIndices []int,
+) error {
+	start := time.Now()
+	cd.results = nil
+
+	failed := cd.downloadVideos(ctx, channel.Name, videos, selectedIndices)
+
+	summary := cd.summary(channel.Name, len(selectedIndices), failed)
+	cd.finishRun(channel, videos, summary, start)
+
+	return partialFailure(len(failed), len(selectedIndices), cd.firstErr)
+}
+
+// summary returns the summary of the current run, in which selectedCount
+// videos were selected and failed ones failed.
+func (cd *channelDownloader) summary(
+	channelName string,
+	selectedCount int,
+	failed []models.Video,
+) models.DownloadSummary {
+	downloaded := 0
+
+	for _, result := range cd.results {
+		if result.Status == models.StatusDownloaded {
+			downloaded++
+		}
+	}
+
+	return models.DownloadSummary{
+		Channel:    channelName,
+		Selected:   selectedCount,
+		Downloaded: downloaded,
+		Failed:     append([]models.Video{}, failed...),
+		Videos:     append([]models.VideoResult{}, cd.results...),
+	}
+}
+
+// addResult records the result of video with status and returns its
+// position in the results. The result of a previous pass of the video is
+// replaced.
+func (cd *channelDownloader) addResult(video models.Video, status string, size int64) int {
+	result := models.VideoResult{
+		ID:      video.ID,
+		Title:   video.Title,
+		Status:  status,
+		Size:    size,
+		Seconds: 0,
+		Speed:   0,
+		Exists:  false,
+		Error:   "",
 	}
 
-	var videos []models.Video
-	if err := cd.client.makeJSONRequest(fullURL, &videos); err != nil {
-		return nil, fmt.Errorf("%w: %w", errFailedToDecodeChannelVideos, err)
+	for i := range cd.results {
+		if cd.results[i].ID == video.ID {
+			cd.results[i] = result
+
+			return i
+		}
 	}
 
-	return videos, nil
+	cd.results = append(cd.results, result)
+
+	return len(cd.results) - 1
 }
 
-// downloadSelectedVideos downloads the selected videos and reports results.
-func (cd *channelDownloader) downloadSelectedVideos(videos []models.Video, selectedIndices []int) {
-	var failed []string
+// finishRun reports the results of a download of channel that started at
+// start, records the failed videos and writes the files describing the
+// channel folder.
+func (cd *channelDownloader) finishRun(
+	channel models.Channel,
+	videos []models.Video,
+	summary models.DownloadSummary,
+	start time.Time,
+) {
+	cd.printResults(summary)
+	cd.recordFailures(channel, videos, summary)
+	cd.writePlaylist(videos)
+	cd.writeShowNFO(channel.ID, channel.Name)
+	cd.notifyWebhook(summary, time.Since(start))
+}
 
-	toDownload := cd.prepareDownloads(videos, selectedIndices, &failed)
-	if len(toDownload) > 0 {
-		failed = append(failed, cd.processDownloads(videos, toDownload)...)
+// partialFailure returns ErrPartialFailure if failed of total items failed,
+// or firstErr, the error of the first failed item, if all of them did.
+func partialFailure(failed, total int, firstErr error) error {
+	switch {
+	case failed == 0:
+		return nil
+	case failed == total && firstErr != nil:
+		return fmt.Errorf("%w", firstErr)
+	default:
+		return fmt.Errorf("%w: %d of %d", ErrPartialFailure, failed, total)
 	}
+}
+
+// downloadVideos downloads the videos at indices and returns the ones that
+// failed. Since most failures are transient, failed videos are retried in up
+// to RetryPasses further passes, unless ctx is done in between.
+func (cd *channelDownloader) downloadVideos(
+	ctx context.Context,
+	channelName string,
+	videos []models.Video,
+	indices []int,
+) []models.Video {
+	cd.firstErr = nil
+
+	for pass := 0; ; pass++ {
+		var failed []models.Video
+
+		queue := cd.prepareDownloads(ctx, channelName, videos, indices, &failed)
+		if len(queue) > 0 {
+			failed = append(failed, cd.processDownloads(ctx, channelName, videos, queue)...)
+		}
 
-	cd.printResults(len(toDownload), len(selectedIndices), failed)
+		if len(failed) == 0 || pass >= cd.config.RetryPasses {
+			return failed
+		}
+
+		slog.Warn("retrying failed videos",
+			"videos", len(failed),
+			"pass", pass+1,
+			"retryPasses", cd.config.RetryPasses)
+
+		if err := sleep(ctx, retryPassDelay); err != nil {
+			return failed
+		}
+
+		indices = failedIndices(videos, failed)
+	}
 }
 
-// prepareDownloads checks which videos need to be downloaded and validates their availability.
+// failedIndices returns the indices of the failed videos in videos.
+func failedIndices(videos, failed []models.Video) []int {
+	var indices []int
+
+	for i, video := range videos {
+		if slices.ContainsFunc(failed, func(f models.Video) bool { return f.ID == video.ID }) {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// prepareDownloads checks which videos of channelName need to be downloaded,
+// validates their availability and determines their size and file name. The
+// variants of all videos are fetched concurrently up front, existing files and
+// the --exec-before command are then checked in order.
 func (cd *channelDownloader) prepareDownloads(
+	ctx context.Context,
+	channelName string,
 	videos []models.Video,
 	indices []int,
-	failed *[]string,
-) []int {
-	var toDownload []int
+	failed *[]models.Video,
+) []queuedVideo {
+	var queue []queuedVideo
+
+	prefetched := cd.prefetchVariants(ctx, videos, indices)
+	titles := fileTitles(videos, cd.config)
 
-	for _, idx := range indices {
+	for i, idx := range indices {
 		video := videos[idx]
-		downloader := newVideoDownloader(
-			cd.config,
-			models.ProgressInfo{CurrentItem: 0, TotalItems: 0},
-			cd.client,
-		)
+		variants, size := prefetched[i].variants, prefetched[i].size
 
-		variants, err := downloader.getVariants(video.ID)
-		if err != nil {
-			fmt.Printf("\nFailed to get video variants for %s: %v\n", video.Title, err)
-			*failed = append(*failed, video.Title)
+		if err := prefetched[i].err; err != nil {
+			slog.Error("failed to get video variants", "title", video.Title, "error", err)
+			*failed = append(*failed, video)
+			cd.results[cd.addResult(video, models.StatusFailed, -1)].Error = err.Error()
+			cd.firstErr = cmp.Or(cd.firstErr, err)
 
 			continue
 		}
 
-		if len(variants) == 0 {
-			fmt.Printf("\nNo variants found for %s\n", video.Title)
-			*failed = append(*failed, video.Title)
+		if outsideSizeLimits(&video, size, cd.config) ||
+			skippedByHook(ctx, &video, channelName, size, cd.config) {
+			cd.addResult(video, models.StatusSkipped, size)
 
 			continue
 		}
 
-		filename := dir.CreateFilename(video.Title, variants[0].MediaType, video.Episode, cd.config)
-		if !dir.OverwriteVideoIfExists(filename, cd.config) {
-			toDownload = append(toDownload, idx)
+		filename := dir.CreateFilename(
+			titles[video.ID],
+			video.ID,
+			variants[0].MediaType,
+			video.Episode,
+			cd.config,
+		)
+		if skipExisting(postprocess.OutputName(filename, cd.config), &video, size, cd.config) {
+			cd.results[cd.addResult(video, models.StatusSkipped, size)].Exists = true
+
+			continue
 		}
+
+		queue = append(queue, queuedVideo{
+			index:  idx,
+			size:   max(size, 0),
+			result: cd.addResult(video, models.StatusDownloaded, size),
+			title:  titles[video.ID],
+		})
 	}
 
-	return toDownload
+	return queue
 }
 
-// processDownloads performs the actual video downloads and returns failed video titles.
-func (cd *channelDownloader) processDownloads(videos []models.Video, indices []int) []string {
-	var failed []string
+// prefetchVariants fetches the variants of the videos at indices with up to
+// prefetchWorkers concurrent requests. The results are in the order of
+// indices.
+func (cd *channelDownloader) prefetchVariants(
+	ctx context.Context,
+	videos []models.Video,
+	indices []int,
+) []prefetchedVariants {
+	results := make([]prefetchedVariants, len(indices))
+	jobs := make(chan int)
 
-	for i, idx := range indices {
-		video := videos[idx]
-		progress := models.ProgressInfo{
-			CurrentItem: i + 1,
-			TotalItems:  len(indices),
-		}
+	var wg sync.WaitGroup
+
+	for range min(prefetchWorkers, len(indices)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				results[i] = cd.fetchVariants(ctx, videos[indices[i]])
+			}
+		}()
+	}
+
+	for i := range indices {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// fetchVariants fetches the variants of video and the size of the one that is
+// downloaded.
+func (cd *channelDownloader) fetchVariants(
+	ctx context.Context,
+	video models.Video,
+) prefetchedVariants {
+	variants, err := cd.api.GetVariants(ctx, video.ID)
+	if err == nil && len(variants) == 0 {
+		err = errNoVariantsFound
+	}
+
+	if err != nil {
+		return prefetchedVariants{variants: nil, size: unknownSize, err: err}
+	}
+
+	variants = cd.client.preferVariant(ctx, variants, cd.config)
+
+	return prefetchedVariants{variants: variants, size: cd.variantSize(ctx, variants), err: nil}
+}
+
+// processDownloads performs the actual video downloads and returns failed
+// videos. The sizes of the queued videos feed the overall progress bar.
+func (cd *channelDownloader) processDownloads(
+	ctx context.Context,
+	channelName string,
+	videos []models.Video,
+	queue []queuedVideo,
+) []models.Video {
+	var failed []models.Video
+
+	progress := models.ProgressInfo{
+		CurrentItem:     0,
+		TotalItems:      len(queue),
+		DownloadedBytes: 0,
+		TotalBytes:      0,
+		StartTime:       time.Now(),
+	}
+
+	for _, queued := range queue {
+		progress.TotalBytes += queued.size
+	}
+
+	for i, queued := range queue {
+		video := videos[queued.index]
+		progress.CurrentItem = i + 1
 
 		downloader := newVideoDownloader(cd.config, progress, cd.client)
-		if err := downloader.downloadVideo(video.ID, false); err != nil {
-			fmt.Printf("\nFailed: %s - %v\n", video.Title, err)
-			failed = append(failed, video.Title)
+		downloader.channel = channelName
+		downloader.fileTitle = queued.title
+
+		start := time.Now()
+		err := downloader.downloadVideo(ctx, video.ID, false)
+		cd.finishResult(queued, time.Since(start), err)
+
+		if err != nil {
+			slog.Error("failed to download video", "title", video.Title, "error", err)
+			failed = append(failed, video)
+			cd.firstErr = cmp.Or(cd.firstErr, err)
+
+			// Failed videos no longer count towards the overall progress
+			progress.TotalBytes -= queued.size
+
+			continue
 		}
+
+		progress.DownloadedBytes += queued.size
 	}
 
 	return failed
 }
 
-// printResults displays the download results summary.
-func (cd *channelDownloader) printResults(downloadCount, selectedCount int, failed []string) {
-	fmt.Printf("\nDownload complete! %d/%d videos successful\n",
-		downloadCount-len(failed), selectedCount)
+// finishResult records that downloading queued took duration and failed
+// with err if it isn't nil.
+func (cd *channelDownloader) finishResult(queued queuedVideo, duration time.Duration, err error) {
+	result := &cd.results[queued.result]
+
+	if err != nil {
+		result.Status = models.StatusFailed
+		result.Error = err.Error()
+
+		return
+	}
+
+	result.Seconds = duration.Seconds()
+	if result.Size > 0 && result.Seconds > 0 {
+		result.Speed = float64(result.Size) / result.Seconds
+	}
+}
+
+// printResults displays the download results summary, as a single line of
+// JSON if requested. It is handed to the OnSummary function of the config
+// instead if there is one.
+func (cd *channelDownloader) printResults(summary models.DownloadSummary) {
+	if cd.config.OnSummary != nil {
+		cd.config.OnSummary(summary)
+
+		return
+	}
+
+	if cd.config.JSON {
+		printJSON(summary)
+
+		return
+	}
+
+	if len(summary.Videos) > 0 {
+		fmt.Println()
+		ui.PrintDownloadSummary(summary.Videos)
+	}
 
-	if len(failed) > 0 {
-		fmt.Println("Failed downloads:")
+	complete := fmt.Sprintf("Download complete! %d/%d videos successful",
+		summary.Downloaded, summary.Selected)
+	fmt.Printf("\n%s\n", ui.Outcome(complete, len(summary.Failed) == 0))
 
-		for _, title := range failed {
-			fmt.Printf("  - %s\n", title)
+	if len(summary.Failed) > 0 {
+		fmt.Println(ui.Failure("Failed downloads:"))
+
+		for _, video := range summary.Failed {
+			fmt.Printf("  - %s\n", video.Title)
 		}
 	}
 }
+
+// printJSON prints v as a single line of JSON to stdout.
+func printJSON(v any) {
+	if err := ui.PrintJSON(v); err != nil {
+		slog.Error("failed to print JSON output", "error", err)
+	}
+}
@@ -0,0 +1,226 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestChannelResults(t *testing.T) {
+	cd := &channelDownloader{}
+
+	videos := []models.Video{
+		{ID: "a", Title: "Downloaded"},
+		{ID: "b", Title: "Failed"},
+		{ID: "c", Title: "Skipped"},
+	}
+
+	downloaded := queuedVideo{index: 0, size: 2000}
+	downloaded.result = cd.addResult(videos[0], models.StatusDownloaded, 2000)
+	failed := queuedVideo{index: 1, size: 0}
+	failed.result = cd.addResult(videos[1], models.StatusDownloaded, -1)
+	cd.addResult(videos[2], models.StatusSkipped, 100)
+
+	cd.finishResult(downloaded, 2*time.Second, nil)
+	cd.finishResult(failed, time.Second, errors.New("connection reset"))
+
+	summary := cd.summary("Channel", 3, []models.Video{videos[1]})
+
+	want := []models.VideoResult{
+		{
+			ID: "a", Title: "Downloaded", Status: models.StatusDownloaded,
+			Size: 2000, Seconds: 2, Speed: 1000,
+		},
+		{ID: "b", Title: "Failed", Status: models.StatusFailed, Size: -1, Error: "connection reset"},
+		{ID: "c", Title: "Skipped", Status: models.StatusSkipped, Size: 100},
+	}
+
+	if len(summary.Videos) != len(want) {
+		t.Fatalf("summary.Videos = %v, want %v", summary.Videos, want)
+	}
+
+	for i := range want {
+		if summary.Videos[i] != want[i] {
+			t.Errorf("summary.Videos[%d] = %+v, want %+v", i, summary.Videos[i], want[i])
+		}
+	}
+
+	if summary.Downloaded != 1 {
+		t.Errorf("summary.Downloaded = %d, want 1", summary.Downloaded)
+	}
+}
+
+func TestPartialFailure(t *testing.T) {
+	if err := partialFailure(0, 3, nil); err != nil {
+		t.Errorf("partialFailure() without failures = %v, want nil", err)
+	}
+
+	if err := partialFailure(1, 3, ErrNotFound); !errors.Is(err, ErrPartialFailure) ||
+		errors.Is(err, ErrNotFound) {
+		t.Errorf("partialFailure() with some failures = %v, want %v", err, ErrPartialFailure)
+	}
+
+	// The cause of the failures decides the exit code if nothing succeeded
+	if err := partialFailure(3, 3, ErrUnauthorized); !errors.Is(err, ErrUnauthorized) ||
+		errors.Is(err, ErrPartialFailure) {
+		t.Errorf("partialFailure() with all failing = %v, want %v", err, ErrUnauthorized)
+	}
+}
+
+// variantsAPI returns a variant with the size given by the ID of each video,
+// answering later videos faster, and counts the concurrent requests.
+type variantsAPI struct {
+	active, peak atomic.Int32
+}
+
+func (a *variantsAPI) GetVideo(context.Context, string) (*models.Video, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (a *variantsAPI) GetVariants(_ context.Context, videoID string) ([]models.Variant, error) {
+	active := a.active.Add(1)
+	defer a.active.Add(-1)
+
+	for peak := a.peak.Load(); active > peak && !a.peak.CompareAndSwap(peak, active); {
+		peak = a.peak.Load()
+	}
+
+	size := int64(len(videoID))
+	time.Sleep(time.Duration(20-size) * time.Millisecond)
+
+	if size == 3 {
+		return nil, nil
+	}
+
+	return []models.Variant{{MediaType: "video/mp4", Path: videoID, Size: size}}, nil
+}
+
+func (a *variantsAPI) GetChannelVideos(context.Context, string) ([]models.Video, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (a *variantsAPI) Stream(context.Context, string, int64) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestPrefetchVariants(t *testing.T) {
+	api := &variantsAPI{}
+	cd := &channelDownloader{api: api}
+
+	var videos []models.Video
+
+	var indices []int
+
+	for i := range 12 {
+		videos = append(videos, models.Video{ID: string(make([]byte, i+1))})
+		indices = append(indices, i)
+	}
+
+	results := cd.prefetchVariants(context.Background(), videos, indices[1:])
+
+	if len(results) != len(indices)-1 {
+		t.Fatalf("prefetchVariants() returned %d results, want %d", len(results), len(indices)-1)
+	}
+
+	for i, result := range results {
+		wantSize := int64(i + 2)
+		if wantSize == 3 {
+			if !errors.Is(result.err, errNoVariantsFound) {
+				t.Errorf("results[%d].err = %v, want errNoVariantsFound", i, result.err)
+			}
+
+			continue
+		}
+
+		if result.err != nil || result.size != wantSize {
+			t.Errorf("results[%d] = size %d, error %v, want size %d", i, result.size, result.err, wantSize)
+		}
+	}
+
+	if peak := api.peak.Load(); peak < 2 || peak > prefetchWorkers {
+		t.Errorf("peak concurrent requests = %d, want between 2 and %d", peak, prefetchWorkers)
+	}
+}
+
+// flakyAPI fails the streams of the first failures requests.
+type flakyAPI struct {
+	mockAPI
+
+	failures int
+	requests int
+}
+
+func (a *flakyAPI) Stream(context.Context, string, int64) (*http.Response, error) {
+	a.requests++
+	if a.requests <= a.failures {
+		return nil, errors.New("connection reset")
+	}
+
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		ContentLength: int64(len(a.data)),
+		Body:          io.NopCloser(strings.NewReader(a.data)),
+	}, nil
+}
+
+func TestDownloadVideosRetryPasses(t *testing.T) {
+	originalSleep := sleep
+	sleep = func(context.Context, time.Duration) error { return nil }
+
+	defer func() { sleep = originalSleep }()
+
+	videos := []models.Video{{ID: "a", Title: "Paging"}, {ID: "b", Title: "Mapping"}}
+
+	tests := []struct {
+		name        string
+		retryPasses int
+		failures    int
+		wantFailed  int
+	}{
+		{name: "no failures", retryPasses: 1, failures: 0, wantFailed: 0},
+		{name: "recovered in retry pass", retryPasses: 1, failures: 2, wantFailed: 0},
+		{name: "retries disabled", retryPasses: 0, failures: 1, wantFailed: 1},
+		{name: "retries exhausted", retryPasses: 2, failures: 5, wantFailed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &flakyAPI{
+				mockAPI: mockAPI{
+					variants: []models.Variant{{MediaType: "video/mp4", Path: "media", Size: 10}},
+					data:     "video data",
+				},
+				failures: tt.failures,
+			}
+
+			config := models.DownloadConfig{
+				Output:      t.TempDir(),
+				Reporter:    discardProgress{},
+				RetryPasses: tt.retryPasses,
+			}
+			cd := newChannelDownloader(config, (&Client{}).WithAPI(api))
+
+			failed := cd.downloadVideos(context.Background(), "Channel", videos, []int{0, 1})
+			if len(failed) != tt.wantFailed || (cd.firstErr != nil) != (tt.failures > 0) {
+				t.Errorf("downloadVideos() failed = %v, first error %v, want %d failures",
+					failed, cd.firstErr, tt.wantFailed)
+			}
+
+			summary := cd.summary("Channel", len(videos), failed)
+			if len(summary.Videos) != len(videos) || summary.Videos[0].ID != "a" {
+				t.Errorf("summary.Videos = %+v, want one result per video in order", summary.Videos)
+			}
+
+			if summary.Downloaded != len(videos)-tt.wantFailed {
+				t.Errorf("summary.Downloaded = %d, want %d", summary.Downloaded, len(videos)-tt.wantFailed)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"switchtube-downloader/internal/models"
+)
+
+var errFailedToGetChannels = errors.New("failed to get channels")
+
+// Channels returns all channels the access token of client gives access to,
+// e.g. the channels of its organization.
+func Channels(ctx context.Context, client *Client) ([]models.Channel, error) {
+	fullURL, err := url.JoinPath(client.baseURL(), channelAPI)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	return client.channels(ctx, fullURL)
+}
+
+// channels retrieves the channels listed at fullURL, following pagination.
+func (c *Client) channels(ctx context.Context, fullURL string) ([]models.Channel, error) {
+	channels, err := fetchAllPages[models.Channel](ctx, c, fullURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToGetChannels, err)
+	}
+
+	if channels == nil {
+		channels = []models.Channel{}
+	}
+
+	return channels, nil
+}
@@ -0,0 +1,44 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+)
+
+func TestChannels(t *testing.T) {
+	pages := map[string][]models.Channel{
+		"1": {{ID: "os", Name: "Operating Systems"}},
+		"2": {{ID: "db", Name: "Databases"}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+			w.Header().Set(headerLink, `</channels?page=2>; rel="next"`)
+		}
+
+		json.NewEncoder(w).Encode(pages[page])
+	}))
+	defer server.Close()
+
+	client, err := NewClient(token.NewTokenManagerWithToken("secret"), models.ClientConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channels, err := client.channels(context.Background(), server.URL+"/channels")
+	if err != nil {
+		t.Fatalf("channels() error = %v", err)
+	}
+
+	if len(channels) != 2 || channels[0].ID != "os" || channels[1].Name != "Databases" {
+		t.Errorf("channels() = %v, want the channels of both pages", channels)
+	}
+}
@@ -0,0 +1,19 @@
+package download
+
+import (
+	"switchtube-downloader/internal/helper/dir"
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/postprocess"
+)
+
+// skipExisting reports whether the download of video to output, which is of
+// size bytes, is skipped because the file exists. With IfChanged, only files
+// that differ from the remote video are downloaded again. The size of
+// post-processed files can't be compared.
+func skipExisting(output string, video *models.Video, size int64, config models.DownloadConfig) bool {
+	if !config.IfChanged || config.Force {
+		return dir.OverwriteVideoIfExists(output, config)
+	}
+
+	return !dir.FileChanged(output, size, video.PublishedAt, !postprocess.Enabled(config))
+}
@@ -0,0 +1,42 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestSkipExisting(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int64
+		config models.DownloadConfig
+		want   bool
+	}{
+		{name: "skip", size: 9, config: models.DownloadConfig{Skip: true}, want: true},
+		{name: "unchanged", size: 5, config: models.DownloadConfig{Skip: true, IfChanged: true}, want: true},
+		{name: "changed", size: 9, config: models.DownloadConfig{Skip: true, IfChanged: true}, want: false},
+		{
+			name:   "post-processed",
+			size:   9,
+			config: models.DownloadConfig{IfChanged: true, Remux: models.RemuxMKV},
+			want:   true,
+		},
+		{name: "force", size: 5, config: models.DownloadConfig{Force: true, IfChanged: true}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "Video.mp4")
+			if err := os.WriteFile(output, []byte("video"), 0o600); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			if got := skipExisting(output, &models.Video{ID: "a"}, tt.size, tt.config); got != tt.want {
+				t.Errorf("skipExisting() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"switchtube-downloader/internal/models"
+)
+
+var (
+	// ErrExternalDownloaderNotFound is returned when an external downloader
+	// is requested but isn't installed.
+	ErrExternalDownloaderNotFound = errors.New("external downloader not found on PATH")
+
+	// ErrExternalDownloaderUnsupported is returned if the external
+	// downloader can't apply a network setting of the client.
+	ErrExternalDownloaderUnsupported = errors.New("not supported by the external downloader")
+
+	errExternalDownloaderFailed = errors.New("external downloader failed")
+)
+
+// aria2cConnections is the number of connections aria2c downloads a video
+// with in segments.
+const aria2cConnections = "8"
+
+// lookPath finds the executable of an external downloader, which tests
+// replace.
+var lookPath = exec.LookPath
+
+// CheckExternalDownloader returns ErrExternalDownloaderNotFound if config
+// hands downloads to an external downloader that isn't installed, so that the
+// error shows up before anything is downloaded.
+func CheckExternalDownloader(config models.DownloadConfig) error {
+	if config.ExternalDownloader == "" {
+		return nil
+	}
+
+	if _, err := lookPath(config.ExternalDownloader); err != nil {
+		return fmt.Errorf("%w: %w", ErrExternalDownloaderNotFound, err)
+	}
+
+	return nil
+}
+
+// externalNetwork holds the network settings of the client that external
+// downloaders are given as well.
+type externalNetwork struct {
+	proxy    string
+	caCert   string
+	insecure bool
+	forced   string
+}
+
+// newExternalNetwork returns the network settings of config for external
+// downloaders.
+func newExternalNetwork(config models.ClientConfig) externalNetwork {
+	return externalNetwork{
+		proxy:    config.Proxy,
+		caCert:   config.CACert,
+		insecure: config.Insecure,
+		forced:   config.Network,
+	}
+}
+
+// CheckExternalNetwork returns ErrExternalDownloaderUnsupported if tool can't
+// apply the network settings of config, so that the combination is rejected
+// before anything is downloaded.
+func CheckExternalNetwork(tool string, config models.ClientConfig) error {
+	if tool == "" {
+		return nil
+	}
+
+	_, err := networkArgs(tool, newExternalNetwork(config))
+
+	return err
+}
+
+// downloadExternal downloads the media at endpoint to filename with the
+// external downloader of the config, which shows its own progress.
+func (vd *videoDownloader) downloadExternal(ctx context.Context, endpoint, filename string) error {
+	path, err := lookPath(vd.config.ExternalDownloader)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrExternalDownloaderNotFound, err)
+	}
+
+	endpointURL, err := url.JoinPath(vd.client.baseURL(), endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	// External downloaders would forward the access token and the extra
+	// headers on redirects to any host, so they get the final URL
+	fullURL, header, err := vd.client.resolveRedirects(ctx, endpointURL)
+	if err != nil {
+		return err
+	}
+
+	network, err := networkArgs(vd.config.ExternalDownloader, vd.client.network)
+	if err != nil {
+		return err
+	}
+
+	args, input := externalArgs(
+		vd.config.ExternalDownloader,
+		fullURL,
+		filename,
+		header,
+		vd.client.network.proxy,
+	)
+	args = append(network, args...)
+
+	slog.Info("running external downloader", "tool", vd.config.ExternalDownloader, "file", filename)
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s: %w", errExternalDownloaderFailed, vd.config.ExternalDownloader, err)
+	}
+
+	return nil
+}
+
+// resolveRedirects follows the redirects of endpointURL with an authenticated
+// HEAD request and returns the URL they end at with the header to request it
+// with. Like for the client itself, the extra headers are only sent if it is
+// on SwitchTube, and the access token only if the redirect policy allows it.
+func (c *Client) resolveRedirects(
+	ctx context.Context,
+	endpointURL string,
+) (string, http.Header, error) {
+	resp, err := c.makeRequestWithMethod(ctx, http.MethodHead, endpointURL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		slog.Warn("failed to close response body", "error", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, statusError(resp.StatusCode)
+	}
+
+	final := resp.Request
+	header := make(http.Header)
+
+	for key, values := range c.header {
+		if strings.EqualFold(final.URL.Host, baseHost(c.base)) || key == "User-Agent" {
+			header[key] = values
+		}
+	}
+
+	if auth := final.Header.Get(headerAuthorization); auth != "" {
+		header.Set(headerAuthorization, auth)
+	}
+
+	return final.URL.String(), header, nil
+}
+
+// externalArgs returns the arguments and the standard input of tool to
+// download fullURL to filename sending header, through proxy if it isn't
+// empty. The header and the proxy are passed on the standard input rather
+// than as arguments, which would expose the access token and the credentials
+// of the proxy to other users of the machine.
+func externalArgs(
+	tool, fullURL, filename string,
+	header http.Header,
+	proxy string,
+) ([]string, string) {
+	var lines []string
+
+	for _, key := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[key] {
+			lines = append(lines, key+": "+value)
+		}
+	}
+
+	if tool == models.ExternalCurl {
+		return curlArgs(fullURL, filename, lines, proxy)
+	}
+
+	// aria2c reads the URL and its options from an input file
+	input := []string{fullURL}
+	for _, line := range lines {
+		input = append(input, "  header="+line)
+	}
+
+	if proxy != "" {
+		input = append(input, "  all-proxy="+proxy)
+	}
+
+	input = append(input, "  dir="+filepath.Dir(filename), "  out="+filepath.Base(filename))
+
+	args := []string{
+		"--input-file=-",
+		"--allow-overwrite=true",
+		"--auto-file-renaming=false",
+		"--max-connection-per-server=" + aria2cConnections,
+		"--split=" + aria2cConnections,
+		"--console-log-level=warn",
+		"--summary-interval=0",
+	}
+
+	return args, strings.Join(input, "\n") + "\n"
+}
+
+// curlArgs returns the arguments and the standard input of curl for
+// externalArgs. curl reads the header lines and the proxy from a config file
+// on its standard input.
+func curlArgs(fullURL, filename string, lines []string, proxy string) ([]string, string) {
+	var input strings.Builder
+
+	for _, line := range lines {
+		input.WriteString("header = " + curlConfigValue(line) + "\n")
+	}
+
+	if proxy != "" {
+		input.WriteString("proxy = " + curlConfigValue(proxy) + "\n")
+	}
+
+	args := []string{
+		"--fail", "--progress-bar", "--retry", "3",
+		"--config", "-", "--output", filename, fullURL,
+	}
+
+	return args, input.String()
+}
+
+// curlConfigValue quotes value for a curl config file.
+func curlConfigValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// networkArgs returns the arguments making tool use the CA certificates, the
+// certificate verification and the IP version of network, or
+// ErrExternalDownloaderUnsupported if it can't apply them or the proxy, which
+// externalArgs passes on. An empty proxy leaves the tools to the proxy
+// environment variables, like the client.
+func networkArgs(tool string, network externalNetwork) ([]string, error) {
+	if tool == models.ExternalCurl {
+		return curlNetworkArgs(network), nil
+	}
+
+	if network.proxy != "" && !strings.HasPrefix(network.proxy, "http://") {
+		return nil, fmt.Errorf("%w: %s only supports HTTP proxies: %s",
+			ErrExternalDownloaderUnsupported, tool, network.proxy)
+	}
+
+	var args []string
+
+	if network.caCert != "" {
+		args = append(args, "--ca-certificate="+network.caCert)
+	}
+
+	if network.insecure {
+		args = append(args, "--check-certificate=false")
+	}
+
+	switch network.forced {
+	case models.NetworkIPv4:
+		args = append(args, "--disable-ipv6=true")
+	case models.NetworkIPv6:
+		return nil, fmt.Errorf("%w: %s can't be restricted to IPv6",
+			ErrExternalDownloaderUnsupported, tool)
+	}
+
+	return args, nil
+}
+
+// curlNetworkArgs returns the arguments making curl use the settings of
+// network, all of which it supports.
+func curlNetworkArgs(network externalNetwork) []string {
+	var args []string
+
+	if network.caCert != "" {
+		args = append(args, "--cacert", network.caCert)
+	}
+
+	if network.insecure {
+		args = append(args, "--insecure")
+	}
+
+	switch network.forced {
+	case models.NetworkIPv4:
+		args = append(args, "--ipv4")
+	case models.NetworkIPv6:
+		args = append(args, "--ipv6")
+	}
+
+	return args
+}
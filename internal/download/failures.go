@@ -0,0 +1,174 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"switchtube-downloader/internal/failures"
+	"switchtube-downloader/internal/models"
+)
+
+// recordFailures adds the videos of channel that failed in summary to the
+// failures file, if it is enabled, so that the retry command downloads them
+// to the channel folder again under the same file names. A failure is logged
+// instead of failing the download.
+func (cd *channelDownloader) recordFailures(
+	channel models.Channel,
+	videos []models.Video,
+	summary models.DownloadSummary,
+) {
+	if cd.config.Failures == "" {
+		return
+	}
+
+	titles := fileTitles(videos, cd.config)
+
+	var entries []models.FailedDownload
+
+	for _, result := range summary.Videos {
+		if result.Status != models.StatusFailed {
+			continue
+		}
+
+		entries = append(entries, models.FailedDownload{
+			ID:       result.ID,
+			Title:    result.Title,
+			Channel:  channel.Name,
+			Error:    result.Error,
+			FailedAt: time.Now(),
+			Options:  retryOptions(cd.config, titles[result.ID]),
+		})
+	}
+
+	addFailures(cd.config.Failures, entries)
+}
+
+// recordFailure adds the failed download of the video id with config to the
+// failures file, if it is enabled.
+func recordFailure(config models.DownloadConfig, id string, err error) {
+	if config.Failures == "" {
+		return
+	}
+
+	addFailures(config.Failures, []models.FailedDownload{{
+		ID:       id,
+		Title:    "",
+		Channel:  "",
+		Error:    err.Error(),
+		FailedAt: time.Now(),
+		Options:  retryOptions(config, ""),
+	}})
+}
+
+// retryOptions returns the options of config that the retry of a video with
+// fileTitle in its file name needs.
+func retryOptions(config models.DownloadConfig, fileTitle string) models.RetryOptions {
+	return models.RetryOptions{
+		UseEpisode:         config.UseEpisode,
+		Skip:               config.Skip,
+		Force:              config.Force,
+		Output:             config.Output,
+		FileTitle:          fileTitle,
+		OnConflict:         config.OnConflict,
+		IfChanged:          config.IfChanged,
+		Slug:               config.Slug,
+		LongPaths:          config.LongPaths,
+		WindowsSafe:        config.WindowsSafe,
+		Remux:              config.Remux,
+		ExtractAudio:       config.ExtractAudio,
+		AudioFormat:        config.AudioFormat,
+		KeepVideo:          config.KeepVideo,
+		WriteNFO:           config.WriteNFO,
+		VideoTimeout:       config.VideoTimeout,
+		Smallest:           config.Smallest,
+		Largest:            config.Largest,
+		MaxHeight:          config.MaxHeight,
+		ExternalDownloader: config.ExternalDownloader,
+	}
+}
+
+// RetryConfig returns the config retrying entry: config, which holds the
+// options of the retry run itself, with the options entry failed with
+// applied. Failures are neither recorded again nor is the results webhook
+// notified, and no command is run before the download.
+func RetryConfig(entry models.FailedDownload, config models.DownloadConfig) models.DownloadConfig {
+	options := entry.Options
+
+	config.Media = entry.ID
+	config.UseEpisode = options.UseEpisode
+	config.Skip = options.Skip
+	config.Force = options.Force
+	config.Output = options.Output
+	config.OnConflict = options.OnConflict
+	config.IfChanged = options.IfChanged
+	config.Slug = options.Slug
+	config.LongPaths = options.LongPaths
+	config.WindowsSafe = options.WindowsSafe
+	config.Remux = options.Remux
+	config.ExtractAudio = options.ExtractAudio
+	config.AudioFormat = options.AudioFormat
+	config.KeepVideo = options.KeepVideo
+	config.WriteNFO = options.WriteNFO
+	config.VideoTimeout = options.VideoTimeout
+	config.Smallest = options.Smallest
+	config.Largest = options.Largest
+	config.MaxHeight = options.MaxHeight
+	config.ExternalDownloader = options.ExternalDownloader
+
+	config.Failures = ""
+	config.NotifyWebhook = ""
+	config.ExecBefore = ""
+
+	return config
+}
+
+// Retry downloads the video of the failed download entry again with config,
+// as returned by RetryConfig, under the file title it was given in its
+// channel.
+func Retry(
+	ctx context.Context,
+	client *Client,
+	entry models.FailedDownload,
+	config models.DownloadConfig,
+) error {
+	unlock, err := lockOutput(config)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ctx, cancel := WithRunTimeout(ctx, config)
+	defer cancel()
+
+	progress := models.ProgressInfo{
+		CurrentItem:     1,
+		TotalItems:      1,
+		DownloadedBytes: 0,
+		TotalBytes:      0,
+		StartTime:       time.Time{},
+	}
+
+	downloader := newVideoDownloader(config, progress, client)
+	downloader.channel = entry.Channel
+	downloader.fileTitle = entry.Options.FileTitle
+
+	if err := downloader.downloadVideo(ctx, entry.ID, true); err != nil {
+		return runTimeoutError(ctx, config, fmt.Errorf("%w: %w", errFailedToDownloadVideo, err))
+	}
+
+	return nil
+}
+
+// addFailures appends entries to the failures file at path, logging a
+// failure.
+func addFailures(path string, entries []models.FailedDownload) {
+	if len(entries) == 0 {
+		return
+	}
+
+	if err := failures.Add(path, entries...); err != nil {
+		slog.Warn("failed to record failed downloads", "error", err)
+	}
+}
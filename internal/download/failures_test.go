@@ -0,0 +1,110 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/failures"
+	"switchtube-downloader/internal/models"
+)
+
+func TestRecordFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failures.jsonl")
+	config := models.DownloadConfig{
+		Output:        "videos/Channel",
+		UseEpisode:    true,
+		Failures:      path,
+		NotifyWebhook: "https://hooks.example.com/secret",
+		ExecBefore:    "touch pwned",
+	}
+	cd := newChannelDownloader(config, nil)
+
+	published := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	videos := []models.Video{
+		{ID: "a", Title: "Mapping", PublishedAt: published},
+		{ID: "b", Title: "Mapping", PublishedAt: published.Add(time.Hour)},
+	}
+
+	summary := models.DownloadSummary{Videos: []models.VideoResult{
+		{ID: "a", Title: "Mapping", Status: models.StatusDownloaded},
+		{ID: "b", Title: "Mapping", Status: models.StatusFailed, Error: "connection reset"},
+		{ID: "c", Title: "Caching", Status: models.StatusSkipped},
+	}}
+	cd.recordFailures(models.Channel{ID: "ch", Name: "Channel"}, videos, summary)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	if strings.Contains(string(data), "secret") || strings.Contains(string(data), "pwned") {
+		t.Errorf("failures file = %s, want no webhook or command", data)
+	}
+
+	entries, err := failures.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("Load() = %+v, want only the failed video", entries)
+	}
+
+	entry := entries[0]
+	if entry.ID != "b" || entry.Title != "Mapping" || entry.Channel != "Channel" ||
+		entry.Error != "connection reset" {
+		t.Errorf("entry = %+v, want the failed video of the channel", entry)
+	}
+
+	if entry.Options.Output != config.Output || !entry.Options.UseEpisode ||
+		entry.Options.FileTitle != fileTitles(videos, config)["b"] || entry.Options.FileTitle == "Mapping" {
+		t.Errorf("entry.Options = %+v, want the options downloading the video", entry.Options)
+	}
+}
+
+func TestRetryConfig(t *testing.T) {
+	entry := models.FailedDownload{
+		ID:      "b",
+		Options: models.RetryOptions{Output: "videos/Channel", UseEpisode: true, Remux: models.RemuxMKV},
+	}
+	base := models.DownloadConfig{
+		ProgressFormat: models.ProgressFormatJSON,
+		History:        "history.jsonl",
+		Failures:       "failures.jsonl",
+		NotifyWebhook:  "https://hooks.example.com/secret",
+		ExecBefore:     "true",
+	}
+
+	config := RetryConfig(entry, base)
+	if config.Media != "b" || config.Output != "videos/Channel" || !config.UseEpisode ||
+		config.Remux != models.RemuxMKV || config.ProgressFormat != models.ProgressFormatJSON ||
+		config.History != base.History {
+		t.Errorf("RetryConfig() = %+v, want the options of the entry and the run", config)
+	}
+
+	if config.Failures != "" || config.NotifyWebhook != "" || config.ExecBefore != "" {
+		t.Errorf("RetryConfig() = %+v, want no failures file, webhook or command", config)
+	}
+}
+
+func TestLoadFailuresWithFormerConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failures.jsonl")
+	line := `{"id":"b","config":{"output":"videos","useEpisode":true,"execBefore":"touch pwned"}}` + "\n"
+
+	if err := os.WriteFile(path, []byte(line), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	entries, err := failures.Load(path)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Load() = %+v, %v, want the entry", entries, err)
+	}
+
+	if config := RetryConfig(entries[0], models.DownloadConfig{}); config.ExecBefore != "" ||
+		config.Output != "videos" || !config.UseEpisode {
+		t.Errorf("RetryConfig() = %+v, want the options without the command", config)
+	}
+}
@@ -0,0 +1,38 @@
+package download
+
+import (
+	"log/slog"
+
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/models"
+)
+
+// outsideSizeLimits reports whether video of size bytes is skipped because of
+// the size limits of config. Videos of unknown size are always downloaded.
+func outsideSizeLimits(video *models.Video, size int64, config models.DownloadConfig) bool {
+	if size < 0 {
+		if config.MinFilesize > 0 || config.MaxFilesize > 0 {
+			slog.Warn("size unknown, ignoring size limits", "title", video.Title)
+		}
+
+		return false
+	}
+
+	var limit string
+
+	switch {
+	case config.MinFilesize > 0 && size < config.MinFilesize:
+		limit = "--min-filesize " + ui.FormatSize(config.MinFilesize)
+	case config.MaxFilesize > 0 && size > config.MaxFilesize:
+		limit = "--max-filesize " + ui.FormatSize(config.MaxFilesize)
+	default:
+		return false
+	}
+
+	slog.Warn("skipped video outside size limits",
+		"title", video.Title,
+		"size", ui.FormatSize(size),
+		"limit", limit)
+
+	return true
+}
@@ -0,0 +1,62 @@
+package download
+
+import (
+	"context"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestOutsideSizeLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		minSize int64
+		maxSize int64
+		want    bool
+	}{
+		{name: "no limits", size: 500, want: false},
+		{name: "unknown size", size: unknownSize, minSize: 10, maxSize: 100, want: false},
+		{name: "within limits", size: 50, minSize: 10, maxSize: 100, want: false},
+		{name: "on the limits", size: 100, minSize: 100, maxSize: 100, want: false},
+		{name: "too small", size: 5, minSize: 10, want: true},
+		{name: "too large", size: 500, maxSize: 100, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := models.DownloadConfig{MinFilesize: tt.minSize, MaxFilesize: tt.maxSize}
+
+			got := outsideSizeLimits(&models.Video{Title: "Paging"}, tt.size, config)
+			if got != tt.want {
+				t.Errorf("outsideSizeLimits(%d) = %t, want %t", tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareDownloadsSkipsBySize(t *testing.T) {
+	config := models.DownloadConfig{Output: t.TempDir(), MinFilesize: 2, MaxFilesize: 4}
+	cd := newChannelDownloader(config, (&Client{}).WithAPI(&variantsAPI{}))
+
+	// The variants API reports the length of the id as the size
+	videos := []models.Video{
+		{ID: "a", Title: "Small"},
+		{ID: "bb", Title: "Fits"},
+		{ID: "ccccc", Title: "Large"},
+	}
+
+	var failed []models.Video
+
+	queue := cd.prepareDownloads(context.Background(), "", videos, []int{0, 1, 2}, &failed)
+	if len(queue) != 1 || queue[0].index != 1 || len(failed) != 0 {
+		t.Fatalf("prepareDownloads() = %+v, failed %v, want only the video within the limits",
+			queue, failed)
+	}
+
+	for _, i := range []int{0, 2} {
+		if cd.results[i].Status != models.StatusSkipped {
+			t.Errorf("results[%d].Status = %q, want %q", i, cd.results[i].Status, models.StatusSkipped)
+		}
+	}
+}
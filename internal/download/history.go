@@ -0,0 +1,54 @@
+package download
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"switchtube-downloader/internal/history"
+	"switchtube-downloader/internal/models"
+)
+
+// recordHistory adds the download of video to filename, which took duration,
+// to the download history, if it is enabled. A failure is logged instead of
+// failing the download.
+func (vd *videoDownloader) recordHistory(
+	video *models.Video,
+	filename string,
+	duration time.Duration,
+) {
+	if vd.config.History == "" {
+		return
+	}
+
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		path = filename
+	}
+
+	var size int64
+	if info, err := os.Stat(filename); err == nil {
+		size = info.Size()
+	}
+
+	checksum, err := history.Checksum(filename)
+	if err != nil {
+		slog.Warn("failed to compute checksum", "file", filename, "error", err)
+	}
+
+	entry := models.HistoryEntry{
+		ID:              video.ID,
+		Title:           video.Title,
+		Channel:         vd.channel,
+		Path:            path,
+		Size:            size,
+		Checksum:        checksum,
+		DownloadedAt:    time.Now(),
+		DownloadSeconds: duration.Seconds(),
+	}
+
+	if err := history.Add(vd.config.History, entry); err != nil {
+		slog.Warn("failed to record download history", "error", err)
+	}
+}
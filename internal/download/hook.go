@@ -0,0 +1,78 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"switchtube-downloader/internal/models"
+)
+
+// skippedByHook reports whether video of size bytes in channel is skipped
+// because the --exec-before command of config rejected it. A command that
+// can't be run skips the video as well, so that a broken filter never
+// downloads videos it was meant to exclude.
+func skippedByHook(
+	ctx context.Context,
+	video *models.Video,
+	channel string,
+	size int64,
+	config models.DownloadConfig,
+) bool {
+	if config.ExecBefore == "" {
+		return false
+	}
+
+	input, err := json.Marshal(models.HookVideo{
+		ID:          video.ID,
+		Title:       video.Title,
+		Episode:     video.Episode,
+		Duration:    video.Duration,
+		Description: video.Description,
+		PublishedAt: video.PublishedAt,
+		Channel:     channel,
+		Size:        size,
+	})
+	if err != nil {
+		slog.Error("failed to encode video for --exec-before", "title", video.Title, "error", err)
+
+		return true
+	}
+
+	cmd := shellCommand(ctx, config.ExecBefore)
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+
+	// Standard output is reserved for results
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	if err == nil {
+		return false
+	}
+
+	if exitErr := (*exec.ExitError)(nil); errors.As(err, &exitErr) {
+		slog.Info("skipped video rejected by --exec-before",
+			"title", video.Title,
+			"exitCode", exitErr.ExitCode())
+	} else {
+		slog.Error("failed to run --exec-before, skipping video", "title", video.Title, "error", err)
+	}
+
+	return true
+}
+
+// shellCommand returns the command running command in the shell of the
+// platform, which is killed once ctx is done.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,63 @@
+package download
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestSkippedByHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands are shell commands")
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		want    bool
+	}{
+		{name: "no hook", command: "", want: false},
+		{name: "accepted", command: "exit 0", want: false},
+		{name: "rejected", command: "exit 3", want: true},
+		{name: "not runnable", command: "switchtube-missing-hook", want: true},
+		{name: "reads metadata", command: `grep -q '"channel":"OS".*"size":42'`, want: false},
+		{name: "filters by title", command: `! grep -q '"title":"Paging"'`, want: true},
+	}
+
+	video := &models.Video{ID: "abc", Title: "Paging"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := models.DownloadConfig{ExecBefore: tt.command}
+
+			got := skippedByHook(context.Background(), video, "OS", 42, config)
+			if got != tt.want {
+				t.Errorf("skippedByHook(%q) = %t, want %t", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareDownloadsSkipsByHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands are shell commands")
+	}
+
+	config := models.DownloadConfig{Output: t.TempDir(), ExecBefore: `grep -q '"id":"keep"'`}
+	cd := newChannelDownloader(config, (&Client{}).WithAPI(&variantsAPI{}))
+
+	videos := []models.Video{{ID: "drop", Title: "Dropped"}, {ID: "keep", Title: "Kept"}}
+
+	var failed []models.Video
+
+	queue := cd.prepareDownloads(context.Background(), "OS", videos, []int{0, 1}, &failed)
+	if len(queue) != 1 || queue[0].index != 1 || len(failed) != 0 {
+		t.Fatalf("prepareDownloads() = %+v, failed %v, want only the accepted video", queue, failed)
+	}
+
+	if cd.results[0].Status != models.StatusSkipped {
+		t.Errorf("results[0].Status = %q, want %q", cd.results[0].Status, models.StatusSkipped)
+	}
+}
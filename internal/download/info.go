@@ -0,0 +1,78 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"switchtube-downloader/internal/models"
+)
+
+var (
+	errFailedToGetInfo = errors.New("failed to get video info")
+	errVideoRequired   = errors.New("a video id or url is required")
+)
+
+// VideoInfo returns the metadata and variants of a video without downloading
+// it.
+func VideoInfo(ctx context.Context, client *Client, media string) (*models.VideoDetails, error) {
+	id, downloadType, err := extractIDAndType(media, client.baseURL())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToExtractType, err)
+	}
+
+	if downloadType == channelType || downloadType == profileType {
+		return nil, errVideoRequired
+	}
+
+	video, err := client.api.GetVideo(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToGetInfo, err)
+	}
+
+	variants, err := client.api.GetVariants(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToGetInfo, err)
+	}
+
+	details := &models.VideoDetails{
+		ID:       id,
+		Title:    video.Title,
+		Episode:  video.Episode,
+		Duration: video.Duration,
+		Variants: make([]models.Variant, 0, len(variants)),
+	}
+
+	for _, variant := range variants {
+		details.Variants = append(details.Variants, models.Variant{
+			Name:      variant.Name,
+			MediaType: variant.MediaType,
+			Path:      variant.Path,
+			Size:      client.variantSize(ctx, variant),
+			Height:    variantHeight(variant),
+		})
+	}
+
+	return details, nil
+}
+
+// variantSize returns the size of variant, asking SwitchTube for it if the
+// API didn't report it, or unknownSize if it can't be determined.
+func (c *Client) variantSize(ctx context.Context, variant models.Variant) int64 {
+	if variant.Size >= 0 {
+		return variant.Size
+	}
+
+	fullURL, err := url.JoinPath(c.baseURL(), variant.Path)
+	if err != nil {
+		return unknownSize
+	}
+
+	size, err := c.contentLength(ctx, fullURL)
+	if err != nil {
+		return unknownSize
+	}
+
+	return size
+}
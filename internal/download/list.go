@@ -0,0 +1,102 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"switchtube-downloader/internal/models"
+)
+
+// unknownSize marks a video whose size couldn't be determined.
+const unknownSize = -1
+
+var errFailedToListChannel = errors.New("failed to list channel")
+
+// ListChannel retrieves the videos of a channel together with the size of
+// the variant that would be downloaded.
+func ListChannel(
+	ctx context.Context,
+	client *Client,
+	media string,
+) (*models.ChannelListing, error) {
+	id, downloadType, err := extractIDAndType(media, client.baseURL())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToExtractType, err)
+	}
+
+	if downloadType == videoType || downloadType == profileType {
+		return nil, errChannelRequired
+	}
+
+	var config models.DownloadConfig
+
+	downloader := newChannelDownloader(config, client)
+
+	listing, err := downloader.list(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToListChannel, err)
+	}
+
+	return listing, nil
+}
+
+// list retrieves the channel metadata and describes each of its videos.
+func (cd *channelDownloader) list(
+	ctx context.Context,
+	channelID string,
+) (*models.ChannelListing, error) {
+	channelInfo, err := cd.getMetadata(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToGetChannelInfo, err)
+	}
+
+	videos, err := cd.api.GetChannelVideos(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToGetChannelVideos, err)
+	}
+
+	listing := &models.ChannelListing{
+		ID:     channelID,
+		Name:   channelInfo.Name,
+		Videos: make([]models.VideoInfo, 0, len(videos)),
+	}
+
+	for i, video := range videos {
+		listing.Videos = append(listing.Videos, models.VideoInfo{
+			Index:    i + 1,
+			ID:       video.ID,
+			Episode:  video.Episode,
+			Title:    video.Title,
+			Duration: video.Duration,
+			Size:     cd.videoSize(ctx, video.ID),
+		})
+	}
+
+	return listing, nil
+}
+
+// videoSize returns the size of the variant that would be downloaded or
+// unknownSize if it can't be determined.
+func (cd *channelDownloader) videoSize(ctx context.Context, videoID string) int64 {
+	var progress models.ProgressInfo
+
+	downloader := newVideoDownloader(cd.config, progress, cd.client)
+
+	variants, err := downloader.api.GetVariants(ctx, videoID)
+	if err != nil {
+		return unknownSize
+	}
+
+	return cd.variantSize(ctx, cd.client.preferVariant(ctx, variants, cd.config))
+}
+
+// variantSize returns the size of the first variant, which is the one that
+// would be downloaded, or unknownSize if it can't be determined.
+func (cd *channelDownloader) variantSize(ctx context.Context, variants []models.Variant) int64 {
+	if len(variants) == 0 {
+		return unknownSize
+	}
+
+	return cd.client.variantSize(ctx, variants[0])
+}
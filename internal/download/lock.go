@@ -0,0 +1,45 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"switchtube-downloader/internal/helper/lock"
+	"switchtube-downloader/internal/models"
+)
+
+// lockOutput locks the output directory of config, which single videos are
+// downloaded into, like lockFolder. Writing to stdout needs no lock.
+func lockOutput(config models.DownloadConfig) (func(), error) {
+	if config.Output == models.OutputStdout {
+		return func() {}, nil
+	}
+
+	output := config.Output
+	if output == "" {
+		output = "."
+	}
+
+	return lockFolder(output, config.WaitLock)
+}
+
+// lockFolder locks folder, so that two instances don't download into it at
+// the same time, and returns the function releasing the lock. Only the folder
+// the files are written into is locked, e.g. the folder of a channel, so that
+// different channels can be downloaded into the same output directory at the
+// same time. If the folder is locked, it fails unless wait is set.
+func lockFolder(folder string, wait bool) (func(), error) {
+	folderLock, err := lock.Acquire(folder, false)
+	if errors.Is(err, lock.ErrLocked) && wait {
+		fmt.Fprintf(os.Stderr, "Waiting for another instance downloading into %s...\n", folder)
+
+		folderLock, err = lock.Acquire(folder, true)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return folderLock.Release, nil
+}
@@ -0,0 +1,65 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"switchtube-downloader/internal/helper/lock"
+	"switchtube-downloader/internal/models"
+)
+
+func TestDownloadLocked(t *testing.T) {
+	output := t.TempDir()
+
+	held, err := lock.Acquire(output, false)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer held.Release()
+
+	config := models.DownloadConfig{Media: "abc123", Output: output}
+
+	// The lock is checked before the video is requested
+	if err := Download(context.Background(), &Client{}, config); !errors.Is(err, lock.ErrLocked) {
+		t.Errorf("Download() error = %v, want %v", err, lock.ErrLocked)
+	}
+
+	unlock, err := lockOutput(models.DownloadConfig{Output: models.OutputStdout})
+	if err != nil {
+		t.Fatalf("lockOutput() for stdout error = %v", err)
+	}
+
+	unlock()
+}
+
+func TestChannelFolderLocked(t *testing.T) {
+	output := t.TempDir()
+
+	// Single videos downloaded into the output directory don't block channels
+	held, err := lock.Acquire(output, false)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	defer held.Release()
+
+	config := models.DownloadConfig{Output: output}
+	cd := newChannelDownloader(config, &Client{})
+
+	_, unlock, err := cd.createFolder("os", "Operating Systems")
+	if err != nil {
+		t.Fatalf("createFolder() error = %v", err)
+	}
+	defer unlock()
+
+	if _, _, err := cd.createFolder("os", "Operating Systems"); !errors.Is(err, lock.ErrLocked) {
+		t.Errorf("createFolder() of a locked channel error = %v, want %v", err, lock.ErrLocked)
+	}
+
+	_, unlockOther, err := cd.createFolder("db", "Databases")
+	if err != nil {
+		t.Fatalf("createFolder() of another channel error = %v", err)
+	}
+
+	unlockOther()
+}
@@ -2,26 +2,42 @@
 package download
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"switchtube-downloader/internal/helper/dir"
+	"switchtube-downloader/internal/helper/lock"
 	"switchtube-downloader/internal/models"
 	"switchtube-downloader/internal/token"
 )
 
 const (
 	// Base URL and API endpoints for SwitchTube.
-	baseURL             = "https://tube.switch.ch/"
+	defaultBaseURL      = "https://tube.switch.ch/"
 	videoAPI            = "api/v1/browse/videos/"
 	channelAPI          = "api/v1/browse/channels/"
+	profileAPI          = "api/v1/browse/profiles/"
+	searchAPI           = "api/v1/search"
+	accountAPI          = "api/v1/browse/profiles/me"
 	videoPrefix         = "videos/"
 	channelPrefix       = "channels/"
+	profilePrefix       = "profiles/"
 	headerAuthorization = "Authorization"
+
+	// DefaultAPITimeout and DefaultReadTimeout are the default timeouts of
+	// API requests and of stalled video streams, which are also the defaults
+	// of the flags setting them.
+	DefaultAPITimeout  = 30 * time.Second
+	DefaultReadTimeout = time.Minute
 )
 
 type mediaType int
@@ -30,52 +46,196 @@ const (
 	unknownType mediaType = iota
 	videoType
 	channelType
+	profileType
 )
 
 var (
+	// ErrUnauthorized is returned if SwitchTube rejects the access token.
+	ErrUnauthorized = errors.New("access denied, check your access token")
+
+	// ErrNotFound is returned if a video, channel or profile doesn't exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrPartialFailure is returned if some videos or channels of a download
+	// failed while others succeeded.
+	ErrPartialFailure = errors.New("some downloads failed")
+
 	errFailedToCreateRequest   = errors.New("failed to create request")
 	errFailedToDecodeResponse  = errors.New("failed to decode response")
 	errFailedToDownloadChannel = errors.New("failed to download channel")
+	errFailedToDownloadProfile = errors.New("failed to download profile")
 	errFailedToDownloadVideo   = errors.New("failed to download video")
 	errFailedToExtractType     = errors.New("failed to extract type")
 	errFailedToGetToken        = errors.New("failed to get token")
+	errInvalidBaseURL          = errors.New("invalid base url")
 	errInvalidURL              = errors.New("invalid url")
 )
 
 // Client handles all API interactions.
 type Client struct {
 	tokenManager *token.Manager
+	api          API
+	cache        *responseCache
+	limiter      *rateLimiter
 	client       *http.Client
+	apiClient    *http.Client
+	hookClient   *http.Client
+	base         string
+	header       http.Header
+	network      externalNetwork
+	readTimeout  time.Duration
 }
 
 // NewClient creates a new instance of Client.
-func NewClient(tm *token.Manager) *Client {
-	return &Client{
+func NewClient(tm *token.Manager, config models.ClientConfig) (*Client, error) {
+	if err := checkBaseURL(config.BaseURL); err != nil {
+		return nil, err
+	}
+
+	transport, err := newTransport(config)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := parseHeaders(config)
+	if err != nil {
+		return nil, err
+	}
+
+	logged := &headerTransport{
+		next:   &loggingTransport{next: transport},
+		header: header,
+		host:   baseHost(config.BaseURL),
+	}
+	checkRedirect := newCheckRedirect(config.AuthRedirectHosts)
+
+	client := &Client{
 		tokenManager: tm,
+		api:          nil,
+		cache:        newResponseCache(config.CacheDir),
+		limiter:      newRateLimiter(config.RateLimit),
 		client: &http.Client{
 			Timeout:       0,
-			Transport:     http.DefaultTransport,
+			Transport:     logged,
+			CheckRedirect: checkRedirect,
+			Jar:           nil,
+		},
+		apiClient: &http.Client{
+			Timeout:       orDefault(config.APITimeout, DefaultAPITimeout),
+			Transport:     logged,
+			CheckRedirect: checkRedirect,
+			Jar:           nil,
+		},
+		hookClient: &http.Client{
+			Timeout:       webhookTimeout,
+			Transport:     transport,
 			CheckRedirect: nil,
 			Jar:           nil,
 		},
+		base:        config.BaseURL,
+		header:      header,
+		network:     newExternalNetwork(config),
+		readTimeout: orDefault(config.ReadTimeout, DefaultReadTimeout),
 	}
+	client.api = client
+
+	return client, nil
 }
 
-// makeRequest makes an authenticated HTTP request.
-func (c *Client) makeRequest(url string) (*http.Response, error) {
+// checkBaseURL returns an error if base isn't empty or an HTTP(S) URL.
+func checkBaseURL(base string) error {
+	if base == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(base)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errInvalidBaseURL, err)
+	}
+
+	if parsed.Scheme != "https" && parsed.Scheme != "http" || parsed.Host == "" {
+		return fmt.Errorf("%w: %s", errInvalidBaseURL, base)
+	}
+
+	return nil
+}
+
+// baseHost returns the host of the SwitchTube instance at base, which has
+// been checked by checkBaseURL.
+func baseHost(base string) string {
+	parsed, err := url.Parse(cmp.Or(base, defaultBaseURL))
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Host
+}
+
+// baseURL returns the URL of the SwitchTube instance the client talks to.
+func (c *Client) baseURL() string {
+	return cmp.Or(c.base, defaultBaseURL)
+}
+
+// orDefault returns timeout or fallback if timeout isn't positive.
+func orDefault(timeout, fallback time.Duration) time.Duration {
+	if timeout <= 0 {
+		return fallback
+	}
+
+	return timeout
+}
+
+// makeRequestWithMethod makes an authenticated API request with the given
+// method. Each attempt is limited to the API timeout and waits for the client
+// rate limit, and rate-limited requests are retried.
+func (c *Client) makeRequestWithMethod(
+	ctx context.Context,
+	method, url string,
+) (*http.Response, error) {
+	return c.makeRequestWithHeader(ctx, method, url, nil)
+}
+
+// makeRequestWithHeader is makeRequestWithMethod sending the additional
+// request header, which may be nil.
+func (c *Client) makeRequestWithHeader(
+	ctx context.Context,
+	method, url string,
+	header http.Header,
+) (*http.Response, error) {
+	return withRetry(ctx, func() (*http.Response, error) {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		return c.send(ctx, c.apiClient, method, url, header)
+	})
+}
+
+// send makes a single authenticated HTTP request using httpClient with the
+// additional request header, which may be nil.
+func (c *Client) send(
+	ctx context.Context,
+	httpClient *http.Client,
+	method, url string,
+	header http.Header,
+) (*http.Response, error) {
 	apiToken, err := c.tokenManager.Get()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errFailedToGetToken, err)
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errFailedToCreateRequest, err)
 	}
 
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
 	req.Header.Set(headerAuthorization, "Token "+apiToken)
 
-	resp, err := c.client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errFailedToCreateRequest, err)
 	}
@@ -83,90 +243,251 @@ func (c *Client) makeRequest(url string) (*http.Response, error) {
 	return resp, nil
 }
 
-// makeRequest makes an authenticated HTTP request and decodes the response.
-func (c *Client) makeJSONRequest(url string, target any) error {
-	resp, err := c.makeRequest(url)
+// makeJSONRequest makes an authenticated HTTP request and decodes the response.
+func (c *Client) makeJSONRequest(ctx context.Context, url string, target any) error {
+	_, err := c.makeJSONRequestWithHeader(ctx, url, target)
+
+	return err
+}
+
+// makeJSONRequestWithHeader makes an authenticated HTTP request, decodes the
+// response and returns its header. A cached response is used if SwitchTube
+// reports that it hasn't changed.
+func (c *Client) makeJSONRequestWithHeader(
+	ctx context.Context,
+	url string,
+	target any,
+) (http.Header, error) {
+	cached := c.cache.load(url)
+
+	resp, err := c.makeRequestWithHeader(ctx, http.MethodGet, url, cached.requestHeader())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Printf("Warning: failed to close response body: %v\n", err)
+			slog.Warn("failed to close response body", "error", err)
 		}
 	}()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		slog.Debug("using cached response", "url", url)
+
+		return cached.header(), decodeJSON(cached.Body, target)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("%w: status %d: %s",
-			errHTTPNotOK,
-			resp.StatusCode,
-			http.StatusText(resp.StatusCode))
+		return nil, statusError(resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToDecodeResponse, err)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+	if err := decodeJSON(body, target); err != nil {
+		return nil, err
+	}
+
+	c.cache.store(url, resp.Header, body)
+
+	return resp.Header, nil
+}
+
+// decodeJSON decodes the response body into target.
+func decodeJSON(body []byte, target any) error {
+	if err := json.Unmarshal(body, target); err != nil {
 		return fmt.Errorf("%w: %w", errFailedToDecodeResponse, err)
 	}
 
 	return nil
 }
 
-// Download initiates the download process based on the provided configuration.
-func Download(config models.DownloadConfig) error {
-	id, downloadType, err := extractIDAndType(config.Media)
+// statusError returns the error for a response with a non-OK status code.
+func statusError(statusCode int) error {
+	err := fmt.Errorf("%w: status %d: %s", errHTTPNotOK, statusCode, http.StatusText(statusCode))
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	default:
+		return err
+	}
+}
+
+// contentLength makes an authenticated HEAD request and returns the content
+// length of the resource, which is -1 if the server doesn't report it.
+func (c *Client) contentLength(ctx context.Context, url string) (int64, error) {
+	resp, err := c.makeRequestWithMethod(ctx, http.MethodHead, url)
 	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToExtractType, err)
+		return 0, err
 	}
 
-	tokenMgr := token.NewTokenManager()
-	client := NewClient(tokenMgr)
+	if err := resp.Body.Close(); err != nil {
+		slog.Warn("failed to close response body", "error", err)
+	}
 
-	videoProgress := models.ProgressInfo{
-		CurrentItem: 1,
-		TotalItems:  1,
+	if resp.StatusCode != http.StatusOK {
+		return 0, statusError(resp.StatusCode)
+	}
+
+	return resp.ContentLength, nil
+}
+
+// Download initiates the download process based on the provided configuration.
+// It is aborted after the run timeout of config, if any. The folder a video is
+// downloaded into, i.e. the output directory or the folder of its channel, is
+// locked against other instances while downloading.
+func Download(ctx context.Context, client *Client, config models.DownloadConfig) error {
+	ctx, cancel := WithRunTimeout(ctx, config)
+	defer cancel()
+
+	return runTimeoutError(ctx, config, download(ctx, client, config))
+}
+
+// download implements Download.
+func download(ctx context.Context, client *Client, config models.DownloadConfig) error {
+	id, downloadType, err := extractIDAndType(config.Media, client.baseURL())
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToExtractType, err)
 	}
 
 	switch downloadType {
 	case videoType:
-		downloader := newVideoDownloader(config, videoProgress, client)
-		if err = downloader.downloadVideo(id, true); err != nil {
+		if err = downloadSingleVideo(ctx, client, config, id); err != nil {
+			if !lock.IsLockError(err) {
+				recordFailure(config, id, err)
+			}
+
 			return fmt.Errorf("%w: %w", errFailedToDownloadVideo, err)
 		}
 	case unknownType:
 		// If the type is unknown, we try to download as a video first.
-		downloader := newVideoDownloader(config, videoProgress, client)
-		if err = downloader.downloadVideo(id, true); err == nil {
+		if err = downloadSingleVideo(ctx, client, config, id); err == nil {
 			return nil
-		} else if errors.Is(err, dir.ErrFailedToCreateFile) {
+		} else if errors.Is(err, dir.ErrFailedToCreateFile) || lock.IsLockError(err) {
 			return fmt.Errorf("%w", err)
+		} else if !errors.Is(err, ErrNotFound) {
+			// The id belongs to a video that failed to download
+			recordFailure(config, id, err)
 		}
 
 		fallthrough
 	case channelType:
 		downloader := newChannelDownloader(config, client)
-		if err = downloader.downloadChannel(id); err != nil {
+		if err = downloader.downloadChannel(ctx, id); err != nil {
 			return fmt.Errorf("%w: %w", errFailedToDownloadChannel, err)
 		}
+	case profileType:
+		downloader := newProfileDownloader(config, client)
+		if err = downloader.downloadProfile(ctx, id); err != nil {
+			return fmt.Errorf("%w: %w", errFailedToDownloadProfile, err)
+		}
 	}
 
 	return nil
 }
 
-// extractIDAndType extracts the id and determines if it's a video or channel.
-func extractIDAndType(input string) (string, mediaType, error) {
+// downloadSingleVideo downloads the video id into the output directory, which
+// is locked while downloading.
+func downloadSingleVideo(
+	ctx context.Context,
+	client *Client,
+	config models.DownloadConfig,
+	id string,
+) error {
+	unlock, err := lockOutput(config)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	progress := models.ProgressInfo{
+		CurrentItem:     1,
+		TotalItems:      1,
+		DownloadedBytes: 0,
+		TotalBytes:      0,
+		StartTime:       time.Time{},
+	}
+
+	return newVideoDownloader(config, progress, client).downloadVideo(ctx, id, true)
+}
+
+// MediaID returns the id of the video, channel or profile media refers to,
+// which is media itself if it isn't a link to the SwitchTube instance at base,
+// or the default one if base is empty.
+func MediaID(media, base string) string {
+	id, _, err := extractIDAndType(media, base)
+	if err != nil {
+		return strings.TrimSpace(media)
+	}
+
+	return id
+}
+
+// linkTypes maps the first segment of the path of a SwitchTube link to the
+// type of media it refers to. Embed links are links to videos.
+var linkTypes = map[string]mediaType{
+	"videos":   videoType,
+	"embed":    videoType,
+	"channels": channelType,
+	"profiles": profileType,
+}
+
+// extractIDAndType extracts the id and determines if it's a video, channel or
+// profile. Links may use any scheme and case of the host or none at all, and
+// contain a slug or other segments after the id, a trailing slash, a query
+// such as ?start=30 or a fragment. Links must point to the SwitchTube instance
+// at base, or the default one if base is empty.
+func extractIDAndType(input, base string) (string, mediaType, error) {
 	input = strings.TrimSpace(input)
 
-	// If input doesn't start with baseURL, return as unknown type
-	// This is the case if the Id was passed as an argument
-	if !strings.HasPrefix(input, baseURL) {
-		return input, unknownType, nil
+	// Input that isn't a link is an id, e.g. one passed as an argument
+	link, isLink, err := parseMediaLink(input, base)
+	if !isLink {
+		return input, unknownType, err
 	}
 
-	switch prefixAndID := strings.TrimPrefix(input, baseURL); {
-	case strings.HasPrefix(prefixAndID, videoPrefix):
-		return strings.TrimPrefix(prefixAndID, videoPrefix), videoType, nil
-	case strings.HasPrefix(prefixAndID, channelPrefix):
-		return strings.TrimPrefix(prefixAndID, channelPrefix), channelType, nil
-	default:
-		return prefixAndID, unknownType, errInvalidURL
+	path := strings.Trim(link.Path, "/")
+	segments := strings.Split(path, "/")
+
+	linkType, ok := linkTypes[strings.ToLower(segments[0])]
+	if !ok || len(segments) < 2 || segments[1] == "" {
+		return path, unknownType, errInvalidURL
 	}
+
+	return segments[1], linkType, nil
+}
+
+// parseMediaLink parses input if it is a link to the SwitchTube instance at
+// base, with or without a scheme, and reports whether it is one. Links to
+// other hosts are invalid. The path of the link is made relative to the one
+// of base, which is empty unless the instance is served below a path.
+func parseMediaLink(input, base string) (*url.URL, bool, error) {
+	baseLink, err := url.Parse(cmp.Or(base, defaultBaseURL))
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", errInvalidBaseURL, err)
+	}
+
+	host := baseLink.Host
+
+	if !strings.Contains(input, "://") {
+		if !strings.HasPrefix(strings.ToLower(input), host+"/") {
+			return nil, false, nil
+		}
+
+		input = "https://" + input
+	}
+
+	link, err := url.Parse(input)
+	if err != nil || !strings.EqualFold(link.Host, host) {
+		return nil, false, errInvalidURL
+	}
+
+	link.Path = strings.TrimPrefix(link.Path, strings.TrimSuffix(baseLink.Path, "/"))
+
+	return link, true, nil
 }
@@ -2,6 +2,7 @@ package download
 
 import (
 	"errors"
+	"net/http"
 	"testing"
 )
 
@@ -16,18 +17,25 @@ func TestExtractIDAndType(t *testing.T) {
 	}{
 		{
 			name:     "video URL",
-			input:    baseURL + videoPrefix + "123",
+			input:    defaultBaseURL + videoPrefix + "123",
 			wantID:   "123",
 			wantType: videoType,
 			wantErr:  false,
 		},
 		{
 			name:     "channel URL",
-			input:    baseURL + channelPrefix + "abc",
+			input:    defaultBaseURL + channelPrefix + "abc",
 			wantID:   "abc",
 			wantType: channelType,
 			wantErr:  false,
 		},
+		{
+			name:     "profile URL",
+			input:    defaultBaseURL + profilePrefix + "xyz",
+			wantID:   "xyz",
+			wantType: profileType,
+			wantErr:  false,
+		},
 		{
 			name:     "ID only (unknown type)",
 			input:    "123",
@@ -37,7 +45,7 @@ func TestExtractIDAndType(t *testing.T) {
 		},
 		{
 			name:     "invalid URL",
-			input:    baseURL + "invalid/123",
+			input:    defaultBaseURL + "invalid/123",
 			wantID:   "invalid/123",
 			wantType: unknownType,
 			wantErr:  true,
@@ -45,16 +53,88 @@ func TestExtractIDAndType(t *testing.T) {
 		},
 		{
 			name:     "input with spaces",
-			input:    "  " + baseURL + videoPrefix + "123  ",
+			input:    "  " + defaultBaseURL + videoPrefix + "123  ",
+			wantID:   "123",
+			wantType: videoType,
+			wantErr:  false,
+		},
+		{
+			name:     "embed URL",
+			input:    defaultBaseURL + "embed/123",
+			wantID:   "123",
+			wantType: videoType,
+			wantErr:  false,
+		},
+		{
+			name:     "permalink with slug",
+			input:    defaultBaseURL + videoPrefix + "123/lecture-1",
 			wantID:   "123",
 			wantType: videoType,
 			wantErr:  false,
 		},
+		{
+			name:     "trailing slash",
+			input:    defaultBaseURL + channelPrefix + "abc/",
+			wantID:   "abc",
+			wantType: channelType,
+			wantErr:  false,
+		},
+		{
+			name:     "start query",
+			input:    defaultBaseURL + videoPrefix + "123?start=30",
+			wantID:   "123",
+			wantType: videoType,
+			wantErr:  false,
+		},
+		{
+			name:     "fragment",
+			input:    defaultBaseURL + videoPrefix + "123#t=10",
+			wantID:   "123",
+			wantType: videoType,
+			wantErr:  false,
+		},
+		{
+			name:     "uppercase host",
+			input:    "https://TUBE.SWITCH.CH/videos/123",
+			wantID:   "123",
+			wantType: videoType,
+			wantErr:  false,
+		},
+		{
+			name:     "http scheme",
+			input:    "http://tube.switch.ch/videos/123",
+			wantID:   "123",
+			wantType: videoType,
+			wantErr:  false,
+		},
+		{
+			name:     "without scheme",
+			input:    "tube.switch.ch/channels/abc",
+			wantID:   "abc",
+			wantType: channelType,
+			wantErr:  false,
+		},
+		{
+			name:     "other host",
+			input:    "https://example.com/videos/123",
+			wantID:   "https://example.com/videos/123",
+			wantType: unknownType,
+			wantErr:  true,
+			errType:  errInvalidURL,
+		},
+		{
+			name:     "missing id",
+			input:    defaultBaseURL + videoPrefix,
+			wantID:   "videos",
+			wantType: unknownType,
+			wantErr:  true,
+			errType:  errInvalidURL,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			id, downloadType, err := extractIDAndType(tt.input)
+			id, downloadType, err := extractIDAndType(tt.input, "")
 
 			if id != tt.wantID {
 				t.Errorf("extractIDAndType() id = %q, want %q", id, tt.wantID)
@@ -74,3 +154,82 @@ func TestExtractIDAndType(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		want       error
+	}{
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, want: ErrUnauthorized},
+		{name: "forbidden", statusCode: http.StatusForbidden, want: ErrUnauthorized},
+		{name: "not found", statusCode: http.StatusNotFound, want: ErrNotFound},
+		{name: "server error", statusCode: http.StatusInternalServerError, want: errHTTPNotOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := statusError(tt.statusCode)
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("statusError(%d) = %v, want %v", tt.statusCode, err, tt.want)
+			}
+
+			if !errors.Is(err, errHTTPNotOK) {
+				t.Errorf("statusError(%d) = %v, want %v", tt.statusCode, err, errHTTPNotOK)
+			}
+		})
+	}
+}
+
+func TestMediaID(t *testing.T) {
+	tests := map[string]string{
+		"https://tube.switch.ch/channels/dh0sX6Fj1I": "dh0sX6Fj1I",
+		" dh0sX6Fj1I ":                   "dh0sX6Fj1I",
+		"https://example.com/channels/x": "https://example.com/channels/x",
+	}
+
+	for media, want := range tests {
+		if got := MediaID(media, ""); got != want {
+			t.Errorf("MediaID(%q) = %q, want %q", media, got, want)
+		}
+	}
+}
+
+func TestMediaIDOfInstance(t *testing.T) {
+	base := "https://test.tube.switch.ch/"
+	tests := map[string]string{
+		"https://test.tube.switch.ch/channels/dh0sX6Fj1I": "dh0sX6Fj1I",
+		"test.tube.switch.ch/videos/abc":                  "abc",
+		"https://tube.switch.ch/channels/dh0sX6Fj1I":      "https://tube.switch.ch/channels/dh0sX6Fj1I",
+	}
+
+	for media, want := range tests {
+		if got := MediaID(media, base); got != want {
+			t.Errorf("MediaID(%q, %q) = %q, want %q", media, base, got, want)
+		}
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	for _, base := range []string{"", "https://test.tube.switch.ch/", "http://localhost:8080"} {
+		if err := checkBaseURL(base); err != nil {
+			t.Errorf("checkBaseURL(%q) error = %v", base, err)
+		}
+	}
+
+	for _, base := range []string{"tube.switch.ch", "ftp://tube.switch.ch", "https://", "https://%zz"} {
+		if err := checkBaseURL(base); !errors.Is(err, errInvalidBaseURL) {
+			t.Errorf("checkBaseURL(%q) error = %v, want %v", base, err, errInvalidBaseURL)
+		}
+	}
+
+	if got := (&Client{}).baseURL(); got != defaultBaseURL {
+		t.Errorf("baseURL() = %q, want %q", got, defaultBaseURL)
+	}
+
+	client := &Client{base: "https://test.tube.switch.ch/"}
+	if got, _ := searchURL(client.baseURL(), "os"); got != "https://test.tube.switch.ch/api/v1/search?q=os" {
+		t.Errorf("searchURL() = %q, want the search of the base url", got)
+	}
+}
@@ -0,0 +1,187 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/history"
+	"switchtube-downloader/internal/models"
+)
+
+// pruneDirPermissions are the permissions of the folder stale files are moved
+// to.
+const pruneDirPermissions = 0o755
+
+var errFailedToListFolder = errors.New("failed to list channel folder")
+
+// mirrorEmpty runs mirror for a channel without videos in mirror mode, so
+// that the files of its last videos are pruned as well.
+func (cd *channelDownloader) mirrorEmpty(channelID, name string) error {
+	if !cd.config.Mirror {
+		return nil
+	}
+
+	folderName, unlock, err := cd.createFolder(channelID, name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cd.config.Output = folderName
+	cd.mirror(nil)
+
+	return nil
+}
+
+// mirror lists the files of the channel folder that the downloader wrote for
+// videos no longer in the channel and, with Prune or PruneTo, removes or
+// moves them. Failures are logged, since the videos have been downloaded
+// already.
+func (cd *channelDownloader) mirror(videos []models.Video) {
+	if cd.config.History == "" {
+		slog.Warn("files no longer in the channel are only found with the download history enabled")
+
+		return
+	}
+
+	entries, err := history.Load(cd.config.History)
+	if err != nil {
+		slog.Warn("failed to find files that are no longer in the channel", "error", err)
+
+		return
+	}
+
+	stale, err := staleFiles(cd.config.Output, videos, entries, cd.config)
+	if err != nil {
+		slog.Warn("failed to find files that are no longer in the channel", "error", err)
+
+		return
+	}
+
+	if len(stale) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "\n%d files are no longer in the channel:\n", len(stale))
+
+	for _, file := range stale {
+		fmt.Fprintf(os.Stderr, "  %s\n", filepath.Base(file))
+	}
+
+	switch {
+	case !cd.config.Prune && cd.config.PruneTo == "":
+		fmt.Fprintln(os.Stderr, "Pass --prune to remove them")
+	case cd.config.PruneTo != "":
+		moveFiles(stale, cd.config.PruneTo)
+	case ui.Confirm("Delete these %d files?", len(stale)):
+		deleteFiles(stale)
+	}
+}
+
+// staleFiles returns the files in folder that the download history records
+// for videos that aren't among videos anymore. Files of such a video with
+// another extension, e.g. its NFO file or extracted audio, are stale as well.
+// Any other file, such as the notes of the user or files of videos that are
+// still in the channel under another name, is kept, as is a file named like
+// a video of the channel.
+func staleFiles(
+	folder string,
+	videos []models.Video,
+	entries []models.HistoryEntry,
+	config models.DownloadConfig,
+) ([]string, error) {
+	dir, err := filepath.Abs(folder)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToListFolder, err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToListFolder, err)
+	}
+
+	current := make(map[string]bool, len(videos))
+	stems := make(map[string]bool, len(videos))
+	titles := fileTitles(videos, config)
+
+	for _, video := range videos {
+		current[video.ID] = true
+		stems[fileStem(titles[video.ID], video.ID, video.Episode, config)] = true
+	}
+
+	// Stems of the files written for videos that are gone
+	gone := make(map[string]bool)
+
+	for _, entry := range entries {
+		if current[entry.ID] || filepath.Dir(entry.Path) != dir {
+			continue
+		}
+
+		gone[stem(filepath.Base(entry.Path))] = true
+	}
+
+	var stale []string
+
+	for _, file := range files {
+		name := stem(file.Name())
+		if !file.IsDir() && gone[name] && !stems[strings.ToLower(name)] {
+			stale = append(stale, filepath.Join(folder, file.Name()))
+		}
+	}
+
+	return stale, nil
+}
+
+// stem returns name without its extension.
+func stem(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// moveFiles moves files to folder.
+func moveFiles(files []string, folder string) {
+	if err := os.MkdirAll(folder, pruneDirPermissions); err != nil {
+		slog.Warn("failed to create folder", "folder", folder, "error", err)
+
+		return
+	}
+
+	moved := 0
+
+	for _, file := range files {
+		if err := os.Rename(file, filepath.Join(folder, filepath.Base(file))); err != nil {
+			slog.Warn("failed to move file", "file", file, "error", err)
+
+			continue
+		}
+
+		slog.Info("moved file that is no longer in the channel", "file", file, "folder", folder)
+
+		moved++
+	}
+
+	fmt.Fprintf(os.Stderr, "Moved %d files to %s\n", moved, folder)
+}
+
+// deleteFiles deletes files.
+func deleteFiles(files []string) {
+	deleted := 0
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			slog.Warn("failed to delete file", "file", file, "error", err)
+
+			continue
+		}
+
+		slog.Info("deleted file that is no longer in the channel", "file", file)
+
+		deleted++
+	}
+
+	fmt.Fprintf(os.Stderr, "Deleted %d files\n", deleted)
+}
@@ -0,0 +1,132 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"switchtube-downloader/internal/history"
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+)
+
+func TestStaleFiles(t *testing.T) {
+	folder := t.TempDir()
+	config := models.DownloadConfig{Output: folder, UseEpisode: true}
+	videos := []models.Video{
+		{ID: "a", Title: "Mapping", Episode: "01"},
+		{ID: "b", Title: "Paging", Episode: "02"},
+	}
+
+	for _, name := range []string{
+		"01_Mapping.mp4", "01_Mapping.nfo", "02_Paging.mp3", "Paging.mp4",
+		"03_Caching.mp4", "03_Caching.nfo", "04_Unrecorded.mp4", "notes.txt",
+		playlistFile, showNFOFile, syncStateFile,
+	} {
+		if err := os.WriteFile(filepath.Join(folder, name), nil, 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	if err := os.Mkdir(filepath.Join(folder, "extra"), 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+
+	entries := []models.HistoryEntry{
+		{ID: "a", Path: filepath.Join(folder, "01_Mapping.mp4")},
+		// Written under another naming, but the video is still in the channel
+		{ID: "b", Path: filepath.Join(folder, "Paging.mp4")},
+		{ID: "c", Path: filepath.Join(folder, "03_Caching.mp4")},
+		// Gone from the channel, but downloaded into another folder
+		{ID: "d", Path: filepath.Join(t.TempDir(), "notes.mp4")},
+	}
+
+	stale, err := staleFiles(folder, videos, entries, config)
+	if err != nil {
+		t.Fatalf("staleFiles() error = %v", err)
+	}
+
+	want := []string{filepath.Join(folder, "03_Caching.mp4"), filepath.Join(folder, "03_Caching.nfo")}
+	if !slices.Equal(stale, want) {
+		t.Errorf("staleFiles() = %v, want %v", stale, want)
+	}
+}
+
+func TestMirrorPruneTo(t *testing.T) {
+	folder := t.TempDir()
+	pruned := filepath.Join(t.TempDir(), "pruned")
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+
+	for _, name := range []string{"Old.mp4", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(folder, name), nil, 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	if err := history.Add(historyFile, models.HistoryEntry{ID: "old", Path: filepath.Join(folder, "Old.mp4")}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	config := models.DownloadConfig{Output: folder, Mirror: true, PruneTo: pruned, History: historyFile}
+	cd := newChannelDownloader(config, nil)
+	cd.mirror([]models.Video{{ID: "a", Title: "New"}})
+
+	if _, err := os.Stat(filepath.Join(pruned, "Old.mp4")); err != nil {
+		t.Errorf("Stat() error = %v, want the stale file to be moved", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(folder, "Old.mp4")); !os.IsNotExist(err) {
+		t.Errorf("Stat() error = %v, want the stale file to be gone from the channel", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(folder, "notes.txt")); err != nil {
+		t.Errorf("Stat() error = %v, want unrelated files to be kept", err)
+	}
+}
+
+func TestMirrorEmptyChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"id":"os","name":"Operating Systems"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(token.NewTokenManagerWithToken("secret"),
+		models.ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := t.TempDir()
+	folder := filepath.Join(output, "Operating Systems")
+	pruned := filepath.Join(t.TempDir(), "pruned")
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+
+	if err := os.Mkdir(folder, 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(folder, "Last.mp4"), nil, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := history.Add(historyFile, models.HistoryEntry{ID: "last", Path: filepath.Join(folder, "Last.mp4")}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// The last video of the channel was removed
+	config := models.DownloadConfig{Output: output, Mirror: true, PruneTo: pruned, History: historyFile}
+	cd := newChannelDownloader(config, client.WithAPI(&syncAPI{}))
+
+	captureOutput(t, func() { err = cd.downloadChannel(context.Background(), "os") })
+	if err != nil {
+		t.Fatalf("downloadChannel() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(pruned, "Last.mp4")); err != nil {
+		t.Errorf("Stat() error = %v, want the file of the removed video to be moved", err)
+	}
+}
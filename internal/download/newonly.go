@@ -0,0 +1,63 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+// downloadNewVideos downloads the videos of channel that were published after
+// the last video downloaded by a previous run, as recorded in the sync state
+// of the channel folder. Neither a selection list is shown nor are the
+// existing files compared, which keeps incremental runs lightweight.
+func (cd *channelDownloader) downloadNewVideos(
+	ctx context.Context,
+	channel models.Channel,
+	videos []models.Video,
+) error {
+	start := time.Now()
+
+	folderName, unlock, err := cd.createFolder(channel.ID, channel.Name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cd.config.Output = folderName
+	statePath := filepath.Join(folderName, syncStateFile)
+
+	state, err := loadSyncState(statePath)
+	if err != nil {
+		return err
+	}
+
+	indices := state.newSince(videos)
+	if len(indices) == 0 {
+		fmt.Fprintf(os.Stderr, "No new videos in channel %s since the last run\n", channel.Name)
+
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Found %d new videos in channel: %s\n", len(indices), channel.Name)
+	fmt.Fprintf(os.Stderr, "Downloading to folder: %s\n", folderName)
+
+	cd.results = nil
+
+	failed := cd.downloadVideos(ctx, channel.Name, videos, indices)
+
+	state.markSynced(cd.results)
+	state.advance(videos, failed)
+
+	if err := state.save(statePath); err != nil {
+		return err
+	}
+
+	summary := cd.summary(channel.Name, len(indices), failed)
+	cd.finishRun(channel, videos, summary, start)
+
+	return partialFailure(len(failed), len(indices), cd.firstErr)
+}
@@ -0,0 +1,131 @@
+package download
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// showNFOFile is the name of the NFO file describing a channel.
+	showNFOFile = "tvshow.nfo"
+
+	// nfoPermissions are the permissions of NFO files.
+	nfoPermissions = 0o644
+
+	// secondsPerMinute converts durations to the runtime of NFO files.
+	secondsPerMinute = 60
+
+	// uniqueIDType identifies SwitchTube ids in NFO files.
+	uniqueIDType = "switchtube"
+)
+
+var errFailedToWriteNFO = errors.New("failed to write NFO file")
+
+// uniqueID is the id of a show or episode in an NFO file.
+type uniqueID struct {
+	Type    string `xml:"type,attr"`
+	Default bool   `xml:"default,attr"`
+	Value   string `xml:",chardata"`
+}
+
+// showNFO describes a channel as a show for Kodi and Jellyfin.
+type showNFO struct {
+	XMLName  xml.Name  `xml:"tvshow"`
+	Title    string    `xml:"title"`
+	UniqueID *uniqueID `xml:"uniqueid,omitempty"`
+}
+
+// episodeNFO describes a video as an episode of the show of its channel.
+type episodeNFO struct {
+	XMLName  xml.Name `xml:"episodedetails"`
+	Title    string   `xml:"title"`
+	Season   int      `xml:"season,omitempty"`
+	Episode  int      `xml:"episode,omitempty"`
+	Plot     string   `xml:"plot,omitempty"`
+	Runtime  int      `xml:"runtime,omitempty"`
+	Aired    string   `xml:"aired,omitempty"`
+	UniqueID uniqueID `xml:"uniqueid"`
+}
+
+// writeShowNFO writes tvshow.nfo for the channel to the channel folder, if it
+// is enabled.
+func (cd *channelDownloader) writeShowNFO(channelID, channelName string) {
+	if !cd.config.WriteNFO {
+		return
+	}
+
+	nfo := showNFO{XMLName: xml.Name{}, Title: channelName, UniqueID: nil}
+	if channelID != "" {
+		nfo.UniqueID = &uniqueID{Type: uniqueIDType, Default: true, Value: channelID}
+	}
+
+	writeNFO(filepath.Join(cd.config.Output, showNFOFile), nfo)
+}
+
+// writeEpisodeNFO writes an NFO file for video next to filename, if it is
+// enabled.
+func (vd *videoDownloader) writeEpisodeNFO(video *models.Video, filename string) {
+	if !vd.config.WriteNFO {
+		return
+	}
+
+	nfo := episodeNFO{
+		XMLName:  xml.Name{},
+		Title:    video.Title,
+		Season:   0,
+		Episode:  0,
+		Plot:     video.Description,
+		Runtime:  int(math.Round(video.Duration / secondsPerMinute)),
+		Aired:    "",
+		UniqueID: uniqueID{Type: uniqueIDType, Default: true, Value: video.ID},
+	}
+
+	// Jellyfin needs a season to sort episodes by their number
+	if number, ok := episodeNumber(video.Episode); ok {
+		nfo.Season = 1
+		nfo.Episode = number
+	}
+
+	if !video.PublishedAt.IsZero() {
+		nfo.Aired = video.PublishedAt.Local().Format(time.DateOnly)
+	}
+
+	writeNFO(strings.TrimSuffix(filename, filepath.Ext(filename))+".nfo", nfo)
+}
+
+// writeNFO writes nfo as XML to path, logging a failure instead of failing
+// the download.
+func writeNFO(path string, nfo any) {
+	if err := encodeNFO(path, nfo); err != nil {
+		slog.Warn("failed to write NFO file", "file", path, "error", err)
+
+		return
+	}
+
+	slog.Info("wrote NFO file", "file", path)
+}
+
+// encodeNFO writes nfo as XML to path.
+func encodeNFO(path string, nfo any) error {
+	data, err := xml.MarshalIndent(nfo, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteNFO, err)
+	}
+
+	data = append([]byte(xml.Header), append(data, '\n')...)
+
+	if err := os.WriteFile(path, data, nfoPermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteNFO, err)
+	}
+
+	return nil
+}
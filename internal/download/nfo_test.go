@@ -0,0 +1,91 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestWriteEpisodeNFO(t *testing.T) {
+	folder := t.TempDir()
+	config := models.DownloadConfig{WriteNFO: true}
+	downloader := newVideoDownloader(config, models.ProgressInfo{}, nil)
+
+	video := &models.Video{
+		ID:          "v1",
+		Title:       "Paging & Segmentation",
+		Episode:     "E03",
+		Duration:    1830,
+		Description: "Virtual memory",
+		PublishedAt: time.Date(2024, 3, 1, 10, 0, 0, 0, time.Local),
+	}
+
+	downloader.writeEpisodeNFO(video, filepath.Join(folder, "E03_Paging.mp4"))
+
+	data, err := os.ReadFile(filepath.Join(folder, "E03_Paging.nfo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<episodedetails>",
+		"<title>Paging &amp; Segmentation</title>",
+		"<season>1</season>",
+		"<episode>3</episode>",
+		"<plot>Virtual memory</plot>",
+		"<runtime>31</runtime>",
+		"<aired>2024-03-01</aired>",
+		`<uniqueid type="switchtube" default="true">v1</uniqueid>`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("NFO = %s, want it to contain %s", data, want)
+		}
+	}
+}
+
+func TestWriteShowNFO(t *testing.T) {
+	tests := []struct {
+		name     string
+		writeNFO bool
+		want     string
+	}{
+		{
+			name:     "enabled",
+			writeNFO: true,
+			want: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				"<tvshow>\n  <title>Operating Systems</title>\n" +
+				`  <uniqueid type="switchtube" default="true">c1</uniqueid>` + "\n</tvshow>\n",
+		},
+		{name: "disabled", writeNFO: false, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := t.TempDir()
+			config := models.DownloadConfig{Output: folder, WriteNFO: tt.writeNFO}
+
+			newChannelDownloader(config, nil).writeShowNFO("c1", "Operating Systems")
+
+			data, err := os.ReadFile(filepath.Join(folder, showNFOFile))
+			if tt.want == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("tvshow.nfo was written although disabled")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(data) != tt.want {
+				t.Errorf("tvshow.nfo = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package download
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"switchtube-downloader/internal/models"
+)
+
+// sortVideos returns videos in the order of config, which determines their
+// numbers in the selection list and the order they are downloaded in.
+func sortVideos(videos []models.Video, config models.DownloadConfig) []models.Video {
+	var sorted []models.Video
+
+	switch config.Order {
+	case models.OrderEpisode:
+		sorted = episodeOrder(videos)
+	case models.OrderDate:
+		sorted = slices.Clone(videos)
+		slices.SortStableFunc(sorted, func(a, b models.Video) int {
+			return a.PublishedAt.Compare(b.PublishedAt)
+		})
+	case models.OrderTitle:
+		sorted = slices.Clone(videos)
+		slices.SortStableFunc(sorted, func(a, b models.Video) int {
+			return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		})
+	default:
+		sorted = slices.Clone(videos)
+	}
+
+	if config.Reverse {
+		slices.Reverse(sorted)
+	}
+
+	return sorted
+}
@@ -0,0 +1,52 @@
+package download
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestSortVideos(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 2, d, 10, 0, 0, 0, time.UTC) }
+	videos := []models.Video{
+		{ID: "a", Title: "paging", Episode: "E03", PublishedAt: day(2)},
+		{ID: "b", Title: "Mapping", Episode: "", PublishedAt: day(3)},
+		{ID: "c", Title: "Caching", Episode: "E01", PublishedAt: day(1)},
+	}
+
+	tests := []struct {
+		name   string
+		config models.DownloadConfig
+		want   []string
+	}{
+		{name: "default", config: models.DownloadConfig{}, want: []string{"a", "b", "c"}},
+		{name: "api", config: models.DownloadConfig{Order: models.OrderAPI}, want: []string{"a", "b", "c"}},
+		{name: "episode", config: models.DownloadConfig{Order: models.OrderEpisode}, want: []string{"c", "a", "b"}},
+		{name: "date", config: models.DownloadConfig{Order: models.OrderDate}, want: []string{"c", "a", "b"}},
+		{name: "title", config: models.DownloadConfig{Order: models.OrderTitle}, want: []string{"c", "b", "a"}},
+		{
+			name:   "reversed date",
+			config: models.DownloadConfig{Order: models.OrderDate, Reverse: true},
+			want:   []string{"b", "a", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, video := range sortVideos(videos, tt.config) {
+				got = append(got, video.ID)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sortVideos() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if videos[0].ID != "a" {
+		t.Errorf("sortVideos() modified its input")
+	}
+}
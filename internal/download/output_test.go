@@ -0,0 +1,106 @@
+package download
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+// captureOutput runs fn and returns what it wrote to stdout and stderr.
+func captureOutput(t *testing.T, fn func()) (string, string) {
+	t.Helper()
+
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdoutWriter, stderrWriter
+
+	stdout, stderr := make(chan string), make(chan string)
+
+	for reader, out := range map[*os.File]chan string{stdoutReader: stdout, stderrReader: stderr} {
+		go func() {
+			data, _ := io.ReadAll(reader)
+			out <- string(data)
+		}()
+	}
+
+	fn()
+
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+
+	stdoutWriter.Close()
+	stderrWriter.Close()
+
+	return <-stdout, <-stderr
+}
+
+func TestOutputStreams(t *testing.T) {
+	api := &mockAPI{
+		video: models.Video{
+			ID:          "abc",
+			Title:       "Paging",
+			PublishedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		variants: []models.Variant{{MediaType: "video/mp4", Path: "media/abc.mp4", Size: 10}},
+		data:     "video data",
+	}
+
+	config := models.DownloadConfig{Output: t.TempDir(), OutputTemplate: "{channel}", All: true}
+	downloader := newChannelDownloader(config, (&Client{}).WithAPI(api))
+	channel := models.Channel{ID: "os", Name: "Operating Systems"}
+
+	var err error
+
+	stdout, stderr := captureOutput(t, func() {
+		err = downloader.downloadNewVideos(context.Background(), channel, []models.Video{api.video})
+	})
+	if err != nil {
+		t.Fatalf("downloadNewVideos() error = %v", err)
+	}
+
+	// Status messages and progress go to stderr, results to stdout
+	if !strings.Contains(stderr, "Found 1 new videos") || strings.Contains(stdout, "Found") {
+		t.Errorf("status messages on stdout %q, want them on stderr %q", stdout, stderr)
+	}
+
+	summary := "Download complete!"
+	if !strings.Contains(stdout, summary) || strings.Contains(stderr, summary) {
+		t.Errorf("summary on stderr %q, want it on stdout %q", stderr, stdout)
+	}
+}
+
+func TestProgressOutputStreams(t *testing.T) {
+	api := &mockAPI{
+		video:    models.Video{ID: "abc", Title: "Paging"},
+		variants: []models.Variant{{MediaType: "video/mp4", Path: "media/abc.mp4", Size: 10}},
+		data:     "video data",
+	}
+
+	// JSON progress events are data for scripts and stay on stdout
+	config := models.DownloadConfig{Output: t.TempDir(), ProgressFormat: models.ProgressFormatJSON}
+	downloader := newVideoDownloader(config, models.ProgressInfo{}, (&Client{}).WithAPI(api))
+
+	var err error
+
+	stdout, stderr := captureOutput(t, func() { err = downloader.downloadVideo(context.Background(), "abc", true) })
+	if err != nil {
+		t.Fatalf("downloadVideo() error = %v", err)
+	}
+
+	if !strings.Contains(stdout, `"state":"finished"`) || stderr != "" {
+		t.Errorf("stdout = %q, stderr = %q, want progress events on stdout only", stdout, stderr)
+	}
+}
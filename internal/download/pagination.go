@@ -0,0 +1,122 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// headerLink is the header used for pagination (RFC 8288).
+	headerLink = "Link"
+
+	// maxPages limits the number of pages followed for a single listing, so a
+	// misbehaving server cannot keep us in an endless loop.
+	maxPages = 1000
+)
+
+var (
+	errTooManyPages      = errors.New("too many pages")
+	errNextPageElsewhere = errors.New("next page is on another host")
+)
+
+// fetchAllPages requests fullURL and follows the "next" links of the Link
+// header, collecting the items of all pages.
+func fetchAllPages[T any](ctx context.Context, c *Client, fullURL string) ([]T, error) {
+	var items []T
+
+	visited := make(map[string]bool)
+
+	for next := fullURL; next != ""; {
+		if visited[next] {
+			break
+		}
+
+		if len(visited) >= maxPages {
+			return nil, fmt.Errorf("%w: more than %d", errTooManyPages, maxPages)
+		}
+
+		visited[next] = true
+
+		var page []T
+
+		header, err := c.makeJSONRequestWithHeader(ctx, next, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, page...)
+
+		next, err = nextPageURL(header, next)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// nextPageURL returns the absolute URL of the "next" link in header or an
+// empty string if there is none. Relative links are resolved against current.
+// Since the next page is requested with the access token, links to another
+// scheme or host are rejected.
+func nextPageURL(header http.Header, current string) (string, error) {
+	for _, value := range header.Values(headerLink) {
+		for link := range strings.SplitSeq(value, ",") {
+			target, isNext := parseLink(link)
+			if !isNext {
+				continue
+			}
+
+			base, err := url.Parse(current)
+			if err != nil {
+				return "", fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+			}
+
+			ref, err := url.Parse(target)
+			if err != nil {
+				return "", fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+			}
+
+			next := base.ResolveReference(ref)
+			if next.Scheme != base.Scheme || !strings.EqualFold(next.Host, base.Host) {
+				return "", fmt.Errorf("%w: %s", errNextPageElsewhere, next)
+			}
+
+			return next.String(), nil
+		}
+	}
+
+	return "", nil
+}
+
+// parseLink parses a single link value like `<https://...>; rel="next"` and
+// reports whether it is the link to the next page.
+func parseLink(link string) (string, bool) {
+	parts := strings.Split(link, ";")
+
+	target := strings.TrimSpace(parts[0])
+	if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+		return "", false
+	}
+
+	target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+
+	for _, param := range parts[1:] {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+			continue
+		}
+
+		for rel := range strings.FieldsSeq(strings.Trim(strings.TrimSpace(value), `"`)) {
+			if strings.EqualFold(rel, "next") {
+				return target, true
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,171 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/user"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		links   []string
+		current string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "no link header",
+			links:   nil,
+			current: "https://example.com/videos",
+			want:    "",
+		},
+		{
+			name:    "absolute next link",
+			links:   []string{`<https://example.com/videos?page=2>; rel="next"`},
+			current: "https://example.com/videos",
+			want:    "https://example.com/videos?page=2",
+		},
+		{
+			name:    "relative next link",
+			links:   []string{`</videos?page=3>; rel="next"`},
+			current: "https://example.com/videos?page=2",
+			want:    "https://example.com/videos?page=3",
+		},
+		{
+			name: "multiple links in one header",
+			links: []string{
+				`<https://example.com/videos?page=1>; rel="prev", ` +
+					`<https://example.com/videos?page=3>; rel="next"`,
+			},
+			current: "https://example.com/videos?page=2",
+			want:    "https://example.com/videos?page=3",
+		},
+		{
+			name:    "only last link",
+			links:   []string{`<https://example.com/videos?page=9>; rel="last"`},
+			current: "https://example.com/videos",
+			want:    "",
+		},
+		{
+			name:    "unquoted rel value",
+			links:   []string{`<https://example.com/videos?page=2>; rel=next`},
+			current: "https://example.com/videos",
+			want:    "https://example.com/videos?page=2",
+		},
+		{
+			name:    "malformed link",
+			links:   []string{`https://example.com/videos?page=2; rel="next"`},
+			current: "https://example.com/videos",
+			want:    "",
+		},
+		{
+			name:    "next link to another host",
+			links:   []string{`<https://attacker.example/videos?page=2>; rel="next"`},
+			current: "https://example.com/videos",
+			wantErr: errNextPageElsewhere,
+		},
+		{
+			name:    "next link to another scheme",
+			links:   []string{`<http://example.com/videos?page=2>; rel="next"`},
+			current: "https://example.com/videos",
+			wantErr: errNextPageElsewhere,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, link := range tt.links {
+				header.Add(headerLink, link)
+			}
+
+			got, err := nextPageURL(header, tt.current)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("nextPageURL() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("nextPageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchAllPages(t *testing.T) {
+	keyring.MockInit()
+
+	currentUser, err := user.Current()
+	if err != nil {
+		t.Fatalf("Failed to get current user: %v", err)
+	}
+
+	keyring.Set("SwitchTube", currentUser.Username, "test-token")
+
+	pages := map[string][]models.Video{
+		"1": {{ID: "a"}, {ID: "b"}},
+		"2": {{ID: "c"}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+
+		if page == "1" {
+			w.Header().Set(headerLink, `</videos?page=2>; rel="next"`)
+		}
+
+		json.NewEncoder(w).Encode(pages[page])
+	}))
+	defer server.Close()
+
+	client, err := NewClient(token.NewTokenManager(), models.ClientConfig{})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	videos, err := fetchAllPages[models.Video](context.Background(), client, server.URL+"/videos")
+	if err != nil {
+		t.Fatalf("fetchAllPages() error = %v", err)
+	}
+
+	if len(videos) != 3 || videos[0].ID != "a" || videos[2].ID != "c" {
+		t.Errorf("fetchAllPages() = %v, want videos a, b, c", videos)
+	}
+}
+
+func TestFetchAllPagesElsewhere(t *testing.T) {
+	elsewhere := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("next page on another host requested with Authorization %q",
+			r.Header.Get(headerAuthorization))
+	}))
+	defer elsewhere.Close()
+
+	// The other server differs in its port, and thus in its host
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(headerLink, "<"+elsewhere.URL+`/videos?page=2>; rel="next"`)
+		json.NewEncoder(w).Encode([]models.Video{{ID: "a"}})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(token.NewTokenManagerWithToken("secret"), models.ClientConfig{})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = fetchAllPages[models.Video](context.Background(), client, server.URL+"/videos")
+	if !errors.Is(err, errNextPageElsewhere) {
+		t.Errorf("fetchAllPages() error = %v, want %v", err, errNextPageElsewhere)
+	}
+}
@@ -0,0 +1,181 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"switchtube-downloader/internal/helper/dir"
+	"switchtube-downloader/internal/history"
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// playlistFile is the name of the playlist written to a channel folder.
+	playlistFile = "playlist.m3u8"
+
+	// playlistPermissions are the permissions of the playlist file.
+	playlistPermissions = 0o644
+)
+
+var errFailedToWritePlaylist = errors.New("failed to write playlist")
+
+// playlistExtensions are the extensions a downloaded video may have after
+// post-processing.
+var playlistExtensions = []string{"mp4", "mkv", "webm", "mov", "mp3", "m4a"}
+
+// writePlaylist writes an M3U playlist of all videos of the channel that
+// exist in the channel folder in episode order, if it is enabled.
+func (cd *channelDownloader) writePlaylist(videos []models.Video) {
+	if !cd.config.Playlist {
+		return
+	}
+
+	path := filepath.Join(cd.config.Output, playlistFile)
+
+	if err := writePlaylist(path, videos, cd.config); err != nil {
+		slog.Warn("failed to write playlist", "file", path, "error", err)
+
+		return
+	}
+
+	slog.Info("wrote playlist", "file", path)
+}
+
+// writePlaylist writes the playlist of the videos found in the output folder
+// of config to path. Nothing is written if none of the videos exist.
+func writePlaylist(path string, videos []models.Video, config models.DownloadConfig) error {
+	var playlist strings.Builder
+
+	playlist.WriteString("#EXTM3U\n")
+
+	entries := 0
+	titles := fileTitles(videos, config)
+	recorded := recordedFiles(config)
+
+	for _, video := range episodeOrder(videos) {
+		filename, ok := findVideoFile(video, titles[video.ID], recorded[video.ID], config)
+		if !ok {
+			continue
+		}
+
+		fmt.Fprintf(&playlist, "#EXTINF:%d,%s\n%s\n",
+			int(math.Round(video.Duration)), video.Title, filepath.Base(filename))
+
+		entries++
+	}
+
+	if entries == 0 {
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(playlist.String()), playlistPermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWritePlaylist, err)
+	}
+
+	return nil
+}
+
+// recordedFiles returns the file the download history of config last
+// recorded for each video in the output folder, by id. Videos downloaded
+// elsewhere can't be listed by their name in the playlist and are left out.
+func recordedFiles(config models.DownloadConfig) map[string]string {
+	files := make(map[string]string)
+
+	if config.History == "" {
+		return files
+	}
+
+	entries, err := history.Load(config.History)
+	if err != nil {
+		slog.Warn("failed to load download history for the playlist", "error", err)
+
+		return files
+	}
+
+	folder, err := filepath.Abs(config.Output)
+	if err != nil {
+		return files
+	}
+
+	for _, entry := range entries {
+		if filepath.Dir(entry.Path) == folder {
+			files[entry.ID] = entry.Path
+		}
+	}
+
+	return files
+}
+
+// findVideoFile returns the file video was downloaded to in the output folder
+// of config: the recorded one of the download history if it still exists,
+// since earlier runs may have named it differently, and otherwise the one
+// under title with any of the playlist extensions.
+func findVideoFile(
+	video models.Video,
+	title, recorded string,
+	config models.DownloadConfig,
+) (string, bool) {
+	if recorded != "" {
+		if _, err := os.Stat(recorded); err == nil {
+			return recorded, true
+		}
+	}
+
+	filename := dir.CreateFilename(title, video.ID, "", video.Episode, config)
+	stem := strings.TrimSuffix(filename, filepath.Ext(filename))
+
+	for _, extension := range playlistExtensions {
+		if _, err := os.Stat(stem + "." + extension); err == nil {
+			return stem + "." + extension, true
+		}
+	}
+
+	return "", false
+}
+
+// episodeOrder returns videos sorted by the number of their episode. Videos
+// without an episode number follow in their original order.
+func episodeOrder(videos []models.Video) []models.Video {
+	sorted := slices.Clone(videos)
+
+	slices.SortStableFunc(sorted, func(a, b models.Video) int {
+		numberA, okA := episodeNumber(a.Episode)
+		numberB, okB := episodeNumber(b.Episode)
+
+		switch {
+		case okA && okB:
+			return numberA - numberB
+		case okA:
+			return -1
+		case okB:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return sorted
+}
+
+// episodeNumber returns the first number in episode, e.g. 3 for "E03".
+func episodeNumber(episode string) (int, bool) {
+	digits := strings.TrimLeftFunc(episode, func(r rune) bool { return !unicode.IsDigit(r) })
+	if end := strings.IndexFunc(digits, func(r rune) bool { return !unicode.IsDigit(r) }); end >= 0 {
+		digits = digits[:end]
+	}
+
+	number, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+
+	return number, true
+}
@@ -0,0 +1,126 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"switchtube-downloader/internal/history"
+	"switchtube-downloader/internal/models"
+)
+
+func TestWritePlaylist(t *testing.T) {
+	folder := t.TempDir()
+	config := models.DownloadConfig{Output: folder, UseEpisode: true}
+
+	for _, name := range []string{"2_Paging.mp4", "1_Intro.mkv", "Extra.mp4"} {
+		if err := os.WriteFile(filepath.Join(folder, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	videos := []models.Video{
+		{ID: "v3", Title: "Extra", Episode: "", Duration: 30},
+		{ID: "v2", Title: "Paging", Episode: "2", Duration: 61.6},
+		{ID: "v4", Title: "Missing", Episode: "3", Duration: 10},
+		{ID: "v1", Title: "Intro", Episode: "1", Duration: 90},
+	}
+
+	path := filepath.Join(folder, playlistFile)
+	if err := writePlaylist(path, videos, config); err != nil {
+		t.Fatalf("writePlaylist() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "#EXTM3U\n" +
+		"#EXTINF:90,Intro\n1_Intro.mkv\n" +
+		"#EXTINF:62,Paging\n2_Paging.mp4\n" +
+		"#EXTINF:30,Extra\nExtra.mp4\n"
+	if string(data) != want {
+		t.Errorf("playlist = %q, want %q", data, want)
+	}
+}
+
+func TestWritePlaylistFromHistory(t *testing.T) {
+	folder := t.TempDir()
+	historyPath := filepath.Join(t.TempDir(), "history.jsonl")
+	config := models.DownloadConfig{Output: folder, History: historyPath}
+
+	// The file was named with the episode prefix by an earlier run
+	renamed := filepath.Join(folder, "1_Intro.mp4")
+	moved := filepath.Join(t.TempDir(), "Paging.mp4")
+
+	for _, name := range []string{renamed, moved} {
+		if err := os.WriteFile(name, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, entry := range []models.HistoryEntry{
+		{ID: "v1", Title: "Intro", Path: renamed},
+		{ID: "v2", Title: "Paging", Path: moved},
+	} {
+		if err := history.Add(historyPath, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	videos := []models.Video{
+		{ID: "v1", Title: "Intro", Episode: "1", Duration: 90},
+		{ID: "v2", Title: "Paging", Episode: "2", Duration: 60},
+	}
+
+	path := filepath.Join(folder, playlistFile)
+	if err := writePlaylist(path, videos, config); err != nil {
+		t.Fatalf("writePlaylist() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "#EXTM3U\n#EXTINF:90,Intro\n1_Intro.mp4\n"; string(data) != want {
+		t.Errorf("playlist = %q, want %q", data, want)
+	}
+}
+
+func TestWritePlaylistWithoutVideos(t *testing.T) {
+	folder := t.TempDir()
+	path := filepath.Join(folder, playlistFile)
+	videos := []models.Video{{ID: "v1", Title: "Intro"}}
+
+	if err := writePlaylist(path, videos, models.DownloadConfig{Output: folder}); err != nil {
+		t.Fatalf("writePlaylist() error = %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("playlist was written without videos")
+	}
+}
+
+func TestEpisodeNumber(t *testing.T) {
+	tests := []struct {
+		episode string
+		want    int
+		wantOK  bool
+	}{
+		{episode: "3", want: 3, wantOK: true},
+		{episode: "E03", want: 3, wantOK: true},
+		{episode: "12b", want: 12, wantOK: true},
+		{episode: "", want: 0, wantOK: false},
+		{episode: "Intro", want: 0, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := episodeNumber(tt.episode)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("episodeNumber(%q) = %d, %v, want %d, %v",
+				tt.episode, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
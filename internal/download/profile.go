@@ -0,0 +1,140 @@
+package download
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+
+	"switchtube-downloader/internal/models"
+)
+
+// profileMetadata represents profile metadata.
+type profileMetadata struct {
+	Name string `json:"name"`
+}
+
+var (
+	errFailedToDecodeProfileMeta    = errors.New("failed to decode profile metadata")
+	errFailedToDecodeProfileChannel = errors.New("failed to decode profile channels")
+	errFailedToGetProfileChannels   = errors.New("failed to get profile channels")
+	errFailedToGetProfileInfo       = errors.New("failed to get profile information")
+)
+
+// profileDownloader handles the downloading of profiles, i.e. all channels
+// belonging to a profile.
+type profileDownloader struct {
+	config models.DownloadConfig
+	client *Client
+}
+
+// newProfileDownloader creates a new instance of profileDownloader.
+func newProfileDownloader(config models.DownloadConfig, client *Client) *profileDownloader {
+	return &profileDownloader{
+		config: config,
+		client: client,
+	}
+}
+
+// downloadProfile downloads the channels of a profile, each into the folder
+// given by the output template, by default nested inside the profile folder.
+func (pd *profileDownloader) downloadProfile(ctx context.Context, profileID string) error {
+	if pd.config.Output == models.OutputStdout {
+		return errStdoutVideoOnly
+	}
+
+	profileInfo, err := pd.getMetadata(ctx, profileID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToGetProfileInfo, err)
+	}
+
+	channels, err := pd.getChannels(ctx, profileID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToGetProfileChannels, err)
+	}
+
+	if len(channels) == 0 {
+		fmt.Fprintln(os.Stderr, "No channels found in this profile")
+
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Found %d channels in profile: %s\n", len(channels), profileInfo.Name)
+
+	var failed []string
+
+	var firstErr error
+
+	for i, channel := range channels {
+		fmt.Fprintf(os.Stderr, "\n[%d/%d] Channel: %s\n", i+1, len(channels), channel.Name)
+
+		downloader := newChannelDownloader(pd.config, pd.client)
+		downloader.profile = profileInfo.Name
+
+		if err := downloader.downloadChannel(ctx, channel.ID); err != nil {
+			slog.Error("failed to download channel", "channel", channel.Name, "error", err)
+			failed = append(failed, channel.Name)
+			firstErr = cmp.Or(firstErr, err)
+		}
+	}
+
+	// The summaries of the channels were handed to OnSummary already
+	if pd.config.OnSummary != nil {
+		return partialFailure(len(failed), len(channels), firstErr)
+	}
+
+	if pd.config.JSON {
+		printJSON(models.ProfileSummary{
+			Profile:  profileInfo.Name,
+			Channels: len(channels),
+			Failed:   append([]string{}, failed...),
+		})
+	} else if len(failed) > 0 {
+		fmt.Println("\nFailed channels:")
+
+		for _, name := range failed {
+			fmt.Printf("  - %s\n", name)
+		}
+	}
+
+	return partialFailure(len(failed), len(channels), firstErr)
+}
+
+// getMetadata retrieves profile metadata from the API.
+func (pd *profileDownloader) getMetadata(
+	ctx context.Context,
+	profileID string,
+) (*profileMetadata, error) {
+	fullURL, err := url.JoinPath(pd.client.baseURL(), profileAPI, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	var data profileMetadata
+	if err := pd.client.makeJSONRequest(ctx, fullURL, &data); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToDecodeProfileMeta, err)
+	}
+
+	return &data, nil
+}
+
+// getChannels retrieves all channels of a profile, following pagination.
+func (pd *profileDownloader) getChannels(
+	ctx context.Context,
+	profileID string,
+) ([]models.Channel, error) {
+	fullURL, err := url.JoinPath(pd.client.baseURL(), profileAPI, profileID, "channels")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	channels, err := fetchAllPages[models.Channel](ctx, pd.client, fullURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToDecodeProfileChannel, err)
+	}
+
+	return channels, nil
+}
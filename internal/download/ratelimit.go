@@ -0,0 +1,75 @@
+package download
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket limiting API requests to rate per second,
+// allowing bursts of up to burst requests after being idle. A nil limiter
+// doesn't limit anything.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// now and sleep are replaced by tests.
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+// newRateLimiter returns a limiter for rate requests per second with a burst
+// of one second worth of requests, or nil if rate isn't positive.
+func newRateLimiter(rate float64) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	burst := math.Max(1, math.Ceil(rate))
+
+	return &rateLimiter{
+		mu:     sync.Mutex{},
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+// wait blocks until a request may be made, or until ctx is done, in which
+// case it returns the error of ctx. Concurrent callers reserve their turns,
+// so they are spread out evenly instead of all retrying at once.
+func (rl *rateLimiter) wait(ctx context.Context) error {
+	if rl == nil {
+		return nil
+	}
+
+	rl.mu.Lock()
+
+	now := rl.now()
+	rl.tokens = math.Min(rl.burst, rl.tokens+now.Sub(rl.last).Seconds()*rl.rate)
+	rl.last = now
+	rl.tokens--
+
+	var delay time.Duration
+	if rl.tokens < 0 {
+		delay = time.Duration(-rl.tokens / rl.rate * float64(time.Second))
+	}
+
+	rl.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	slog.Debug("waiting for the rate limit", "delay", delay)
+
+	return rl.sleep(ctx, delay)
+}
@@ -0,0 +1,71 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+
+	var delays []time.Duration
+
+	limiter := newRateLimiter(2)
+	limiter.last = now
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(_ context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+
+		return nil
+	}
+
+	// The burst of two requests passes, the next ones are spread out
+	for range 4 {
+		limiter.wait(context.Background())
+	}
+
+	want := []time.Duration{500 * time.Millisecond, time.Second}
+	if len(delays) != len(want) || delays[0] != want[0] || delays[1] != want[1] {
+		t.Errorf("delays = %v, want %v", delays, want)
+	}
+
+	// Being idle refills the bucket up to the burst
+	delays = nil
+	now = now.Add(time.Hour)
+
+	for range 2 {
+		limiter.wait(context.Background())
+	}
+
+	if len(delays) != 0 {
+		t.Errorf("delays after idling = %v, want none", delays)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	if limiter := newRateLimiter(0); limiter != nil {
+		t.Fatalf("newRateLimiter(0) = %+v, want nil", limiter)
+	}
+
+	var limiter *rateLimiter
+
+	limiter.wait(context.Background())
+}
+
+func TestRateLimiterCancelled(t *testing.T) {
+	limiter := newRateLimiter(0.001)
+
+	if err := limiter.wait(context.Background()); err != nil {
+		t.Fatalf("wait() within the burst error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The next request would have to wait for over 15 minutes
+	if err := limiter.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait() error = %v, want context.Canceled", err)
+	}
+}
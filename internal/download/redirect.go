@@ -0,0 +1,77 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// maxRedirects is the number of redirects a request follows, like the
+// default policy of http.Client.
+const maxRedirects = 10
+
+var errTooManyRedirects = errors.New("too many redirects")
+
+// newCheckRedirect returns the redirect policy of the client. The access
+// token is only sent to the host of the original request and to the allowed
+// hosts, e.g. a CDN the video variants redirect to, and never after a
+// downgrade from HTTPS to plain HTTP. All other redirects have their
+// Authorization header stripped.
+func newCheckRedirect(allowed []string) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("%w: stopped after %d", errTooManyRedirects, maxRedirects)
+		}
+
+		original := via[0]
+
+		auth := original.Header.Get(headerAuthorization)
+		if auth == "" {
+			return nil
+		}
+
+		if !forwardAuth(req, original, allowed) {
+			slog.Debug("stripping authorization from redirect",
+				"from", original.URL.Redacted(),
+				"to", req.URL.Redacted())
+			req.Header.Del(headerAuthorization)
+
+			return nil
+		}
+
+		// http.Client drops the header on redirects to other hosts by itself
+		req.Header.Set(headerAuthorization, auth)
+
+		return nil
+	}
+}
+
+// forwardAuth reports whether the access token of the original request may
+// be sent along with the redirected request req.
+func forwardAuth(req, original *http.Request, allowed []string) bool {
+	if original.URL.Scheme == "https" && req.URL.Scheme != "https" {
+		return false
+	}
+
+	host := req.URL.Hostname()
+
+	return sameHost(host, original.URL.Hostname()) || allowedHost(host, allowed)
+}
+
+// allowedHost reports whether host is one of the allowed hosts or a subdomain
+// of one.
+func allowedHost(host string, allowed []string) bool {
+	return slices.ContainsFunc(allowed, func(entry string) bool {
+		entry = strings.TrimPrefix(entry, ".")
+
+		return sameHost(host, entry) || strings.HasSuffix(strings.ToLower(host), "."+strings.ToLower(entry))
+	})
+}
+
+// sameHost reports whether the host names a and b are equal.
+func sameHost(a, b string) bool {
+	return a != "" && strings.EqualFold(a, b)
+}
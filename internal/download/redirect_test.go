@@ -0,0 +1,122 @@
+package download
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCheckRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		allowed  []string
+		wantAuth bool
+	}{
+		{name: "same host", from: "https://tube.switch.ch/api", to: "https://tube.switch.ch/media", wantAuth: true},
+		{name: "same host other port", from: "https://tube.switch.ch", to: "https://TUBE.switch.ch:8443", wantAuth: true},
+		{name: "other host", from: "https://tube.switch.ch", to: "https://cdn.example.com"},
+		{name: "subdomain", from: "https://tube.switch.ch", to: "https://cdn.tube.switch.ch"},
+		{
+			name:     "allowed host",
+			from:     "https://tube.switch.ch",
+			to:       "https://cdn.example.com",
+			allowed:  []string{"example.org", "cdn.example.com"},
+			wantAuth: true,
+		},
+		{
+			name:     "subdomain of allowed host",
+			from:     "https://tube.switch.ch",
+			to:       "https://eu.cdn.example.com",
+			allowed:  []string{".cdn.example.com"},
+			wantAuth: true,
+		},
+		{
+			name:    "suffix of allowed host",
+			from:    "https://tube.switch.ch",
+			to:      "https://evilexample.com",
+			allowed: []string{"example.com"},
+		},
+		{
+			name:    "downgrade to http",
+			from:    "https://tube.switch.ch",
+			to:      "http://tube.switch.ch",
+			allowed: []string{"tube.switch.ch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, _ := http.NewRequest(http.MethodGet, tt.from, nil)
+			original.Header.Set(headerAuthorization, "Token secret-token")
+
+			req, _ := http.NewRequest(http.MethodGet, tt.to, nil)
+			req.Header.Set(headerAuthorization, "Token secret-token")
+
+			if err := newCheckRedirect(tt.allowed)(req, []*http.Request{original}); err != nil {
+				t.Fatalf("CheckRedirect() error = %v", err)
+			}
+
+			if got := req.Header.Get(headerAuthorization) != ""; got != tt.wantAuth {
+				t.Errorf("authorization forwarded = %v, want %v", got, tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestCheckRedirectLimit(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://tube.switch.ch", nil)
+
+	via := make([]*http.Request, maxRedirects)
+	for i := range via {
+		via[i] = req
+	}
+
+	if err := newCheckRedirect(nil)(req, via); !errors.Is(err, errTooManyRedirects) {
+		t.Errorf("CheckRedirect() error = %v, want %v", err, errTooManyRedirects)
+	}
+}
+
+func TestClientRedirect(t *testing.T) {
+	var received string
+
+	cdn := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(headerAuthorization)
+	}))
+	defer cdn.Close()
+
+	// The CDN is reached through another host name than the API
+	cdnURL, _ := url.Parse(cdn.URL)
+	cdnURL.Host = strings.Replace(cdnURL.Host, "127.0.0.1", "localhost", 1)
+
+	api := httptest.NewServer(http.RedirectHandler(cdnURL.String(), http.StatusFound))
+	defer api.Close()
+
+	for _, tt := range []struct {
+		allowed []string
+		want    string
+	}{
+		{allowed: nil, want: ""},
+		{allowed: []string{"localhost"}, want: "Token secret-token"},
+	} {
+		received = "unset"
+		client := &http.Client{CheckRedirect: newCheckRedirect(tt.allowed)}
+
+		req, _ := http.NewRequest(http.MethodGet, api.URL, nil)
+		req.Header.Set(headerAuthorization, "Token secret-token")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		resp.Body.Close()
+
+		if received != tt.want {
+			t.Errorf("allowed %v: CDN received authorization %q, want %q", tt.allowed, received, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// headerRetryAfter is the header of rate-limited responses telling how
+	// long to wait before retrying (RFC 9110).
+	headerRetryAfter = "Retry-After"
+
+	// maxRetries is the number of times a rate-limited request is retried.
+	maxRetries = 5
+
+	// baseRetryDelay is the first delay if the server doesn't send a
+	// Retry-After header. It doubles with every retry.
+	baseRetryDelay = time.Second
+
+	// maxRetryDelay caps the delay, so a bogus Retry-After header cannot keep
+	// us waiting for hours.
+	maxRetryDelay = 5 * time.Minute
+)
+
+// sleep waits for the given duration like sleepContext. It is a variable so
+// tests don't have to actually wait.
+var sleep = sleepContext
+
+// sleepContext waits for the given duration, or until ctx is done, in which
+// case it returns the error of ctx.
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// withRetry calls send until the response is neither 429 Too Many Requests nor
+// 503 Service Unavailable, waiting as requested by the Retry-After header in
+// between unless ctx is done. After maxRetries the last response is returned
+// as is.
+func withRetry(ctx context.Context, send func() (*http.Response, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := send()
+		if err != nil || !isRetryable(resp.StatusCode) || attempt >= maxRetries {
+			return resp, err
+		}
+
+		delay := retryDelay(resp.Header.Get(headerRetryAfter), attempt, time.Now())
+
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("failed to close response body", "error", err)
+		}
+
+		slog.Warn("server is busy, retrying",
+			"status", resp.StatusCode,
+			"delay", delay,
+			"attempt", attempt+1,
+			"maxRetries", maxRetries)
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isRetryable reports whether a response with the given status code should be
+// retried.
+func isRetryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests ||
+		statusCode == http.StatusServiceUnavailable
+}
+
+// retryDelay returns the delay before the next attempt. The Retry-After value
+// is either a number of seconds or an HTTP date. Without a valid one, the delay
+// grows exponentially with the attempt.
+func retryDelay(retryAfter string, attempt int, now time.Time) time.Duration {
+	delay := baseRetryDelay << attempt
+
+	retryAfter = strings.TrimSpace(retryAfter)
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		// Capped first, since huge values overflow the duration
+		delay = time.Duration(min(seconds, int(maxRetryDelay/time.Second))) * time.Second
+	} else if date, err := http.ParseTime(retryAfter); err == nil {
+		delay = max(date.Sub(now), 0)
+	}
+
+	return min(delay, maxRetryDelay)
+}
@@ -0,0 +1,183 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempt    int
+		want       time.Duration
+	}{
+		{
+			name:       "seconds",
+			retryAfter: "120",
+			attempt:    0,
+			want:       2 * time.Minute,
+		},
+		{
+			name:       "http date",
+			retryAfter: now.Add(30 * time.Second).Format(http.TimeFormat),
+			attempt:    0,
+			want:       30 * time.Second,
+		},
+		{
+			name:       "http date in the past",
+			retryAfter: now.Add(-time.Minute).Format(http.TimeFormat),
+			attempt:    0,
+			want:       0,
+		},
+		{
+			name:       "missing header backs off exponentially",
+			retryAfter: "",
+			attempt:    3,
+			want:       8 * time.Second,
+		},
+		{
+			name:       "invalid header",
+			retryAfter: "soon",
+			attempt:    0,
+			want:       time.Second,
+		},
+		{
+			name:       "capped",
+			retryAfter: "86400",
+			attempt:    0,
+			want:       maxRetryDelay,
+		},
+		{
+			name:       "overflowing duration",
+			retryAfter: "9223372036854775807",
+			attempt:    0,
+			want:       maxRetryDelay,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryDelay(tt.retryAfter, tt.attempt, now); got != tt.want {
+				t.Errorf("retryDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	var delays []time.Duration
+
+	sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+
+		return nil
+	}
+	defer func() { sleep = sleepContext }()
+
+	tests := []struct {
+		name       string
+		statuses   []int
+		wantStatus int
+		wantCalls  int
+	}{
+		{
+			name:       "success without retry",
+			statuses:   []int{http.StatusOK},
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name:       "rate limited then success",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus: http.StatusOK,
+			wantCalls:  3,
+		},
+		{
+			name:       "other errors are not retried",
+			statuses:   []int{http.StatusNotFound},
+			wantStatus: http.StatusNotFound,
+			wantCalls:  1,
+		},
+		{
+			name: "gives up after max retries",
+			statuses: []int{
+				http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests,
+				http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests,
+				http.StatusOK,
+			},
+			wantStatus: http.StatusTooManyRequests,
+			wantCalls:  maxRetries + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays = nil
+			calls := 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set(headerRetryAfter, "7")
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			defer server.Close()
+
+			resp, err := withRetry(context.Background(), func() (*http.Response, error) {
+				return http.Get(server.URL)
+			})
+			if err != nil {
+				t.Fatalf("withRetry() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("withRetry() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("withRetry() made %d requests, want %d", calls, tt.wantCalls)
+			}
+
+			for _, delay := range delays {
+				if delay != 7*time.Second {
+					t.Errorf("withRetry() waited %v, want %v", delay, 7*time.Second)
+				}
+			}
+		})
+	}
+}
+
+func TestWithRetryCancelled(t *testing.T) {
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(headerRetryAfter, "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		calls++
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+
+	_, err := withRetry(ctx, func() (*http.Response, error) {
+		return http.Get(server.URL)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("withRetry() error = %v, want context.Canceled", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second || calls != 1 {
+		t.Errorf("withRetry() returned after %v and %d requests, want right after the first",
+			elapsed, calls)
+	}
+}
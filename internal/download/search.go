@@ -0,0 +1,57 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"switchtube-downloader/internal/models"
+)
+
+var (
+	errEmptySearchQuery     = errors.New("search query cannot be empty")
+	errFailedToDecodeSearch = errors.New("failed to decode search results")
+)
+
+// Search queries the SwitchTube search API for videos and channels.
+func Search(ctx context.Context, client *Client, query string) (*models.SearchResult, error) {
+	if query == "" {
+		return nil, errEmptySearchQuery
+	}
+
+	fullURL, err := searchURL(client.baseURL(), query)
+	if err != nil {
+		return nil, err
+	}
+
+	var result models.SearchResult
+	if err := client.makeJSONRequest(ctx, fullURL, &result); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToDecodeSearch, err)
+	}
+
+	return &result, nil
+}
+
+// VideoURL returns the URL of the video with the given id on the SwitchTube
+// instance of the client.
+func (c *Client) VideoURL(id string) string {
+	return strings.TrimSuffix(c.baseURL(), "/") + "/" + videoPrefix + id
+}
+
+// ChannelURL returns the URL of the channel with the given id on the
+// SwitchTube instance of the client.
+func (c *Client) ChannelURL(id string) string {
+	return strings.TrimSuffix(c.baseURL(), "/") + "/" + channelPrefix + id
+}
+
+// searchURL builds the search API URL of the instance at base for query.
+func searchURL(base, query string) (string, error) {
+	fullURL, err := url.JoinPath(base, searchAPI)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	return fullURL + "?" + url.Values{"q": {query}}.Encode(), nil
+}
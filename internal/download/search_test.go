@@ -0,0 +1,55 @@
+package download
+
+import "testing"
+
+func TestSearchURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "single word",
+			query: "networks",
+			want:  defaultBaseURL + searchAPI + "?q=networks",
+		},
+		{
+			name:  "query with spaces and special characters",
+			query: "operating systems & more",
+			want:  defaultBaseURL + searchAPI + "?q=operating+systems+%26+more",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := searchURL(defaultBaseURL, tt.query)
+			if err != nil {
+				t.Fatalf("searchURL() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("searchURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediaURLs(t *testing.T) {
+	for _, base := range []string{"", "https://test.tube.switch.ch", "http://localhost:8080/tube/"} {
+		client := &Client{base: base}
+
+		for _, tt := range []struct {
+			url      string
+			wantID   string
+			wantType mediaType
+		}{
+			{url: client.VideoURL("abc"), wantID: "abc", wantType: videoType},
+			{url: client.ChannelURL("xyz"), wantID: "xyz", wantType: channelType},
+		} {
+			id, downloadType, err := extractIDAndType(tt.url, base)
+			if err != nil || id != tt.wantID || downloadType != tt.wantType {
+				t.Errorf("extractIDAndType(%q, %q) = %q, %v, %v", tt.url, base, id, downloadType, err)
+			}
+		}
+	}
+}
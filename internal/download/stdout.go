@@ -0,0 +1,46 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"switchtube-downloader/internal/models"
+)
+
+var errStdoutVideoOnly = errors.New("only a single video can be written to stdout")
+
+// downloadToStdout streams the media of video at endpoint to standard output.
+// The stream can't be resumed or post-processed and isn't recorded in the
+// download history since no file is written.
+func (vd *videoDownloader) downloadToStdout(
+	ctx context.Context,
+	video *models.Video,
+	endpoint string,
+) error {
+	slog.Info("streaming video to stdout", "id", video.ID, "title", video.Title)
+
+	resp, err := vd.api.Stream(ctx, endpoint, 0)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToFetchVideoStream, err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %w", errFailedToDownloadVideo, statusError(resp.StatusCode))
+	}
+
+	if err := vd.copyWithProgress(video.ID, resp, os.Stdout, video.Title); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToDownloadVideo, err)
+	}
+
+	return nil
+}
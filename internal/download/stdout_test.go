@@ -0,0 +1,42 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestDownloadToStdout(t *testing.T) {
+	api := &mockAPI{
+		video:    models.Video{ID: "abc", Title: "Paging"},
+		variants: []models.Variant{{MediaType: "video/mp4", Path: "media/abc.mp4", Size: 10}},
+		data:     "video data",
+	}
+
+	t.Chdir(t.TempDir())
+
+	config := models.DownloadConfig{Output: models.OutputStdout, Reporter: discardProgress{}}
+	downloader := newVideoDownloader(config, models.ProgressInfo{}, (&Client{}).WithAPI(api))
+
+	var err error
+
+	stdout, _ := captureOutput(t, func() { err = downloader.downloadVideo(context.Background(), "abc", true) })
+	if err != nil {
+		t.Fatalf("downloadVideo() error = %v", err)
+	}
+
+	if stdout != api.data {
+		t.Errorf("stdout = %q, want only the video data %q", stdout, api.data)
+	}
+}
+
+func TestDownloadChannelToStdout(t *testing.T) {
+	config := models.DownloadConfig{Output: models.OutputStdout, All: true}
+	downloader := newChannelDownloader(config, (&Client{}).WithAPI(&mockAPI{}))
+
+	if err := downloader.downloadChannel(context.Background(), "os"); !errors.Is(err, errStdoutVideoOnly) {
+		t.Errorf("downloadChannel() error = %v, want %v", err, errStdoutVideoOnly)
+	}
+}
@@ -0,0 +1,101 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+var errStreamStalled = errors.New("stream stalled")
+
+// stallReader cancels a streaming request once no data was received for the
+// read timeout. It replaces an overall timeout, which would abort the download
+// of every video taking longer than that.
+type stallReader struct {
+	body    io.ReadCloser
+	timer   *time.Timer
+	timeout time.Duration
+	cancel  context.CancelFunc
+	stalled *atomic.Bool
+}
+
+// makeStreamRequest makes an authenticated HTTP GET request for a video stream
+// with the additional request header, which may be nil. The request is
+// cancelled if the server doesn't send any data for the read timeout, both
+// while waiting for the response and while reading the body. Rate-limited
+// requests are retried.
+func (c *Client) makeStreamRequest(
+	ctx context.Context,
+	url string,
+	header http.Header,
+) (*http.Response, error) {
+	return withRetry(ctx, func() (*http.Response, error) {
+		return c.makeStreamAttempt(ctx, url, header)
+	})
+}
+
+// makeStreamAttempt makes a single attempt of makeStreamRequest.
+func (c *Client) makeStreamAttempt(
+	ctx context.Context,
+	url string,
+	header http.Header,
+) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	stalled := new(atomic.Bool)
+	timer := time.AfterFunc(c.readTimeout, func() {
+		stalled.Store(true)
+		cancel()
+	})
+
+	resp, err := c.send(ctx, c.client, http.MethodGet, url, header)
+	if err != nil {
+		timer.Stop()
+		cancel()
+
+		if stalled.Load() {
+			return nil, fmt.Errorf("%w: no response for %s", errStreamStalled, c.readTimeout)
+		}
+
+		return nil, err
+	}
+
+	timer.Reset(c.readTimeout)
+
+	resp.Body = &stallReader{
+		body:    resp.Body,
+		timer:   timer,
+		timeout: c.readTimeout,
+		cancel:  cancel,
+		stalled: stalled,
+	}
+
+	return resp, nil
+}
+
+// Read reads from the response body and resets the stall timer whenever data
+// was received.
+func (r *stallReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+
+	if err != nil && !errors.Is(err, io.EOF) && r.stalled.Load() {
+		return n, fmt.Errorf("%w: no data received for %s", errStreamStalled, r.timeout)
+	}
+
+	return n, err //nolint:wrapcheck // io.Reader must return io.EOF unwrapped.
+}
+
+// Close stops the stall timer and closes the response body.
+func (r *stallReader) Close() error {
+	r.timer.Stop()
+	defer r.cancel()
+
+	return r.body.Close() //nolint:wrapcheck // Implements io.Closer.
+}
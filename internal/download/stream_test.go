@@ -0,0 +1,90 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/user"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestMakeStreamRequest(t *testing.T) {
+	keyring.MockInit()
+
+	currentUser, err := user.Current()
+	if err != nil {
+		t.Fatalf("Failed to get current user: %v", err)
+	}
+
+	keyring.Set("SwitchTube", currentUser.Username, "test-token")
+
+	const readTimeout = 100 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		chunks  int
+		delay   time.Duration
+		wantErr error
+	}{
+		{
+			// Takes longer than the read timeout in total but never stalls
+			name:    "slow but steady stream",
+			chunks:  5,
+			delay:   readTimeout / 2,
+			wantErr: nil,
+		},
+		{
+			name:    "stalled stream",
+			chunks:  2,
+			delay:   3 * readTimeout,
+			wantErr: errStreamStalled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for range tt.chunks {
+					w.Write([]byte("chunk"))
+					w.(http.Flusher).Flush()
+
+					select {
+					case <-r.Context().Done():
+						return
+					case <-time.After(tt.delay):
+					}
+				}
+			}))
+			defer server.Close()
+
+			client, err := NewClient(token.NewTokenManager(), models.ClientConfig{
+				APITimeout:  readTimeout,
+				ReadTimeout: readTimeout,
+			})
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			resp, err := client.makeStreamRequest(context.Background(), server.URL, nil)
+			if err != nil {
+				t.Fatalf("makeStreamRequest() error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			_, err = io.Copy(io.Discard, resp.Body)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Copy() error = %v, want nil", err)
+			} else if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Copy() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
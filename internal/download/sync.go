@@ -0,0 +1,277 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// syncStateFile is the name of the state file stored in the channel folder.
+	syncStateFile = ".switchtube-sync.json"
+
+	// stateFilePermissions restricts the state file to the current user.
+	stateFilePermissions = 0o600
+)
+
+var (
+	errChannelRequired       = errors.New("a channel id or url is required")
+	errFailedToEncodeState   = errors.New("failed to encode sync state")
+	errFailedToLoadSyncState = errors.New("failed to load sync state")
+	errFailedToSaveSyncState = errors.New("failed to save sync state")
+	errFailedToSyncChannel   = errors.New("failed to sync channel")
+)
+
+// syncState records which videos of a channel have already been synced, and
+// the most recently published video up to which all videos have been
+// downloaded, which --new-only continues from.
+type syncState struct {
+	Videos []string `json:"videos"`
+
+	LastPublishedAt time.Time `json:"lastPublishedAt,omitzero"`
+	LastVideo       string    `json:"lastVideo,omitempty"`
+
+	synced map[string]bool
+}
+
+// Sync downloads all videos of a channel that have not been synced before.
+// It never prompts, which makes it suitable for unattended runs, and is
+// aborted after the run timeout of config, if any. Like Download, it locks the
+// channel folder.
+func Sync(ctx context.Context, client *Client, config models.DownloadConfig) error {
+	ctx, cancel := WithRunTimeout(ctx, config)
+	defer cancel()
+
+	return runTimeoutError(ctx, config, syncMedia(ctx, client, config))
+}
+
+// syncMedia implements Sync.
+func syncMedia(ctx context.Context, client *Client, config models.DownloadConfig) error {
+	id, downloadType, err := extractIDAndType(config.Media, client.baseURL())
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToExtractType, err)
+	}
+
+	if downloadType == videoType || downloadType == profileType {
+		return errChannelRequired
+	}
+
+	// Existing files are treated as synced instead of prompting for them
+	config.All = true
+	config.Skip = true
+	config.Force = false
+
+	downloader := newChannelDownloader(config, client)
+	if err := downloader.syncChannel(ctx, id); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToSyncChannel, err)
+	}
+
+	return nil
+}
+
+// syncChannel downloads the videos of a channel that are not yet recorded in
+// the sync state of the channel folder.
+func (cd *channelDownloader) syncChannel(ctx context.Context, channelID string) error {
+	start := time.Now()
+
+	channelInfo, err := cd.getMetadata(ctx, channelID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToGetChannelInfo, err)
+	}
+
+	videos, err := cd.api.GetChannelVideos(ctx, channelID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToGetChannelVideos, err)
+	}
+
+	videos = sortVideos(videos, cd.config)
+
+	folderName, unlock, err := cd.createFolder(channelID, channelInfo.Name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cd.config.Output = folderName
+	statePath := filepath.Join(folderName, syncStateFile)
+
+	state, err := loadSyncState(statePath)
+	if err != nil {
+		return err
+	}
+
+	pending := cd.pendingVideos(state, videos)
+	slog.Info("loaded sync state",
+		"file", statePath,
+		"synced", len(state.Videos),
+		"pending", len(pending))
+
+	if len(pending) == 0 {
+		fmt.Fprintf(os.Stderr, "Channel %s is up to date\n", channelInfo.Name)
+
+		return nil
+	}
+
+	if cd.config.IfChanged {
+		fmt.Fprintf(os.Stderr, "Checking %d videos for changes in channel: %s\n",
+			len(pending), channelInfo.Name)
+	} else {
+		fmt.Fprintf(os.Stderr, "Found %d new videos in channel: %s\n", len(pending), channelInfo.Name)
+	}
+
+	cd.results = nil
+
+	failed := cd.downloadVideos(ctx, channelInfo.Name, videos, pending)
+
+	state.markSynced(cd.results)
+	state.advance(videos, failed)
+
+	if err := state.save(statePath); err != nil {
+		return err
+	}
+
+	channel := models.Channel{ID: channelID, Name: channelInfo.Name}
+	summary := cd.summary(channelInfo.Name, len(pending), failed)
+	cd.finishRun(channel, videos, summary, start)
+
+	return partialFailure(len(failed), len(pending), cd.firstErr)
+}
+
+// loadSyncState reads the sync state from path. A missing file results in an
+// empty state.
+func loadSyncState(path string) (*syncState, error) {
+	state := &syncState{
+		Videos:          nil,
+		LastPublishedAt: time.Time{},
+		LastVideo:       "",
+		synced:          make(map[string]bool),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToLoadSyncState, err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToLoadSyncState, err)
+	}
+
+	for _, id := range state.Videos {
+		state.synced[id] = true
+	}
+
+	return state, nil
+}
+
+// save writes the sync state to path.
+func (s *syncState) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToEncodeState, err)
+	}
+
+	if err := os.WriteFile(path, data, stateFilePermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToSaveSyncState, err)
+	}
+
+	return nil
+}
+
+// pending returns the indices of all videos that have not been synced yet.
+func (s *syncState) pending(videos []models.Video) []int {
+	var indices []int
+
+	for i, video := range videos {
+		if !s.synced[video.ID] {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// pendingVideos returns the indices of the videos that have to be synced.
+// With IfChanged, synced videos are checked for changes as well.
+func (cd *channelDownloader) pendingVideos(state *syncState, videos []models.Video) []int {
+	if !cd.config.IfChanged {
+		return state.pending(videos)
+	}
+
+	indices := make([]int, len(videos))
+	for i := range videos {
+		indices[i] = i
+	}
+
+	return indices
+}
+
+// markSynced records the videos of results that were downloaded or whose file
+// already exists as synced. Videos skipped because of the size limits or the
+// --exec-before command aren't, so that a later run without them downloads
+// them.
+func (s *syncState) markSynced(results []models.VideoResult) {
+	for _, result := range results {
+		finished := result.Status == models.StatusDownloaded ||
+			result.Status == models.StatusSkipped && result.Exists
+		if !finished || s.synced[result.ID] {
+			continue
+		}
+
+		s.Videos = append(s.Videos, result.ID)
+		s.synced[result.ID] = true
+	}
+}
+
+// newSince returns the indices of the videos that were published after the
+// last video recorded in the state, or of all videos if there is none. Videos
+// published at the same time as the last video are new unless they have been
+// synced.
+func (s *syncState) newSince(videos []models.Video) []int {
+	var indices []int
+
+	for i, video := range videos {
+		if video.PublishedAt.After(s.LastPublishedAt) ||
+			video.PublishedAt.Equal(s.LastPublishedAt) && !s.synced[video.ID] {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// advance records the most recently published video of the channel that was
+// published before its oldest unfinished video, i.e. one that isn't synced or
+// failed in this run, so that the next run with --new-only downloads the
+// unfinished video again. An unfinished video published at the same time as
+// the recorded one stops it as well, since newSince still finds it. It has to
+// be called after markSynced.
+func (s *syncState) advance(videos []models.Video, failed []models.Video) {
+	published := slices.Clone(videos)
+	slices.SortStableFunc(published, func(a, b models.Video) int {
+		return a.PublishedAt.Compare(b.PublishedAt)
+	})
+
+	for _, video := range published {
+		if video.PublishedAt.Before(s.LastPublishedAt) {
+			continue
+		}
+
+		if !s.synced[video.ID] ||
+			slices.ContainsFunc(failed, func(f models.Video) bool { return f.ID == video.ID }) {
+			return
+		}
+
+		s.LastPublishedAt = video.PublishedAt
+		s.LastVideo = video.ID
+	}
+}
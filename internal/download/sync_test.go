@@ -0,0 +1,302 @@
+package download
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+)
+
+func TestSyncStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), syncStateFile)
+
+	state, err := loadSyncState(path)
+	if err != nil {
+		t.Fatalf("loadSyncState() on missing file error = %v, want nil", err)
+	}
+
+	if len(state.Videos) != 0 {
+		t.Errorf("loadSyncState() on missing file = %v, want empty", state.Videos)
+	}
+
+	videos := []models.Video{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	state.markSynced([]models.VideoResult{
+		{ID: "a", Status: models.StatusDownloaded},
+		{ID: "b", Status: models.StatusFailed},
+		{ID: "c", Status: models.StatusSkipped, Exists: true},
+	})
+
+	if err := state.save(path); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	loaded, err := loadSyncState(path)
+	if err != nil {
+		t.Fatalf("loadSyncState() error = %v", err)
+	}
+
+	got := loaded.pending(videos)
+	if !equalInts(got, []int{1}) {
+		t.Errorf("pending() = %v, want [1]", got)
+	}
+}
+
+func TestSyncStatePending(t *testing.T) {
+	tests := []struct {
+		name   string
+		synced []string
+		videos []models.Video
+		want   []int
+	}{
+		{
+			name:   "nothing synced",
+			synced: nil,
+			videos: []models.Video{{ID: "a"}, {ID: "b"}},
+			want:   []int{0, 1},
+		},
+		{
+			name:   "partially synced",
+			synced: []string{"a"},
+			videos: []models.Video{{ID: "a"}, {ID: "b"}},
+			want:   []int{1},
+		},
+		{
+			name:   "fully synced",
+			synced: []string{"a", "b"},
+			videos: []models.Video{{ID: "a"}, {ID: "b"}},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &syncState{Videos: tt.synced, synced: make(map[string]bool)}
+			for _, id := range tt.synced {
+				state.synced[id] = true
+			}
+
+			if got := state.pending(tt.videos); !equalInts(got, tt.want) {
+				t.Errorf("pending() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSyncStateInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), syncStateFile)
+	if err := os.WriteFile(path, []byte("not json"), stateFilePermissions); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+
+	if _, err := loadSyncState(path); err == nil {
+		t.Error("loadSyncState() expected error for invalid file, got nil")
+	}
+}
+
+// downloadedResults returns the results of downloading the videos with ids.
+func downloadedResults(ids ...string) []models.VideoResult {
+	results := make([]models.VideoResult, len(ids))
+	for i, id := range ids {
+		results[i] = models.VideoResult{ID: id, Status: models.StatusDownloaded}
+	}
+
+	return results
+}
+
+// equalInts compares two int slices for equality.
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestSyncStateNewSince(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 4, d, 8, 0, 0, 0, time.UTC) }
+	videos := []models.Video{
+		{ID: "d", PublishedAt: day(4)},
+		{ID: "a", PublishedAt: day(1)},
+		{ID: "c", PublishedAt: day(3)},
+		{ID: "b", PublishedAt: day(2)},
+	}
+
+	state := &syncState{synced: make(map[string]bool)}
+	if got := state.newSince(videos); !equalInts(got, []int{0, 1, 2, 3}) {
+		t.Fatalf("newSince() without a last video = %v, want all videos", got)
+	}
+
+	// b was published before c but failed, so c and d count as new again
+	failed := []models.Video{{ID: "b"}}
+	state.markSynced(append(downloadedResults("d", "a", "c"),
+		models.VideoResult{ID: "b", Status: models.StatusFailed}))
+	state.advance(videos, failed)
+
+	if state.LastVideo != "a" || !state.LastPublishedAt.Equal(day(1)) {
+		t.Errorf("advance() = %s at %v, want a", state.LastVideo, state.LastPublishedAt)
+	}
+
+	if got := state.newSince(videos); !equalInts(got, []int{0, 2, 3}) {
+		t.Errorf("newSince() = %v, want [0 2 3]", got)
+	}
+
+	state.markSynced(downloadedResults("d", "c", "b"))
+	state.advance(videos, nil)
+
+	if got := state.newSince(videos); len(got) != 0 || state.LastVideo != "d" {
+		t.Errorf("newSince() = %v after downloading everything, last %s, want none, d",
+			got, state.LastVideo)
+	}
+}
+
+func TestSyncStateAdvanceStopsAtUnfinished(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 4, d, 8, 0, 0, 0, time.UTC) }
+	videos := []models.Video{
+		{ID: "a", PublishedAt: day(1)},
+		{ID: "b", PublishedAt: day(2)},
+		{ID: "c", PublishedAt: day(3)},
+	}
+
+	// b failed in an earlier run and isn't part of this one
+	state := &syncState{synced: make(map[string]bool)}
+	state.markSynced(downloadedResults("a", "c"))
+	state.advance(videos, nil)
+
+	if state.LastVideo != "a" || !state.LastPublishedAt.Equal(day(1)) {
+		t.Errorf("advance() = %s at %v, want a before the unfinished b",
+			state.LastVideo, state.LastPublishedAt)
+	}
+}
+
+func TestSyncStateSamePublicationTime(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 4, d, 8, 0, 0, 0, time.UTC) }
+	videos := []models.Video{
+		{ID: "a", PublishedAt: day(1)},
+		{ID: "b", PublishedAt: day(2)},
+	}
+
+	state := &syncState{synced: make(map[string]bool)}
+	state.markSynced(downloadedResults("a", "b"))
+	state.advance(videos, nil)
+
+	// c appears later with the same publication time as the last video b
+	videos = append(videos, models.Video{ID: "c", PublishedAt: day(2)})
+	if got := state.newSince(videos); !equalInts(got, []int{2}) {
+		t.Fatalf("newSince() = %v, want [2] published at the time of the last video", got)
+	}
+
+	// Skipped again, c keeps the marker from passing it
+	state.advance(videos, nil)
+
+	if got := state.newSince(videos); !equalInts(got, []int{2}) {
+		t.Errorf("newSince() after skipping c = %v, want [2]", got)
+	}
+
+	state.markSynced(downloadedResults("c"))
+	state.advance(videos, nil)
+
+	if got := state.newSince(videos); len(got) != 0 || state.LastVideo != "c" {
+		t.Errorf("newSince() = %v after downloading c, last %s, want none, c",
+			got, state.LastVideo)
+	}
+}
+
+// syncAPI serves videos whose variants have the size given by their ID, like
+// variantsAPI, and streams as many bytes.
+type syncAPI struct {
+	variantsAPI
+
+	videos []models.Video
+}
+
+func (a *syncAPI) GetVideo(_ context.Context, id string) (*models.Video, error) {
+	i := slices.IndexFunc(a.videos, func(video models.Video) bool { return video.ID == id })
+
+	return &a.videos[i], nil
+}
+
+func (a *syncAPI) GetChannelVideos(context.Context, string) ([]models.Video, error) {
+	return a.videos, nil
+}
+
+func (a *syncAPI) Stream(_ context.Context, path string, _ int64) (*http.Response, error) {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		ContentLength: int64(len(path)),
+		Body:          io.NopCloser(strings.NewReader(strings.Repeat("x", len(path)))),
+	}, nil
+}
+
+func TestSyncSizeLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"id":"os","name":"Operating Systems"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(token.NewTokenManagerWithToken("secret"),
+		models.ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2026, 4, d, 8, 0, 0, 0, time.UTC) }
+	client = client.WithAPI(&syncAPI{videos: []models.Video{
+		{ID: "bb", Title: "Large", PublishedAt: day(1)},
+		{ID: "a", Title: "Small", PublishedAt: day(2)},
+	}})
+
+	output := t.TempDir()
+	config := models.DownloadConfig{Media: "os", Output: output, Reporter: discardProgress{}}
+
+	syncWith := func(maxSize int64) *syncState {
+		t.Helper()
+
+		config.MaxFilesize = maxSize
+
+		captureOutput(t, func() { err = Sync(context.Background(), client, config) })
+		if err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+
+		paths, _ := filepath.Glob(filepath.Join(output, "*", syncStateFile))
+		if len(paths) != 1 {
+			t.Fatalf("sync state files = %v, want one", paths)
+		}
+
+		state, err := loadSyncState(paths[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return state
+	}
+
+	// The large video is skipped, so neither it nor the later small one
+	// count as finished for --new-only
+	state := syncWith(1)
+	if !slices.Equal(state.Videos, []string{"a"}) || state.LastVideo != "" {
+		t.Errorf("sync state with a size limit = %v, last %q, want [a], none",
+			state.Videos, state.LastVideo)
+	}
+
+	state = syncWith(0)
+	if !slices.Equal(state.Videos, []string{"a", "bb"}) || state.LastVideo != "a" {
+		t.Errorf("sync state without a size limit = %v, last %q, want [a bb], a",
+			state.Videos, state.LastVideo)
+	}
+}
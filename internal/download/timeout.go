@@ -0,0 +1,44 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"switchtube-downloader/internal/models"
+)
+
+var (
+	errRunTimedOut   = errors.New("run timed out")
+	errVideoTimedOut = errors.New("video timed out")
+)
+
+// IsTimeoutLimit reports whether err was caused by the run or video timeout
+// of the download config, rather than by a slow connection.
+func IsTimeoutLimit(err error) bool {
+	return errors.Is(err, errRunTimedOut) || errors.Is(err, errVideoTimedOut)
+}
+
+// WithRunTimeout returns a copy of ctx that is cancelled after the run timeout
+// of config and the function releasing its resources. Without a run timeout,
+// ctx itself is returned.
+func WithRunTimeout(
+	ctx context.Context,
+	config models.DownloadConfig,
+) (context.Context, context.CancelFunc) {
+	if config.RunTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, config.RunTimeout)
+}
+
+// runTimeoutError returns err, marked as caused by the run timeout of config
+// if the deadline of ctx has passed.
+func runTimeoutError(ctx context.Context, config models.DownloadConfig, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s: %w", errRunTimedOut, config.RunTimeout, err)
+	}
+
+	return err
+}
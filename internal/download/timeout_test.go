@@ -0,0 +1,67 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestWithRunTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	unlimited, cancel := WithRunTimeout(ctx, models.DownloadConfig{})
+	cancel()
+
+	if unlimited != ctx {
+		t.Error("WithRunTimeout() without a timeout returned a new context")
+	}
+
+	config := models.DownloadConfig{RunTimeout: time.Nanosecond}
+
+	limited, cancel := WithRunTimeout(ctx, config)
+	defer cancel()
+
+	<-limited.Done()
+
+	err := runTimeoutError(limited, config, errors.New("request failed"))
+	if !errors.Is(err, errRunTimedOut) {
+		t.Errorf("runTimeoutError() = %v, want errRunTimedOut", err)
+	}
+
+	if !IsTimeoutLimit(err) || IsTimeoutLimit(context.DeadlineExceeded) {
+		t.Errorf("IsTimeoutLimit() doesn't tell the run timeout from other deadlines")
+	}
+
+	if err := runTimeoutError(ctx, config, nil); err != nil {
+		t.Errorf("runTimeoutError() = %v, want nil without an error", err)
+	}
+}
+
+func TestDownloadVideoTimeout(t *testing.T) {
+	api := &blockingAPI{mockAPI: mockAPI{video: models.Video{ID: "abc", Title: "Slow"}}}
+	config := models.DownloadConfig{Output: t.TempDir(), VideoTimeout: time.Millisecond}
+
+	var progress models.ProgressInfo
+
+	downloader := newVideoDownloader(config, progress, (&Client{}).WithAPI(api))
+
+	err := downloader.downloadVideo(context.Background(), "abc", true)
+	if !errors.Is(err, errVideoTimedOut) || !IsTimeoutLimit(err) {
+		t.Errorf("downloadVideo() = %v, want errVideoTimedOut", err)
+	}
+}
+
+// blockingAPI blocks every request for the metadata of a video until its
+// context is done.
+type blockingAPI struct {
+	mockAPI
+}
+
+func (*blockingAPI) GetVideo(ctx context.Context, _ string) (*models.Video, error) {
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
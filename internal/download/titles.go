@@ -0,0 +1,61 @@
+package download
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"switchtube-downloader/internal/helper/dir"
+	"switchtube-downloader/internal/models"
+)
+
+// fileTitles returns the title used in the file name of each of videos by its
+// id. Videos whose file name would be the same as the one of a video that was
+// published earlier get their episode or, if that doesn't tell them apart,
+// their id appended, so that they don't overwrite each other. The earliest
+// video keeps its title, which keeps existing files valid when a duplicate is
+// added to a channel, regardless of the order of videos.
+func fileTitles(videos []models.Video, config models.DownloadConfig) map[string]string {
+	titles := make(map[string]string, len(videos))
+	taken := make(map[string]bool, len(videos))
+
+	published := slices.Clone(videos)
+	slices.SortStableFunc(published, func(a, b models.Video) int {
+		return cmp.Or(a.PublishedAt.Compare(b.PublishedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	for _, video := range published {
+		candidates := []string{video.Title}
+		if video.Episode != "" && !config.UseEpisode {
+			candidates = append(candidates, video.Title+" ("+video.Episode+")")
+		}
+
+		candidates = append(candidates, video.Title+" ("+video.ID+")")
+
+		for _, title := range candidates {
+			stem := fileStem(title, video.ID, video.Episode, config)
+			if !taken[stem] {
+				titles[video.ID] = title
+				taken[stem] = true
+
+				break
+			}
+		}
+
+		if _, ok := titles[video.ID]; !ok {
+			titles[video.ID] = candidates[len(candidates)-1]
+		}
+	}
+
+	return titles
+}
+
+// fileStem returns the file name of the video id with title and episode
+// without its extension, ignoring case, which doesn't tell files apart on
+// every filesystem.
+func fileStem(title, id, episode string, config models.DownloadConfig) string {
+	filename := filepath.Base(dir.CreateFilename(title, id, "", episode, config))
+
+	return strings.ToLower(strings.TrimSuffix(filename, filepath.Ext(filename)))
+}
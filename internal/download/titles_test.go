@@ -0,0 +1,58 @@
+package download
+
+import (
+	"testing"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestFileTitles(t *testing.T) {
+	videos := []models.Video{
+		{ID: "a", Title: "Exercise", Episode: "01"},
+		{ID: "b", Title: "Exercise", Episode: "02"},
+		{ID: "c", Title: "exercise", Episode: ""},
+		{ID: "d", Title: "Exercise", Episode: "02"},
+		{ID: "e", Title: "Solution", Episode: "03"},
+	}
+
+	tests := []struct {
+		name   string
+		config models.DownloadConfig
+		want   map[string]string
+	}{
+		{
+			name:   "without episode prefix",
+			config: models.DownloadConfig{},
+			want: map[string]string{
+				"a": "Exercise",
+				"b": "Exercise (02)",
+				"c": "exercise (c)",
+				"d": "Exercise (d)",
+				"e": "Solution",
+			},
+		},
+		{
+			name:   "with episode prefix",
+			config: models.DownloadConfig{UseEpisode: true},
+			want: map[string]string{
+				"a": "Exercise",
+				"b": "Exercise",
+				"c": "exercise",
+				"d": "Exercise (d)",
+				"e": "Solution",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fileTitles(videos, tt.config)
+
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("fileTitles()[%q] = %q, want %q", id, got[id], want)
+				}
+			}
+		})
+	}
+}
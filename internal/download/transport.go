@@ -0,0 +1,255 @@
+package download
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+// Defaults of the connection settings of the transport, which are also the
+// defaults of the flags setting them.
+const (
+	DefaultMaxIdleConns = 100
+	DefaultKeepAlive    = 30 * time.Second
+	DefaultDialTimeout  = 30 * time.Second
+)
+
+var (
+	errFailedToCreateTransport = errors.New("failed to create transport")
+	errFailedToLoadCACert      = errors.New("failed to load ca certificate")
+	errInvalidHeader           = errors.New("invalid header")
+	errInvalidProxy            = errors.New("invalid proxy url")
+	errNoCertificatesFound     = errors.New("no certificates found")
+)
+
+// loggingTransport logs every HTTP request at debug level. Headers are never
+// logged, since they contain the access token.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip performs the request using the next transport and logs its method,
+// URL, status and duration.
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		slog.Debug("http request failed",
+			"method", req.Method,
+			"url", req.URL.Redacted(),
+			"duration", time.Since(start),
+			"error", err)
+
+		return nil, err //nolint:wrapcheck // Transports must not alter errors.
+	}
+
+	slog.Debug("http request",
+		"method", req.Method,
+		"url", req.URL.Redacted(),
+		"status", resp.StatusCode,
+		"duration", time.Since(start))
+
+	return resp, nil
+}
+
+// headerTransport adds the User-Agent and the extra headers of the client to
+// every request that doesn't set them itself. The extra headers are only
+// added to requests to host, the host of SwitchTube, and not after a redirect
+// to another host, e.g. a CDN.
+type headerTransport struct {
+	next   http.RoundTripper
+	header http.Header
+	host   string
+}
+
+// RoundTrip performs the request with the headers using the next transport.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.header) > 0 {
+		// A RoundTripper must not modify the request of its caller
+		req = req.Clone(req.Context())
+		sameHost := strings.EqualFold(req.URL.Host, t.host)
+
+		for key, values := range t.header {
+			if _, ok := req.Header[key]; !ok && (sameHost || key == "User-Agent") {
+				req.Header[key] = values
+			}
+		}
+	}
+
+	return t.next.RoundTrip(req) //nolint:wrapcheck // Transports must not alter errors.
+}
+
+// parseHeaders returns the User-Agent and the extra headers of config, which
+// are given in the form "Name: Value".
+func parseHeaders(config models.ClientConfig) (http.Header, error) {
+	header := make(http.Header)
+
+	for _, line := range config.Headers {
+		name, value, found := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("%w: %q, expected \"Name: Value\"", errInvalidHeader, line)
+		}
+
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("%w: %q contains a line break", errInvalidHeader, name)
+		}
+
+		header.Add(name, strings.TrimSpace(value))
+	}
+
+	if config.UserAgent != "" {
+		header.Set("User-Agent", config.UserAgent)
+	}
+
+	return header, nil
+}
+
+// newTransport creates the HTTP transport of the client based on the default
+// transport, which already respects the proxy environment variables, with
+// the connection settings of config.
+func newTransport(config models.ClientConfig) (*http.Transport, error) {
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errFailedToCreateTransport
+	}
+
+	transport := defaultTransport.Clone()
+	transport.DialContext = dialContext(newDialer(config), config.Network)
+
+	// Nearly all connections go to SwitchTube, so the limit applies per host
+	transport.MaxIdleConns = cmp.Or(config.MaxIdleConns, DefaultMaxIdleConns)
+	transport.MaxIdleConnsPerHost = transport.MaxIdleConns
+
+	transport.Protocols = new(http.Protocols)
+	transport.Protocols.SetHTTP1(true)
+	transport.Protocols.SetHTTP2(!config.DisableHTTP2)
+
+	if config.Proxy != "" {
+		proxyURL, err := parseProxy(config.Proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// newDialer creates the dialer establishing the connections of the transport
+// with the timeout and keep-alive of config.
+func newDialer(config models.ClientConfig) *net.Dialer {
+	var dialer net.Dialer
+
+	dialer.Timeout = orDefault(config.DialTimeout, DefaultDialTimeout)
+	dialer.KeepAlive = cmp.Or(config.KeepAlive, DefaultKeepAlive)
+
+	return &dialer
+}
+
+// dialContext returns the function the transport dials connections with,
+// which restricts TCP connections to forced if it isn't empty.
+func dialContext(
+	dialer *net.Dialer,
+	forced string,
+) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if forced == "" {
+		return dialer.DialContext
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if network == "tcp" {
+			network = forced
+		}
+
+		return dialer.DialContext(ctx, network, address) //nolint:wrapcheck // Passed through to the transport.
+	}
+}
+
+// newTLSConfig creates the TLS configuration trusting the system certificates
+// and the ones from config.CACert.
+func newTLSConfig(config models.ClientConfig) (*tls.Config, error) {
+	var tlsConfig tls.Config
+
+	tlsConfig.MinVersion = tls.VersionTLS12
+
+	if config.CACert != "" {
+		pool, err := loadCertPool(config.CACert)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.Insecure {
+		slog.Warn("TLS certificate verification is disabled, " +
+			"the connection to SwitchTube can be intercepted and your token stolen")
+
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // Explicitly requested with --insecure.
+	}
+
+	return &tlsConfig, nil
+}
+
+// loadCertPool returns the system certificate pool extended by the
+// certificates in the PEM file at path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToLoadCACert, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w: %w: %s", errFailedToLoadCACert, errNoCertificatesFound, path)
+	}
+
+	return pool, nil
+}
+
+// parseProxy parses and validates a proxy URL such as socks5://host:port.
+func parseProxy(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidProxy, err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("%w: unsupported scheme %q", errInvalidProxy, proxyURL.Scheme)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("%w: missing host", errInvalidProxy)
+	}
+
+	return proxyURL, nil
+}
@@ -0,0 +1,125 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"switchtube-downloader/internal/helper/ui"
+	"switchtube-downloader/internal/models"
+)
+
+var errVideoOrChannelRequired = errors.New("a video or channel id or url is required")
+
+// MediaURLs resolves the direct URLs of the media of the video or channel of
+// config without downloading anything. The videos of a channel are sorted
+// and selected like for a download.
+func MediaURLs(
+	ctx context.Context,
+	client *Client,
+	config models.DownloadConfig,
+) ([]models.MediaURL, error) {
+	id, downloadType, err := extractIDAndType(config.Media, client.baseURL())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToExtractType, err)
+	}
+
+	if downloadType == profileType {
+		return nil, errVideoOrChannelRequired
+	}
+
+	apiToken, err := client.tokenManager.Get()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToGetToken, err)
+	}
+
+	header := map[string]string{headerAuthorization: "Token " + apiToken}
+
+	var videos []models.Video
+
+	if downloadType != channelType {
+		video, err := client.api.GetVideo(ctx, id)
+		if err == nil {
+			videos = []models.Video{*video}
+		} else if downloadType == videoType || !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("%w: %w", errFailedToGetVideoInfo, err)
+		}
+	}
+
+	// An unknown id that isn't a video is tried as a channel
+	if videos == nil {
+		if videos, err = selectChannelVideos(ctx, client, id, config); err != nil {
+			return nil, err
+		}
+	}
+
+	urls := make([]models.MediaURL, 0, len(videos))
+
+	for _, video := range videos {
+		mediaURL, err := resolveMediaURL(ctx, client, video, header, config)
+		if err != nil {
+			return nil, err
+		}
+
+		urls = append(urls, *mediaURL)
+	}
+
+	return urls, nil
+}
+
+// selectChannelVideos returns the videos of a channel the user selects, or
+// all of them if config says so.
+func selectChannelVideos(
+	ctx context.Context,
+	client *Client,
+	channelID string,
+	config models.DownloadConfig,
+) ([]models.Video, error) {
+	videos, err := client.api.GetChannelVideos(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToGetChannelVideos, err)
+	}
+
+	videos = sortVideos(videos, config)
+
+	indices, err := ui.SelectVideos(videos, nil, config.All)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToSelectVideos, err)
+	}
+
+	selected := make([]models.Video, 0, len(indices))
+	for _, idx := range indices {
+		selected = append(selected, videos[idx])
+	}
+
+	return selected, nil
+}
+
+// resolveMediaURL returns the URL of the variant of video that would be
+// downloaded with config, which has to be requested with header.
+func resolveMediaURL(
+	ctx context.Context,
+	client *Client,
+	video models.Video,
+	header map[string]string,
+	config models.DownloadConfig,
+) (*models.MediaURL, error) {
+	variants, err := client.api.GetVariants(ctx, video.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToGetVideoVariants, err)
+	}
+
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("%w: %s", errNoVariantsFound, video.Title)
+	}
+
+	variants = client.preferVariant(ctx, variants, config)
+
+	fullURL, err := url.JoinPath(client.baseURL(), variants[0].Path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+	}
+
+	return &models.MediaURL{ID: video.ID, Title: video.Title, URL: fullURL, Header: header}, nil
+}
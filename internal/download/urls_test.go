@@ -0,0 +1,55 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+)
+
+func TestMediaURLsResolve(t *testing.T) {
+	api := &mockAPI{
+		video:    models.Video{ID: "abc", Title: "Paging"},
+		variants: []models.Variant{{MediaType: "video/mp4", Path: "/media/abc.mp4", Size: 10}},
+	}
+
+	client, err := NewClient(token.NewTokenManagerWithToken("secret"), models.ClientConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client = client.WithAPI(api)
+
+	for _, media := range []string{
+		"https://tube.switch.ch/videos/abc",
+		"https://tube.switch.ch/channels/os",
+	} {
+		t.Run(media, func(t *testing.T) {
+			urls, err := MediaURLs(context.Background(), client, models.DownloadConfig{Media: media, All: true})
+			if err != nil {
+				t.Fatalf("MediaURLs() error = %v", err)
+			}
+
+			want := models.MediaURL{
+				ID:     "abc",
+				Title:  "Paging",
+				URL:    "https://tube.switch.ch/media/abc.mp4",
+				Header: map[string]string{headerAuthorization: "Token secret"},
+			}
+			if len(urls) != 1 || urls[0].URL != want.URL ||
+				urls[0].Header[headerAuthorization] != want.Header[headerAuthorization] {
+				t.Errorf("MediaURLs() = %+v, want [%+v]", urls, want)
+			}
+		})
+	}
+
+	t.Run("profile", func(t *testing.T) {
+		config := models.DownloadConfig{Media: "https://tube.switch.ch/profiles/42"}
+
+		if _, err := MediaURLs(context.Background(), client, config); !errors.Is(err, errVideoOrChannelRequired) {
+			t.Errorf("MediaURLs() error = %v, want %v", err, errVideoOrChannelRequired)
+		}
+	})
+}
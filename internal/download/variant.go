@@ -0,0 +1,111 @@
+package download
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"switchtube-downloader/internal/models"
+)
+
+// heightPatterns match the vertical resolution in the name or path of a
+// variant, e.g. "720p" or "1280x720".
+var heightPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(?:^|[^0-9])([0-9]{3,4})p(?:$|[^0-9a-z])`),
+	regexp.MustCompile(`(?:^|[^0-9])[0-9]{3,4}x([0-9]{3,4})(?:$|[^0-9])`),
+}
+
+// variantHeight returns the vertical resolution of variant read from its
+// name or path, or 0 if it can't be determined.
+func variantHeight(variant models.Variant) int {
+	for _, text := range []string{variant.Name, variant.Path} {
+		for _, pattern := range heightPatterns {
+			if match := pattern.FindStringSubmatch(text); match != nil {
+				height, err := strconv.Atoi(match[1])
+				if err == nil {
+					return height
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+// preferVariant returns variants with the variant config prefers first, which
+// is the one that is downloaded. With MaxHeight, variants within the height
+// are preferred, the highest first. With Smallest or Largest, the sizes of
+// all variants are determined and the smallest or largest one is preferred; a
+// variant of unknown size is never preferred. Otherwise, the order of
+// SwitchTube is kept.
+func (c *Client) preferVariant(
+	ctx context.Context,
+	variants []models.Variant,
+	config models.DownloadConfig,
+) []models.Variant {
+	if (!config.Smallest && !config.Largest && config.MaxHeight <= 0) || len(variants) < 2 {
+		return variants
+	}
+
+	sized := slices.Clone(variants)
+
+	if config.Smallest || config.Largest {
+		for i := range sized {
+			sized[i].Size = c.variantSize(ctx, sized[i])
+		}
+	}
+
+	best := 0
+
+	for i := range sized {
+		if betterVariant(sized[i], sized[best], config) {
+			best = i
+		}
+	}
+
+	if config.MaxHeight > 0 && !withinHeight(sized[best], config.MaxHeight) {
+		slog.Warn("no variant within --max-height, keeping the default",
+			"maxHeight", config.MaxHeight,
+			"path", sized[best].Path)
+	}
+
+	// The preferred variant moves to the front, the others keep their order
+	preferred := sized[best]
+
+	return append([]models.Variant{preferred}, slices.Delete(sized, best, best+1)...)
+}
+
+// betterVariant reports whether config prefers variant over current.
+func betterVariant(variant, current models.Variant, config models.DownloadConfig) bool {
+	if config.MaxHeight > 0 {
+		within, currentWithin := withinHeight(variant, config.MaxHeight),
+			withinHeight(current, config.MaxHeight)
+		if within != currentWithin {
+			return within
+		}
+	}
+
+	if config.Smallest || config.Largest {
+		switch {
+		case variant.Size == current.Size:
+		case variant.Size < 0:
+			return false
+		case current.Size < 0:
+			return true
+		default:
+			return (variant.Size < current.Size) == config.Smallest
+		}
+	}
+
+	return config.MaxHeight > 0 && variantHeight(variant) > variantHeight(current)
+}
+
+// withinHeight reports whether the height of variant is known and doesn't
+// exceed maxHeight.
+func withinHeight(variant models.Variant, maxHeight int) bool {
+	height := variantHeight(variant)
+
+	return height > 0 && height <= maxHeight
+}
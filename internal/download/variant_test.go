@@ -0,0 +1,111 @@
+package download
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestPreferVariant(t *testing.T) {
+	variants := []models.Variant{
+		{MediaType: "video/mp4", Path: "hd", Size: 300},
+		{MediaType: "video/mp4", Path: "sd", Size: 100},
+		{MediaType: "video/mp4", Path: "uhd", Size: 900},
+	}
+
+	tests := []struct {
+		name   string
+		config models.DownloadConfig
+		want   []string
+	}{
+		{name: "no preference", config: models.DownloadConfig{}, want: []string{"hd", "sd", "uhd"}},
+		{
+			name:   "smallest",
+			config: models.DownloadConfig{Smallest: true},
+			want:   []string{"sd", "hd", "uhd"},
+		},
+		{
+			name:   "largest",
+			config: models.DownloadConfig{Largest: true},
+			want:   []string{"uhd", "hd", "sd"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preferred := (&Client{}).preferVariant(context.Background(), variants, tt.config)
+
+			var paths []string
+			for _, variant := range preferred {
+				paths = append(paths, variant.Path)
+			}
+
+			if !slices.Equal(paths, tt.want) {
+				t.Errorf("preferVariant() = %v, want %v", paths, tt.want)
+			}
+		})
+	}
+
+	if variants[0].Path != "hd" {
+		t.Errorf("preferVariant() reordered its input to %v", variants)
+	}
+}
+
+func TestVariantHeight(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant models.Variant
+		want    int
+	}{
+		{name: "name", variant: models.Variant{Name: "720p"}, want: 720},
+		{name: "name with label", variant: models.Variant{Name: "HD 1080p"}, want: 1080},
+		{name: "path", variant: models.Variant{Path: "/media/abc_480p.mp4"}, want: 480},
+		{name: "dimensions", variant: models.Variant{Path: "/media/abc-1280x720.mp4"}, want: 720},
+		{name: "name before path", variant: models.Variant{Name: "360p", Path: "a_720p.mp4"}, want: 360},
+		{name: "unknown", variant: models.Variant{Path: "/media/abc123.mp4"}, want: 0},
+		{name: "id digits", variant: models.Variant{Path: "/media/x1080px.mp4"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := variantHeight(tt.variant); got != tt.want {
+				t.Errorf("variantHeight(%+v) = %d, want %d", tt.variant, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreferVariantMaxHeight(t *testing.T) {
+	variants := []models.Variant{
+		{Name: "1080p", Path: "fhd", Size: 900},
+		{Name: "480p", Path: "sd", Size: 100},
+		{Name: "720p", Path: "hd", Size: 300},
+		{Path: "unknown", Size: 50},
+	}
+
+	tests := []struct {
+		name   string
+		config models.DownloadConfig
+		want   string
+	}{
+		{name: "highest within", config: models.DownloadConfig{MaxHeight: 720}, want: "hd"},
+		{name: "all within", config: models.DownloadConfig{MaxHeight: 2160}, want: "fhd"},
+		{name: "none within", config: models.DownloadConfig{MaxHeight: 240}, want: "fhd"},
+		{
+			name:   "smallest within",
+			config: models.DownloadConfig{MaxHeight: 720, Smallest: true},
+			want:   "sd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preferred := (&Client{}).preferVariant(context.Background(), variants, tt.config)
+			if preferred[0].Path != tt.want || len(preferred) != len(variants) {
+				t.Errorf("preferVariant() = %+v, want %s first", preferred, tt.want)
+			}
+		})
+	}
+}
@@ -1,23 +1,21 @@
 package download
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
-	"net/url"
 	"os"
+	"time"
 
 	"switchtube-downloader/internal/helper/dir"
 	"switchtube-downloader/internal/helper/ui"
 	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/postprocess"
 )
 
-// videoVariant represents a video download variant.
-type videoVariant struct {
-	Path      string `json:"path"`
-	MediaType string `json:"mediaType"`
-}
-
 var (
 	errFailedToConstructURL     = errors.New("failed to construct URL")
 	errFailedToCopyVideoData    = errors.New("failed to copy video data")
@@ -28,14 +26,28 @@ var (
 	errFailedToGetVideoInfo     = errors.New("failed to get video information")
 	errFailedToGetVideoVariants = errors.New("failed to get video variants")
 	errHTTPNotOK                = errors.New("HTTP request failed with non-OK status")
+	errIncompleteDownload       = errors.New("incomplete download")
+	errFailedToPostProcess      = errors.New("failed to post-process video")
 	errNoVariantsFound          = errors.New("no video variants found")
 )
 
+// maxResumes is the number of times an incomplete download is resumed.
+const maxResumes = 3
+
 // videoDownloader handles the downloading of individual videos.
 type videoDownloader struct {
 	config   models.DownloadConfig
 	progress models.ProgressInfo
 	client   *Client
+	api      API
+
+	// channel is the name of the channel the video is downloaded with, if
+	// any, which is recorded in the download history.
+	channel string
+
+	// fileTitle replaces the title of the video in its file name, if set,
+	// to tell videos of a channel with the same title apart.
+	fileTitle string
 }
 
 // newVideoDownloader creates a new instance of VideoDownloader.
@@ -45,20 +57,45 @@ func newVideoDownloader(
 	client *Client,
 ) *videoDownloader {
 	return &videoDownloader{
-		config:   config,
-		progress: progress,
-		client:   client,
+		config:    config,
+		progress:  progress,
+		client:    client,
+		api:       client.currentAPI(),
+		channel:   "",
+		fileTitle: "",
+	}
+}
+
+// downloadVideo downloads a video within the video timeout of the config, if
+// any.
+func (vd *videoDownloader) downloadVideo(
+	ctx context.Context,
+	videoID string,
+	checkExists bool,
+) error {
+	if vd.config.VideoTimeout <= 0 {
+		return vd.download(ctx, videoID, checkExists)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, vd.config.VideoTimeout)
+	defer cancel()
+
+	err := vd.download(ctx, videoID, checkExists)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s: %w", errVideoTimedOut, vd.config.VideoTimeout, err)
 	}
+
+	return err
 }
 
-// downloadVideo downloads a video.
-func (vd *videoDownloader) downloadVideo(videoID string, checkExists bool) error {
-	video, err := vd.getMetadata(videoID)
+// download downloads a video.
+func (vd *videoDownloader) download(ctx context.Context, videoID string, checkExists bool) error {
+	video, err := vd.api.GetVideo(ctx, videoID)
 	if err != nil {
 		return fmt.Errorf("%w: %w", errFailedToGetVideoInfo, err)
 	}
 
-	variants, err := vd.getVariants(videoID)
+	variants, err := vd.api.GetVariants(ctx, videoID)
 	if err != nil {
 		return fmt.Errorf("%w: %w", errFailedToGetVideoVariants, err)
 	}
@@ -67,84 +104,232 @@ func (vd *videoDownloader) downloadVideo(videoID string, checkExists bool) error
 		return errNoVariantsFound
 	}
 
-	filename := dir.CreateFilename(video.Title, variants[0].MediaType, video.Episode, vd.config)
-	if checkExists && dir.OverwriteVideoIfExists(filename, vd.config) {
+	variants = vd.client.preferVariant(ctx, variants, vd.config)
+
+	size := int64(unknownSize)
+	if vd.config.MinFilesize > 0 || vd.config.MaxFilesize > 0 || vd.config.IfChanged {
+		size = vd.client.variantSize(ctx, variants[0])
+	}
+
+	if outsideSizeLimits(video, size, vd.config) ||
+		skippedByHook(ctx, video, vd.channel, size, vd.config) {
+		return nil
+	}
+
+	if vd.config.Output == models.OutputStdout {
+		return vd.downloadToStdout(ctx, video, variants[0].Path)
+	}
+
+	title := video.Title
+	if vd.fileTitle != "" {
+		title = vd.fileTitle
+	}
+
+	filename := dir.CreateFilename(
+		title, videoID, variants[0].MediaType, video.Episode, vd.config,
+	)
+
+	// Existing files are checked under the name post-processing produces
+	output := postprocess.OutputName(filename, vd.config)
+	if checkExists && skipExisting(output, video, size, vd.config) {
+		slog.Info("skipped existing video", "id", videoID, "file", output)
+
 		return nil // Skip download
 	}
 
-	file, err := dir.CreateVideoFile(filename)
-	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToCreateVideoFile, err)
+	output = dir.ResolveFilename(output, vd.config)
+	filename = postprocess.InputName(output, filename, vd.config)
+
+	slog.Info("downloading video",
+		"id", videoID,
+		"title", video.Title,
+		"file", filename,
+		"mediaType", variants[0].MediaType)
+
+	start := time.Now()
+
+	if err := vd.downloadFile(ctx, videoID, variants[0].Path, filename); err != nil {
+		return err
 	}
 
-	// Download the video
-	err = vd.downloadProcess(variants[0].Path, file)
+	duration := time.Since(start)
+	slog.Info("downloaded video", "id", videoID, "duration", duration)
+
+	output, err = postprocess.Run(filename, video.Duration, vd.config)
 	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToDownloadVideo, err)
+		return fmt.Errorf("%w: %w", errFailedToPostProcess, err)
 	}
 
+	vd.writeEpisodeNFO(video, output)
+	vd.recordHistory(video, output, duration)
+
 	return nil
 }
 
-// getMetadata retrieves video metadata from the API.
-func (vd *videoDownloader) getMetadata(videoID string) (*models.Video, error) {
-	fullURL, err := url.JoinPath(baseURL, videoAPI, videoID)
+// downloadFile downloads the video from endpoint to filename, with the
+// external downloader of the config if set. The file is removed if the
+// download fails.
+func (vd *videoDownloader) downloadFile(
+	ctx context.Context,
+	videoID, endpoint, filename string,
+) error {
+	file, err := dir.CreateVideoFile(filename)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+		return fmt.Errorf("%w: %w", errFailedToCreateVideoFile, err)
 	}
 
-	var videoData models.Video
-	if err := vd.client.makeJSONRequest(fullURL, &videoData); err != nil {
-		return nil, fmt.Errorf("%w: %w", errFailedToDecodeVideoMeta, err)
+	if vd.config.ExternalDownloader != "" {
+		err = vd.downloadExternal(ctx, endpoint, filename)
+	} else {
+		err = vd.downloadProcess(ctx, videoID, endpoint, file)
 	}
 
-	return &videoData, nil
-}
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
 
-// getVariants retrieves available video variants from the API.
-func (vd *videoDownloader) getVariants(videoID string) ([]videoVariant, error) {
-	fullURL, err := url.JoinPath(baseURL, videoAPI, videoID, "video_variants")
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+		removeIncompleteFile(filename)
+
+		return fmt.Errorf("%w: %w", errFailedToDownloadVideo, err)
 	}
 
-	var variants []videoVariant
-	if err := vd.client.makeJSONRequest(fullURL, &variants); err != nil {
-		return nil, fmt.Errorf("%w: %w", errFailedToDecodeVariants, err)
+	return nil
+}
+
+// removeIncompleteFile removes the file of a failed download, which would
+// otherwise look like an existing video the next time.
+func removeIncompleteFile(filename string) {
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("failed to remove incomplete file", "file", filename, "error", err)
+
+		return
 	}
 
-	return variants, nil
+	slog.Warn("removed incomplete file", "file", filename)
 }
 
-// downloadProcess handles the actual file download.
-func (vd *videoDownloader) downloadProcess(endpoint string, file *os.File) error {
-	fullURL, err := url.JoinPath(baseURL, endpoint)
-	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToConstructURL, err)
+// downloadProcess handles the actual file download. A download that breaks
+// off or ends before the size reported by the server is resumed up to
+// maxResumes times before it fails.
+func (vd *videoDownloader) downloadProcess(
+	ctx context.Context,
+	videoID, endpoint string,
+	file *os.File,
+) error {
+	var offset int64
+
+	for resumes := 0; ; resumes++ {
+		size, expected, err := vd.streamTo(ctx, videoID, endpoint, file, offset)
+		if err == nil && expected >= 0 && size != expected {
+			err = fmt.Errorf("%w: received %d of %d bytes", errIncompleteDownload, size, expected)
+		}
+
+		if err == nil {
+			return nil
+		}
+
+		resumable := errors.Is(err, errFailedToCopyVideoData) || errors.Is(err, errIncompleteDownload)
+		if !resumable || resumes >= maxResumes || (expected >= 0 && size > expected) {
+			return err
+		}
+
+		slog.Warn("resuming incomplete download", "id", videoID, "bytes", size, "error", err)
+
+		offset = size
 	}
+}
 
-	resp, err := vd.client.makeRequest(fullURL)
+// streamTo downloads the video from endpoint into file starting at offset. It
+// returns the size of the file afterwards and the size the server reported
+// for the whole video, which is -1 if unknown. The file is written from the
+// start again if the server doesn't support resuming.
+func (vd *videoDownloader) streamTo(
+	ctx context.Context,
+	videoID, endpoint string,
+	file *os.File,
+	offset int64,
+) (int64, int64, error) {
+	resp, err := vd.api.Stream(ctx, endpoint, offset)
 	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToFetchVideoStream, err)
+		return offset, unknownSize, fmt.Errorf("%w: %w", errFailedToFetchVideoStream, err)
 	}
 
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Printf("Warning: failed to close response body: %v\n", err)
+			slog.Warn("failed to close response body", "error", err)
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("%w: status %d: %s",
-			errHTTPNotOK,
-			resp.StatusCode,
-			http.StatusText(resp.StatusCode))
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		if offset, err = rewind(file, offset); err != nil {
+			return 0, unknownSize, err
+		}
+	case resp.StatusCode != http.StatusPartialContent || offset == 0:
+		return offset, unknownSize, statusError(resp.StatusCode)
+	}
+
+	expected := int64(unknownSize)
+	if resp.ContentLength >= 0 {
+		expected = offset + resp.ContentLength
+	}
+
+	err = vd.copyWithProgress(videoID, resp, file, file.Name())
+
+	size, seekErr := file.Seek(0, io.SeekCurrent)
+	if seekErr != nil {
+		return offset, expected, fmt.Errorf("%w: %w", errFailedToCopyVideoData, seekErr)
+	}
+
+	return size, expected, err
+}
+
+// rewind truncates file if the server sent the whole video although offset
+// bytes were already downloaded, and returns the new offset.
+func rewind(file *os.File, offset int64) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+
+	slog.Info("server doesn't support resuming, restarting download", "file", file.Name())
+
+	if err := file.Truncate(0); err != nil {
+		return offset, fmt.Errorf("%w: %w", errFailedToCopyVideoData, err)
 	}
 
-	currentItem := max(vd.progress.CurrentItem, 1)
-	totalItems := max(vd.progress.TotalItems, 1)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return offset, fmt.Errorf("%w: %w", errFailedToCopyVideoData, err)
+	}
+
+	return 0, nil
+}
+
+// copyWithProgress copies the body of resp to dst while showing the progress
+// of the file name.
+func (vd *videoDownloader) copyWithProgress(
+	videoID string,
+	resp *http.Response,
+	dst io.Writer,
+	name string,
+) error {
+	progress := vd.progress
+	progress.CurrentItem = max(progress.CurrentItem, 1)
+	progress.TotalItems = max(progress.TotalItems, 1)
+
+	var err error
+
+	switch {
+	case vd.config.Reporter != nil:
+		err = ui.ProgressReport(resp.Body, dst, resp.ContentLength, videoID, name, progress,
+			vd.config.Reporter)
+	case vd.config.ProgressFormat == models.ProgressFormatJSON:
+		err = ui.ProgressJSON(resp.Body, dst, resp.ContentLength, videoID, name, progress)
+	default:
+		err = ui.ProgressBar(resp.Body, dst, resp.ContentLength, name, vd.config.ProgressStyle,
+			progress)
+	}
 
-	err = ui.ProgressBar(resp.Body, file, resp.ContentLength, file.Name(), currentItem, totalItems)
 	if err != nil {
 		return fmt.Errorf("%w: %w", errFailedToCopyVideoData, err)
 	}
@@ -0,0 +1,151 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+)
+
+// truncatingAPI streams data, breaking off after cut bytes until it was asked
+// for cuts ranges. It ignores range requests unless ranges is set.
+type truncatingAPI struct {
+	mockAPI
+
+	cut     int
+	cuts    int
+	ranges  bool
+	offsets []int64
+}
+
+func (a *truncatingAPI) Stream(_ context.Context, _ string, offset int64) (*http.Response, error) {
+	a.offsets = append(a.offsets, offset)
+
+	status := http.StatusOK
+	if a.ranges && offset > 0 {
+		status = http.StatusPartialContent
+	} else {
+		offset = 0
+	}
+
+	rest := a.data[offset:]
+	sent := rest
+	if len(a.offsets) <= a.cuts {
+		sent = rest[:min(a.cut, len(rest))]
+	}
+
+	return &http.Response{
+		StatusCode:    status,
+		ContentLength: int64(len(rest)),
+		Body:          io.NopCloser(strings.NewReader(sent)),
+	}, nil
+}
+
+func TestDownloadProcessResumes(t *testing.T) {
+	tests := []struct {
+		name        string
+		ranges      bool
+		cuts        int
+		wantOffsets []int64
+		wantErr     error
+	}{
+		{
+			name:        "complete download",
+			ranges:      true,
+			cuts:        0,
+			wantOffsets: []int64{0},
+		},
+		{
+			name:        "resumed with range requests",
+			ranges:      true,
+			cuts:        2,
+			wantOffsets: []int64{0, 4, 8},
+		},
+		{
+			name:        "restarted without range support",
+			ranges:      false,
+			cuts:        1,
+			wantOffsets: []int64{0, 4},
+		},
+		{
+			name:        "fails after too many resumes",
+			ranges:      true,
+			cuts:        maxResumes + 1,
+			wantOffsets: []int64{0, 4, 8, 12},
+			wantErr:     errIncompleteDownload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &truncatingAPI{
+				mockAPI: mockAPI{data: "0123456789abcdefghij"},
+				cut:     4,
+				cuts:    tt.cuts,
+				ranges:  tt.ranges,
+			}
+
+			file, err := os.Create(filepath.Join(t.TempDir(), "video.mp4"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			config := models.DownloadConfig{Reporter: discardProgress{}}
+			downloader := newVideoDownloader(config, models.ProgressInfo{}, (&Client{}).WithAPI(api))
+
+			err = downloader.downloadProcess(context.Background(), "abc", "media/abc.mp4", file)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("downloadProcess() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(api.offsets) != len(tt.wantOffsets) {
+				t.Fatalf("offsets = %v, want %v", api.offsets, tt.wantOffsets)
+			}
+
+			for i, offset := range tt.wantOffsets {
+				if api.offsets[i] != offset {
+					t.Errorf("offsets = %v, want %v", api.offsets, tt.wantOffsets)
+				}
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			data, err := os.ReadFile(file.Name())
+			if err != nil || string(data) != api.data {
+				t.Errorf("file = %q, %v, want %q", data, err, api.data)
+			}
+		})
+	}
+}
+
+// failingAPI fails every stream request.
+type failingAPI struct {
+	mockAPI
+}
+
+func (*failingAPI) Stream(context.Context, string, int64) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestDownloadFileRemovesIncompleteFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "video.mp4")
+	config := models.DownloadConfig{Reporter: discardProgress{}}
+	downloader := newVideoDownloader(config, models.ProgressInfo{}, (&Client{}).WithAPI(&failingAPI{}))
+
+	if err := downloader.downloadFile(context.Background(), "abc", "media/abc.mp4", filename); err == nil {
+		t.Fatal("downloadFile() error = nil, want an error")
+	}
+
+	if _, err := os.Stat(filename); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat() error = %v, want the incomplete file to be removed", err)
+	}
+}
@@ -0,0 +1,109 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/schedule"
+)
+
+var (
+	errInvalidInterval = errors.New("watch interval must be positive")
+	errNeverScheduled  = errors.New("schedule never runs")
+)
+
+// Watch syncs a channel every interval until ctx is cancelled. Failed runs
+// are reported but do not stop watching, since most failures are transient.
+func Watch(
+	ctx context.Context,
+	client *Client,
+	config models.DownloadConfig,
+	interval time.Duration,
+) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w: %s", errInvalidInterval, interval)
+	}
+
+	next := func(now time.Time) time.Time { return now.Add(interval) }
+
+	return watch(ctx, client, config, next, true)
+}
+
+// WatchSchedule syncs a channel whenever sched is due until ctx is cancelled.
+// Unlike Watch, the first sync waits for the schedule as well.
+func WatchSchedule(
+	ctx context.Context,
+	client *Client,
+	config models.DownloadConfig,
+	sched *schedule.Schedule,
+) error {
+	return watch(ctx, client, config, sched.Next, false)
+}
+
+// watch syncs a channel at the times next returns for the end of the
+// previous run until ctx is cancelled, starting with a sync if immediately is
+// set. The outcome of every run is logged.
+func watch(
+	ctx context.Context,
+	client *Client,
+	config models.DownloadConfig,
+	next func(time.Time) time.Time,
+	immediately bool,
+) error {
+	_, downloadType, err := extractIDAndType(config.Media, client.baseURL())
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToExtractType, err)
+	}
+
+	if downloadType == videoType || downloadType == profileType {
+		return errChannelRequired
+	}
+
+	for run := immediately; ; run = true {
+		if run {
+			runSync(ctx, client, config)
+		}
+
+		at := next(time.Now())
+		if at.IsZero() {
+			return errNeverScheduled
+		}
+
+		fmt.Fprintf(os.Stderr, "Next check at %s\n", formatNextCheck(at))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Until(at)):
+		}
+	}
+}
+
+// runSync syncs the channel of config once and logs the outcome. Cancelling
+// ctx stops watching after the run rather than aborting it.
+func runSync(ctx context.Context, client *Client, config models.DownloadConfig) {
+	start := time.Now()
+
+	if err := Sync(context.WithoutCancel(ctx), client, config); err != nil {
+		slog.Error("sync failed", "media", config.Media, "duration", time.Since(start), "error", err)
+
+		return
+	}
+
+	slog.Info("sync finished", "media", config.Media, "duration", time.Since(start))
+}
+
+// formatNextCheck formats the time of the next check, with the date if it
+// isn't today.
+func formatNextCheck(at time.Time) string {
+	if now := time.Now(); at.YearDay() != now.YearDay() || at.Year() != now.Year() {
+		return at.Format(time.DateTime)
+	}
+
+	return at.Format(time.TimeOnly)
+}
@@ -0,0 +1,71 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/schedule"
+)
+
+func TestWatchValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		media    string
+		interval time.Duration
+		err      error
+	}{
+		{
+			name:     "zero interval",
+			media:    defaultBaseURL + channelPrefix + "abc",
+			interval: 0,
+			err:      errInvalidInterval,
+		},
+		{
+			name:     "negative interval",
+			media:    defaultBaseURL + channelPrefix + "abc",
+			interval: -1,
+			err:      errInvalidInterval,
+		},
+		{
+			name:     "video instead of channel",
+			media:    defaultBaseURL + videoPrefix + "123",
+			interval: 1,
+			err:      errChannelRequired,
+		},
+		{
+			name:     "invalid url",
+			media:    defaultBaseURL + "invalid/123",
+			interval: 1,
+			err:      errInvalidURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := models.DownloadConfig{Media: tt.media}
+
+			err := Watch(context.Background(), &Client{}, config, tt.interval)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Watch() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestWatchScheduleNeverDue(t *testing.T) {
+	sched, err := schedule.Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	config := models.DownloadConfig{Media: defaultBaseURL + channelPrefix + "abc"}
+
+	// The first sync waits for the schedule, so no client is needed
+	err = WatchSchedule(context.Background(), &Client{}, config, sched)
+	if !errors.Is(err, errNeverScheduled) {
+		t.Errorf("WatchSchedule() error = %v, want %v", err, errNeverScheduled)
+	}
+}
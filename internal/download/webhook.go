@@ -0,0 +1,88 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+// webhookTimeout limits how long notifying the webhook may take, so that a
+// slow webhook doesn't hold up the run.
+const webhookTimeout = 30 * time.Second
+
+var (
+	errFailedToEncodeNotification = errors.New("failed to encode notification")
+	errFailedToNotify             = errors.New("failed to notify webhook")
+)
+
+// notifyWebhook posts the summary of a channel download that took duration
+// to the notification webhook, if one is configured. A failure is logged
+// instead of failing the download.
+func (cd *channelDownloader) notifyWebhook(summary models.DownloadSummary, duration time.Duration) {
+	if cd.config.NotifyWebhook == "" {
+		return
+	}
+
+	text := fmt.Sprintf("SwitchTube: downloaded %d/%d videos of %s in %s",
+		summary.Downloaded, summary.Selected, summary.Channel, duration.Round(time.Second))
+	if len(summary.Failed) > 0 {
+		text += fmt.Sprintf(", %d failed", len(summary.Failed))
+	}
+
+	notification := models.WebhookNotification{
+		DownloadSummary: summary,
+		Text:            text,
+		Content:         text,
+		DurationSeconds: duration.Seconds(),
+	}
+
+	if err := cd.client.postJSON(cd.config.NotifyWebhook, notification); err != nil {
+		slog.Warn("failed to notify webhook", "error", err)
+
+		return
+	}
+
+	slog.Info("notified webhook", "channel", summary.Channel)
+}
+
+// postJSON posts payload as JSON to url using the proxy and certificate
+// settings of the API client. Neither the access token nor the extra headers
+// are sent, and the request isn't logged, since the URL usually contains a
+// secret and belongs to a third party.
+func (c *Client) postJSON(url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToEncodeNotification, err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		context.Background(), http.MethodPost, url, bytes.NewReader(body),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToCreateRequest, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.hookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToNotify, err)
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		slog.Warn("failed to close response body", "error", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s", errFailedToNotify, resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package download
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+)
+
+func TestNotifyWebhook(t *testing.T) {
+	var (
+		received      models.WebhookNotification
+		authorization string
+		extra         string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get(headerAuthorization)
+		extra = r.Header.Get("X-Extra")
+
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode notification: %v", err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	clientConfig := models.ClientConfig{Headers: []string{"X-Extra: secret"}}
+
+	client, err := NewClient(token.NewTokenManager(), clientConfig)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	config := models.DownloadConfig{NotifyWebhook: server.URL}
+	summary := models.DownloadSummary{
+		Channel:    "Operating Systems",
+		Selected:   3,
+		Downloaded: 2,
+		Failed:     []models.Video{{ID: "v3", Title: "Paging"}},
+	}
+
+	newChannelDownloader(config, client).notifyWebhook(summary, 90*time.Second)
+
+	if authorization != "" {
+		t.Errorf("webhook received Authorization header %q", authorization)
+	}
+
+	if extra != "" {
+		t.Errorf("webhook received extra header %q", extra)
+	}
+
+	if received.Channel != summary.Channel || received.Downloaded != 2 || len(received.Failed) != 1 {
+		t.Errorf("notification = %+v, want summary %+v", received, summary)
+	}
+
+	if received.DurationSeconds != 90 {
+		t.Errorf("durationSeconds = %v, want 90", received.DurationSeconds)
+	}
+
+	want := "downloaded 2/3 videos of Operating Systems in 1m30s, 1 failed"
+	if !strings.Contains(received.Text, want) || received.Content != received.Text {
+		t.Errorf("text = %q, content = %q, want them to contain %q",
+			received.Text, received.Content, want)
+	}
+}
+
+func TestPostJSONError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(token.NewTokenManager(), models.ClientConfig{})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if err := client.postJSON(server.URL, struct{}{}); err == nil {
+		t.Error("postJSON() error = nil, want error for status 400")
+	}
+}
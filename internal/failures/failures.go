@@ -0,0 +1,158 @@
+// Package failures records failed downloads in a JSON Lines file, so that they
+// can be retried later with the same options.
+package failures
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"switchtube-downloader/internal/config"
+	"switchtube-downloader/internal/helper/dir"
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// fileName is the name of the failures file in the config directory.
+	fileName = "failures.jsonl"
+
+	// File and directory permissions of the failures file.
+	dirPermissions  = 0o755
+	filePermissions = 0o600
+
+	// maxLineSize is the maximum size of an entry of the failures file.
+	maxLineSize = 1 << 20
+)
+
+var (
+	errFailedToGetPath    = errors.New("failed to get failures file path")
+	errFailedToReadEntry  = errors.New("failed to read failed download")
+	errFailedToReadFile   = errors.New("failed to read failures file")
+	errFailedToWriteEntry = errors.New("failed to record failed download")
+	errFailedToWriteFile  = errors.New("failed to write failures file")
+)
+
+// DefaultPath returns the default location of the failures file, e.g.
+// ~/.config/switchtube-dl/failures.jsonl on Linux.
+func DefaultPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToGetPath, err)
+	}
+
+	return filepath.Join(dir, fileName), nil
+}
+
+// Add appends entries to the failures file at path, creating it if needed.
+func Add(path string, entries ...models.FailedDownload) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirPermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteEntry, err)
+	}
+
+	data, err := encode(entries)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteEntry, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, filePermissions)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteEntry, err)
+	}
+
+	defer dir.CloseFile(file)
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteEntry, err)
+	}
+
+	return nil
+}
+
+// Save replaces the contents of the failures file at path with entries. The
+// file is removed if there are none.
+func Save(path string, entries []models.FailedDownload) error {
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+		}
+
+		return nil
+	}
+
+	data, err := encode(entries)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	if err := os.WriteFile(path, data, filePermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	return nil
+}
+
+// Load returns the failed downloads recorded at path, keeping only the most
+// recent entry of each video in the order they first failed. A missing file
+// results in no failures.
+func Load(path string) ([]models.FailedDownload, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToReadFile, err)
+	}
+	defer dir.CloseFile(file)
+
+	var entries []models.FailedDownload
+
+	positions := make(map[string]int)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var entry models.FailedDownload
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", errFailedToReadEntry, line, err)
+		}
+
+		if i, ok := positions[entry.ID]; ok {
+			entries[i] = entry
+
+			continue
+		}
+
+		positions[entry.ID] = len(entries)
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToReadFile, err)
+	}
+
+	return entries, nil
+}
+
+// encode returns entries as JSON Lines.
+func encode(entries []models.FailedDownload) ([]byte, error) {
+	var data []byte
+
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w", err)
+		}
+
+		data = append(append(data, line...), '\n')
+	}
+
+	return data, nil
+}
@@ -0,0 +1,70 @@
+package failures
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestAddLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dir", fileName)
+
+	entries, err := Load(path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Load() of a missing file = %v, %v, want no entries", entries, err)
+	}
+
+	failedAt := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	first := models.FailedDownload{
+		ID:       "a1B2c3",
+		Title:    "Mapping",
+		Channel:  "Operating Systems",
+		Error:    "connection reset",
+		FailedAt: failedAt,
+		Options:  models.RetryOptions{UseEpisode: true, Remux: models.RemuxMKV},
+	}
+	second := models.FailedDownload{ID: "x9Y8z7", Title: "Paging", Error: "timed out"}
+	again := first
+	again.Error = "no video variants found"
+
+	if err := Add(path, first, second); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if err := Add(path, again); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	entries, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].ID != first.ID || entries[1].ID != second.ID {
+		t.Fatalf("Load() = %+v, want one entry per video in order", entries)
+	}
+
+	if entries[0].Error != again.Error || !entries[0].Options.UseEpisode ||
+		entries[0].Options.Remux != models.RemuxMKV || !entries[0].FailedAt.Equal(failedAt) {
+		t.Errorf("Load()[0] = %+v, want the most recent failure with its config", entries[0])
+	}
+
+	if err := Save(path, entries[1:]); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if entries, err = Load(path); err != nil || len(entries) != 1 || entries[0].ID != second.ID {
+		t.Errorf("Load() after Save() = %+v, %v, want only %s", entries, err, second.ID)
+	}
+
+	if err := Save(path, nil); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Stat() error = %v, want the file to be removed without failures", err)
+	}
+}
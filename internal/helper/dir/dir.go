@@ -4,9 +4,11 @@ package dir
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"switchtube-downloader/internal/helper/ui"
 	"switchtube-downloader/internal/models"
@@ -29,8 +31,12 @@ var (
 )
 
 // CreateFilename creates a sanitized filename from video title and media type.
+// The id of the video is used instead of a title that sanitizes to nothing,
+// e.g. one in a non-Latin script with Slug or one of only dots with
+// WindowsSafe, and if the output directory leaves no room for the title.
 func CreateFilename(
 	title string,
+	videoID string,
 	mediaType string,
 	episodeNr string,
 	config models.DownloadConfig,
@@ -44,35 +50,111 @@ func CreateFilename(
 	}
 
 	sanitizedTitle := sanitizeFilename(title)
+	if config.Slug {
+		sanitizedTitle = slugify(sanitizedTitle)
+	}
+
 	sanitizedTitle = strings.ReplaceAll(sanitizedTitle, " ", "_")
 
+	if config.WindowsSafe {
+		sanitizedTitle = sanitizeWindowsName(sanitizedTitle)
+	}
+
 	// Add episode prefix if episode flag is set
-	var filename string
+	prefix := ""
 	if config.UseEpisode && episodeNr != "" {
-		filename = fmt.Sprintf("%s_%s.%s", episodeNr, sanitizedTitle, extension)
-	} else {
-		filename = fmt.Sprintf("%s.%s", sanitizedTitle, extension)
+		prefix = episodeNr + "_"
+	}
+
+	// Shorten the title so that the file name and path stay within the limits
+	budget := titleBudget(config.Output, prefix, "."+extension, config.LongPaths)
+	if budget <= 0 {
+		slog.Warn("output directory leaves no room for the title, use --long-paths",
+			"output", config.Output)
 	}
 
+	truncated := truncateTitle(sanitizedTitle, budget)
+	if truncated == "" {
+		truncated = sanitizeFilename(videoID)
+	}
+
+	filename := prefix + truncated + "." + extension
+
 	if config.Output != "" {
 		filename = filepath.Join(config.Output, filename)
 	}
 
+	if config.LongPaths {
+		filename = withLongPathPrefix(filename)
+	}
+
 	return filepath.Clean(filename)
 }
 
-// OverwriteVideoIfExists checks if a video file exists and prompts to overwrite
-// it. Returns false if the file doesn't exist or if overwriting is declined.
+// OverwriteVideoIfExists checks if a video file exists and decides whether
+// to skip it according to the conflict policy, prompting to overwrite it by
+// default. Returns false if the file doesn't exist, if it is overwritten or
+// if a new name will be used.
 func OverwriteVideoIfExists(filename string, config models.DownloadConfig) bool {
-	if !config.Force {
-		if _, err := os.Stat(filename); err == nil {
-			if config.Skip || !ui.Confirm("File %s already exists. Overwrite?", filename) {
-				return true
-			}
-		}
+	if config.Force {
+		return false
+	}
+
+	if _, err := os.Stat(filename); err != nil {
+		return false
 	}
 
-	return false
+	if config.Skip {
+		return true
+	}
+
+	switch config.OnConflict {
+	case models.ConflictSkip:
+		return true
+	case models.ConflictOverwrite, models.ConflictRename:
+		return false
+	default:
+		return !ui.Confirm("File %s already exists. Overwrite?", filename)
+	}
+}
+
+// FileChanged reports whether a video of size bytes that was published at
+// publishedAt differs from the existing file filename and has to be
+// downloaded again. A missing file has always changed. The sizes are only
+// compared if compareSize is set and size is known; a video published after
+// the file was written has changed as well.
+func FileChanged(filename string, size int64, publishedAt time.Time, compareSize bool) bool {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return true
+	}
+
+	if compareSize && size >= 0 && info.Size() != size {
+		return true
+	}
+
+	return publishedAt.After(info.ModTime())
+}
+
+// ResolveFilename returns the filename to write a video to. If the rename
+// policy applies and the file exists, " (1)", " (2)" and so on is appended to
+// the name until it is unique.
+func ResolveFilename(filename string, config models.DownloadConfig) string {
+	if config.Force || config.Skip || config.OnConflict != models.ConflictRename {
+		return filename
+	}
+
+	extension := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, extension)
+	candidate := filename
+
+	for i := 1; ; i++ {
+		if _, err := os.Stat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, extension)
+	}
 }
 
 // CreateVideoFile creates a video file on disk with the specified filename.
@@ -89,22 +171,6 @@ func CreateVideoFile(filename string) (*os.File, error) {
 	return fd, nil
 }
 
-// CreateChannelFolder creates a folder for the channel using its name.
-func CreateChannelFolder(channelName string, config models.DownloadConfig) (string, error) {
-	folderName := strings.ReplaceAll(channelName, "/", " - ")
-	folderName = filepath.Clean(folderName)
-
-	if config.Output != "" {
-		folderName = filepath.Join(config.Output, folderName)
-	}
-
-	if err := os.MkdirAll(folderName, dirPermissions); err != nil {
-		return "", fmt.Errorf("%w: %w", errFailedToCreateFolder, err)
-	}
-
-	return folderName, nil
-}
-
 // sanitizeFilename removes or replaces invalid characters in filenames.
 func sanitizeFilename(filename string) string {
 	replacements := map[string]string{
@@ -131,3 +197,10 @@ func sanitizeFilename(filename string) string {
 
 	return sanitized
 }
+
+// CloseFile closes file, logging a failure. It is meant for deferred closes.
+func CloseFile(file *os.File) {
+	if err := file.Close(); err != nil {
+		slog.Warn("failed to close file", "file", file.Name(), "error", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"switchtube-downloader/internal/models"
 )
@@ -51,6 +52,38 @@ func TestCreateFilename(t *testing.T) {
 			config:    models.DownloadConfig{UseEpisode: false},
 			want:      "Test-Video-WithInvalidChars.mp4",
 		},
+		{
+			name:      "windows reserved name",
+			title:     "aux",
+			mediaType: "video/mp4",
+			episodeNr: "",
+			config:    models.DownloadConfig{WindowsSafe: true},
+			want:      "aux_.mp4",
+		},
+		{
+			name:      "windows name of only dots",
+			title:     "...",
+			mediaType: "video/mp4",
+			episodeNr: "",
+			config:    models.DownloadConfig{WindowsSafe: true},
+			want:      "a1B2c3.mp4",
+		},
+		{
+			name:      "slug",
+			title:     "Übung: Café",
+			mediaType: "video/mp4",
+			episodeNr: "",
+			config:    models.DownloadConfig{Slug: true},
+			want:      "Uebung-_Cafe.mp4",
+		},
+		{
+			name:      "slug of a non-Latin title",
+			title:     "日本語の講義",
+			mediaType: "video/mp4",
+			episodeNr: "",
+			config:    models.DownloadConfig{Slug: true},
+			want:      "a1B2c3.mp4",
+		},
 		{
 			name:      "video with output path",
 			title:     "Test Video",
@@ -63,7 +96,7 @@ func TestCreateFilename(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := CreateFilename(tt.title, tt.mediaType, tt.episodeNr, tt.config)
+			got := CreateFilename(tt.title, "a1B2c3", tt.mediaType, tt.episodeNr, tt.config)
 			if got != tt.want {
 				t.Errorf("CreateFilename() = %q, want %q", got, tt.want)
 			}
@@ -144,6 +177,42 @@ func TestOverwriteVideoIfExists(t *testing.T) {
 			wantValue:   false,
 			createFile:  false,
 		},
+		{
+			name:        "video exists, skip policy",
+			filename:    "existing_video.mp4",
+			config:      models.DownloadConfig{OnConflict: models.ConflictSkip},
+			wantPrompt:  "",
+			promptInput: "",
+			wantValue:   true,
+			createFile:  true,
+		},
+		{
+			name:        "video exists, overwrite policy",
+			filename:    "existing_video.mp4",
+			config:      models.DownloadConfig{OnConflict: models.ConflictOverwrite},
+			wantPrompt:  "",
+			promptInput: "",
+			wantValue:   false,
+			createFile:  true,
+		},
+		{
+			name:        "video exists, rename policy",
+			filename:    "existing_video.mp4",
+			config:      models.DownloadConfig{OnConflict: models.ConflictRename},
+			wantPrompt:  "",
+			promptInput: "",
+			wantValue:   false,
+			createFile:  true,
+		},
+		{
+			name:        "video exists, skip-flag overrides rename policy",
+			filename:    "existing_video.mp4",
+			config:      models.DownloadConfig{Skip: true, OnConflict: models.ConflictRename},
+			wantPrompt:  "",
+			promptInput: "",
+			wantValue:   true,
+			createFile:  true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -180,11 +249,11 @@ func TestOverwriteVideoIfExists(t *testing.T) {
 
 			defer func() { os.Stdin = oldStdin }()
 
-			oldStdout := os.Stdout
+			oldStderr := os.Stderr
 			r, w, _ := os.Pipe()
-			os.Stdout = w
+			os.Stderr = w
 
-			defer func() { os.Stdout = oldStdout }()
+			defer func() { os.Stderr = oldStderr }()
 
 			got := OverwriteVideoIfExists(filename, tt.config)
 
@@ -218,6 +287,105 @@ func TestOverwriteVideoIfExists(t *testing.T) {
 	}
 }
 
+func TestResolveFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		config   models.DownloadConfig
+		want     string
+	}{
+		{
+			name:     "no conflict",
+			existing: nil,
+			config:   models.DownloadConfig{OnConflict: models.ConflictRename},
+			want:     "Video.mp4",
+		},
+		{
+			name:     "first conflict",
+			existing: []string{"Video.mp4"},
+			config:   models.DownloadConfig{OnConflict: models.ConflictRename},
+			want:     "Video (1).mp4",
+		},
+		{
+			name:     "several conflicts",
+			existing: []string{"Video.mp4", "Video (1).mp4", "Video (2).mp4"},
+			config:   models.DownloadConfig{OnConflict: models.ConflictRename},
+			want:     "Video (3).mp4",
+		},
+		{
+			name:     "other policy keeps the name",
+			existing: []string{"Video.mp4"},
+			config:   models.DownloadConfig{OnConflict: models.ConflictOverwrite},
+			want:     "Video.mp4",
+		},
+		{
+			name:     "force-flag keeps the name",
+			existing: []string{"Video.mp4"},
+			config:   models.DownloadConfig{Force: true, OnConflict: models.ConflictRename},
+			want:     "Video.mp4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			for _, name := range tt.existing {
+				if err := os.WriteFile(filepath.Join(tempDir, name), nil, 0o600); err != nil {
+					t.Fatalf("Failed to create file: %v", err)
+				}
+			}
+
+			got := ResolveFilename(filepath.Join(tempDir, "Video.mp4"), tt.config)
+			if want := filepath.Join(tempDir, tt.want); got != want {
+				t.Errorf("ResolveFilename() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFileChanged(t *testing.T) {
+	written := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		missing     bool
+		size        int64
+		publishedAt time.Time
+		compareSize bool
+		want        bool
+	}{
+		{name: "missing file", missing: true, size: 5, want: true},
+		{name: "same size", size: 5, compareSize: true, want: false},
+		{name: "different size", size: 8, compareSize: true, want: true},
+		{name: "unknown size", size: -1, compareSize: true, want: false},
+		{name: "size not compared", size: 8, compareSize: false, want: false},
+		{name: "published before", size: 5, publishedAt: written.AddDate(0, -1, 0), want: false},
+		{name: "published after", size: 5, publishedAt: written.AddDate(0, 0, 1), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "Video.mp4")
+
+			if !tt.missing {
+				if err := os.WriteFile(filename, []byte("video"), 0o600); err != nil {
+					t.Fatalf("Failed to create file: %v", err)
+				}
+
+				if err := os.Chtimes(filename, written, written); err != nil {
+					t.Fatalf("Failed to set modification time: %v", err)
+				}
+			}
+
+			got := FileChanged(filename, tt.size, tt.publishedAt, tt.compareSize)
+			if got != tt.want {
+				t.Errorf("FileChanged() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCreateVideoFile(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -283,42 +451,66 @@ func TestCreateVideoFile(t *testing.T) {
 	}
 }
 
-func TestCreateChannelFolder(t *testing.T) {
+func TestCreateFolder(t *testing.T) {
 	tests := []struct {
-		name        string
-		channelName string
-		config      models.DownloadConfig
-		wantFolder  string
-		wantErr     bool
-		err         error
+		name       string
+		fields     FolderFields
+		config     models.DownloadConfig
+		wantFolder string
+		wantErr    bool
+		err        error
 	}{
 		{
-			name:        "basic folder creation",
-			channelName: "Test Channel",
-			config:      models.DownloadConfig{Output: ""},
-			wantFolder:  "Test Channel",
-			wantErr:     false,
+			name:       "basic folder creation",
+			fields:     FolderFields{Channel: "Test Channel"},
+			config:     models.DownloadConfig{Output: ""},
+			wantFolder: "Test Channel",
+			wantErr:    false,
+		},
+		{
+			name:       "folder with slashes",
+			fields:     FolderFields{Channel: "Test/Channel"},
+			config:     models.DownloadConfig{Output: "output"},
+			wantFolder: filepath.Join("output", "Test - Channel"),
+			wantErr:    false,
 		},
 		{
-			name:        "folder with slashes",
-			channelName: "Test/Channel",
-			config:      models.DownloadConfig{Output: "output"},
-			wantFolder:  filepath.Join("output", "Test - Channel"),
-			wantErr:     false,
+			name:       "empty channel name",
+			fields:     FolderFields{Channel: ""},
+			config:     models.DownloadConfig{Output: ""},
+			wantFolder: ".",
+			wantErr:    false,
 		},
 		{
-			name:        "empty channel name",
-			channelName: "",
-			config:      models.DownloadConfig{Output: ""},
-			wantFolder:  ".",
-			wantErr:     false,
+			name:       "folder in specific output directory",
+			fields:     FolderFields{Channel: "Test Channel"},
+			config:     models.DownloadConfig{Output: "test_path"},
+			wantFolder: filepath.Join("test_path", "Test Channel"),
+			wantErr:    false,
 		},
 		{
-			name:        "folder in specific output directory",
-			channelName: "Test Channel",
-			config:      models.DownloadConfig{Output: "test_path"},
-			wantFolder:  filepath.Join("test_path", "Test Channel"),
-			wantErr:     false,
+			name:       "channel of a profile",
+			fields:     FolderFields{Profile: "Lectures", Channel: "Test Channel"},
+			config:     models.DownloadConfig{Output: ""},
+			wantFolder: filepath.Join("Lectures", "Test Channel"),
+			wantErr:    false,
+		},
+		{
+			name:   "custom template",
+			fields: FolderFields{Profile: "Lectures", Channel: "OS", ChannelID: "abc"},
+			config: models.DownloadConfig{
+				Output:         "",
+				OutputTemplate: "SwitchTube/{channel} ({channel_id})",
+			},
+			wantFolder: filepath.Join("SwitchTube", "OS (abc)"),
+			wantErr:    false,
+		},
+		{
+			name:       "field with slash",
+			fields:     FolderFields{Profile: "A/B", Channel: "OS"},
+			config:     models.DownloadConfig{Output: "", OutputTemplate: "{profile}/{channel}"},
+			wantFolder: filepath.Join("A - B", "OS"),
+			wantErr:    false,
 		},
 	}
 
@@ -327,18 +519,18 @@ func TestCreateChannelFolder(t *testing.T) {
 			tempDir := t.TempDir()
 			tt.config.Output = filepath.Join(tempDir, tt.config.Output)
 
-			folder, err := CreateChannelFolder(tt.channelName, tt.config)
+			folder, err := CreateFolder(tt.fields, tt.config)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("CreateChannelFolder() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("CreateFolder() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
 			if tt.wantErr && !errors.Is(err, tt.err) {
-				t.Errorf("CreateChannelFolder() error = %v, want %v", err, tt.err)
+				t.Errorf("CreateFolder() error = %v, want %v", err, tt.err)
 			}
 
 			if folder != filepath.Join(tempDir, tt.wantFolder) {
 				t.Errorf(
-					"CreateChannelFolder() folder = %q, want %q",
+					"CreateFolder() folder = %q, want %q",
 					folder,
 					filepath.Join(tempDir, tt.wantFolder),
 				)
@@ -346,13 +538,36 @@ func TestCreateChannelFolder(t *testing.T) {
 
 			if !tt.wantErr {
 				if _, err := os.Stat(folder); os.IsNotExist(err) {
-					t.Errorf("CreateChannelFolder() did not create folder %q", folder)
+					t.Errorf("CreateFolder() did not create folder %q", folder)
 				}
 			}
 		})
 	}
 }
 
+func TestValidateOutputTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		wantErr  bool
+	}{
+		{template: "{profile}/{channel}", wantErr: false},
+		{template: "SwitchTube/{channel_id}", wantErr: false},
+		{template: "{course}/{channel}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateOutputTemplate(tt.template)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateOutputTemplate(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+		}
+
+		if tt.wantErr && !errors.Is(err, ErrInvalidOutputTemplate) {
+			t.Errorf("ValidateOutputTemplate(%q) error = %v, want %v",
+				tt.template, err, ErrInvalidOutputTemplate)
+		}
+	}
+}
+
 func TestSanitizeFilename(t *testing.T) {
 	tests := []struct {
 		name string
@@ -394,3 +609,137 @@ func TestSanitizeFilename(t *testing.T) {
 		})
 	}
 }
+
+func TestSanitizeWindowsName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "regular name", in: "Lecture_01", want: "Lecture_01"},
+		{name: "reserved name", in: "CON", want: "CON_"},
+		{name: "reserved name ignoring case", in: "com1", want: "com1_"},
+		{name: "reserved name with extension", in: "nul.tar", want: "nul_.tar"},
+		{name: "reserved prefix", in: "CONSOLE", want: "CONSOLE"},
+		{name: "trailing dots and spaces", in: "Intro... ", want: "Intro"},
+		{name: "control characters", in: "Intro\x00\tPart", want: "IntroPart"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeWindowsName(tt.in); got != tt.want {
+				t.Errorf("sanitizeWindowsName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "ascii", in: "Lecture 01", want: "Lecture 01"},
+		{name: "umlauts", in: "Übung Größe", want: "Uebung Groesse"},
+		{name: "accents", in: "Café crème", want: "Cafe creme"},
+		{name: "decomposed accents", in: "Café", want: "Cafe"},
+		{name: "decomposed umlaut", in: "Übung", want: "Uebung"},
+		{name: "whitespace", in: "  Intro \t to   OS ", want: "Intro to OS"},
+		{name: "other scripts", in: "Intro 日本", want: "Intro"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slugify(tt.in); got != tt.want {
+				t.Errorf("slugify() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateTitle(t *testing.T) {
+	tests := []struct {
+		name   string
+		title  string
+		budget int
+		want   string
+	}{
+		{name: "short title", title: "Intro", budget: 10, want: "Intro"},
+		{name: "cut", title: "Operating_Systems", budget: 9, want: "Operating"},
+		{name: "trailing separator", title: "Operating_Systems", budget: 10, want: "Operating"},
+		{name: "multibyte character", title: "Größe", budget: 3, want: "Gr"},
+		{name: "no budget", title: "Intro", budget: 0, want: ""},
+		{name: "negative budget", title: "Intro", budget: -5, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateTitle(tt.title, tt.budget); got != tt.want {
+				t.Errorf("truncateTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateFilenamePathLength(t *testing.T) {
+	title := strings.Repeat("a", 300)
+	output := t.TempDir()
+
+	tests := []struct {
+		name      string
+		goos      string
+		longPaths bool
+		wantLen   int
+	}{
+		{
+			name:      "file name limit",
+			goos:      "linux",
+			longPaths: false,
+			wantLen:   len(output) + 1 + maxComponentLength,
+		},
+		{
+			name:      "windows path limit",
+			goos:      "windows",
+			longPaths: false,
+			wantLen:   maxWindowsPathLength,
+		},
+		{
+			name:      "windows long paths",
+			goos:      "windows",
+			longPaths: true,
+			wantLen:   len(longPathPrefix) + len(output) + 1 + maxComponentLength,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(original string) { goos = original }(goos)
+
+			goos = tt.goos
+			config := models.DownloadConfig{Output: output, UseEpisode: true, LongPaths: tt.longPaths}
+
+			got := CreateFilename(title, "a1B2c3", "video/mp4", "E01", config)
+			if len(got) != tt.wantLen {
+				t.Errorf("len(CreateFilename()) = %d, want %d", len(got), tt.wantLen)
+			}
+
+			if !strings.HasSuffix(got, ".mp4") || !strings.Contains(got, "E01_aaa") {
+				t.Errorf("CreateFilename() = %q, want episode prefix and extension", got)
+			}
+		})
+	}
+}
+
+func TestCreateFilenameWithoutBudget(t *testing.T) {
+	defer func(original string) { goos = original }(goos)
+
+	goos = "windows"
+	output := filepath.Join(t.TempDir(), strings.Repeat("d", maxWindowsPathLength))
+	config := models.DownloadConfig{Output: output, UseEpisode: true}
+
+	want := filepath.Join(output, "E01_a1B2c3.mp4")
+	if got := CreateFilename("Intro", "a1B2c3", "video/mp4", "E01", config); got != want {
+		t.Errorf("CreateFilename() = %q, want %q", got, want)
+	}
+}
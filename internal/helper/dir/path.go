@@ -0,0 +1,82 @@
+package dir
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxComponentLength is the maximum length of a file or folder name in
+	// bytes on common filesystems such as ext4, APFS and NTFS.
+	maxComponentLength = 255
+
+	// maxWindowsPathLength is the maximum length of a path on Windows without
+	// the long path prefix (MAX_PATH minus the terminating null character).
+	maxWindowsPathLength = 259
+
+	// longPathPrefix lifts the path length limit of the Windows API.
+	longPathPrefix = `\\?\`
+)
+
+// goos is the operating system whose path limits apply, which tests replace.
+var goos = runtime.GOOS
+
+// titleBudget returns the number of bytes available for the title of a file
+// with the given name parts in dir, so that neither the file name nor, on
+// Windows without long paths, the whole path gets too long.
+func titleBudget(dir, prefix, extension string, longPaths bool) int {
+	fixed := len(prefix) + len(extension)
+	budget := maxComponentLength - fixed
+
+	if goos == "windows" && !longPaths {
+		budget = min(budget, maxWindowsPathLength-len(absPath(dir))-len(`\`)-fixed)
+	}
+
+	return budget
+}
+
+// truncateTitle shortens title to at most budget bytes without splitting a
+// character, removing separators left at the end by the cut. Nothing is left
+// of it if budget isn't positive.
+func truncateTitle(title string, budget int) string {
+	budget = max(budget, 0)
+	if len(title) <= budget {
+		return title
+	}
+
+	cut := budget
+	for cut > 0 && !utf8.RuneStart(title[cut]) {
+		cut--
+	}
+
+	truncated := strings.TrimRight(title[:cut], "_- .")
+	if truncated == "" {
+		return title[:cut]
+	}
+
+	return truncated
+}
+
+// withLongPathPrefix returns path as an absolute path with the Windows long
+// path prefix, which allows paths of up to 32767 characters. Other systems
+// don't need it, so path is returned unchanged there.
+func withLongPathPrefix(path string) string {
+	if goos != "windows" || strings.HasPrefix(path, longPathPrefix) {
+		return path
+	}
+
+	return longPathPrefix + absPath(path)
+}
+
+// absPath returns the absolute form of path, or path itself if the working
+// directory can't be determined.
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	return abs
+}
@@ -0,0 +1,36 @@
+package dir
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations are spelled out instead of dropping their accents, since
+// "Übung" reads better as "Uebung" than as "Ubung".
+var transliterations = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue",
+	"Ä", "Ae", "Ö", "Oe", "Ü", "Ue",
+	"ß", "ss", "æ", "ae", "Æ", "Ae",
+	"ø", "o", "Ø", "O", "œ", "oe", "Œ", "Oe",
+)
+
+// slugify turns name into a portable ASCII name: it is NFC-normalized,
+// umlauts are transliterated, accents are removed, whitespace is collapsed
+// and any remaining non-ASCII characters are dropped, e.g. "Übung  Café"
+// becomes "Uebung Cafe".
+func slugify(name string) string {
+	name = transliterations.Replace(norm.NFC.String(name))
+
+	// Decompose the remaining accented characters to drop their marks
+	name = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) || r > unicode.MaxASCII || unicode.IsControl(r) {
+			return -1
+		}
+
+		return r
+	}, norm.NFD.String(name))
+
+	return strings.Join(strings.Fields(name), " ")
+}
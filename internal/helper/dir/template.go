@@ -0,0 +1,118 @@
+package dir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"switchtube-downloader/internal/models"
+)
+
+// ErrInvalidOutputTemplate is returned for output templates with unknown
+// fields.
+var ErrInvalidOutputTemplate = errors.New("invalid output template")
+
+// templateField matches a field of an output template, e.g. "{channel}".
+var templateField = regexp.MustCompile(`\{[^{}]*\}`)
+
+// FolderFields are the values the fields of an output template are replaced
+// with. Empty values are left out, e.g. the profile of a single channel.
+type FolderFields struct {
+	Profile   string
+	Channel   string
+	ChannelID string
+}
+
+// values returns the value of every template field.
+func (f FolderFields) values() map[string]string {
+	return map[string]string{
+		"{profile}":    f.Profile,
+		"{channel}":    f.Channel,
+		"{channel_id}": f.ChannelID,
+	}
+}
+
+// ValidateOutputTemplate returns an error if template contains a field other
+// than {profile}, {channel} and {channel_id}.
+func ValidateOutputTemplate(template string) error {
+	fields := FolderFields{}.values()
+
+	for _, field := range templateField.FindAllString(template, -1) {
+		if _, ok := fields[field]; !ok {
+			return fmt.Errorf("%w: unknown field %s", ErrInvalidOutputTemplate, field)
+		}
+	}
+
+	return nil
+}
+
+// CreateFolder creates the folder for the videos of a channel by expanding
+// the output template of config, models.DefaultOutputTemplate if it is unset,
+// inside the output directory. Every directory of the template is named like
+// a channel folder; directories whose fields are all empty are left out, so
+// "{profile}/{channel}" becomes "Profile/Channel" for the channels of a
+// profile and just "Channel" for a single channel.
+func CreateFolder(fields FolderFields, config models.DownloadConfig) (string, error) {
+	template := config.OutputTemplate
+	if template == "" {
+		template = models.DefaultOutputTemplate
+	}
+
+	values := fields.values()
+	folderName := config.Output
+
+	for _, segment := range strings.Split(filepath.ToSlash(template), "/") {
+		empty := true
+		name := templateField.ReplaceAllStringFunc(segment, func(field string) string {
+			value := values[field]
+			if value != "" {
+				empty = false
+			}
+
+			return strings.ReplaceAll(value, "/", " - ")
+		})
+
+		if empty && templateField.MatchString(segment) {
+			continue
+		}
+
+		if name = folderSegment(name, config); name != "" {
+			folderName = filepath.Join(folderName, name)
+		}
+	}
+
+	if folderName == "" {
+		folderName = "."
+	}
+
+	if config.LongPaths {
+		folderName = withLongPathPrefix(folderName)
+	}
+
+	if err := os.MkdirAll(folderName, dirPermissions); err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToCreateFolder, err)
+	}
+
+	return folderName, nil
+}
+
+// folderSegment turns name into a valid folder name according to config.
+func folderSegment(name string, config models.DownloadConfig) string {
+	name = strings.TrimSpace(name)
+	if config.Slug {
+		name = slugify(name)
+	}
+
+	if config.WindowsSafe {
+		name = sanitizeWindowsName(sanitizeFilename(name))
+	}
+
+	if name == "." || name == ".." {
+		return ""
+	}
+
+	return truncateTitle(name, maxComponentLength)
+}
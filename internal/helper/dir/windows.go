@@ -0,0 +1,39 @@
+package dir
+
+import (
+	"strings"
+	"unicode"
+)
+
+// windowsReservedNames are the device names Windows doesn't allow as file or
+// folder names, with or without an extension.
+var windowsReservedNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// sanitizeWindowsName makes name valid on Windows: control characters are
+// removed, trailing dots and spaces are trimmed and reserved device names get
+// an underscore appended, e.g. "CON" becomes "CON_".
+func sanitizeWindowsName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+
+		return r
+	}, name)
+
+	name = strings.TrimRight(name, ". ")
+
+	// Windows ignores the extension, so "CON.txt" is reserved as well
+	stem, _, _ := strings.Cut(name, ".")
+	for _, reserved := range windowsReservedNames {
+		if strings.EqualFold(strings.TrimRight(stem, " "), reserved) {
+			return stem + "_" + strings.TrimPrefix(name, stem)
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,106 @@
+// Package lock provides advisory locks on directories, so that two instances
+// don't download into the same folder at the same time. The locks are
+// released by the operating system if the process dies.
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	// FileName is the name of the lock file in the locked directory.
+	FileName = ".switchtube.lock"
+
+	// File and directory permissions of the lock file.
+	dirPermissions  = 0o755
+	filePermissions = 0o600
+)
+
+var (
+	// ErrLocked is returned if another instance holds the lock.
+	ErrLocked = errors.New("another instance is downloading into this directory")
+
+	errFailedToLock = errors.New("failed to lock directory")
+
+	// errWouldBlock is returned by tryLock if the file is locked already.
+	errWouldBlock = errors.New("lock is held by another process")
+)
+
+// Lock is an acquired lock on a directory.
+type Lock struct {
+	file *os.File
+}
+
+// Acquire locks dir, creating it if needed. If another instance holds the
+// lock, ErrLocked is returned unless wait is set, in which case Acquire blocks
+// until the lock is released.
+func Acquire(dir string, wait bool) (*Lock, error) {
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToLock, err)
+	}
+
+	acquired, err := AcquireFile(filepath.Join(dir, FileName), wait)
+	if errors.Is(err, ErrLocked) {
+		return nil, fmt.Errorf("%w: %s", ErrLocked, dir)
+	}
+
+	return acquired, err
+}
+
+// AcquireFile locks the lock file at path, creating it if needed, e.g. to
+// guard a file that is read and written again by several instances. The
+// directory of path must exist. Like Acquire, it fails with ErrLocked unless
+// wait is set.
+func AcquireFile(path string, wait bool) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, filePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToLock, err)
+	}
+
+	err = tryLock(file)
+	if errors.Is(err, errWouldBlock) && wait {
+		slog.Info("waiting for lock", "file", path)
+
+		err = waitLock(file)
+	}
+
+	if err != nil {
+		_ = file.Close()
+
+		if errors.Is(err, errWouldBlock) {
+			return nil, ErrLocked
+		}
+
+		return nil, fmt.Errorf("%w: %w", errFailedToLock, err)
+	}
+
+	// The pid helps to find the other instance, it isn't needed for locking
+	if err := file.Truncate(0); err == nil {
+		_, _ = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// IsLockError reports whether err is a failure to lock a directory, either
+// because another instance holds the lock or because it couldn't be created.
+func IsLockError(err error) bool {
+	return errors.Is(err, ErrLocked) || errors.Is(err, errFailedToLock)
+}
+
+// Release releases the lock. The lock file is kept, since removing it could
+// let two instances lock different files of the same name.
+func (l *Lock) Release() {
+	if err := unlock(l.file); err != nil {
+		slog.Warn("failed to release lock", "file", l.file.Name(), "error", err)
+	}
+
+	if err := l.file.Close(); err != nil {
+		slog.Warn("failed to close file", "file", l.file.Name(), "error", err)
+	}
+}
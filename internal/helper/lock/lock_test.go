@@ -0,0 +1,67 @@
+package lock
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "videos")
+
+	first, err := Acquire(dir, false)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	if _, err := Acquire(dir, false); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Acquire() of a locked directory error = %v, want %v", err, ErrLocked)
+	}
+
+	first.Release()
+
+	second, err := Acquire(dir, false)
+	if err != nil {
+		t.Fatalf("Acquire() after Release() error = %v", err)
+	}
+
+	second.Release()
+}
+
+func TestAcquireWait(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := Acquire(dir, false)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	acquired := make(chan error, 1)
+
+	go func() {
+		second, err := Acquire(dir, true)
+		if err == nil {
+			second.Release()
+		}
+
+		acquired <- err
+	}()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("Acquire() with wait returned %v while the directory was locked", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	first.Release()
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("Acquire() with wait error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Acquire() with wait didn't return after the lock was released")
+	}
+}
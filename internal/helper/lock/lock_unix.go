@@ -0,0 +1,41 @@
+//go:build !windows
+
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// tryLock locks file without blocking, returning errWouldBlock if another
+// process holds the lock.
+func tryLock(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errWouldBlock
+	} else if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// waitLock locks file, blocking until another process releases the lock.
+func waitLock(file *os.File) error {
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// unlock releases the lock on file.
+func unlock(file *os.File) error {
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
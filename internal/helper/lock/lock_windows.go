@@ -0,0 +1,49 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFlags lock a file exclusively.
+const lockFlags = windows.LOCKFILE_EXCLUSIVE_LOCK
+
+// tryLock locks file without blocking, returning errWouldBlock if another
+// process holds the lock.
+func tryLock(file *os.File) error {
+	err := lockFile(file, lockFlags|windows.LOCKFILE_FAIL_IMMEDIATELY)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errWouldBlock
+	}
+
+	return err
+}
+
+// waitLock locks file, blocking until another process releases the lock.
+func waitLock(file *os.File) error {
+	return lockFile(file, lockFlags)
+}
+
+// lockFile locks the first byte of file, which is enough for an advisory
+// lock.
+func lockFile(file *os.File, flags uint32) error {
+	overlapped := new(windows.Overlapped)
+	if err := windows.LockFileEx(windows.Handle(file.Fd()), flags, 0, 1, 0, overlapped); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// unlock releases the lock on file.
+func unlock(file *os.File) error {
+	overlapped := new(windows.Overlapped)
+	if err := windows.UnlockFileEx(windows.Handle(file.Fd()), 0, 1, 0, overlapped); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
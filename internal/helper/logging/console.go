@@ -0,0 +1,147 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// consoleHandler writes records as short human-readable lines such as
+// "Warning: failed to close response body error=...". Unlike the text handler
+// it omits the time, which is noise on an interactive terminal.
+type consoleHandler struct {
+	w      io.Writer
+	level  slog.Leveler
+	attrs  []slog.Attr
+	prefix string
+	mu     *sync.Mutex
+}
+
+// newConsoleHandler creates a console handler writing records of at least
+// level to w.
+func newConsoleHandler(w io.Writer, level slog.Leveler) *consoleHandler {
+	return &consoleHandler{
+		w:      w,
+		level:  level,
+		attrs:  nil,
+		prefix: "",
+		mu:     new(sync.Mutex),
+	}
+}
+
+// Enabled reports whether records of level are written.
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle writes a single line for record.
+func (h *consoleHandler) Handle(_ context.Context, record slog.Record) error {
+	var line strings.Builder
+
+	line.WriteString(levelLabel(record.Level))
+	line.WriteString(record.Message)
+
+	for _, attr := range h.attrs {
+		writeAttr(&line, "", attr)
+	}
+
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&line, h.prefix, attr)
+
+		return true
+	})
+
+	line.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := io.WriteString(h.w, line.String()); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// WithAttrs returns a handler that adds attrs to every record.
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handler := *h
+	handler.attrs = slices.Concat(h.attrs, prefixAttrs(h.prefix, attrs))
+
+	return &handler
+}
+
+// WithGroup returns a handler that prefixes the keys of following attributes
+// with name.
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	handler := *h
+	handler.prefix = h.prefix + name + "."
+
+	return &handler
+}
+
+// levelLabel returns the prefix of a line with the given level.
+func levelLabel(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "Error: "
+	case level >= slog.LevelWarn:
+		return "Warning: "
+	case level >= slog.LevelInfo:
+		return "Info: "
+	default:
+		return "Debug: "
+	}
+}
+
+// prefixAttrs returns attrs with prefix added to their keys.
+func prefixAttrs(prefix string, attrs []slog.Attr) []slog.Attr {
+	if prefix == "" {
+		return attrs
+	}
+
+	prefixed := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		prefixed[i] = slog.Attr{Key: prefix + attr.Key, Value: attr.Value}
+	}
+
+	return prefixed
+}
+
+// writeAttr appends " key=value" to line, quoting values with spaces. Groups
+// are flattened using dotted keys.
+func writeAttr(line *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{Key: "", Value: slog.Value{}}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+
+		for _, groupAttr := range attr.Value.Group() {
+			writeAttr(line, groupPrefix, groupAttr)
+		}
+
+		return
+	}
+
+	value := attr.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+
+	line.WriteString(" " + prefix + attr.Key + "=" + value)
+}
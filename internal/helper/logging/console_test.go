@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestConsoleHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(logger *slog.Logger)
+		want string
+	}{
+		{
+			name: "warning with error",
+			log: func(logger *slog.Logger) {
+				logger.Warn("failed to close response body", "error", errors.New("broken pipe"))
+			},
+			want: "Warning: failed to close response body error=\"broken pipe\"\n",
+		},
+		{
+			name: "error with plain value",
+			log: func(logger *slog.Logger) {
+				logger.Error("failed to download video", "title", "Mapping")
+			},
+			want: "Error: failed to download video title=Mapping\n",
+		},
+		{
+			name: "filtered by level",
+			log: func(logger *slog.Logger) {
+				logger.Info("downloading video")
+			},
+			want: "",
+		},
+		{
+			name: "attributes and groups",
+			log: func(logger *slog.Logger) {
+				logger.With("run", 2).WithGroup("http").Warn("slow", "status", 429)
+			},
+			want: "Warning: slow run=2 http.status=429\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			tt.log(slog.New(newConsoleHandler(&out, slog.LevelWarn)))
+
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
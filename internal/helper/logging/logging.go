@@ -0,0 +1,117 @@
+// Package logging configures the structured logger used for diagnostic output.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+const (
+	// Verbosity levels as given by the number of -v flags.
+	verbosityInfo  = 1
+	verbosityDebug = 2
+
+	// logFilePermissions restricts the log file to the current user, as it
+	// contains the URLs of all requested videos.
+	logFilePermissions = 0o600
+)
+
+var errFailedToOpenLogFile = errors.New("failed to open log file")
+
+// multiHandler passes every record to all handlers that are enabled for its
+// level.
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+// Setup installs the default logger writing warnings, errors and diagnostics
+// to stderr. The verbosity is the
+// number of -v flags: warnings are always logged, -v adds download milestones
+// and -vv every HTTP request. If logFile is not empty, all records including
+// debug ones are additionally appended to that file, which stays open until
+// the process exits.
+func Setup(verbosity int, logFile string) error {
+	handlers := []slog.Handler{newConsoleHandler(os.Stderr, Level(verbosity))}
+
+	if logFile != "" {
+		file, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, logFilePermissions)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errFailedToOpenLogFile, err)
+		}
+
+		handlers = append(handlers, newHandler(file, slog.LevelDebug))
+	}
+
+	slog.SetDefault(slog.New(&multiHandler{handlers: handlers}))
+
+	return nil
+}
+
+// Level returns the minimum log level for the given verbosity.
+func Level(verbosity int) slog.Level {
+	switch {
+	case verbosity >= verbosityDebug:
+		return slog.LevelDebug
+	case verbosity == verbosityInfo:
+		return slog.LevelInfo
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// newHandler creates a text handler writing records of at least level to w.
+func newHandler(w io.Writer, level slog.Level) slog.Handler {
+	return slog.NewTextHandler(w, &slog.HandlerOptions{
+		AddSource:   false,
+		Level:       level,
+		ReplaceAttr: nil,
+	})
+}
+
+// Enabled reports whether any handler handles records of level.
+func (h *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handle passes record to every handler enabled for its level.
+func (h *multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a handler adding attrs to the records of all handlers.
+func (h *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+
+	return &multiHandler{handlers: handlers}
+}
+
+// WithGroup returns a handler starting the group name in all handlers.
+func (h *multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+
+	return &multiHandler{handlers: handlers}
+}
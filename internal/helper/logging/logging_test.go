@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		verbosity int
+		want      slog.Level
+	}{
+		{verbosity: 0, want: slog.LevelWarn},
+		{verbosity: 1, want: slog.LevelInfo},
+		{verbosity: 2, want: slog.LevelDebug},
+		{verbosity: 5, want: slog.LevelDebug},
+	}
+
+	for _, tt := range tests {
+		if got := Level(tt.verbosity); got != tt.want {
+			t.Errorf("Level(%d) = %v, want %v", tt.verbosity, got, tt.want)
+		}
+	}
+}
+
+func TestMultiHandler(t *testing.T) {
+	var console, file bytes.Buffer
+
+	logger := slog.New(&multiHandler{handlers: []slog.Handler{
+		newHandler(&console, slog.LevelWarn),
+		newHandler(&file, slog.LevelDebug),
+	}}).With("run", 1)
+
+	logger.Debug("http request")
+	logger.Warn("disk almost full")
+
+	if strings.Contains(console.String(), "http request") {
+		t.Errorf("console log %q contains debug record", console.String())
+	}
+
+	if !strings.Contains(console.String(), "disk almost full") {
+		t.Errorf("console log %q is missing warning", console.String())
+	}
+
+	for _, want := range []string{"http request", "disk almost full", "run=1"} {
+		if !strings.Contains(file.String(), want) {
+			t.Errorf("file log %q does not contain %q", file.String(), want)
+		}
+	}
+}
+
+func TestSetupLogFile(t *testing.T) {
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+
+	logFile := filepath.Join(t.TempDir(), "switchtube.log")
+
+	if err := Setup(0, logFile); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	slog.Debug("http request", "status", 200)
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+
+	if !strings.Contains(string(data), "status=200") {
+		t.Errorf("log file %q does not contain debug record", data)
+	}
+
+	if err := Setup(0, filepath.Join(logFile, "invalid")); !errors.Is(err, errFailedToOpenLogFile) {
+		t.Errorf("Setup() error = %v, want %v", err, errFailedToOpenLogFile)
+	}
+}
@@ -0,0 +1,405 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// defaultBrowseRows is the number of list rows shown before the terminal
+	// size is known.
+	defaultBrowseRows = 20
+
+	// browseChromeRows are the rows used by the header and the key help.
+	browseChromeRows = 4
+
+	// browseDetailsRows are the rows used by the details of a video.
+	browseDetailsRows = 5
+)
+
+var errFailedToBrowse = errors.New("failed to run browser")
+
+// VideoLoader loads the videos of the channel with the given id.
+type VideoLoader func(channelID string) ([]models.Video, error)
+
+// browseView is the list currently shown by the browser.
+type browseView int
+
+const (
+	channelView browseView = iota
+	videoView
+)
+
+// videosLoadedMsg is sent when the videos of a channel have been loaded.
+type videosLoadedMsg struct {
+	channelID string
+	videos    []models.Video
+	err       error
+}
+
+// browser is the model of the interactive channel browser.
+type browser struct {
+	channels []models.Channel
+	load     VideoLoader
+
+	videos map[string][]models.Video
+	marked map[string]map[string]bool
+	errs   map[string]error
+
+	view          browseView
+	channelCursor int
+	videoCursor   int
+	details       bool
+	rows          int
+	confirmed     bool
+}
+
+// Browse lets the user navigate channels, inspect videos and mark them with
+// space in a full-screen terminal UI. It returns the marked videos of every
+// channel once the user starts the download, or nil if the user quits.
+func Browse(channels []models.Channel, load VideoLoader) ([]models.ChannelSelection, error) {
+	if !inputAllowed {
+		return nil, ErrInputDisabled
+	}
+
+	model, err := tea.NewProgram(
+		newBrowser(channels, load),
+		tea.WithAltScreen(),
+		tea.WithOutput(os.Stderr),
+	).Run()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToBrowse, err)
+	}
+
+	result, ok := model.(*browser)
+	if !ok || !result.confirmed {
+		return nil, nil
+	}
+
+	return result.selections(), nil
+}
+
+// newBrowser creates a browser for channels. A single channel is opened
+// right away.
+func newBrowser(channels []models.Channel, load VideoLoader) *browser {
+	b := &browser{
+		channels:      channels,
+		load:          load,
+		videos:        make(map[string][]models.Video),
+		marked:        make(map[string]map[string]bool),
+		errs:          make(map[string]error),
+		view:          channelView,
+		channelCursor: 0,
+		videoCursor:   0,
+		details:       false,
+		rows:          defaultBrowseRows,
+		confirmed:     false,
+	}
+
+	if len(channels) == 1 {
+		b.view = videoView
+	}
+
+	return b
+}
+
+// Init starts loading the videos if a single channel is browsed.
+func (b *browser) Init() tea.Cmd {
+	if b.view == videoView {
+		return b.loadVideos()
+	}
+
+	return nil
+}
+
+// Update handles key presses, terminal resizes and loaded videos.
+func (b *browser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.rows = max(1, msg.Height-browseChromeRows)
+	case videosLoadedMsg:
+		if msg.err != nil {
+			b.errs[msg.channelID] = msg.err
+		} else {
+			b.videos[msg.channelID] = msg.videos
+		}
+	case tea.KeyMsg:
+		return b, b.handleKey(msg.String())
+	}
+
+	return b, nil
+}
+
+// handleKey applies the action bound to key.
+func (b *browser) handleKey(key string) tea.Cmd {
+	switch key {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "d":
+		b.confirmed = true
+
+		return tea.Quit
+	case "up", "k":
+		b.moveCursor(-1)
+	case "down", "j":
+		b.moveCursor(1)
+	case "enter", "right", "l":
+		if b.view == channelView && len(b.channels) > 0 {
+			b.view = videoView
+			b.videoCursor = 0
+
+			return b.loadVideos()
+		}
+
+		b.details = !b.details
+	case "i":
+		b.details = !b.details
+	case "esc", "left", "h", "backspace":
+		if len(b.channels) > 1 {
+			b.view = channelView
+		}
+	case " ":
+		b.toggleMark()
+	case "a":
+		b.toggleAll()
+	}
+
+	return nil
+}
+
+// loadVideos returns a command loading the videos of the current channel if
+// they are not loaded yet.
+func (b *browser) loadVideos() tea.Cmd {
+	channelID := b.channels[b.channelCursor].ID
+	if _, ok := b.videos[channelID]; ok {
+		return nil
+	}
+
+	delete(b.errs, channelID)
+
+	return func() tea.Msg {
+		videos, err := b.load(channelID)
+
+		return videosLoadedMsg{channelID: channelID, videos: videos, err: err}
+	}
+}
+
+// moveCursor moves the cursor of the current list by delta, staying within
+// the list.
+func (b *browser) moveCursor(delta int) {
+	if b.view == channelView {
+		b.channelCursor = clamp(b.channelCursor+delta, len(b.channels))
+	} else {
+		b.videoCursor = clamp(b.videoCursor+delta, len(b.currentVideos()))
+	}
+}
+
+// clamp limits index to the range of a list with n items.
+func clamp(index, n int) int {
+	return max(0, min(index, n-1))
+}
+
+// toggleMark marks or unmarks the video under the cursor.
+func (b *browser) toggleMark() {
+	videos := b.currentVideos()
+	if b.view != videoView || len(videos) == 0 {
+		return
+	}
+
+	marked := b.channelMarks()
+	id := videos[b.videoCursor].ID
+	marked[id] = !marked[id]
+}
+
+// toggleAll marks all videos of the current channel, or unmarks them if all
+// are marked already.
+func (b *browser) toggleAll() {
+	if b.view != videoView {
+		return
+	}
+
+	videos := b.currentVideos()
+	marked := b.channelMarks()
+	all := b.markedCount(b.channels[b.channelCursor].ID) == len(videos)
+
+	for _, video := range videos {
+		marked[video.ID] = !all
+	}
+}
+
+// channelMarks returns the marks of the current channel.
+func (b *browser) channelMarks() map[string]bool {
+	channelID := b.channels[b.channelCursor].ID
+	if b.marked[channelID] == nil {
+		b.marked[channelID] = make(map[string]bool)
+	}
+
+	return b.marked[channelID]
+}
+
+// markedCount returns the number of marked videos of a channel.
+func (b *browser) markedCount(channelID string) int {
+	count := 0
+
+	for _, marked := range b.marked[channelID] {
+		if marked {
+			count++
+		}
+	}
+
+	return count
+}
+
+// currentVideos returns the videos of the current channel, which are empty
+// while they are loading.
+func (b *browser) currentVideos() []models.Video {
+	if len(b.channels) == 0 {
+		return nil
+	}
+
+	return b.videos[b.channels[b.channelCursor].ID]
+}
+
+// selections returns the marked videos grouped by channel, in list order.
+func (b *browser) selections() []models.ChannelSelection {
+	var selections []models.ChannelSelection
+
+	for _, channel := range b.channels {
+		var videos []models.Video
+
+		for _, video := range b.videos[channel.ID] {
+			if b.marked[channel.ID][video.ID] {
+				videos = append(videos, video)
+			}
+		}
+
+		if len(videos) > 0 {
+			selections = append(selections, models.ChannelSelection{
+				Profile: "",
+				Channel: channel,
+				Videos:  videos,
+			})
+		}
+	}
+
+	return selections
+}
+
+// View renders the current list, the details of the video under the cursor
+// and the key help.
+func (b *browser) View() string {
+	var view strings.Builder
+
+	if b.view == channelView {
+		b.renderChannels(&view)
+	} else {
+		b.renderVideos(&view)
+	}
+
+	view.WriteString("\n")
+
+	if b.view == channelView {
+		view.WriteString("↑/↓ move • enter open • d download marked • q quit\n")
+	} else {
+		view.WriteString("↑/↓ move • space mark • a mark all • i details • " +
+			"d download marked • q quit")
+
+		if len(b.channels) > 1 {
+			view.WriteString(" • esc back")
+		}
+
+		view.WriteString("\n")
+	}
+
+	return view.String()
+}
+
+// renderChannels renders the channel list.
+func (b *browser) renderChannels(view *strings.Builder) {
+	fmt.Fprintf(view, "Channels (%d)\n\n", len(b.channels))
+
+	start, end := visibleRange(b.channelCursor, len(b.channels), b.rows)
+	for i := start; i < end; i++ {
+		channel := b.channels[i]
+
+		marks := ""
+		if count := b.markedCount(channel.ID); count > 0 {
+			marks = fmt.Sprintf(" (%d marked)", count)
+		}
+
+		fmt.Fprintf(view, "%s %s%s\n", cursor(i == b.channelCursor), channel.Name, marks)
+	}
+}
+
+// renderVideos renders the video list of the current channel.
+func (b *browser) renderVideos(view *strings.Builder) {
+	channel := b.channels[b.channelCursor]
+	videos := b.currentVideos()
+
+	if err := b.errs[channel.ID]; err != nil {
+		fmt.Fprintf(view, "%s\n\nError: %v\n", channel.Name, err)
+
+		return
+	}
+
+	if _, ok := b.videos[channel.ID]; !ok {
+		fmt.Fprintf(view, "%s\n\nLoading videos...\n", channel.Name)
+
+		return
+	}
+
+	fmt.Fprintf(view, "%s (%d videos, %d marked)\n\n",
+		channel.Name, len(videos), b.markedCount(channel.ID))
+
+	rows := b.rows
+	if b.details {
+		rows = max(1, rows-browseDetailsRows)
+	}
+
+	marked := b.marked[channel.ID]
+
+	start, end := visibleRange(b.videoCursor, len(videos), rows)
+	for i := start; i < end; i++ {
+		mark := "[ ]"
+		if marked[videos[i].ID] {
+			mark = "[x]"
+		}
+
+		fmt.Fprintf(view, "%s %s %s\n", cursor(i == b.videoCursor), mark, videos[i].Title)
+	}
+
+	if b.details && len(videos) > 0 {
+		video := videos[b.videoCursor]
+		fmt.Fprintf(view, "\nTitle:    %s\n", video.Title)
+		fmt.Fprintf(view, "ID:       %s\n", video.ID)
+		fmt.Fprintf(view, "Episode:  %s\n", orDash(video.Episode))
+		fmt.Fprintf(view, "Duration: %s\n", FormatDuration(video.Duration))
+	}
+}
+
+// visibleRange returns the range of a list with n items to show in rows
+// rows so that the cursor stays visible.
+func visibleRange(cursor, n, rows int) (int, int) {
+	if n <= rows {
+		return 0, n
+	}
+
+	start := max(0, min(cursor-rows/2, n-rows))
+
+	return start, start + rows
+}
+
+// cursor returns the cursor marker of a list row.
+func cursor(active bool) string {
+	if active {
+		return ">"
+	}
+
+	return " "
+}
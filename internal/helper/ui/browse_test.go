@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"switchtube-downloader/internal/models"
+)
+
+var errLoadFailed = errors.New("load failed")
+
+// press sends the keys to b, running any returned command that loads videos.
+func press(b *browser, keys ...string) {
+	for _, key := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key), Alt: false, Paste: false}
+
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter, Runes: nil, Alt: false, Paste: false}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc, Runes: nil, Alt: false, Paste: false}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" "), Alt: false, Paste: false}
+		}
+
+		_, cmd := b.Update(msg)
+		if cmd == nil {
+			continue
+		}
+
+		if loaded, ok := cmd().(videosLoadedMsg); ok {
+			b.Update(loaded)
+		}
+	}
+}
+
+func TestBrowse(t *testing.T) {
+	channels := []models.Channel{{ID: "c1", Name: "Channel 1"}, {ID: "c2", Name: "Channel 2"}}
+	videos := map[string][]models.Video{
+		"c1": {{ID: "v1", Title: "Intro"}, {ID: "v2", Title: "Mapping"}, {ID: "v3", Title: "Paging"}},
+		"c2": {{ID: "v4", Title: "Sockets"}},
+	}
+
+	load := func(channelID string) ([]models.Video, error) {
+		return videos[channelID], nil
+	}
+
+	tests := []struct {
+		name          string
+		channels      []models.Channel
+		keys          []string
+		wantConfirmed bool
+		want          map[string][]string
+	}{
+		{
+			name:          "mark videos of one channel",
+			channels:      channels,
+			keys:          []string{"enter", " ", "j", "j", " ", "d"},
+			wantConfirmed: true,
+			want:          map[string][]string{"c1": {"v1", "v3"}},
+		},
+		{
+			name:          "mark videos of several channels",
+			channels:      channels,
+			keys:          []string{"enter", "j", " ", "esc", "j", "enter", "a", "d"},
+			wantConfirmed: true,
+			want:          map[string][]string{"c1": {"v2"}, "c2": {"v4"}},
+		},
+		{
+			name:          "unmark video",
+			channels:      channels,
+			keys:          []string{"enter", " ", " ", "j", " ", "d"},
+			wantConfirmed: true,
+			want:          map[string][]string{"c1": {"v2"}},
+		},
+		{
+			name:          "toggle all twice",
+			channels:      channels,
+			keys:          []string{"enter", "a", "a", "d"},
+			wantConfirmed: true,
+			want:          map[string][]string{},
+		},
+		{
+			name:          "single channel opens right away",
+			channels:      channels[1:],
+			keys:          []string{" ", "esc", "d"},
+			wantConfirmed: true,
+			want:          map[string][]string{"c2": {"v4"}},
+		},
+		{
+			name:          "cursor stays in list",
+			channels:      channels,
+			keys:          []string{"k", "enter", "j", "j", "j", "j", " ", "d"},
+			wantConfirmed: true,
+			want:          map[string][]string{"c1": {"v3"}},
+		},
+		{
+			name:          "quit without download",
+			channels:      channels,
+			keys:          []string{"enter", " ", "q"},
+			wantConfirmed: false,
+			want:          map[string][]string{"c1": {"v1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBrowser(tt.channels, load)
+			if cmd := b.Init(); cmd != nil {
+				b.Update(cmd())
+			}
+
+			press(b, tt.keys...)
+
+			if b.confirmed != tt.wantConfirmed {
+				t.Errorf("confirmed = %v, want %v", b.confirmed, tt.wantConfirmed)
+			}
+
+			got := make(map[string][]string)
+
+			for _, selection := range b.selections() {
+				for _, video := range selection.Videos {
+					got[selection.Channel.ID] = append(got[selection.Channel.ID], video.ID)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("selections = %v, want %v", got, tt.want)
+			}
+
+			for channelID, ids := range tt.want {
+				if strings.Join(got[channelID], ",") != strings.Join(ids, ",") {
+					t.Errorf("selections[%s] = %v, want %v", channelID, got[channelID], ids)
+				}
+			}
+		})
+	}
+}
+
+func TestBrowseLoadError(t *testing.T) {
+	load := func(string) ([]models.Video, error) {
+		return nil, errLoadFailed
+	}
+
+	b := newBrowser([]models.Channel{{ID: "c1", Name: "Channel 1"}}, load)
+	b.Update(b.Init()())
+
+	press(b, " ")
+
+	if view := b.View(); !strings.Contains(view, errLoadFailed.Error()) {
+		t.Errorf("View() = %q, want error %q", view, errLoadFailed)
+	}
+
+	if selections := b.selections(); len(selections) != 0 {
+		t.Errorf("selections() = %v, want none", selections)
+	}
+}
+
+func TestBrowseView(t *testing.T) {
+	videos := []models.Video{
+		{ID: "v1", Title: "Intro", Episode: "01", Duration: 90},
+		{ID: "v2", Title: "Mapping", Episode: "02", Duration: 125},
+	}
+
+	b := newBrowser([]models.Channel{{ID: "c1", Name: "Channel 1"}}, nil)
+	b.Update(videosLoadedMsg{channelID: "c1", videos: videos, err: nil})
+
+	press(b, "j", " ", "i")
+
+	view := b.View()
+	for _, want := range []string{
+		"Channel 1 (2 videos, 1 marked)",
+		"  [ ] Intro",
+		"> [x] Mapping",
+		"Episode:  02",
+		"Duration: 2:05",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() = %q, want it to contain %q", view, want)
+		}
+	}
+}
+
+func TestVisibleRange(t *testing.T) {
+	tests := []struct {
+		cursor, n, rows int
+		wantStart       int
+		wantEnd         int
+	}{
+		{cursor: 0, n: 5, rows: 10, wantStart: 0, wantEnd: 5},
+		{cursor: 0, n: 50, rows: 10, wantStart: 0, wantEnd: 10},
+		{cursor: 25, n: 50, rows: 10, wantStart: 20, wantEnd: 30},
+		{cursor: 49, n: 50, rows: 10, wantStart: 40, wantEnd: 50},
+	}
+
+	for _, tt := range tests {
+		start, end := visibleRange(tt.cursor, tt.n, tt.rows)
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("visibleRange(%d, %d, %d) = %d, %d, want %d, %d",
+				tt.cursor, tt.n, tt.rows, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
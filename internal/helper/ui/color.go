@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences of the colors used in the output.
+const (
+	colorReset = "\x1b[0m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+)
+
+// colorAllowed is false once colors have been turned off with SetColor.
+var colorAllowed = true
+
+// isTerminal reports whether file is a terminal, which tests replace.
+var isTerminal = func(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// SetColor turns colored output on or off. Colors are off regardless of
+// enabled if the NO_COLOR environment variable is set, see
+// https://no-color.org.
+func SetColor(enabled bool) {
+	colorAllowed = enabled && os.Getenv("NO_COLOR") == ""
+}
+
+// colorFor reports whether output written to file is colored, which requires
+// file to be a terminal.
+func colorFor(file *os.File) bool {
+	return colorAllowed && isTerminal(file)
+}
+
+// colorize returns text in color if enabled is set.
+func colorize(text, color string, enabled bool) string {
+	if !enabled || text == "" {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+// Success returns text colored green for stdout, e.g. for a summary of
+// downloads that all succeeded.
+func Success(text string) string {
+	return colorize(text, colorGreen, colorFor(os.Stdout))
+}
+
+// Failure returns text colored red for stdout, e.g. for failed downloads.
+func Failure(text string) string {
+	return colorize(text, colorRed, colorFor(os.Stdout))
+}
+
+// Outcome returns text colored as a Success if ok is set and as a Failure
+// otherwise.
+func Outcome(text string, ok bool) string {
+	if ok {
+		return Success(text)
+	}
+
+	return Failure(text)
+}
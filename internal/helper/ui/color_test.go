@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"os"
+	"testing"
+)
+
+// fakeTerminal makes every file look like a terminal, or none if terminal
+// isn't set.
+func fakeTerminal(t *testing.T, terminal bool) {
+	t.Helper()
+
+	original := isTerminal
+	isTerminal = func(*os.File) bool { return terminal }
+
+	t.Cleanup(func() {
+		isTerminal = original
+		colorAllowed = true
+	})
+}
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal bool
+		noColor  string
+		enabled  bool
+		ok       bool
+		want     string
+	}{
+		{name: "success", terminal: true, enabled: true, ok: true, want: "\x1b[32mdone\x1b[0m"},
+		{name: "failure", terminal: true, enabled: true, ok: false, want: "\x1b[31mdone\x1b[0m"},
+		{name: "no terminal", terminal: false, enabled: true, ok: true, want: "done"},
+		{name: "NO_COLOR", terminal: true, noColor: "1", enabled: true, ok: true, want: "done"},
+		{name: "--no-color", terminal: true, enabled: false, ok: true, want: "done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeTerminal(t, tt.terminal)
+			t.Setenv("NO_COLOR", tt.noColor)
+			SetColor(tt.enabled)
+
+			if got := Outcome("done", tt.ok); got != tt.want {
+				t.Errorf("Outcome() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// copyBufferSize is the size of the buffers downloads are copied with. Large
+// buffers mean fewer reads and writes, and fewer progress updates, for every
+// video.
+const copyBufferSize = 1 << 20
+
+// copyBuffers holds the buffers of copyBuffered, so that downloading many
+// videos doesn't allocate a new buffer for each of them.
+var copyBuffers = sync.Pool{
+	New: func() any {
+		buffer := make([]byte, copyBufferSize)
+
+		return &buffer
+	},
+}
+
+// copyBuffered copies src to dst like io.Copy, but with a pooled buffer of
+// copyBufferSize bytes.
+func copyBuffered(dst io.Writer, src io.Reader) (int64, error) {
+	buffer, ok := copyBuffers.Get().(*[]byte)
+	if !ok {
+		return 0, errFailedToCopyData
+	}
+	defer copyBuffers.Put(buffer)
+
+	// Hiding io.ReaderFrom of files makes io.CopyBuffer use the buffer
+	// instead of the small one of os.File.ReadFrom.
+	written, err := io.CopyBuffer(struct{ io.Writer }{dst}, src, *buffer)
+	if err != nil {
+		return written, fmt.Errorf("%w", err)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyBuffered(t *testing.T) {
+	data := bytes.Repeat([]byte("video data"), copyBufferSize/4)
+
+	var dst bytes.Buffer
+
+	written, err := copyBuffered(&dst, bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("copyBuffered() error = %v", err)
+	}
+
+	if written != int64(len(data)) || !bytes.Equal(dst.Bytes(), data) {
+		t.Errorf("copyBuffered() copied %d bytes, want %d", written, len(data))
+	}
+}
+
+// benchmarkCopy measures copying 64 MiB from a reader without io.WriterTo to
+// a file, like a download does.
+func benchmarkCopy(b *testing.B, copyFn func(io.Writer, io.Reader) (int64, error)) {
+	b.Helper()
+
+	data := make([]byte, 64<<20)
+
+	file, err := os.Create(filepath.Join(b.TempDir(), "video.mp4"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer file.Close()
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := copyFn(file, struct{ io.Reader }{bytes.NewReader(data)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCopy(b *testing.B) {
+	benchmarkCopy(b, io.Copy)
+}
+
+func BenchmarkCopyBuffered(b *testing.B) {
+	benchmarkCopy(b, copyBuffered)
+}
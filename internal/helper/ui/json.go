@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var errFailedToEncodeJSON = errors.New("failed to encode JSON")
+
+// PrintJSON prints v to stdout as JSON. All JSON output goes through it, so
+// that every result is a single line and the results of several downloads
+// can be read line by line.
+func PrintJSON(v any) error {
+	return writeJSON(os.Stdout, v)
+}
+
+// writeJSON writes v to w as a single line of JSON.
+func writeJSON(w io.Writer, v any) error {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToEncodeJSON, err)
+	}
+
+	return nil
+}
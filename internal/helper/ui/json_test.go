@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+
+	for _, v := range []any{map[string]string{"title": "A & B"}, []int{1, 2}} {
+		if err := writeJSON(&out, v); err != nil {
+			t.Fatalf("writeJSON() error = %v", err)
+		}
+	}
+
+	if want := "{\"title\":\"A \\u0026 B\"}\n[1,2]\n"; out.String() != want {
+		t.Errorf("writeJSON() = %q, want %q", out.String(), want)
+	}
+
+	if err := writeJSON(&out, func() {}); !errors.Is(err, errFailedToEncodeJSON) {
+		t.Errorf("writeJSON() of a function error = %v, want %v", err, errFailedToEncodeJSON)
+	}
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+const (
+	// selectorChromeRows are the terminal rows used by the selector besides
+	// the items: header, page info and prompts.
+	selectorChromeRows = 6
+
+	// minPageSize is the smallest number of items shown per page.
+	minPageSize = 5
+)
+
+// terminalHeight returns the number of rows of the terminal stderr, which
+// prompts are shown on, is connected to, or 0 if it isn't a terminal.
+var terminalHeight = func() int {
+	_, height, err := term.GetSize(int(os.Stderr.Fd()))
+	if err != nil {
+		return 0
+	}
+
+	return height
+}
+
+// pager shows a list of items page by page so that it fits into the
+// terminal.
+type pager struct {
+	noun   string
+	header string
+	items  []string
+	shown  []int
+	page   int
+	size   int
+}
+
+// newPager creates a pager showing all items. The page size is derived from
+// the terminal height; without a terminal, all items are shown at once.
+func newPager(noun string, items []string) *pager {
+	size := len(items)
+	if height := terminalHeight(); height > 0 {
+		size = max(minPageSize, height-selectorChromeRows)
+	}
+
+	return &pager{
+		noun:   noun,
+		header: "Available " + noun,
+		items:  items,
+		shown:  allIndices(len(items)),
+		page:   0,
+		size:   size,
+	}
+}
+
+// paged reports whether the shown items need more than one page.
+func (p *pager) paged() bool {
+	return len(p.shown) > p.size
+}
+
+// show replaces the shown items by the items at indices, which are described
+// by header, and goes to the first page.
+func (p *pager) show(header string, indices []int) {
+	p.header = header
+	p.shown = indices
+	p.page = 0
+}
+
+// turn moves delta pages forward or backward and reports whether the page
+// changed.
+func (p *pager) turn(delta int) bool {
+	pages := (len(p.shown) + p.size - 1) / p.size
+	page := max(0, min(p.page+delta, pages-1))
+
+	if page == p.page {
+		return false
+	}
+
+	p.page = page
+
+	return true
+}
+
+// print prints the current page with the numbers of the items in the full
+// list, followed by the page position if there are several.
+func (p *pager) print() {
+	fmt.Fprintf(os.Stderr, "\n%s:\n", p.header)
+
+	start := min(p.page*p.size, len(p.shown))
+	end := min(start+p.size, len(p.shown))
+
+	for _, index := range p.shown[start:end] {
+		fmt.Fprintf(os.Stderr, "%d. %s\n", index+1, p.items[index])
+	}
+
+	if p.paged() {
+		fmt.Fprintf(os.Stderr, "Showing %d–%d of %d %s, press n/p for the next/previous page\n",
+			start+1, end, len(p.shown), p.noun)
+	}
+}
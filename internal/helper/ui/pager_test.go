@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureSelect runs Select with input on stdin and returns its result and
+// output.
+func captureSelect(t *testing.T, items []string, input string) ([]int, string) {
+	t.Helper()
+
+	tmpFile, err := os.CreateTemp(t.TempDir(), "test-input")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, err = tmpFile.WriteString(input); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+
+	if _, err = tmpFile.Seek(0, 0); err != nil {
+		t.Fatalf("Failed to seek temp file: %v", err)
+	}
+
+	oldStdin, oldStderr := os.Stdin, os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stdin, os.Stderr = tmpFile, w
+
+	defer func() { os.Stdin, os.Stderr = oldStdin, oldStderr }()
+
+	result, err := Select("videos", items, false)
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+
+	w.Close()
+
+	output, _ := io.ReadAll(r)
+
+	return result, string(output)
+}
+
+func TestSelectPaging(t *testing.T) {
+	oldHeight := terminalHeight
+	terminalHeight = func() int { return selectorChromeRows + minPageSize }
+
+	defer func() { terminalHeight = oldHeight }()
+
+	items := make([]string, 12)
+	for i := range items {
+		items[i] = fmt.Sprintf("Video%d", i+1)
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		want      []int
+		wantPages []string
+		notWant   string
+	}{
+		{
+			name:      "first page only",
+			input:     "1\n",
+			want:      []int{0},
+			wantPages: []string{"Showing 1–5 of 12 videos"},
+			notWant:   "6. Video6",
+		},
+		{
+			name:  "next pages stop at the last page",
+			input: "n\nn\nn\n12\n",
+			want:  []int{11},
+			wantPages: []string{
+				"Showing 1–5 of 12 videos",
+				"6. Video6\n",
+				"Showing 6–10 of 12 videos",
+				"11. Video11\n12. Video12\nShowing 11–12 of 12 videos",
+			},
+			notWant: "",
+		},
+		{
+			name:      "previous page",
+			input:     "n\np\n2\n",
+			want:      []int{1},
+			wantPages: []string{"Showing 6–10 of 12 videos", "Showing 1–5 of 12 videos"},
+			notWant:   "",
+		},
+		{
+			name:      "select all of a filtered list",
+			input:     "video1\n\n",
+			want:      []int{0, 9, 10, 11},
+			wantPages: []string{"Videos matching 'video1':\n1. Video1\n10. Video10"},
+			notWant:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, output := captureSelect(t, items, tt.input)
+
+			if !equalIntSlices(result, tt.want) {
+				t.Errorf("Select() = %v, want %v", result, tt.want)
+			}
+
+			for _, want := range tt.wantPages {
+				if !strings.Contains(output, want) {
+					t.Errorf("Select() output = %q, want it to contain %q", output, want)
+				}
+			}
+
+			if tt.notWant != "" && strings.Contains(output, tt.notWant) {
+				t.Errorf("Select() output = %q, want it not to contain %q", output, tt.notWant)
+			}
+		})
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/vbauerster/mpb/v8"
 	"github.com/vbauerster/mpb/v8/decor"
+
+	"switchtube-downloader/internal/models"
 )
 
 const (
@@ -20,51 +24,106 @@ const (
 var errFailedToCopyData = errors.New("failed to copy data")
 
 // ProgressBar sets up a progress bar for downloading and copies data from
-// src to dst.
+// src to dst. If progress has a total size, a second bar below shows the
+// overall progress of all items. style is one of the
+// models.ProgressStyle* constants. The progress is shown in the terminal
+// title as well.
 func ProgressBar(
 	src io.Reader,
 	dst io.Writer,
 	total int64,
-	filename string,
-	currentItem, totalItems int,
+	filename, style string,
+	progress models.ProgressInfo,
 ) error {
+	r := rendererFor(style)
+
+	meter := newSpeedMeter(time.Now())
+	src = &speedReader{reader: src, meter: meter}
+
+	if titleFor(os.Stderr) {
+		title := newTitleReader(src, os.Stderr, total, progress)
+		defer title.restore()
+
+		src = title
+	}
+
 	p := mpb.New(
 		mpb.WithWidth(progressBarWidth),
 		mpb.WithRefreshRate(refreshRateMs*time.Millisecond),
+		mpb.WithOutput(os.Stderr),
 	)
 
-	bar := p.New(total,
-		mpb.BarStyle().Rbound("|"),
-		mpb.PrependDecorators(
-			decor.Name(
-				fmt.Sprintf("[%d/%d] %s ", currentItem, totalItems, filepath.Base(filename)),
-			),
-			decor.Counters(decor.SizeB1024(0), "% .2f / % .2f"),
-		),
-		mpb.AppendDecorators(
-			decor.EwmaETA(decor.ET_STYLE_GO, etaSmoothingFactor),
-			decor.Name(" ] "),
-			decor.EwmaSpeed(decor.SizeB1024(0), "% .2f", etaSmoothingFactor),
-		),
-	)
+	name := fmt.Sprintf("[%d/%d] %s",
+		progress.CurrentItem,
+		progress.TotalItems,
+		filepath.Base(filename))
+	lines := newBarLines()
+	bar := p.New(total, lines.measure(r), r.videoDecorators(name, meter)...)
+
+	var overall *mpb.Bar
+	if progress.TotalBytes > 0 {
+		overall = newOverallBar(p, lines.measure(r), r, progress)
+		src = overall.ProxyReader(src)
+	}
 
 	proxyReader := bar.ProxyReader(src)
 
 	defer func() {
 		if err := proxyReader.Close(); err != nil {
-			fmt.Printf("error waiting for progress bar: %v\n", err)
+			slog.Warn("failed to wait for progress bar", "error", err)
 		}
 	}()
 
+	stopWatching := watchResize(func() { lines.resized(p) })
+	defer stopWatching()
+
 	start := time.Now()
 
-	if _, err := io.Copy(dst, proxyReader); err != nil {
+	if _, err := copyBuffered(dst, proxyReader); err != nil {
 		return fmt.Errorf("%w: %w", errFailedToCopyData, err)
 	}
 
 	bar.EwmaIncrInt64(total, time.Since(start))
 
+	if overall != nil {
+		if progress.CurrentItem < progress.TotalItems {
+			// The next item shows the overall bar again
+			overall.Abort(true)
+		} else {
+			overall.SetTotal(-1, true)
+		}
+	}
+
 	p.Wait()
 
 	return nil
 }
+
+// newOverallBar adds a bar drawn with filler and the decorators of r to p
+// showing the total size, percentage and ETA of all items. Averages are based
+// on the start time of the first item.
+func newOverallBar(
+	p *mpb.Progress,
+	filler mpb.BarFillerBuilder,
+	r renderer,
+	progress models.ProgressInfo,
+) *mpb.Bar {
+	overall := p.New(progress.TotalBytes, filler, r.totalDecorators()...)
+
+	overall.SetCurrent(progress.DownloadedBytes)
+	overall.DecoratorAverageAdjust(progress.StartTime)
+
+	return overall
+}
+
+// speedText formats the current and the average speed of meter, e.g.
+// "2.50 MiB/s (avg 1.80 MiB/s)".
+func speedText(meter *speedMeter) string {
+	now := time.Now()
+
+	current := fmt.Sprintf("% .2f/s", decor.SizeB1024(int64(meter.current(now))))
+
+	return fmt.Sprintf("%s (avg % .2f/s)",
+		colorize(current, colorGreen, colorFor(os.Stderr)),
+		decor.SizeB1024(int64(meter.average(now))))
+}
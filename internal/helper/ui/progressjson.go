@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+// progressEventInterval is the minimum time between two progress events while
+// downloading.
+const progressEventInterval = time.Second
+
+// eventProgress passes progress events of a single download to report.
+type eventProgress struct {
+	report   func(event models.ProgressEvent)
+	event    models.ProgressEvent
+	speed    *speedMeter
+	lastEmit time.Time
+}
+
+// eventProgressReader counts the bytes read from the underlying reader and
+// emits progress events.
+type eventProgressReader struct {
+	reader   io.Reader
+	progress *eventProgress
+}
+
+// ProgressJSON copies data from src to dst like ProgressBar, but writes
+// newline-delimited JSON progress events to stdout instead of drawing a bar,
+// so scripts and GUIs can follow the download.
+func ProgressJSON(
+	src io.Reader,
+	dst io.Writer,
+	total int64,
+	videoID, filename string,
+	progress models.ProgressInfo,
+) error {
+	return copyWithJSONProgress(os.Stdout, src, dst, total, videoID, filename, progress)
+}
+
+// ProgressReport copies data from src to dst like ProgressBar, but passes
+// progress events to reporter instead of drawing a bar.
+func ProgressReport(
+	src io.Reader,
+	dst io.Writer,
+	total int64,
+	videoID, filename string,
+	progress models.ProgressInfo,
+	reporter models.ProgressReporter,
+) error {
+	return copyWithProgress(reporter.Report, src, dst, total, videoID, filename, progress)
+}
+
+// copyWithJSONProgress implements ProgressJSON writing the events to out.
+func copyWithJSONProgress(
+	out io.Writer,
+	src io.Reader,
+	dst io.Writer,
+	total int64,
+	videoID, filename string,
+	progress models.ProgressInfo,
+) error {
+	encoder := json.NewEncoder(out)
+	report := func(event models.ProgressEvent) {
+		if err := encoder.Encode(event); err != nil {
+			slog.Warn("failed to write progress", "error", err)
+		}
+	}
+
+	return copyWithProgress(report, src, dst, total, videoID, filename, progress)
+}
+
+// copyWithProgress copies data from src to dst and passes progress events to
+// report.
+func copyWithProgress(
+	report func(event models.ProgressEvent),
+	src io.Reader,
+	dst io.Writer,
+	total int64,
+	videoID, filename string,
+	progress models.ProgressInfo,
+) error {
+	now := time.Now()
+	reporter := &eventProgress{
+		report: report,
+		event: models.ProgressEvent{
+			VideoID:      videoID,
+			File:         filepath.Base(filename),
+			Item:         progress.CurrentItem,
+			Items:        progress.TotalItems,
+			Bytes:        0,
+			Total:        total,
+			Speed:        0,
+			AverageSpeed: 0,
+			State:        models.ProgressStarted,
+		},
+		speed:    newSpeedMeter(now),
+		lastEmit: now,
+	}
+
+	reporter.emit(models.ProgressStarted)
+
+	if _, err := copyBuffered(dst, &eventProgressReader{reader: src, progress: reporter}); err != nil {
+		reporter.emit(models.ProgressFailed)
+
+		return fmt.Errorf("%w: %w", errFailedToCopyData, err)
+	}
+
+	reporter.emit(models.ProgressFinished)
+
+	return nil
+}
+
+// Read reads from the underlying reader and emits a progress event at most
+// every progressEventInterval.
+func (r *eventProgressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.progress.event.Bytes += int64(n)
+	r.progress.speed.add(time.Now(), int64(n))
+
+	if time.Since(r.progress.lastEmit) >= progressEventInterval {
+		r.progress.emit(models.ProgressDownloading)
+	}
+
+	return n, err //nolint:wrapcheck // io.Reader must return io.EOF unwrapped.
+}
+
+// emit reports a progress event with the given state.
+func (p *eventProgress) emit(state string) {
+	p.lastEmit = time.Now()
+	p.event.State = state
+	p.event.Speed = p.speed.current(p.lastEmit)
+	p.event.AverageSpeed = p.speed.average(p.lastEmit)
+
+	p.report(p.event)
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestCopyWithJSONProgress(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		failing    bool
+		wantStates []string
+		wantBytes  int64
+	}{
+		{
+			name:       "successful download",
+			src:        "video data",
+			wantStates: []string{models.ProgressStarted, models.ProgressFinished},
+			wantBytes:  10,
+		},
+		{
+			name:       "failed download",
+			failing:    true,
+			wantStates: []string{models.ProgressStarted, models.ProgressFailed},
+			wantBytes:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, dst bytes.Buffer
+
+			src := iotest.ErrReader(errors.New("connection reset"))
+			if !tt.failing {
+				src = strings.NewReader(tt.src)
+			}
+
+			progress := models.ProgressInfo{CurrentItem: 2, TotalItems: 3}
+
+			err := copyWithJSONProgress(&out, src, &dst, int64(len(tt.src)), "abc", "dir/video.mp4", progress)
+			if (err != nil) != tt.failing {
+				t.Fatalf("copyWithJSONProgress() error = %v, failing %v", err, tt.failing)
+			}
+
+			var states []string
+
+			var last models.ProgressEvent
+
+			scanner := bufio.NewScanner(&out)
+			for scanner.Scan() {
+				if err := json.Unmarshal(scanner.Bytes(), &last); err != nil {
+					t.Fatalf("Invalid JSON line %q: %v", scanner.Text(), err)
+				}
+
+				states = append(states, last.State)
+			}
+
+			if strings.Join(states, ",") != strings.Join(tt.wantStates, ",") {
+				t.Errorf("states = %v, want %v", states, tt.wantStates)
+			}
+
+			if last.VideoID != "abc" || last.File != "video.mp4" || last.Item != 2 || last.Items != 3 {
+				t.Errorf("last event = %+v, want video abc, file video.mp4, item 2/3", last)
+			}
+
+			if last.Bytes != tt.wantBytes {
+				t.Errorf("last event bytes = %d, want %d", last.Bytes, tt.wantBytes)
+			}
+
+			if dst.String() != tt.src {
+				t.Errorf("dst = %q, want %q", dst.String(), tt.src)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+
+	"switchtube-downloader/internal/models"
+)
+
+// renderer draws the progress bars of downloads in one of the
+// models.ProgressStyle* styles.
+type renderer interface {
+	// filler builds the bar itself.
+	filler() mpb.BarFillerBuilder
+
+	// videoDecorators returns the decorators of the bar of a single video
+	// labelled name, whose speed meter measures.
+	videoDecorators(name string, meter *speedMeter) []mpb.BarOption
+
+	// totalDecorators returns the decorators of the bar showing the overall
+	// progress of all videos.
+	totalDecorators() []mpb.BarOption
+}
+
+// barRenderer draws a bar of characters, followed by the downloaded and total
+// size, the ETA and the speed.
+type barRenderer struct {
+	lbound, fill, tip, padding, rbound string
+}
+
+// minimalRenderer leaves out the bar and prints the percentage and speed
+// only, which suits narrow terminals and logs.
+type minimalRenderer struct{}
+
+// renderers are the renderers of the progress styles.
+var renderers = map[string]renderer{
+	models.ProgressStyleDefault: newBarRenderer("[", "=", ">", "-", "|"),
+	models.ProgressStyleASCII:   newBarRenderer("[", "#", "#", "-", "]"),
+	models.ProgressStyleUnicode: newBarRenderer("│", "█", "▌", "░", "│"),
+	models.ProgressStyleBraille: newBarRenderer(" ", "⣿", "⡇", "⣀", " "),
+	models.ProgressStyleMinimal: minimalRenderer{},
+}
+
+// newBarRenderer creates a barRenderer drawing the bar between lbound and
+// rbound with fill up to tip and padding after it.
+func newBarRenderer(lbound, fill, tip, padding, rbound string) barRenderer {
+	return barRenderer{lbound: lbound, fill: fill, tip: tip, padding: padding, rbound: rbound}
+}
+
+// rendererFor returns the renderer of style, which falls back to
+// models.ProgressStyleDefault if it is unknown.
+func rendererFor(style string) renderer {
+	if r, ok := renderers[style]; ok {
+		return r
+	}
+
+	return renderers[models.ProgressStyleDefault]
+}
+
+func (r barRenderer) filler() mpb.BarFillerBuilder {
+	color := colorFor(os.Stderr)
+	cyan := func(s string) string { return colorize(s, colorCyan, color) }
+
+	return mpb.BarStyle().
+		Lbound(r.lbound).
+		Filler(r.fill).
+		FillerMeta(cyan).
+		Tip(r.tip).
+		TipMeta(cyan).
+		Padding(r.padding).
+		Rbound(r.rbound)
+}
+
+func (barRenderer) videoDecorators(name string, meter *speedMeter) []mpb.BarOption {
+	return []mpb.BarOption{
+		mpb.PrependDecorators(
+			decor.Name(name+" "),
+			decor.Counters(decor.SizeB1024(0), "% .2f / % .2f"),
+		),
+		mpb.AppendDecorators(
+			decor.EwmaETA(decor.ET_STYLE_GO, etaSmoothingFactor),
+			decor.Name(" ] "),
+			decor.Any(func(decor.Statistics) string { return speedText(meter) }),
+		),
+	}
+}
+
+func (barRenderer) totalDecorators() []mpb.BarOption {
+	return []mpb.BarOption{
+		mpb.PrependDecorators(
+			decor.Name("Total "),
+			decor.Counters(decor.SizeB1024(0), "% .2f / % .2f"),
+		),
+		mpb.AppendDecorators(
+			decor.Percentage(decor.WCSyncSpace),
+			decor.Name(" ETA "),
+			decor.AverageETA(decor.ET_STYLE_GO),
+		),
+	}
+}
+
+func (minimalRenderer) filler() mpb.BarFillerBuilder {
+	return mpb.NopStyle()
+}
+
+func (minimalRenderer) videoDecorators(name string, meter *speedMeter) []mpb.BarOption {
+	return []mpb.BarOption{
+		mpb.PrependDecorators(
+			decor.Name(name+" "),
+			decor.Percentage(),
+		),
+		mpb.AppendDecorators(
+			decor.Any(func(decor.Statistics) string { return " " + speedText(meter) }),
+		),
+	}
+}
+
+func (minimalRenderer) totalDecorators() []mpb.BarOption {
+	return []mpb.BarOption{
+		mpb.PrependDecorators(
+			decor.Name("Total "),
+			decor.Percentage(),
+		),
+		mpb.AppendDecorators(
+			decor.Name(" ETA "),
+			decor.AverageETA(decor.ET_STYLE_GO),
+		),
+	}
+}
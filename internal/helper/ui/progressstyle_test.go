@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vbauerster/mpb/v8/decor"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestRendererFiller(t *testing.T) {
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{style: models.ProgressStyleDefault, want: "[====>-----|"},
+		{style: models.ProgressStyleASCII, want: "[#####-----]"},
+		{style: models.ProgressStyleUnicode, want: "│████▌░░░░░│"},
+		{style: models.ProgressStyleBraille, want: " ⣿⣿⣿⣿⡇⣀⣀⣀⣀⣀ "},
+		{style: models.ProgressStyleMinimal, want: ""},
+		{style: "unknown", want: "[====>-----|"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			var out bytes.Buffer
+
+			stat := decor.Statistics{AvailableWidth: 12, RequestedWidth: 12, Total: 10, Current: 5}
+			if err := rendererFor(tt.style).filler().Build().Fill(&out, stat); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.TrimRight(out.String(), "\n"); got != tt.want {
+				t.Errorf("bar = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,25 +2,121 @@
 package ui
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+
+	"golang.org/x/term"
 )
 
+// ErrInputDisabled is returned instead of asking for an answer that has no
+// default while prompts are turned off with SetInput.
+var ErrInputDisabled = errors.New("input required, but prompts are disabled with --no-input")
+
+// inputAllowed is false once prompts have been turned off with SetInput.
+var inputAllowed = true
+
+// assumeYes is set once confirmations are answered with SetAssumeYes.
+var assumeYes = false
+
+// SetInput turns prompts on or off. Without them, Confirm answers no, Input
+// and Password return an empty string and selections fail with
+// ErrInputDisabled, so that unattended runs never wait for stdin.
+func SetInput(enabled bool) {
+	inputAllowed = enabled
+}
+
+// SetAssumeYes makes Confirm answer yes without asking if yes is set,
+// regardless of whether prompts are turned on.
+func SetAssumeYes(yes bool) {
+	assumeYes = yes
+}
+
+// InputEnabled reports whether prompts are turned on, for callers that fail
+// with ErrInputDisabled rather than continue with an empty answer.
+func InputEnabled() bool {
+	return inputAllowed
+}
+
 // Input prompts the user for input and returns the entered string.
 func Input(prompt string) string {
-	fmt.Print(prompt)
+	if !inputAllowed {
+		return ""
+	}
 
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
+	fmt.Fprint(os.Stderr, prompt)
 
-	return strings.TrimSpace(input)
+	return strings.TrimSpace(readLine())
+}
+
+// Password prompts the user for a secret such as a passphrase, which isn't
+// echoed if stdin is a terminal.
+func Password(prompt string) string {
+	if !inputAllowed {
+		return ""
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+
+	if !isTerminal(os.Stdin) {
+		return strings.TrimSpace(readLine())
+	}
+
+	secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(secret))
+}
+
+// readLine reads a line from stdin. It reads byte by byte so that nothing
+// after the newline is consumed and the next prompt sees the next line when
+// stdin is a pipe.
+func readLine() string {
+	var line strings.Builder
+
+	buf := make([]byte, 1)
+
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+
+			line.WriteByte(buf[0])
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	return line.String()
 }
 
 // Confirm prompts the user for a yes/no confirmation and returns true for yes.
+// If the answer is given by SetAssumeYes or missing without prompts, the
+// question is still shown along with the answer.
 func Confirm(format string, args ...any) bool {
 	prompt := fmt.Sprintf(format, args...)
+
+	if assumeYes {
+		fmt.Fprintln(os.Stderr, prompt+" (y/N): y (--yes)")
+
+		return true
+	}
+
+	if !inputAllowed {
+		fmt.Fprintln(os.Stderr, prompt+" (y/N): n (--no-input)")
+
+		return false
+	}
+
 	response := Input(prompt + " (y/N): ")
 	response = strings.ToLower(strings.TrimSpace(response))
 
@@ -1,7 +1,9 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"testing"
@@ -68,12 +70,12 @@ func TestInput(t *testing.T) {
 
 			defer func() { os.Stdin = oldStdin }()
 
-			// Capture stdout to verify prompt is printed
-			oldStdout := os.Stdout
+			// Capture stderr to verify prompt is printed
+			oldStderr := os.Stderr
 			r, w, _ := os.Pipe()
-			os.Stdout = w
+			os.Stderr = w
 
-			defer func() { os.Stdout = oldStdout }()
+			defer func() { os.Stderr = oldStderr }()
 
 			// Test the function
 			result := Input(tt.prompt)
@@ -212,12 +214,12 @@ func TestConfirm(t *testing.T) {
 
 			defer func() { os.Stdin = oldStdin }()
 
-			// Capture stdout to verify prompt is printed
-			oldStdout := os.Stdout
+			// Capture stderr to verify prompt is printed
+			oldStderr := os.Stderr
 			r, w, _ := os.Pipe()
-			os.Stdout = w
+			os.Stderr = w
 
-			defer func() { os.Stdout = oldStdout }()
+			defer func() { os.Stderr = oldStderr }()
 
 			// Test the function
 			var result bool
@@ -275,11 +277,11 @@ func TestConfirmPromptFormat(t *testing.T) {
 
 	defer func() { os.Stdin = oldStdin }()
 
-	oldStdout := os.Stdout
+	oldStderr := os.Stderr
 	r, w, _ := os.Pipe()
-	os.Stdout = w
+	os.Stderr = w
 
-	defer func() { os.Stdout = oldStdout }()
+	defer func() { os.Stderr = oldStderr }()
 
 	Confirm("Test prompt")
 
@@ -310,11 +312,11 @@ func TestInputEmptyPrompt(t *testing.T) {
 
 	defer func() { os.Stdin = oldStdin }()
 
-	oldStdout := os.Stdout
+	oldStderr := os.Stderr
 	r, w, _ := os.Pipe()
-	os.Stdout = w
+	os.Stderr = w
 
-	defer func() { os.Stdout = oldStdout }()
+	defer func() { os.Stderr = oldStderr }()
 
 	result := Input("")
 
@@ -332,3 +334,99 @@ func TestInputEmptyPrompt(t *testing.T) {
 		t.Errorf("Input() with empty prompt should print nothing, got: %v", capturedOutput)
 	}
 }
+
+func TestPasswordFromPipe(t *testing.T) {
+	tmpFile, err := os.CreateTemp(t.TempDir(), "test-input")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, err = tmpFile.WriteString("  correct horse  \nnext\n"); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+
+	tmpFile.Seek(0, 0)
+
+	oldStdin, oldStderr := os.Stdin, os.Stderr
+	os.Stdin = tmpFile
+	os.Stderr, _ = os.Open(os.DevNull)
+
+	defer func() { os.Stdin, os.Stderr = oldStdin, oldStderr }()
+
+	if got := Password("Passphrase: "); got != "correct horse" {
+		t.Errorf("Password() = %q, want %q", got, "correct horse")
+	}
+}
+
+func TestNoInput(t *testing.T) {
+	tmpFile, err := os.CreateTemp(t.TempDir(), "test-input")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, err = tmpFile.WriteString("y\ny\ny\n1\n"); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+
+	tmpFile.Seek(0, 0)
+
+	oldStdin, oldStderr := os.Stdin, os.Stderr
+	os.Stdin = tmpFile
+	os.Stderr, _ = os.Open(os.DevNull)
+
+	SetInput(false)
+
+	defer func() {
+		os.Stdin, os.Stderr = oldStdin, oldStderr
+		SetInput(true)
+	}()
+
+	if Confirm("Overwrite?") {
+		t.Error("Confirm() = true, want false without prompts")
+	}
+
+	if got := Input("Value: "); got != "" {
+		t.Errorf("Input() = %q, want empty without prompts", got)
+	}
+
+	if got := Password("Passphrase: "); got != "" {
+		t.Errorf("Password() = %q, want empty without prompts", got)
+	}
+
+	if _, err := Select("videos", []string{"a", "b"}, false); !errors.Is(err, ErrInputDisabled) {
+		t.Errorf("Select() error = %v, want %v", err, ErrInputDisabled)
+	}
+
+	if got, err := Select("videos", []string{"a", "b"}, true); err != nil || len(got) != 2 {
+		t.Errorf("Select() with all = %v, %v, want both items", got, err)
+	}
+
+	// Nothing may have been read from stdin
+	if offset, _ := tmpFile.Seek(0, io.SeekCurrent); offset != 0 {
+		t.Errorf("stdin was read up to %d without prompts", offset)
+	}
+}
+
+func TestAssumeYes(t *testing.T) {
+	oldStderr := os.Stderr
+	os.Stderr, _ = os.Open(os.DevNull)
+
+	SetAssumeYes(true)
+
+	defer func() {
+		os.Stderr = oldStderr
+		SetAssumeYes(false)
+		SetInput(true)
+	}()
+
+	if !Confirm("Overwrite?") {
+		t.Error("Confirm() = false, want true with an assumed yes")
+	}
+
+	// An assumed yes also answers confirmations without prompts
+	SetInput(false)
+
+	if !Confirm("Overwrite?") {
+		t.Error("Confirm() without prompts = false, want true with an assumed yes")
+	}
+}
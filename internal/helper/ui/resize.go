@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"unicode"
+
+	"github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+	"golang.org/x/term"
+)
+
+// terminalWidth returns the number of columns of the terminal stderr, which
+// the progress bars are drawn on, is connected to, or 0 if it isn't a
+// terminal.
+var terminalWidth = func() int {
+	width, _, err := term.GetSize(int(os.Stderr.Fd()))
+	if err != nil {
+		return 0
+	}
+
+	return width
+}
+
+// barLines records the width of the line every bar was last drawn with and
+// the width of the terminal at that time. Terminals rewrap these lines when
+// they get narrower, so the lines then occupy more rows than the progress
+// bars clear before drawing the next frame.
+type barLines struct {
+	mu     sync.Mutex
+	widths map[int]int
+	term   int
+}
+
+// newBarLines creates an empty barLines.
+func newBarLines() *barLines {
+	return &barLines{
+		mu:     sync.Mutex{},
+		widths: make(map[int]int),
+		term:   0,
+	}
+}
+
+// measure returns a builder of the fillers of r that records the width of
+// the lines of their bars.
+func (l *barLines) measure(r renderer) mpb.BarFillerBuilder {
+	return measuredBuilder{builder: r.filler(), lines: l}
+}
+
+// set records that the bar with id was drawn width columns wide on a
+// terminal that was termWidth columns wide.
+func (l *barLines) set(id, width, termWidth int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.widths[id] = width
+	l.term = termWidth
+}
+
+// extraRows returns the number of rows the lines of the last frame occupy
+// more since the terminal got width columns wide.
+func (l *barLines) extraRows(width int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if width <= 0 || l.term <= 0 || width >= l.term {
+		return 0
+	}
+
+	extra := 0
+	for _, line := range l.widths {
+		extra += rows(line, width) - rows(line, l.term)
+	}
+
+	return extra
+}
+
+// resized clears the rows the last frame occupies after the terminal has been
+// resized, by moving the cursor up before the next frame of p is drawn.
+func (l *barLines) resized(p io.Writer) {
+	extra := l.extraRows(terminalWidth())
+	if extra <= 0 {
+		return
+	}
+
+	if _, err := fmt.Fprintf(p, "\x1b[%dA\x1b[J", extra); err != nil && !errors.Is(err, mpb.ErrDone) {
+		slog.Debug("failed to clear progress bars after resize", "error", err)
+	}
+}
+
+// rows returns the number of rows a line of width columns occupies on a
+// terminal termWidth columns wide.
+func rows(width, termWidth int) int {
+	return max(1, (width+termWidth-1)/termWidth)
+}
+
+// measuredBuilder builds fillers that record the width of the lines of their
+// bars in lines.
+type measuredBuilder struct {
+	builder mpb.BarFillerBuilder
+	lines   *barLines
+}
+
+// Build builds the filler of the underlying builder.
+func (b measuredBuilder) Build() mpb.BarFiller {
+	filler := b.builder.Build()
+
+	return mpb.BarFillerFunc(func(w io.Writer, stat decor.Statistics) error {
+		counter := &cellCounter{w: w, cells: 0, escape: false}
+		err := filler.Fill(counter, stat)
+
+		// The decorators have used up the columns that are no longer available
+		termWidth := terminalWidth()
+		b.lines.set(stat.ID, termWidth-stat.AvailableWidth+counter.cells, termWidth)
+
+		return err //nolint:wrapcheck // Passed through to mpb.
+	})
+}
+
+// cellCounter counts the characters written to w, leaving out ANSI escape
+// sequences such as colors. All characters of the bars are one cell wide.
+type cellCounter struct {
+	w      io.Writer
+	cells  int
+	escape bool
+}
+
+// Write writes p to the underlying writer and counts its characters.
+func (c *cellCounter) Write(p []byte) (int, error) {
+	for _, r := range string(p) {
+		switch {
+		case r == '\x1b':
+			c.escape = true
+		case c.escape:
+			// Escape sequences end with a letter
+			c.escape = !unicode.IsLetter(r)
+		default:
+			c.cells++
+		}
+	}
+
+	return c.w.Write(p) //nolint:wrapcheck // Passed through to mpb.
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vbauerster/mpb/v8/decor"
+
+	"switchtube-downloader/internal/models"
+)
+
+// fakeTerminalWidth makes the terminal width columns wide.
+func fakeTerminalWidth(t *testing.T, width *int) {
+	t.Helper()
+
+	original := terminalWidth
+	terminalWidth = func() int { return *width }
+
+	t.Cleanup(func() { terminalWidth = original })
+}
+
+func TestBarLinesExtraRows(t *testing.T) {
+	width := 100
+	fakeTerminalWidth(t, &width)
+
+	lines := newBarLines()
+	filler := lines.measure(rendererFor(models.ProgressStyleASCII)).Build()
+
+	// The decorators of both bars used 40 columns, leaving 60 for the bar
+	for id := range 2 {
+		stat := decor.Statistics{ID: id, AvailableWidth: 60, Total: 10, Current: 5}
+		if err := filler.Fill(&bytes.Buffer{}, stat); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		width int
+		want  int
+	}{
+		{width: 120, want: 0},
+		{width: 100, want: 0},
+		{width: 50, want: 2},
+		{width: 30, want: 6},
+		{width: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := lines.extraRows(tt.width); got != tt.want {
+			t.Errorf("extraRows(%d) = %d, want %d", tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestBarLinesResized(t *testing.T) {
+	width := 80
+	fakeTerminalWidth(t, &width)
+
+	lines := newBarLines()
+	lines.set(0, 80, 80)
+
+	var out bytes.Buffer
+
+	width = 40
+	lines.resized(&out)
+
+	if out.String() != "\x1b[1A\x1b[J" {
+		t.Errorf("resized() wrote %q, want the cursor moved up one row", out.String())
+	}
+
+	out.Reset()
+
+	width = 120
+	lines.resized(&out)
+
+	if out.Len() != 0 {
+		t.Errorf("resized() wrote %q for a wider terminal, want nothing", out.String())
+	}
+}
+
+func TestCellCounter(t *testing.T) {
+	var out strings.Builder
+
+	counter := &cellCounter{w: &out}
+	if _, err := counter.Write([]byte("[\x1b[36m####\x1b[0m-]│█")); err != nil {
+		t.Fatal(err)
+	}
+
+	if counter.cells != 9 {
+		t.Errorf("cells = %d, want 9", counter.cells)
+	}
+}
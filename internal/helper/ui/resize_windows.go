@@ -0,0 +1,7 @@
+package ui
+
+// watchResize does nothing on Windows, which has no resize signal. The bars
+// still adapt to the width of the terminal with every frame.
+func watchResize(func()) func() {
+	return func() {}
+}
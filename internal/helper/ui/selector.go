@@ -3,61 +3,281 @@ package ui
 import (
 	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"switchtube-downloader/internal/models"
 )
 
-const rangePartsCount = 2
+const (
+	rangePartsCount = 2
+
+	// keywordPrefix starts a selection by title, e.g. "~exercise".
+	keywordPrefix = "~"
+
+	// unknownSize marks a video whose size isn't known.
+	unknownSize = -1
+)
 
 var (
 	errInvalidRange           = errors.New("invalid range")
 	errInvalidNumber          = errors.New("invalid number")
 	errInvalidEndNumber       = errors.New("invalid end number")
+	errInvalidKeyword         = errors.New("invalid keyword")
 	errNumberOutOfRange       = errors.New("number out of range")
 	errInvalidRangeFormat     = errors.New("invalid range format")
 	errInvalidStartNumber     = errors.New("invalid start number")
 	errNoValidSelectionsFound = errors.New("no valid selections found")
 )
 
-// SelectVideos displays the video list and handles user selection.
-func SelectVideos(videos []models.Video, all bool) ([]int, error) {
-	// If --all flag is used, select all videos
-	if all || len(videos) == 0 {
-		indices := make([]int, len(videos))
-		for i := range indices {
-			indices[i] = i
+// SelectVideos displays the video list and handles user selection. Each
+// video is shown with its duration, publication date and the matching entry
+// of sizes if they are known; sizes may be nil.
+func SelectVideos(videos []models.Video, sizes []int64, all bool) ([]int, error) {
+	labels := make([]string, len(videos))
+	titles := make([]string, len(videos))
+
+	for i, video := range videos {
+		titles[i] = video.Title
+
+		size := int64(unknownSize)
+		if i < len(sizes) {
+			size = sizes[i]
 		}
 
-		return indices, nil
+		labels[i] = videoLabel(video, size)
+	}
+
+	return selectItems("videos", labels, titles, all)
+}
+
+// videoLabel returns the title of video followed by the details that are
+// known, e.g. "Mapping (1:02:03, 2024-03-01, 512.0 MiB)".
+func videoLabel(video models.Video, size int64) string {
+	var details []string
+
+	if video.Duration > 0 {
+		details = append(details, FormatDuration(video.Duration))
 	}
 
-	fmt.Println("\nAvailable videos:")
+	if !video.PublishedAt.IsZero() {
+		details = append(details, video.PublishedAt.Local().Format(time.DateOnly))
+	}
 
-	for i, video := range videos {
-		fmt.Printf("%d. %s\n", i+1, video.Title)
+	if size >= 0 {
+		details = append(details, FormatSize(size))
+	}
+
+	if len(details) == 0 {
+		return video.Title
+	}
+
+	return video.Title + " (" + strings.Join(details, ", ") + ")"
+}
+
+// Select displays a numbered list of items and handles user selection. The
+// noun describes the items in the prompts, e.g. "videos". Lists longer than
+// the terminal are paged with n and p. Text that is not a valid selection
+// filters the list by fuzzy match, after which Enter selects all matching
+// items.
+func Select(noun string, items []string, all bool) ([]int, error) {
+	return selectItems(noun, items, items, all)
+}
+
+// selectItems implements Select for items, which are shown to the user,
+// while filters and keywords match the corresponding titles.
+func selectItems(noun string, items, titles []string, all bool) ([]int, error) {
+	// If --all flag is used, select all items
+	if all || len(items) == 0 {
+		return allIndices(len(items)), nil
+	}
+
+	if !inputAllowed {
+		return nil, fmt.Errorf("%w: pass --all to select all %s", ErrInputDisabled, noun)
+	}
+
+	list := newPager(noun, items)
+	list.print()
+
+	fmt.Fprintf(os.Stderr,
+		"\nSelect %s (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\n",
+		noun,
+	)
+
+	for {
+		input := strings.TrimSpace(Input("Selection: "))
+
+		switch {
+		case input == "":
+			// If input is empty, select all items that are shown
+			return list.shown, nil
+		case list.paged() && (input == "n" || input == "p"):
+			delta := 1
+			if input == "p" {
+				delta = -1
+			}
+
+			if list.turn(delta) {
+				list.print()
+			}
+
+			continue
+		}
+
+		indices, err := parseSelection(input, titles)
+		if err == nil || !isFilter(input) {
+			return indices, err
+		}
+
+		// Text without matches is most likely a mistyped selection
+		matches := fuzzyFilter(input, titles)
+		if len(matches) == 0 {
+			return nil, err
+		}
+
+		list.show(fmt.Sprintf("%s matching '%s'", capitalize(noun), input), matches)
+		list.print()
+		fmt.Fprintf(os.Stderr,
+			"\nSelect %s (numbers as listed, text to filter again, or Enter for all %d shown):\n",
+			noun,
+			len(matches),
+		)
+	}
+}
+
+// allIndices returns the indices of a list with n items.
+func allIndices(n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+
+	return indices
+}
+
+// PrintList prints a numbered list of items.
+func PrintList(noun string, items []string) {
+	fmt.Printf("\nAvailable %s:\n", noun)
+
+	for i, item := range items {
+		fmt.Printf("%d. %s\n", i+1, item)
+	}
+}
+
+// capitalize returns s with an upper case first letter.
+func capitalize(s string) string {
+	runes := []rune(s)
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+
+	return string(runes)
+}
+
+// isFilter reports whether input is meant as a filter, i.e. contains a
+// letter, rather than a mistyped numeric selection.
+func isFilter(input string) bool {
+	return strings.ContainsFunc(input, unicode.IsLetter)
+}
+
+// fuzzyFilter returns the indices of the items that fuzzily match query.
+func fuzzyFilter(query string, items []string) []int {
+	var matches []int
+
+	for i, item := range items {
+		if fuzzyMatch(query, item) {
+			matches = append(matches, i)
+		}
+	}
+
+	return matches
+}
+
+// fuzzyMatch reports whether all characters of query except spaces appear in
+// text in the same order, ignoring case. For example "os map" matches
+// "Operating Systems: Mapping".
+func fuzzyMatch(query, text string) bool {
+	remaining := []rune(strings.ToLower(text))
+
+	for _, r := range strings.ToLower(query) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+
+		i := slices.Index(remaining, r)
+		if i < 0 {
+			return false
+		}
+
+		remaining = remaining[i+1:]
 	}
 
-	fmt.Println("\nSelect videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):")
+	return true
+}
+
+// parseSelection parses user input and returns the selected indices of
+// items. A leading "!", "^" or "all except" inverts the selection, e.g. "!3,5"
+// selects all items but the third and fifth.
+func parseSelection(input string, items []string) ([]int, error) {
+	if rest, ok := cutInverse(input); ok {
+		excluded, err := parseRanges(rest, items)
+		if err != nil {
+			return nil, err
+		}
+
+		return invertSelection(excluded, len(items))
+	}
+
+	return parseRanges(input, items)
+}
 
-	input := strings.TrimSpace(Input("Selection: "))
-	if input == "" {
-		// If input is empty, select all videos
-		indices := make([]int, len(videos))
-		for i := range indices {
-			indices[i] = i
+// cutInverse returns input without the prefix of an inverse selection and
+// reports whether there was one.
+func cutInverse(input string) (string, bool) {
+	input = strings.TrimSpace(input)
+
+	for _, prefix := range []string{"!", "^"} {
+		if rest, ok := strings.CutPrefix(input, prefix); ok {
+			return rest, true
 		}
+	}
 
-		return indices, nil
+	const allExcept = "all except "
+	if len(input) > len(allExcept) && strings.EqualFold(input[:len(allExcept)], allExcept) {
+		return input[len(allExcept):], true
 	}
 
-	return parseSelection(input, len(videos))
+	return input, false
 }
 
-// parseSelection parses user input and returns selected video indices.
-func parseSelection(input string, availableVideos int) ([]int, error) {
+// invertSelection returns the indices of all videos that are not excluded.
+func invertSelection(excluded []int, availableVideos int) ([]int, error) {
+	var indices []int
+
+	for i := range availableVideos {
+		if !slices.Contains(excluded, i) {
+			indices = append(indices, i)
+		}
+	}
+
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("%w", errNoValidSelectionsFound)
+	}
+
+	return indices, nil
+}
+
+// parseRanges parses comma or space separated numbers, ranges and keywords
+// and returns the selected indices of items.
+func parseRanges(input string, items []string) ([]int, error) {
+	availableVideos := len(items)
+
 	var indices []int
 
 	seen := make(map[int]bool)
@@ -74,8 +294,13 @@ func parseSelection(input string, availableVideos int) ([]int, error) {
 		}
 
 		var err error
-		// Handle range (e.g., "1-5")
-		if strings.Contains(part, "-") {
+		// Handle keyword (e.g., "~exercise") and range (e.g., "1-5")
+		if keyword, ok := strings.CutPrefix(part, keywordPrefix); ok {
+			indices, err = handleKeywordSelection(keyword, items, indices, seen)
+			if err != nil {
+				return nil, err
+			}
+		} else if strings.Contains(part, "-") {
 			indices, err = handleRangeSelection(part, availableVideos, indices, seen)
 			if err != nil {
 				return nil, err
@@ -97,6 +322,34 @@ func parseSelection(input string, availableVideos int) ([]int, error) {
 	return indices, nil
 }
 
+// handleKeywordSelection processes a keyword selection like "~exercise",
+// which selects all items matching the keyword as a case-insensitive regular
+// expression. Plain words match as substrings.
+func handleKeywordSelection(
+	keyword string,
+	items []string,
+	indices []int,
+	seen map[int]bool,
+) ([]int, error) {
+	if keyword == "" {
+		return nil, fmt.Errorf("%w: %s", errInvalidKeyword, keywordPrefix)
+	}
+
+	pattern, err := regexp.Compile("(?i)" + keyword)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errInvalidKeyword, keyword, err)
+	}
+
+	for index, item := range items {
+		if pattern.MatchString(item) && !seen[index] {
+			indices = append(indices, index)
+			seen[index] = true
+		}
+	}
+
+	return indices, nil
+}
+
 // handleRangeSelection processes a range selection like "1-5".
 func handleRangeSelection(
 	part string,
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"switchtube-downloader/internal/models"
 )
@@ -36,7 +37,7 @@ func TestSelectVideos(t *testing.T) {
 			want:    []int{0, 1},
 			wantErr: false,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "select single video",
@@ -46,7 +47,7 @@ func TestSelectVideos(t *testing.T) {
 			want:    []int{0},
 			wantErr: false,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "select range",
@@ -56,7 +57,7 @@ func TestSelectVideos(t *testing.T) {
 			want:    []int{0, 1, 2},
 			wantErr: false,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n3. Video3\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "select multiple videos with comma",
@@ -66,7 +67,7 @@ func TestSelectVideos(t *testing.T) {
 			want:    []int{0, 2},
 			wantErr: false,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n3. Video3\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "select multiple videos with space",
@@ -76,7 +77,7 @@ func TestSelectVideos(t *testing.T) {
 			want:    []int{0, 2},
 			wantErr: false,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n3. Video3\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "invalid number",
@@ -87,7 +88,7 @@ func TestSelectVideos(t *testing.T) {
 			wantErr: true,
 			err:     errInvalidNumber,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "number out of range",
@@ -98,7 +99,7 @@ func TestSelectVideos(t *testing.T) {
 			wantErr: true,
 			err:     errNumberOutOfRange,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "invalid range format",
@@ -109,7 +110,7 @@ func TestSelectVideos(t *testing.T) {
 			wantErr: true,
 			err:     errInvalidRangeFormat,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "invalid start number in range",
@@ -120,7 +121,7 @@ func TestSelectVideos(t *testing.T) {
 			wantErr: true,
 			err:     errInvalidStartNumber,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "invalid end number in range",
@@ -131,7 +132,7 @@ func TestSelectVideos(t *testing.T) {
 			wantErr: true,
 			err:     errInvalidEndNumber,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "range out of bounds",
@@ -142,7 +143,7 @@ func TestSelectVideos(t *testing.T) {
 			wantErr: true,
 			err:     errInvalidRange,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "start greater than end in range",
@@ -153,7 +154,7 @@ func TestSelectVideos(t *testing.T) {
 			wantErr: true,
 			err:     errInvalidRange,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:    "no valid selections",
@@ -164,7 +165,7 @@ func TestSelectVideos(t *testing.T) {
 			wantErr: true,
 			err:     errNoValidSelectionsFound,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
 		},
 		{
 			name:       "empty video list",
@@ -183,7 +184,33 @@ func TestSelectVideos(t *testing.T) {
 			want:    []int{0, 1},
 			wantErr: false,
 			wantPrompt: "\nAvailable videos:\n1. Video1\n2. Video2\n\n" +
-				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', or Enter for all):\nSelection: ",
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\nSelection: ",
+		},
+		{
+			name:    "filter and select all matches",
+			videos:  []models.Video{{Title: "Intro"}, {Title: "Mapping"}, {Title: "Paging"}},
+			all:     false,
+			input:   "mapg\n\n",
+			want:    []int{1},
+			wantErr: false,
+			wantPrompt: "\nAvailable videos:\n1. Intro\n2. Mapping\n3. Paging\n\n" +
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\n" +
+				"Selection: \nVideos matching 'mapg':\n2. Mapping\n\n" +
+				"Select videos (numbers as listed, text to filter again, or Enter for all 1 shown):\n" +
+				"Selection: ",
+		},
+		{
+			name:    "filter and select by number",
+			videos:  []models.Video{{Title: "Intro"}, {Title: "Mapping"}, {Title: "Paging"}},
+			all:     false,
+			input:   "ing\n3\n",
+			want:    []int{2},
+			wantErr: false,
+			wantPrompt: "\nAvailable videos:\n1. Intro\n2. Mapping\n3. Paging\n\n" +
+				"Select videos (e.g., '1-3', '1,3,5', '1 3 5', text to filter, or Enter for all):\n" +
+				"Selection: \nVideos matching 'ing':\n2. Mapping\n3. Paging\n\n" +
+				"Select videos (numbers as listed, text to filter again, or Enter for all 2 shown):\n" +
+				"Selection: ",
 		},
 	}
 
@@ -208,13 +235,13 @@ func TestSelectVideos(t *testing.T) {
 
 			defer func() { os.Stdin = oldStdin }()
 
-			oldStdout := os.Stdout
+			oldStderr := os.Stderr
 			r, w, _ := os.Pipe()
-			os.Stdout = w
+			os.Stderr = w
 
-			defer func() { os.Stdout = oldStdout }()
+			defer func() { os.Stderr = oldStderr }()
 
-			result, err := SelectVideos(tt.videos, tt.all)
+			result, err := SelectVideos(tt.videos, nil, tt.all)
 
 			w.Close()
 
@@ -255,3 +282,129 @@ func equalIntSlices(a, b []int) bool {
 
 	return true
 }
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		want  bool
+	}{
+		{query: "map", text: "Mapping", want: true},
+		{query: "MAP", text: "mapping", want: true},
+		{query: "os map", text: "Operating Systems: Mapping", want: true},
+		{query: "mpg", text: "Mapping", want: true},
+		{query: "gpm", text: "Mapping", want: false},
+		{query: "exercise", text: "Lecture", want: false},
+		{query: "", text: "Lecture", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.query, tt.text); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.query, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestVideoLabel(t *testing.T) {
+	published := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		video models.Video
+		size  int64
+		want  string
+	}{
+		{
+			name:  "title only",
+			video: models.Video{Title: "Mapping"},
+			size:  -1,
+			want:  "Mapping",
+		},
+		{
+			name:  "all details",
+			video: models.Video{Title: "Mapping", Duration: 3723, PublishedAt: published},
+			size:  512 * 1024 * 1024,
+			want:  "Mapping (1:02:03, 2024-03-01, 512.0 MiB)",
+		},
+		{
+			name:  "unknown size",
+			video: models.Video{Title: "Mapping", Duration: 90},
+			size:  -1,
+			want:  "Mapping (1:30)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := videoLabel(tt.video, tt.size); got != tt.want {
+				t.Errorf("videoLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSelectionInverse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []int
+		wantErr error
+	}{
+		{name: "exclamation mark", input: "!3,5", want: []int{0, 1, 3}, wantErr: nil},
+		{name: "caret", input: "^1-2", want: []int{2, 3, 4}, wantErr: nil},
+		{name: "all except", input: "all except 2-4", want: []int{0, 4}, wantErr: nil},
+		{name: "all except ignores case", input: "All Except 1", want: []int{1, 2, 3, 4}, wantErr: nil},
+		{name: "prefix with space", input: "! 2 3", want: []int{0, 3, 4}, wantErr: nil},
+		{name: "everything excluded", input: "!1-5", want: nil, wantErr: errNoValidSelectionsFound},
+		{name: "nothing to exclude", input: "!", want: nil, wantErr: errNoValidSelectionsFound},
+		{name: "invalid exclusion", input: "!7", want: nil, wantErr: errNumberOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSelection(tt.input, []string{"A", "B", "C", "D", "E"})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("parseSelection(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+
+			if !equalIntSlices(got, tt.want) {
+				t.Errorf("parseSelection(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSelectionKeyword(t *testing.T) {
+	items := []string{"Lecture 1", "Exercise 1", "Lecture 2", "Exercise 2 (solution)", "Exam"}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []int
+		wantErr error
+	}{
+		{name: "substring", input: "~exercise", want: []int{1, 3}, wantErr: nil},
+		{name: "combined with numbers", input: "1,~exercise", want: []int{0, 1, 3}, wantErr: nil},
+		{name: "combined with range", input: "~exam 1-2", want: []int{0, 1, 4}, wantErr: nil},
+		{name: "regular expression", input: "~^ex.*[0-9]$", want: []int{1}, wantErr: nil},
+		{name: "inverse", input: "!~lecture", want: []int{1, 3, 4}, wantErr: nil},
+		{name: "no match", input: "~quiz", want: nil, wantErr: errNoValidSelectionsFound},
+		{name: "empty keyword", input: "~", want: nil, wantErr: errInvalidKeyword},
+		{name: "invalid regular expression", input: "~(", want: nil, wantErr: errInvalidKeyword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSelection(tt.input, items)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("parseSelection(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+
+			if !equalIntSlices(got, tt.want) {
+				t.Errorf("parseSelection(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// speedWindow is the period the current download speed is measured over.
+	speedWindow = 5 * time.Second
+
+	// speedResolution is the minimum time between two samples, which bounds
+	// the number of samples kept for the window.
+	speedResolution = 100 * time.Millisecond
+)
+
+// speedSample is the number of bytes downloaded until a point in time.
+type speedSample struct {
+	at    time.Time
+	bytes int64
+}
+
+// speedMeter measures the current speed of a download over the last
+// speedWindow and its average speed since the start. It is safe for
+// concurrent use, since progress bars read it while data is copied.
+type speedMeter struct {
+	mu      sync.Mutex
+	start   time.Time
+	bytes   int64
+	samples []speedSample
+}
+
+// newSpeedMeter creates a speedMeter for a download started at start.
+func newSpeedMeter(start time.Time) *speedMeter {
+	return &speedMeter{
+		mu:      sync.Mutex{},
+		start:   start,
+		bytes:   0,
+		samples: []speedSample{{at: start, bytes: 0}},
+	}
+}
+
+// add records that n more bytes were downloaded at now.
+func (m *speedMeter) add(now time.Time, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bytes += n
+	sample := speedSample{at: now, bytes: m.bytes}
+
+	if count := len(m.samples); count > 1 && now.Sub(m.samples[count-2].at) < speedResolution {
+		m.samples[count-1] = sample
+	} else {
+		m.samples = append(m.samples, sample)
+	}
+
+	// The newest sample before the window is kept as its start
+	drop := 0
+	for drop+1 < len(m.samples) && now.Sub(m.samples[drop+1].at) >= speedWindow {
+		drop++
+	}
+
+	m.samples = m.samples[drop:]
+}
+
+// current returns the speed in bytes per second over the last speedWindow
+// before now. It is zero once nothing was downloaded for speedWindow.
+func (m *speedMeter) current(now time.Time) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	last := m.samples[len(m.samples)-1]
+	if now.Sub(last.at) >= speedWindow {
+		return 0
+	}
+
+	first := m.samples[0]
+
+	elapsed := now.Sub(first.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(m.bytes-first.bytes) / elapsed
+}
+
+// average returns the speed in bytes per second since the start until now.
+func (m *speedMeter) average(now time.Time) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elapsed := now.Sub(m.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(m.bytes) / elapsed
+}
+
+// speedReader records the bytes read from the underlying reader in meter.
+type speedReader struct {
+	reader io.Reader
+	meter  *speedMeter
+}
+
+// Read reads from the underlying reader and records the bytes read.
+func (r *speedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.meter.add(time.Now(), int64(n))
+
+	return n, err //nolint:wrapcheck // io.Reader must return io.EOF unwrapped.
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpeedMeter(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	meter := newSpeedMeter(start)
+
+	// 10 seconds at 1000 B/s, then 5 seconds at 4000 B/s
+	for second := 1; second <= 10; second++ {
+		meter.add(start.Add(time.Duration(second)*time.Second), 1000)
+	}
+
+	for second := 11; second <= 15; second++ {
+		meter.add(start.Add(time.Duration(second)*time.Second), 4000)
+	}
+
+	now := start.Add(15 * time.Second)
+
+	if got := meter.current(now); got != 4000 {
+		t.Errorf("current() = %v, want 4000", got)
+	}
+
+	if got := meter.average(now); got != 2000 {
+		t.Errorf("average() = %v, want 2000", got)
+	}
+
+	if got := meter.current(now.Add(speedWindow)); got != 0 {
+		t.Errorf("current() after a stall = %v, want 0", got)
+	}
+
+	if len(meter.samples) > int(speedWindow/time.Second)+1 {
+		t.Errorf("meter keeps %d samples, want only the window", len(meter.samples))
+	}
+}
+
+func TestSpeedMeterResolution(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	meter := newSpeedMeter(start)
+
+	for ms := 1; ms <= 1000; ms++ {
+		meter.add(start.Add(time.Duration(ms)*time.Millisecond), 1)
+	}
+
+	if maxSamples := int(time.Second/speedResolution) + 2; len(meter.samples) > maxSamples {
+		t.Errorf("meter keeps %d samples, want at most %d", len(meter.samples), maxSamples)
+	}
+
+	if got := meter.current(start.Add(time.Second)); got != 1000 {
+		t.Errorf("current() = %v, want 1000", got)
+	}
+}
+
+func TestSpeedMeterEmpty(t *testing.T) {
+	start := time.Now()
+	meter := newSpeedMeter(start)
+
+	if got := meter.current(start); got != 0 {
+		t.Errorf("current() = %v, want 0", got)
+	}
+
+	if got := meter.average(start); got != 0 {
+		t.Errorf("average() = %v, want 0", got)
+	}
+}
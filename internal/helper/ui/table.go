@@ -0,0 +1,316 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// tabPadding is the padding between the columns of a table.
+	tabPadding = 2
+
+	// sizeUnit is the base of binary size units.
+	sizeUnit = 1024
+
+	secondsPerMinute = 60
+	secondsPerHour   = 60 * secondsPerMinute
+)
+
+var errInvalidSize = errors.New("invalid size, e.g. 500M or 1.5G")
+
+// PrintChannelListing prints the videos of a channel as a table.
+func PrintChannelListing(listing *models.ChannelListing) {
+	fmt.Printf("Channel: %s (%d videos)\n\n", listing.Name, len(listing.Videos))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, tabPadding, ' ', 0)
+	fmt.Fprintln(w, "#\tEpisode\tTitle\tDuration\tSize")
+
+	for _, video := range listing.Videos {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+			video.Index,
+			orDash(video.Episode),
+			video.Title,
+			FormatDuration(video.Duration),
+			FormatSize(video.Size))
+	}
+
+	if err := w.Flush(); err != nil {
+		slog.Warn("failed to print table", "error", err)
+	}
+}
+
+// PrintChannels prints the id and name of channels as a table.
+func PrintChannels(channels []models.Channel) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, tabPadding, ' ', 0)
+	fmt.Fprintln(w, "ID\tName")
+
+	for _, channel := range channels {
+		fmt.Fprintf(w, "%s\t%s\n", channel.ID, channel.Name)
+	}
+
+	if err := w.Flush(); err != nil {
+		slog.Warn("failed to print table", "error", err)
+	}
+}
+
+// PrintAliases prints the aliases defined in the config file as a table.
+func PrintAliases(aliases []models.Alias) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, tabPadding, ' ', 0)
+	fmt.Fprintln(w, "Alias\tTarget")
+
+	for _, alias := range aliases {
+		fmt.Fprintf(w, "%s\t%s\n", alias.Name, alias.Target)
+	}
+
+	if err := w.Flush(); err != nil {
+		slog.Warn("failed to print table", "error", err)
+	}
+}
+
+// PrintAccounts prints the accounts defined in the config file as a table,
+// marking the default account with an asterisk.
+func PrintAccounts(accounts []models.AccountConfig) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, tabPadding, ' ', 0)
+	fmt.Fprintln(w, "Account\tSettings")
+
+	for _, account := range accounts {
+		name := account.Name
+		if account.Default {
+			name += " *"
+		}
+
+		settings := make([]string, 0, len(account.Settings))
+		for _, key := range slices.Sorted(maps.Keys(account.Settings)) {
+			settings = append(settings, key+"="+account.Settings[key])
+		}
+
+		fmt.Fprintf(w, "%s\t%s\n", name, strings.Join(settings, " "))
+	}
+
+	if err := w.Flush(); err != nil {
+		slog.Warn("failed to print table", "error", err)
+	}
+}
+
+// PrintQueue prints the entries of the download queue as a table, numbered
+// from 1.
+func PrintQueue(entries []models.QueueEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, tabPadding, ' ', 0)
+	fmt.Fprintln(w, "#\tAdded\tMedia")
+
+	for i, entry := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", i+1, entry.AddedAt.Local().Format(time.DateTime), entry.Media)
+	}
+
+	if err := w.Flush(); err != nil {
+		slog.Warn("failed to print table", "error", err)
+	}
+}
+
+// PrintHistory prints entries of the download history as a table.
+func PrintHistory(entries []models.HistoryEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, tabPadding, ' ', 0)
+	fmt.Fprintln(w, "Downloaded\tChannel\tTitle\tSize\tPath")
+
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			entry.DownloadedAt.Local().Format(time.DateTime),
+			orDash(entry.Channel),
+			entry.Title,
+			FormatSize(entry.Size),
+			entry.Path)
+	}
+
+	if err := w.Flush(); err != nil {
+		slog.Warn("failed to print table", "error", err)
+	}
+}
+
+// PrintDownloadSummary prints the result of every video of a channel
+// download as a table.
+func PrintDownloadSummary(results []models.VideoResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, tabPadding, ' ', 0)
+	fmt.Fprintln(w, "Title\tStatus\tSize\tDuration\tSpeed")
+
+	for _, result := range results {
+		duration := "-"
+		if result.Seconds > 0 {
+			duration = FormatDuration(result.Seconds)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			result.Title,
+			result.Status,
+			FormatSize(result.Size),
+			duration,
+			formatSpeed(result.Speed))
+	}
+
+	if err := w.Flush(); err != nil {
+		slog.Warn("failed to print table", "error", err)
+	}
+}
+
+// PrintHistoryStats prints the totals of the download history followed by
+// tables per channel and per month.
+func PrintHistoryStats(stats models.HistoryStats) {
+	fmt.Printf("Videos:        %d\n", stats.Total.Videos)
+	fmt.Printf("Downloaded:    %s\n", FormatSize(stats.Total.Bytes))
+	fmt.Printf("Average speed: %s\n", formatSpeed(stats.Total.AverageSpeed))
+
+	for _, table := range []struct {
+		title  string
+		groups []models.GroupStats
+	}{
+		{title: "Channel", groups: stats.Channels},
+		{title: "Month", groups: stats.Months},
+	} {
+		fmt.Println()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, tabPadding, ' ', 0)
+		fmt.Fprintf(w, "%s\tVideos\tSize\tAverage speed\n", table.title)
+
+		for _, group := range table.groups {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
+				orDash(group.Name),
+				group.Videos,
+				FormatSize(group.Bytes),
+				formatSpeed(group.AverageSpeed))
+		}
+
+		if err := w.Flush(); err != nil {
+			slog.Warn("failed to print table", "error", err)
+		}
+	}
+}
+
+// formatSpeed formats a speed in bytes per second, e.g. "2.5 MiB/s".
+func formatSpeed(bytesPerSecond float64) string {
+	if bytesPerSecond <= 0 {
+		return "-"
+	}
+
+	return FormatSize(int64(bytesPerSecond)) + "/s"
+}
+
+// PrintVideoDetails prints the metadata of a video followed by a table of its
+// variants.
+func PrintVideoDetails(details *models.VideoDetails) {
+	fmt.Printf("Title:    %s\n", details.Title)
+	fmt.Printf("ID:       %s\n", details.ID)
+	fmt.Printf("Episode:  %s\n", orDash(details.Episode))
+	fmt.Printf("Duration: %s\n\n", FormatDuration(details.Duration))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, tabPadding, ' ', 0)
+	fmt.Fprintln(w, "#\tMedia type\tResolution\tSize")
+
+	for i, variant := range details.Variants {
+		resolution := "-"
+		if variant.Height > 0 {
+			resolution = strconv.Itoa(variant.Height) + "p"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, variant.MediaType, resolution,
+			FormatSize(variant.Size))
+	}
+
+	if err := w.Flush(); err != nil {
+		slog.Warn("failed to print table", "error", err)
+	}
+}
+
+// FormatDuration formats seconds as h:mm:ss or m:ss. Non-positive durations
+// are shown as a dash.
+func FormatDuration(seconds float64) string {
+	if seconds <= 0 {
+		return "-"
+	}
+
+	total := int(time.Duration(seconds * float64(time.Second)).Round(time.Second).Seconds())
+	h := total / secondsPerHour
+	m := total % secondsPerHour / secondsPerMinute
+	s := total % secondsPerMinute
+
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// FormatSize formats a size in bytes using binary units. Negative sizes are
+// unknown and shown as a dash.
+func FormatSize(bytes int64) string {
+	if bytes < 0 {
+		return "-"
+	}
+
+	if bytes < sizeUnit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	value := float64(bytes)
+	units := []string{"KiB", "MiB", "GiB", "TiB"}
+
+	unit := ""
+	for _, u := range units {
+		value /= sizeUnit
+		unit = u
+
+		if value < sizeUnit {
+			break
+		}
+	}
+
+	return fmt.Sprintf("%.1f %s", value, unit)
+}
+
+// ParseSize parses a size such as 500M, 1.5GiB or 2048 in bytes. The units
+// K, M, G and T are binary like the ones of FormatSize and may be followed by
+// B or iB. Sizes that aren't finite, are negative or don't fit in an int64
+// are rejected.
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "B"), "I")
+
+	multiplier := int64(1)
+
+	for i, unit := range "KMGT" {
+		if strings.HasSuffix(value, string(unit)) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, string(unit)))
+			multiplier = int64(math.Pow(sizeUnit, float64(i+1)))
+
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	bytes := number * float64(multiplier)
+
+	// Infinities are out of range as well
+	if err != nil || math.IsNaN(bytes) || bytes < 0 || bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %s", errInvalidSize, s)
+	}
+
+	return int64(bytes), nil
+}
+
+// orDash returns s or a dash if s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,81 @@
+package ui
+
+import "testing"
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		seconds float64
+		want    string
+	}{
+		{name: "unknown", seconds: 0, want: "-"},
+		{name: "seconds only", seconds: 7, want: "0:07"},
+		{name: "minutes", seconds: 754, want: "12:34"},
+		{name: "hours", seconds: 3723, want: "1:02:03"},
+		{name: "rounded", seconds: 59.6, want: "1:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDuration(tt.seconds); got != tt.want {
+				t.Errorf("FormatDuration(%v) = %q, want %q", tt.seconds, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes int64
+		want  string
+	}{
+		{name: "unknown", bytes: -1, want: "-"},
+		{name: "bytes", bytes: 512, want: "512 B"},
+		{name: "kibibytes", bytes: 1536, want: "1.5 KiB"},
+		{name: "mebibytes", bytes: 120 * 1024 * 1024, want: "120.0 MiB"},
+		{name: "gibibytes", bytes: 3 * 1024 * 1024 * 1024, want: "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatSize(tt.bytes); got != tt.want {
+				t.Errorf("FormatSize(%d) = %q, want %q", tt.bytes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "2048", want: 2048},
+		{input: "512B", want: 512},
+		{input: "500K", want: 500 * 1024},
+		{input: "1.5G", want: 3 * 512 * 1024 * 1024},
+		{input: "200 MiB", want: 200 * 1024 * 1024},
+		{input: "1tb", want: 1024 * 1024 * 1024 * 1024},
+		{input: "", wantErr: true},
+		{input: "-1M", wantErr: true},
+		{input: "NaN", wantErr: true},
+		{input: "Inf", wantErr: true},
+		{input: "-Inf", wantErr: true},
+		{input: "+InfinityK", wantErr: true},
+		{input: "1e400", wantErr: true},
+		{input: "9000000T", wantErr: true},
+		{input: "big", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, %v, want %d (error %t)",
+					tt.input, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
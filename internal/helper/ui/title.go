@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// titleInterval is the minimum time between two updates of the title.
+	titleInterval = time.Second
+
+	// titleName is shown in the title after the progress.
+	titleName = "SwitchTube DL"
+
+	// percent converts a fraction to a percentage.
+	percent = 100
+
+	// Escape sequences saving and restoring the title on the title stack of
+	// xterm compatible terminals, and setting it.
+	titlePush   = "\x1b[22;0t"
+	titlePop    = "\x1b[23;0t"
+	titleFormat = "\x1b]0;%s\a"
+)
+
+// titleReader is a reader that shows the progress of the data read from it in
+// the terminal title written to out, e.g. "[3/12] 46% – SwitchTube DL", so
+// that downloads can be followed from the taskbar.
+type titleReader struct {
+	reader   io.Reader
+	out      io.Writer
+	progress models.ProgressInfo
+	total    int64
+	read     int64
+	updated  time.Time
+}
+
+// titleFor reports whether the title of the terminal file is written to can
+// be set.
+func titleFor(file *os.File) bool {
+	return isTerminal(file) && os.Getenv("TERM") != "dumb"
+}
+
+// newTitleReader creates a titleReader for reading the item of progress with
+// the size total from reader. The current title is saved, to be restored by
+// restore.
+func newTitleReader(
+	reader io.Reader,
+	out io.Writer,
+	total int64,
+	progress models.ProgressInfo,
+) *titleReader {
+	fmt.Fprint(out, titlePush)
+
+	r := &titleReader{
+		reader:   reader,
+		out:      out,
+		progress: progress,
+		total:    total,
+		read:     0,
+		updated:  time.Time{},
+	}
+	r.update(time.Now())
+
+	return r
+}
+
+// Read reads from the underlying reader and updates the title at most every
+// titleInterval.
+func (r *titleReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	if now := time.Now(); now.Sub(r.updated) >= titleInterval {
+		r.update(now)
+	}
+
+	return n, err //nolint:wrapcheck // io.Reader must return io.EOF unwrapped.
+}
+
+// update writes the current progress to the title.
+func (r *titleReader) update(now time.Time) {
+	r.updated = now
+
+	fmt.Fprintf(r.out, titleFormat, titleText(r.progress, r.read, r.total))
+}
+
+// restore restores the title saved by newTitleReader.
+func (r *titleReader) restore() {
+	fmt.Fprint(r.out, titlePop)
+}
+
+// titleText returns the title for read bytes of the item of progress with the
+// size total. The percentage is the one of all items if their total size is
+// known.
+func titleText(progress models.ProgressInfo, read, total int64) string {
+	items := fmt.Sprintf("[%d/%d]", progress.CurrentItem, progress.TotalItems)
+
+	done, size := read, total
+	if progress.TotalBytes > 0 {
+		done, size = progress.DownloadedBytes+read, progress.TotalBytes
+	}
+
+	if size <= 0 {
+		return fmt.Sprintf("%s – %s", items, titleName)
+	}
+
+	return fmt.Sprintf("%s %d%% – %s", items, min(done*percent/size, percent), titleName)
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestTitleText(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress models.ProgressInfo
+		read     int64
+		total    int64
+		want     string
+	}{
+		{
+			name:     "overall progress",
+			progress: models.ProgressInfo{CurrentItem: 3, TotalItems: 12, DownloadedBytes: 400, TotalBytes: 1000},
+			read:     60,
+			total:    200,
+			want:     "[3/12] 46% – SwitchTube DL",
+		},
+		{
+			name:     "single video",
+			progress: models.ProgressInfo{CurrentItem: 1, TotalItems: 1},
+			read:     50,
+			total:    200,
+			want:     "[1/1] 25% – SwitchTube DL",
+		},
+		{
+			name:     "unknown size",
+			progress: models.ProgressInfo{CurrentItem: 2, TotalItems: 5},
+			read:     50,
+			total:    -1,
+			want:     "[2/5] – SwitchTube DL",
+		},
+		{
+			name:     "more than announced",
+			progress: models.ProgressInfo{CurrentItem: 1, TotalItems: 1},
+			read:     300,
+			total:    200,
+			want:     "[1/1] 100% – SwitchTube DL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titleText(tt.progress, tt.read, tt.total); got != tt.want {
+				t.Errorf("titleText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTitleReader(t *testing.T) {
+	var out bytes.Buffer
+
+	progress := models.ProgressInfo{CurrentItem: 1, TotalItems: 2}
+	reader := newTitleReader(strings.NewReader("data"), &out, 4, progress)
+
+	data, err := io.ReadAll(reader)
+	if err != nil || string(data) != "data" {
+		t.Fatalf("ReadAll() = %q, %v, want the data of the underlying reader", data, err)
+	}
+
+	reader.restore()
+
+	want := titlePush + "\x1b]0;[1/2] 0% – SwitchTube DL\a" + titlePop
+	if got := out.String(); got != want {
+		t.Errorf("title output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,232 @@
+// Package history records completed downloads in a local JSON Lines file.
+package history
+
+import (
+	"bufio"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"switchtube-downloader/internal/config"
+	"switchtube-downloader/internal/helper/dir"
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// fileName is the name of the history file in the config directory.
+	fileName = "history.jsonl"
+
+	// File and directory permissions of the history.
+	dirPermissions  = 0o755
+	filePermissions = 0o600
+
+	// maxLineSize is the maximum size of an entry of the history file.
+	maxLineSize = 1 << 20
+)
+
+var (
+	errFailedToChecksum   = errors.New("failed to compute checksum")
+	errFailedToGetPath    = errors.New("failed to get history path")
+	errFailedToReadEntry  = errors.New("failed to read history entry")
+	errFailedToReadFile   = errors.New("failed to read history")
+	errFailedToWriteEntry = errors.New("failed to write history entry")
+)
+
+// DefaultPath returns the default location of the history file, e.g.
+// ~/.config/switchtube-dl/history.jsonl on Linux.
+func DefaultPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToGetPath, err)
+	}
+
+	return filepath.Join(dir, fileName), nil
+}
+
+// Add appends entry to the history file at path, creating it if needed.
+func Add(path string, entry models.HistoryEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirPermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteEntry, err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteEntry, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, filePermissions)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteEntry, err)
+	}
+
+	defer dir.CloseFile(file)
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteEntry, err)
+	}
+
+	return nil
+}
+
+// Load returns all entries of the history file at path, oldest first. A
+// missing file results in an empty history.
+func Load(path string) ([]models.HistoryEntry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToReadFile, err)
+	}
+	defer dir.CloseFile(file)
+
+	var entries []models.HistoryEntry
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var entry models.HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", errFailedToReadEntry, line, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToReadFile, err)
+	}
+
+	return entries, nil
+}
+
+// Search returns the entries whose id, title, channel or path contain query,
+// ignoring case.
+func Search(entries []models.HistoryEntry, query string) []models.HistoryEntry {
+	query = strings.ToLower(query)
+
+	var matches []models.HistoryEntry
+
+	for _, entry := range entries {
+		for _, field := range []string{entry.ID, entry.Title, entry.Channel, entry.Path} {
+			if strings.Contains(strings.ToLower(field), query) {
+				matches = append(matches, entry)
+
+				break
+			}
+		}
+	}
+
+	return matches
+}
+
+// Checksum returns the SHA-256 checksum of the file at path as hex.
+func Checksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToChecksum, err)
+	}
+	defer dir.CloseFile(file)
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToChecksum, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Summarize returns the totals of entries, broken down by channel and by the
+// month of the download. Average speeds only take the entries into account
+// whose download time is known.
+func Summarize(entries []models.HistoryEntry) models.HistoryStats {
+	total := newGroup("")
+	channels := make(map[string]*models.GroupStats)
+	months := make(map[string]*models.GroupStats)
+
+	for _, entry := range entries {
+		month := entry.DownloadedAt.Local().Format("2006-01")
+
+		for _, group := range []*models.GroupStats{
+			&total,
+			groupOf(channels, entry.Channel),
+			groupOf(months, month),
+		} {
+			group.Videos++
+			group.Bytes += entry.Size
+
+			if entry.DownloadSeconds > 0 {
+				group.TimedBytes += entry.Size
+				group.Seconds += entry.DownloadSeconds
+			}
+		}
+	}
+
+	return models.HistoryStats{
+		Total:    finishGroup(total),
+		Channels: sortedGroups(channels, true),
+		Months:   sortedGroups(months, false),
+	}
+}
+
+// newGroup returns empty statistics for name.
+func newGroup(name string) models.GroupStats {
+	return models.GroupStats{
+		Name:         name,
+		Videos:       0,
+		Bytes:        0,
+		TimedBytes:   0,
+		Seconds:      0,
+		AverageSpeed: 0,
+	}
+}
+
+// groupOf returns the statistics of name in groups, adding them if needed.
+func groupOf(groups map[string]*models.GroupStats, name string) *models.GroupStats {
+	if groups[name] == nil {
+		group := newGroup(name)
+		groups[name] = &group
+	}
+
+	return groups[name]
+}
+
+// finishGroup computes the average speed of group in bytes per second.
+func finishGroup(group models.GroupStats) models.GroupStats {
+	if group.Seconds > 0 {
+		group.AverageSpeed = float64(group.TimedBytes) / group.Seconds
+	}
+
+	return group
+}
+
+// sortedGroups returns groups sorted by the number of bytes, most first, or
+// by name.
+func sortedGroups(groups map[string]*models.GroupStats, byBytes bool) []models.GroupStats {
+	sorted := make([]models.GroupStats, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, finishGroup(*group))
+	}
+
+	slices.SortFunc(sorted, func(a, b models.GroupStats) int {
+		if byBytes && a.Bytes != b.Bytes {
+			return cmp.Compare(b.Bytes, a.Bytes)
+		}
+
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return sorted
+}
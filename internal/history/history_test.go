@@ -0,0 +1,141 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"switchtube-downloader/internal/models"
+)
+
+func TestAddAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", fileName)
+
+	entries, err := Load(path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Load() of missing file = %v, %v, want empty history", entries, err)
+	}
+
+	want := []models.HistoryEntry{
+		{ID: "v1", Title: "Intro", Channel: "OS", Path: "/videos/Intro.mp4", Size: 10},
+		{ID: "v2", Title: "Paging", Channel: "OS", Path: "/videos/Paging.mp4", Size: 20},
+	}
+
+	for _, entry := range want {
+		entry.DownloadedAt = time.Now()
+		if err := Add(path, entry); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Load() = %d entries, want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].Path != want[i].Path || got[i].Size != want[i].Size {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadInvalidEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(path, []byte("{\"id\":\"v1\"}\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Load() error = nil, want error for invalid entry")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	entries := []models.HistoryEntry{
+		{ID: "v1", Title: "Intro", Channel: "Operating Systems", Path: "/videos/os/Intro.mp4"},
+		{ID: "v2", Title: "Sockets", Channel: "Networks", Path: "/videos/net/Sockets.mp4"},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "intro", want: []string{"v1"}},
+		{query: "NETWORKS", want: []string{"v2"}},
+		{query: "/videos/", want: []string{"v1", "v2"}},
+		{query: "v2", want: []string{"v2"}},
+		{query: "paging", want: nil},
+	}
+
+	for _, tt := range tests {
+		got := Search(entries, tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q) = %d entries, want %d", tt.query, len(got), len(tt.want))
+
+			continue
+		}
+
+		for i, entry := range got {
+			if entry.ID != tt.want[i] {
+				t.Errorf("Search(%q)[%d] = %s, want %s", tt.query, i, entry.ID, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(path, []byte("video"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Checksum(path)
+	if err != nil {
+		t.Fatalf("Checksum() error = %v", err)
+	}
+
+	const want = "0cab1c9617404faf2b24e221e189ca5945813e14d3f766345b09ca13bbe28ffc"
+	if got != want {
+		t.Errorf("Checksum() = %s, want %s", got, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	march := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	april := time.Date(2024, 4, 2, 12, 0, 0, 0, time.Local)
+
+	entries := []models.HistoryEntry{
+		{ID: "v1", Channel: "OS", Size: 300, DownloadedAt: march, DownloadSeconds: 1},
+		{ID: "v2", Channel: "OS", Size: 100, DownloadedAt: april, DownloadSeconds: 3},
+		{ID: "v3", Channel: "Networks", Size: 200, DownloadedAt: april, DownloadSeconds: 0},
+	}
+
+	stats := Summarize(entries)
+
+	if stats.Total.Videos != 3 || stats.Total.Bytes != 600 {
+		t.Errorf("total = %+v, want 3 videos and 600 bytes", stats.Total)
+	}
+
+	// The download without a known duration doesn't count towards the speed
+	if stats.Total.AverageSpeed != 100 {
+		t.Errorf("total average speed = %v, want 100", stats.Total.AverageSpeed)
+	}
+
+	if len(stats.Channels) != 2 || stats.Channels[0].Name != "OS" || stats.Channels[0].Bytes != 400 {
+		t.Errorf("channels = %+v, want OS with 400 bytes first", stats.Channels)
+	}
+
+	if len(stats.Months) != 2 || stats.Months[0].Name != "2024-03" || stats.Months[1].Videos != 2 {
+		t.Errorf("months = %+v, want 2024-03 and 2024-04 with 2 videos", stats.Months)
+	}
+
+	if stats.Months[0].AverageSpeed != 300 {
+		t.Errorf("average speed of 2024-03 = %v, want 300", stats.Months[0].AverageSpeed)
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+// Channel represents a channel.
+type Channel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ChannelListing represents the contents of a channel.
+type ChannelListing struct {
+	ID     string      `json:"id"`
+	Name   string      `json:"name"`
+	Videos []VideoInfo `json:"videos"`
+}
+
+// VideoInfo describes a single video of a channel listing. Size is -1 if it
+// is unknown.
+type VideoInfo struct {
+	Index    int     `json:"index"`
+	ID       string  `json:"id"`
+	Episode  string  `json:"episode"`
+	Title    string  `json:"title"`
+	Duration float64 `json:"duration"`
+	Size     int64   `json:"size"`
+}
+
+// SearchResult holds the videos and channels matching a search query.
+type SearchResult struct {
+	Videos   []Video   `json:"videos"`
+	Channels []Channel `json:"channels"`
+}
+
+// ChannelSelection holds the videos of a channel that were selected for
+// download. Profile is the name of the profile the channel belongs to if it
+// was browsed as part of one.
+type ChannelSelection struct {
+	Profile string  `json:"profile"`
+	Channel Channel `json:"channel"`
+	Videos  []Video `json:"videos"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+// Networks a ClientConfig can restrict connections to.
+const (
+	NetworkIPv4 = "tcp4"
+	NetworkIPv6 = "tcp6"
+)
+
+// ClientConfig holds configuration options for the HTTP client used to talk to
+// SwitchTube.
+type ClientConfig struct {
+	// BaseURL is the URL of the SwitchTube instance, e.g. a test instance of
+	// an institution. Empty uses https://tube.switch.ch/.
+	BaseURL string
+
+	// Proxy is the URL of an HTTP(S) or SOCKS5 proxy. If empty, the proxy is
+	// taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string
+
+	// CACert is the path to a PEM file with additional CA certificates, e.g.
+	// of a TLS-intercepting proxy.
+	CACert string
+
+	// Insecure disables the verification of TLS certificates.
+	Insecure bool
+
+	// APITimeout limits the duration of metadata requests. Zero uses the
+	// default of 30 seconds.
+	APITimeout time.Duration
+
+	// ReadTimeout is the time a video stream may go without receiving data
+	// before it is considered stalled. Downloads have no overall timeout, so
+	// large videos on slow connections never get aborted. Zero uses the
+	// default of one minute.
+	ReadTimeout time.Duration
+
+	// CacheDir is the directory metadata responses are stored in together
+	// with their ETag, so that repeated requests only have to check whether
+	// they changed. Responses aren't cached if it is empty.
+	CacheDir string
+
+	// MaxIdleConns is the maximum number of idle connections kept open for
+	// reuse. Zero uses the default of 100.
+	MaxIdleConns int
+
+	// DisableHTTP2 restricts connections to HTTP/1.1, which some VPNs and
+	// middleboxes handle more reliably.
+	DisableHTTP2 bool
+
+	// KeepAlive is the interval of TCP keep-alive probes, which detect dead
+	// connections on flaky networks. Zero uses the default of 30 seconds and
+	// a negative value disables them.
+	KeepAlive time.Duration
+
+	// DialTimeout limits how long establishing a connection may take. Zero
+	// uses the default of 30 seconds.
+	DialTimeout time.Duration
+
+	// Network is NetworkIPv4 or NetworkIPv6 to only connect over that IP
+	// version, e.g. if IPv6 is broken on a VPN. Both are used if it is empty.
+	Network string
+
+	// UserAgent identifies the client in every request. Go's default is sent
+	// if it is empty.
+	UserAgent string
+
+	// Headers are sent with every request in the form "Name: Value", e.g. if
+	// an institution requires identification headers. Headers the request
+	// sets itself, such as the authorization, take precedence.
+	Headers []string
+
+	// AuthRedirectHosts are the hosts the access token is still sent to when
+	// a request is redirected there, including their subdomains. Redirects to
+	// any other host than the one of the request have it stripped.
+	AuthRedirectHosts []string
+
+	// RateLimit is the maximum number of metadata requests per second, which
+	// keeps bulk downloads from triggering the throttling of SwitchTube.
+	// Requests aren't limited if it is zero.
+	RateLimit float64
+}
+
+// Account describes the SwitchTube account an access token belongs to.
+// Scopes are the permissions of the token, which are empty if SwitchTube
+// doesn't report them, and ExpiresAt is when the token expires, which is zero
+// if it doesn't report that.
+type Account struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"` //nolint:tagliatelle // SwitchTube API field
+}
+
+// TokenStatus describes the access token in use. Source is where the token
+// comes from, either the token store or the environment variable overriding
+// it. Account is the account of the token if SwitchTube accepts it. Checked
+// is false if SwitchTube doesn't report the account, in which case it is
+// unknown whether the token is valid. Times that aren't known are zero.
+type TokenStatus struct {
+	Source  string    `json:"source"`
+	Valid   bool      `json:"valid"`
+	Checked bool      `json:"checked"`
+	Account *Account  `json:"account,omitempty"`
+	Created time.Time `json:"created,omitzero"`
+	Expires time.Time `json:"expires,omitzero"`
+}
@@ -1,12 +1,220 @@
 // Package models defines the structures used in the application.
 package models
 
-// DownloadConfig holds configuration options for the Download function.
+import "time"
+
+// Formats of the download progress.
+const (
+	ProgressFormatBar  = "bar"
+	ProgressFormatJSON = "json"
+)
+
+// Policies for files that already exist.
+const (
+	ConflictPrompt    = "prompt"
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// Containers a video can be remuxed to.
+const (
+	RemuxMKV = "mkv"
+	RemuxMP4 = "mp4"
+)
+
+// Formats audio can be extracted in.
+const (
+	AudioMP3 = "mp3"
+	AudioM4A = "m4a"
+)
+
+// Orders the videos of a channel can be downloaded in.
+const (
+	OrderAPI     = "api"
+	OrderEpisode = "episode"
+	OrderDate    = "date"
+	OrderTitle   = "title"
+)
+
+// Tools downloads can be handed to instead of downloading them directly.
+const (
+	ExternalAria2c = "aria2c"
+	ExternalCurl   = "curl"
+)
+
+// DefaultOutputTemplate nests the channels of a profile in a profile folder.
+const DefaultOutputTemplate = "{profile}/{channel}"
+
+// OutputStdout as the output writes a single video to standard output instead
+// of a file, e.g. to pipe it into a player.
+const OutputStdout = "-"
+
+// DownloadConfig holds configuration options for the Download function. Part
+// of it is stored with failed downloads as RetryOptions to retry them with the
+// same options.
 type DownloadConfig struct {
-	Media      string
-	UseEpisode bool
-	Skip       bool
-	Force      bool
-	All        bool
-	Output     string
+	Media      string `json:"media"`
+	UseEpisode bool   `json:"useEpisode"`
+	Skip       bool   `json:"skip"`
+	Force      bool   `json:"force"`
+	All        bool   `json:"all"`
+	Output     string `json:"output"`
+
+	// ProgressFormat is either ProgressFormatBar or ProgressFormatJSON. It
+	// defaults to ProgressFormatBar if empty.
+	ProgressFormat string `json:"progressFormat"`
+
+	// ProgressStyle is one of the ProgressStyle* constants and draws the
+	// progress bar. It defaults to ProgressStyleDefault if empty.
+	ProgressStyle string `json:"progressStyle"`
+
+	// Reporter receives the progress of downloads instead of the progress
+	// bar or JSON events if it isn't nil.
+	Reporter ProgressReporter `json:"-"`
+
+	// JSON prints the results summary as JSON instead of text.
+	JSON bool `json:"json"`
+
+	// OnSummary receives the results summary of every channel instead of it
+	// being printed if it isn't nil, e.g. for programs using the library.
+	OnSummary func(summary DownloadSummary) `json:"-"`
+
+	// OnConflict is one of the Conflict* policies and applies to existing
+	// files unless Force or Skip is set. It defaults to ConflictPrompt if
+	// empty.
+	OnConflict string `json:"onConflict"`
+
+	// IfChanged downloads existing videos again only if they differ from the
+	// remote video, instead of applying Skip or OnConflict. Force takes
+	// precedence.
+	IfChanged bool `json:"ifChanged"`
+
+	// Mirror downloads every video of a channel that doesn't exist yet and
+	// lists the files of the channel folder that no longer belong to a video
+	// of the channel. Prune removes them after a confirmation, PruneTo moves
+	// them to that folder instead.
+	Mirror  bool   `json:"mirror"`
+	Prune   bool   `json:"prune"`
+	PruneTo string `json:"pruneTo"`
+
+	// OutputTemplate names the folders of a channel inside Output, e.g.
+	// "{profile}/{channel}". It defaults to DefaultOutputTemplate if empty.
+	OutputTemplate string `json:"outputTemplate"`
+
+	// Slug turns file and folder names into portable ASCII, transliterating
+	// umlauts and removing accents.
+	Slug bool `json:"slug"`
+
+	// LongPaths uses the Windows long path prefix instead of shortening titles
+	// to keep paths within 260 characters.
+	LongPaths bool `json:"longPaths"`
+
+	// WindowsSafe makes file and folder names valid on Windows, avoiding
+	// reserved names such as CON and trailing dots.
+	WindowsSafe bool `json:"windowsSafe"`
+
+	// Remux is the container, RemuxMKV or RemuxMP4, that downloaded videos
+	// are losslessly remuxed to with ffmpeg. Videos are kept as they are if
+	// it is empty.
+	Remux string `json:"remux"`
+
+	// ExtractAudio extracts the audio of downloaded videos with ffmpeg in
+	// AudioFormat, AudioMP3 or AudioM4A, which defaults to AudioMP3 if empty.
+	// The video is removed afterwards unless KeepVideo is set.
+	ExtractAudio bool   `json:"extractAudio"`
+	AudioFormat  string `json:"audioFormat"`
+	KeepVideo    bool   `json:"keepVideo"`
+
+	// Playlist writes an M3U playlist of the downloaded videos to the folder
+	// of a channel.
+	Playlist bool `json:"playlist"`
+
+	// WriteNFO writes tvshow.nfo for a channel and an NFO file for every
+	// video, which Kodi and Jellyfin read.
+	WriteNFO bool `json:"writeNfo"`
+
+	// NotifyWebhook is a URL the results of a channel download are posted to
+	// as JSON.
+	NotifyWebhook string `json:"notifyWebhook"`
+
+	// Failures is the path of the file failed downloads are recorded in, so
+	// that they can be retried later. Failures aren't recorded if it is
+	// empty.
+	Failures string `json:"failures"`
+
+	// History is the path of the download history file every completed
+	// download is recorded in. Downloads aren't recorded if it is empty.
+	History string `json:"history"`
+
+	// VideoTimeout aborts the download of a single video, including its
+	// metadata requests, after this long. Videos have no limit if it is zero.
+	VideoTimeout time.Duration `json:"videoTimeout"`
+
+	// RunTimeout aborts a whole download or sync run after this long. Runs
+	// have no limit if it is zero.
+	RunTimeout time.Duration `json:"runTimeout"`
+
+	// RetryPasses is the number of times the failed videos of a channel are
+	// retried after all others have been downloaded.
+	RetryPasses int `json:"retryPasses"`
+
+	// NewOnly downloads only the videos of a channel that were published
+	// after the last video a previous run downloaded, without a selection
+	// list.
+	NewOnly bool `json:"newOnly"`
+
+	// Order is one of the Order* constants and sorts the videos of a channel
+	// for the selection list and the download. It defaults to OrderAPI if
+	// empty. Reverse reverses the order.
+	Order   string `json:"order"`
+	Reverse bool   `json:"reverse"`
+
+	// NoSize skips fetching the size of every video of a channel for the
+	// selection list.
+	NoSize bool `json:"noSize"`
+
+	// MinFilesize and MaxFilesize skip videos whose size in bytes is below or
+	// above them, unless their size is unknown. A bound of zero is disabled.
+	MinFilesize int64 `json:"minFilesize"`
+	MaxFilesize int64 `json:"maxFilesize"`
+
+	// Smallest and Largest download the smallest or largest variant of a
+	// video by file size instead of the first one SwitchTube lists.
+	Smallest bool `json:"smallest"`
+	Largest  bool `json:"largest"`
+
+	// MaxHeight prefers the variant with the highest vertical resolution
+	// that doesn't exceed it, as far as the resolution can be read from the
+	// name or path of the variants. It is disabled if zero.
+	MaxHeight int `json:"maxHeight"`
+
+	// ExternalDownloader is ExternalAria2c or ExternalCurl, which downloads
+	// the media of videos instead of the built-in downloader if set.
+	ExternalDownloader string `json:"externalDownloader"`
+
+	// WaitLock waits for another instance downloading into the same folder,
+	// e.g. of a channel, to finish instead of failing.
+	WaitLock bool `json:"waitLock"`
+
+	// ExecBefore is a shell command run for every video before it is
+	// downloaded, which receives the metadata of the video as a HookVideo.
+	// The video is skipped if the command exits with a non-zero status.
+	ExecBefore string `json:"execBefore"`
+}
+
+// AccountConfig describes an account defined in the config file with the
+// defaults it overrides. Default is set for the account used when no other
+// is selected.
+type AccountConfig struct {
+	Name     string            `json:"name"`
+	Default  bool              `json:"default"`
+	Settings map[string]string `json:"settings"`
+}
+
+// Alias represents a name defined in the config file for a video, channel or
+// profile id or link.
+type Alias struct {
+	Name   string `json:"name"`
+	Target string `json:"target"`
 }
@@ -1,7 +1,52 @@
 package models
 
+import "time"
+
 // ProgressInfo tracks download progress information.
 type ProgressInfo struct {
 	CurrentItem int
 	TotalItems  int
+
+	// Overall progress of a channel download. TotalBytes is zero when there is
+	// no overall progress to show, e.g. for a single video.
+	DownloadedBytes int64
+	TotalBytes      int64
+	StartTime       time.Time
+}
+
+// Styles of the progress bar.
+const (
+	ProgressStyleDefault = "default"
+	ProgressStyleASCII   = "ascii"
+	ProgressStyleUnicode = "unicode"
+	ProgressStyleBraille = "braille"
+	ProgressStyleMinimal = "minimal"
+)
+
+// States of a download reported in progress events.
+const (
+	ProgressStarted     = "started"
+	ProgressDownloading = "downloading"
+	ProgressFinished    = "finished"
+	ProgressFailed      = "failed"
+)
+
+// ProgressEvent reports the progress of downloading a single video. Speed is
+// the speed over the last seconds and AverageSpeed the average speed since
+// the start, both in bytes per second, and Total is -1 if unknown.
+type ProgressEvent struct {
+	VideoID      string  `json:"videoId"`
+	File         string  `json:"file"`
+	Item         int     `json:"item"`
+	Items        int     `json:"items"`
+	Bytes        int64   `json:"bytes"`
+	Total        int64   `json:"total"`
+	Speed        float64 `json:"speed"`
+	AverageSpeed float64 `json:"averageSpeed"`
+	State        string  `json:"state"`
+}
+
+// ProgressReporter receives progress events while videos are downloaded.
+type ProgressReporter interface {
+	Report(event ProgressEvent)
 }
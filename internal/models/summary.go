@@ -0,0 +1,136 @@
+package models
+
+import "time"
+
+// Statuses of a video in a download summary.
+const (
+	StatusDownloaded = "downloaded"
+	StatusSkipped    = "skipped"
+	StatusFailed     = "failed"
+)
+
+// DownloadSummary is the result of downloading the selected videos of a
+// channel. Videos holds the result of every selected video in selection
+// order.
+type DownloadSummary struct {
+	Channel    string        `json:"channel"`
+	Selected   int           `json:"selected"`
+	Downloaded int           `json:"downloaded"`
+	Failed     []Video       `json:"failed"`
+	Videos     []VideoResult `json:"videos"`
+}
+
+// VideoResult is the result of downloading a single video of a channel. Size
+// is -1 if it is unknown. Seconds is the time the download took and Speed the
+// average speed in bytes per second, both 0 unless the video was downloaded.
+// Exists is set if the video was skipped because its file already exists,
+// rather than because of the size limits or the --exec-before command.
+type VideoResult struct {
+	ID      string  `json:"id"`
+	Title   string  `json:"title"`
+	Status  string  `json:"status"`
+	Size    int64   `json:"size"`
+	Seconds float64 `json:"seconds"`
+	Speed   float64 `json:"speed"`
+	Exists  bool    `json:"exists,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// ProfileSummary is the result of downloading all channels of a profile.
+type ProfileSummary struct {
+	Profile  string   `json:"profile"`
+	Channels int      `json:"channels"`
+	Failed   []string `json:"failed"`
+}
+
+// WebhookNotification is posted to the notification webhook when the
+// download of a channel completes. Text and Content hold a human-readable
+// message for Slack and Matrix respectively Discord.
+type WebhookNotification struct {
+	DownloadSummary
+
+	Text            string  `json:"text"`
+	Content         string  `json:"content"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// HistoryEntry records a completed download. Path is the absolute path of
+// the downloaded file, Checksum its SHA-256 checksum as hex and
+// DownloadSeconds the time the download took.
+type HistoryEntry struct {
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+	Channel         string    `json:"channel"`
+	Path            string    `json:"path"`
+	Size            int64     `json:"size"`
+	Checksum        string    `json:"checksum"`
+	DownloadedAt    time.Time `json:"downloadedAt"`
+	DownloadSeconds float64   `json:"downloadSeconds"`
+}
+
+// HistoryStats are the totals of the download history, broken down by
+// channel and by month.
+type HistoryStats struct {
+	Total    GroupStats   `json:"total"`
+	Channels []GroupStats `json:"channels"`
+	Months   []GroupStats `json:"months"`
+}
+
+// GroupStats are the totals of a group of downloads. AverageSpeed is in bytes
+// per second and only considers TimedBytes, the bytes of the downloads whose
+// download time in Seconds is known.
+type GroupStats struct {
+	Name         string  `json:"name"`
+	Videos       int     `json:"videos"`
+	Bytes        int64   `json:"bytes"`
+	TimedBytes   int64   `json:"timedBytes"`
+	Seconds      float64 `json:"seconds"`
+	AverageSpeed float64 `json:"averageSpeed"`
+}
+
+// FailedDownload is a video whose download failed, recorded together with the
+// options that retry the download of the video.
+type FailedDownload struct {
+	ID       string       `json:"id"`
+	Title    string       `json:"title"`
+	Channel  string       `json:"channel"`
+	Error    string       `json:"error"`
+	FailedAt time.Time    `json:"failedAt"`
+	Options  RetryOptions `json:"config"`
+}
+
+// RetryOptions are the options of a failed download that decide where and
+// how its video is downloaded again. They are named like the fields of
+// DownloadConfig. Secrets such as the webhook URL and commands such as
+// ExecBefore are left out, so that the failures file neither leaks them nor
+// runs commands written to it. FileTitle is the title of the video in its
+// file name, which differs from its title if another video of its channel
+// has the same one.
+type RetryOptions struct {
+	UseEpisode         bool          `json:"useEpisode"`
+	Skip               bool          `json:"skip"`
+	Force              bool          `json:"force"`
+	Output             string        `json:"output"`
+	FileTitle          string        `json:"fileTitle,omitempty"`
+	OnConflict         string        `json:"onConflict"`
+	IfChanged          bool          `json:"ifChanged"`
+	Slug               bool          `json:"slug"`
+	LongPaths          bool          `json:"longPaths"`
+	WindowsSafe        bool          `json:"windowsSafe"`
+	Remux              string        `json:"remux"`
+	ExtractAudio       bool          `json:"extractAudio"`
+	AudioFormat        string        `json:"audioFormat"`
+	KeepVideo          bool          `json:"keepVideo"`
+	WriteNFO           bool          `json:"writeNfo"`
+	VideoTimeout       time.Duration `json:"videoTimeout"`
+	Smallest           bool          `json:"smallest"`
+	Largest            bool          `json:"largest"`
+	MaxHeight          int           `json:"maxHeight"`
+	ExternalDownloader string        `json:"externalDownloader"`
+}
+
+// QueueEntry is a video, channel or profile waiting in the download queue.
+type QueueEntry struct {
+	Media   string    `json:"media"`
+	AddedAt time.Time `json:"addedAt"`
+}
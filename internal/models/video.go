@@ -1,8 +1,57 @@
 package models
 
-// Video represents a Video.
+import "time"
+
+// Video represents a Video. PublishedAt is zero if SwitchTube doesn't report
+// it.
 type Video struct {
-	ID      string `json:"id"`
-	Title   string `json:"title"`
-	Episode string `json:"episode"`
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Episode     string    `json:"episode"`
+	Duration    float64   `json:"duration"`
+	Description string    `json:"description"`
+	PublishedAt time.Time `json:"published_at"` //nolint:tagliatelle // SwitchTube API field
+}
+
+// VideoDetails describes a video including its downloadable variants.
+type VideoDetails struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Episode  string    `json:"episode"`
+	Duration float64   `json:"duration"`
+	Variants []Variant `json:"variants"`
+}
+
+// Variant describes a downloadable variant of a video. Size is -1 if it is
+// unknown. Name is the label of the variant, e.g. "720p", if SwitchTube
+// reports one. Height is the vertical resolution read from the name or path,
+// which is 0 if unknown.
+type Variant struct {
+	Name      string `json:"name,omitempty"`
+	MediaType string `json:"mediaType"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Height    int    `json:"height,omitempty"`
+}
+
+// HookVideo is the metadata of a video the command of --exec-before receives
+// as JSON on its standard input. Size is -1 if it is unknown.
+type HookVideo struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Episode     string    `json:"episode"`
+	Duration    float64   `json:"duration"`
+	Description string    `json:"description"`
+	PublishedAt time.Time `json:"publishedAt,omitzero"`
+	Channel     string    `json:"channel"`
+	Size        int64     `json:"size"`
+}
+
+// MediaURL is the direct URL of the media of a video and the header that has
+// to be sent with the request for it.
+type MediaURL struct {
+	ID     string            `json:"id"`
+	Title  string            `json:"title"`
+	URL    string            `json:"url"`
+	Header map[string]string `json:"header"`
 }
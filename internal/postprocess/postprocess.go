@@ -0,0 +1,248 @@
+// Package postprocess converts downloaded videos with ffmpeg.
+package postprocess
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// percent converts a fraction to a percentage.
+	percent = 100
+
+	// microseconds is the number of microseconds in a second, the unit of
+	// the progress ffmpeg reports.
+	microseconds = 1_000_000
+)
+
+var (
+	// ErrFFmpegNotFound is returned when post-processing is requested but
+	// ffmpeg isn't installed.
+	ErrFFmpegNotFound = errors.New(
+		"ffmpeg not found on PATH, install it to use --remux or --extract-audio",
+	)
+
+	errFailedToExtractAudio = errors.New("failed to extract audio")
+	errFailedToRemux        = errors.New("failed to remux video")
+)
+
+// lookPath finds the ffmpeg executable, which tests replace.
+var lookPath = exec.LookPath
+
+// audioCodecs are the ffmpeg arguments encoding the audio of each format.
+var audioCodecs = map[string][]string{
+	models.AudioMP3: {"-c:a", "libmp3lame", "-q:a", "2"},
+	models.AudioM4A: {"-c:a", "aac", "-b:a", "128k"},
+}
+
+// Enabled reports whether config requires any post-processing.
+func Enabled(config models.DownloadConfig) bool {
+	return config.Remux != "" || config.ExtractAudio
+}
+
+// InputName returns the file a video has to be downloaded to so that
+// post-processing according to config produces output. It is output with the
+// extension of the downloaded media in filename.
+func InputName(output, filename string, config models.DownloadConfig) string {
+	if !Enabled(config) {
+		return output
+	}
+
+	return withExtension(output, filepath.Ext(filename))
+}
+
+// OutputName returns the file post-processing according to config produces
+// from the downloaded file filename, which is the audio file if the video
+// isn't kept.
+func OutputName(filename string, config models.DownloadConfig) string {
+	if config.ExtractAudio && !config.KeepVideo {
+		return audioName(filename, config)
+	}
+
+	return videoName(filename, config)
+}
+
+// videoName returns the name of the video filename after remuxing.
+func videoName(filename string, config models.DownloadConfig) string {
+	if config.Remux == "" {
+		return filename
+	}
+
+	return withExtension(filename, "."+config.Remux)
+}
+
+// audioName returns the name of the audio extracted from filename.
+func audioName(filename string, config models.DownloadConfig) string {
+	return withExtension(filename, "."+audioFormat(config))
+}
+
+// audioFormat returns the format audio is extracted in, which defaults to
+// models.AudioMP3.
+func audioFormat(config models.DownloadConfig) string {
+	if config.AudioFormat == "" {
+		return models.AudioMP3
+	}
+
+	return config.AudioFormat
+}
+
+// withExtension replaces the extension of filename with extension.
+func withExtension(filename, extension string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + extension
+}
+
+// CheckFFmpeg returns ErrFFmpegNotFound if config requires ffmpeg and it
+// isn't installed, so that the error shows up before anything is downloaded.
+func CheckFFmpeg(config models.DownloadConfig) error {
+	if !Enabled(config) {
+		return nil
+	}
+
+	if _, err := lookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("%w: %w", ErrFFmpegNotFound, err)
+	}
+
+	return nil
+}
+
+// Run post-processes the downloaded video filename according to config and
+// returns the name of the resulting file. The video is remuxed first, then
+// its audio is extracted, removing the video unless it is kept. duration is
+// the length of the video in seconds, which is used to show the progress of
+// ffmpeg.
+func Run(filename string, duration float64, config models.DownloadConfig) (string, error) {
+	video := videoName(filename, config)
+	if video != filename {
+		err := ffmpeg(filename, video, duration, config, "Remuxing", "-map", "0", "-c", "copy")
+		if err != nil {
+			return filename, fmt.Errorf("%w: %w", errFailedToRemux, err)
+		}
+
+		removeFile(filename, "original video")
+	}
+
+	if !config.ExtractAudio {
+		return video, nil
+	}
+
+	audio := audioName(video, config)
+	args := append([]string{"-vn"}, audioCodecs[audioFormat(config)]...)
+
+	if err := ffmpeg(video, audio, duration, config, "Extracting audio of", args...); err != nil {
+		return video, fmt.Errorf("%w: %w", errFailedToExtractAudio, err)
+	}
+
+	if config.KeepVideo {
+		return video, nil
+	}
+
+	removeFile(video, "video")
+
+	return audio, nil
+}
+
+// ffmpeg converts input to output with the given ffmpeg arguments, showing
+// the progress after action. A partial output is removed if ffmpeg fails.
+func ffmpeg(
+	input, output string,
+	duration float64,
+	config models.DownloadConfig,
+	action string,
+	args ...string,
+) error {
+	path, err := lookPath("ffmpeg")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFFmpegNotFound, err)
+	}
+
+	slog.Info("running ffmpeg", "action", action, "file", input, "output", output)
+
+	// Titles may start with a dash or contain a colon, which ffmpeg would
+	// parse as an option or a protocol unless the paths are absolute
+	inputArg, err := filepath.Abs(input)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	outputArg, err := filepath.Abs(output)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	args = append([]string{
+		"-hide_banner", "-loglevel", "error", "-nostats", "-progress", "pipe:1", "-y",
+		"-i", inputArg,
+	}, append(args, outputArg)...)
+
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = os.Stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	label := fmt.Sprintf("%s %s", action, filepath.Base(input))
+	show := config.ProgressFormat != models.ProgressFormatJSON && config.Reporter == nil
+	reportProgress(stdout, label, duration, show)
+
+	if err := cmd.Wait(); err != nil {
+		if err := os.Remove(output); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to remove partial output", "file", output, "error", err)
+		}
+
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// removeFile removes a file that has been converted, logging a failure
+// instead of failing the download.
+func removeFile(filename, description string) {
+	if err := os.Remove(filename); err != nil {
+		slog.Warn("failed to remove "+description, "file", filename, "error", err)
+	}
+}
+
+// reportProgress reads the key=value progress of ffmpeg from r and, if show
+// is set, prints the percentage of duration that has been processed.
+func reportProgress(r io.Reader, label string, duration float64, show bool) {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+		if !show {
+			continue
+		}
+
+		switch key {
+		case "out_time_us":
+			processed, err := strconv.ParseFloat(value, 64)
+			if err != nil || duration <= 0 || processed < 0 {
+				continue
+			}
+
+			done := min(processed/microseconds/duration, 1)
+			fmt.Fprintf(os.Stderr, "\r%s: %3.0f%%", label, done*percent)
+		case "progress":
+			if value == "end" {
+				fmt.Fprintf(os.Stderr, "\r%s: done\n", label)
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package postprocess
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"switchtube-downloader/internal/models"
+)
+
+// fakeFFmpeg replaces ffmpeg with a shell script that copies its input to
+// its output, or fails if fail is set or the paths aren't absolute.
+func fakeFFmpeg(t *testing.T, fail bool) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg is a shell script")
+	}
+
+	script := `#!/bin/sh
+while [ "$1" != "-i" ]; do shift; done
+input="$2"
+for last; do :; done
+case "$input" in /*) ;; *) exit 2 ;; esac
+case "$last" in /*) ;; *) exit 2 ;; esac
+echo "out_time_us=500000"
+echo "progress=end"
+cp "$input" "$last"
+`
+	if fail {
+		script = "#!/bin/sh\necho partial > \"$(eval echo \\${$#})\"\nexit 1\n"
+	}
+
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	original := lookPath
+	lookPath = func(string) (string, error) { return path, nil }
+
+	t.Cleanup(func() { lookPath = original })
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     models.DownloadConfig
+		wantOutput string
+		wantInput  string
+	}{
+		{
+			name:       "no post-processing",
+			config:     models.DownloadConfig{},
+			wantOutput: "Intro.mp4",
+			wantInput:  "Intro (1).mp4",
+		},
+		{
+			name:       "remux",
+			config:     models.DownloadConfig{Remux: models.RemuxMKV},
+			wantOutput: "Intro.mkv",
+			wantInput:  "Intro (1).mp4",
+		},
+		{
+			name:       "extract audio",
+			config:     models.DownloadConfig{ExtractAudio: true},
+			wantOutput: "Intro.mp3",
+			wantInput:  "Intro (1).mp4",
+		},
+		{
+			name: "extract audio and keep remuxed video",
+			config: models.DownloadConfig{
+				Remux:        models.RemuxMKV,
+				ExtractAudio: true,
+				KeepVideo:    true,
+			},
+			wantOutput: "Intro.mkv",
+			wantInput:  "Intro (1).mp4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+
+			if got := OutputName("Intro.mp4", config); got != tt.wantOutput {
+				t.Errorf("OutputName() = %q, want %q", got, tt.wantOutput)
+			}
+
+			output := "Intro (1)" + filepath.Ext(tt.wantOutput)
+			if got := InputName(output, "Intro.mp4", config); got != tt.wantInput {
+				t.Errorf("InputName() = %q, want %q", got, tt.wantInput)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		remux      string
+		audio      bool
+		keepVideo  bool
+		fail       bool
+		wantOutput string
+		wantErr    bool
+		wantInput  bool
+	}{
+		{name: "remux", remux: models.RemuxMKV, wantOutput: "Intro.mkv", wantInput: false},
+		{name: "same container", remux: models.RemuxMP4, wantOutput: "Intro.mp4", wantInput: true},
+		{name: "no remux", remux: "", wantOutput: "Intro.mp4", wantInput: true},
+		{name: "extract audio", audio: true, wantOutput: "Intro.m4a", wantInput: false},
+		{
+			name:       "extract audio and keep video",
+			audio:      true,
+			keepVideo:  true,
+			wantOutput: "Intro.mp4",
+			wantInput:  true,
+		},
+		{
+			name:       "remux and extract audio",
+			remux:      models.RemuxMKV,
+			audio:      true,
+			wantOutput: "Intro.m4a",
+			wantInput:  false,
+		},
+		{
+			name:       "ffmpeg fails",
+			remux:      models.RemuxMKV,
+			fail:       true,
+			wantOutput: "Intro.mp4",
+			wantErr:    true,
+			wantInput:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeFFmpeg(t, tt.fail)
+
+			dir := t.TempDir()
+			input := filepath.Join(dir, "Intro.mp4")
+
+			if err := os.WriteFile(input, []byte("video"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			config := models.DownloadConfig{
+				Remux:          tt.remux,
+				ExtractAudio:   tt.audio,
+				AudioFormat:    models.AudioM4A,
+				KeepVideo:      tt.keepVideo,
+				ProgressFormat: models.ProgressFormatJSON,
+			}
+
+			output, err := Run(input, 1, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if output != filepath.Join(dir, tt.wantOutput) {
+				t.Errorf("Run() = %q, want %q", output, filepath.Join(dir, tt.wantOutput))
+			}
+
+			if _, err := os.Stat(input); (err == nil) != tt.wantInput {
+				t.Errorf("input exists = %v, want %v", err == nil, tt.wantInput)
+			}
+
+			if tt.keepVideo {
+				if _, err := os.Stat(filepath.Join(dir, "Intro.m4a")); err != nil {
+					t.Errorf("audio was not extracted: %v", err)
+				}
+			}
+
+			if tt.fail {
+				if _, err := os.Stat(filepath.Join(dir, "Intro.mkv")); err == nil {
+					t.Error("partial output was not removed")
+				}
+			}
+		})
+	}
+}
+
+func TestRunRelativePath(t *testing.T) {
+	fakeFFmpeg(t, false)
+	t.Chdir(t.TempDir())
+
+	// Without a directory, the name would be parsed as an ffmpeg option
+	if err := os.WriteFile("-Intro.mp4", []byte("video"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := models.DownloadConfig{Remux: models.RemuxMKV, ProgressFormat: models.ProgressFormatJSON}
+
+	output, err := Run("-Intro.mp4", 1, config)
+	if err != nil || output != "-Intro.mkv" {
+		t.Fatalf("Run() = %q, %v, want -Intro.mkv", output, err)
+	}
+
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("output was not written: %v", err)
+	}
+}
+
+func TestCheckFFmpeg(t *testing.T) {
+	original := lookPath
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+
+	t.Cleanup(func() { lookPath = original })
+
+	if err := CheckFFmpeg(models.DownloadConfig{Remux: ""}); err != nil {
+		t.Errorf("CheckFFmpeg() without remux error = %v, want nil", err)
+	}
+
+	err := CheckFFmpeg(models.DownloadConfig{Remux: models.RemuxMKV})
+	if !errors.Is(err, ErrFFmpegNotFound) {
+		t.Errorf("CheckFFmpeg() error = %v, want %v", err, ErrFFmpegNotFound)
+	}
+}
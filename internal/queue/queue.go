@@ -0,0 +1,238 @@
+// Package queue stores videos and channels to download later in a JSON Lines
+// file, so that links can be collected and downloaded in one go.
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"switchtube-downloader/internal/config"
+	"switchtube-downloader/internal/helper/dir"
+	"switchtube-downloader/internal/helper/lock"
+	"switchtube-downloader/internal/models"
+)
+
+const (
+	// fileName is the name of the queue file in the config directory.
+	fileName = "queue.jsonl"
+
+	// File and directory permissions of the queue file.
+	dirPermissions  = 0o755
+	filePermissions = 0o600
+
+	// maxLineSize is the maximum size of an entry of the queue file.
+	maxLineSize = 1 << 20
+
+	// lockSuffix is appended to the path of the queue file for the file
+	// locking it while it is updated.
+	lockSuffix = ".lock"
+)
+
+var (
+	errFailedToGetPath   = errors.New("failed to get queue file path")
+	errFailedToReadEntry = errors.New("failed to read queue entry")
+	errFailedToReadFile  = errors.New("failed to read queue file")
+	errFailedToWriteFile = errors.New("failed to write queue file")
+)
+
+// DefaultPath returns the default location of the queue file, e.g.
+// ~/.config/switchtube-dl/queue.jsonl on Linux.
+func DefaultPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToGetPath, err)
+	}
+
+	return filepath.Join(dir, fileName), nil
+}
+
+// Add appends media to the queue file at path, creating it if needed, and
+// returns the number of entries added. Media that is already queued is left
+// out.
+func Add(path string, media ...string) (int, error) {
+	added := 0
+
+	err := Update(path, func(entries []models.QueueEntry) []models.QueueEntry {
+		for _, item := range media {
+			item = strings.TrimSpace(item)
+			if item == "" || queued(entries, item) {
+				continue
+			}
+
+			entries = append(entries, models.QueueEntry{Media: item, AddedAt: time.Now()})
+			added++
+		}
+
+		return entries
+	})
+
+	return added, err
+}
+
+// Update replaces the entries of the queue file at path with the ones update
+// returns for the current ones. The queue file is locked meanwhile, so that
+// entries added by another instance, e.g. while 'queue run' is downloading,
+// aren't lost.
+func Update(path string, update func([]models.QueueEntry) []models.QueueEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirPermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	fileLock, err := lock.AcquireFile(path+lockSuffix, true)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+	defer fileLock.Release()
+
+	entries, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	return Save(path, update(entries))
+}
+
+// Complete removes done, the entries downloaded by 'queue run', from the
+// queue file at path, keeping the ones added since it was loaded.
+func Complete(path string, done []models.QueueEntry) error {
+	return Update(path, func(entries []models.QueueEntry) []models.QueueEntry {
+		return slices.DeleteFunc(entries, func(entry models.QueueEntry) bool {
+			return queued(done, entry.Media)
+		})
+	})
+}
+
+// queued reports whether media is among entries.
+func queued(entries []models.QueueEntry, media string) bool {
+	return slices.ContainsFunc(entries, func(entry models.QueueEntry) bool {
+		return entry.Media == media
+	})
+}
+
+// Remove returns entries without the one ref refers to, which is either its
+// position starting at 1 or its media, and reports whether there was one.
+func Remove(entries []models.QueueEntry, ref string) ([]models.QueueEntry, bool) {
+	index := slices.IndexFunc(entries, func(entry models.QueueEntry) bool {
+		return entry.Media == ref
+	})
+
+	if position, err := strconv.Atoi(ref); index < 0 && err == nil &&
+		position >= 1 && position <= len(entries) {
+		index = position - 1
+	}
+
+	if index < 0 {
+		return entries, false
+	}
+
+	return slices.Delete(slices.Clone(entries), index, index+1), true
+}
+
+// Save replaces the contents of the queue file at path with entries. The
+// file is removed if there are none. The entries are written to a temporary
+// file that replaces the queue file, so that it is never left half written.
+// Use Update to modify the entries of a queue file.
+func Save(path string, entries []models.QueueEntry) error {
+	if len(entries) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+		}
+
+		return nil
+	}
+
+	var data []byte
+
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+		}
+
+		data = append(append(data, line...), '\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), dirPermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	if err := writeFile(path, data); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	return nil
+}
+
+// writeFile writes data to a temporary file in the directory of path, which
+// then replaces the file at path.
+func writeFile(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(file.Name(), filePermissions)
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(file.Name())
+
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// Load returns the entries of the queue file at path in the order they were
+// added. A missing file results in an empty queue.
+func Load(path string) ([]models.QueueEntry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToReadFile, err)
+	}
+	defer dir.CloseFile(file)
+
+	var entries []models.QueueEntry
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var entry models.QueueEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", errFailedToReadEntry, line, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToReadFile, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestAddLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dir", fileName)
+
+	entries, err := Load(path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Load() of a missing file = %v, %v, want no entries", entries, err)
+	}
+
+	added, err := Add(path, "a1B2c3", "https://tube.switch.ch/channels/x9Y8z7", "a1B2c3", " ")
+	if err != nil || added != 2 {
+		t.Fatalf("Add() = %d, %v, want 2 entries added", added, err)
+	}
+
+	if added, err = Add(path, "a1B2c3"); err != nil || added != 0 {
+		t.Fatalf("Add() of a queued video = %d, %v, want none added", added, err)
+	}
+
+	entries, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].Media != "a1B2c3" || entries[0].AddedAt.IsZero() {
+		t.Fatalf("Load() = %+v, want both entries in order", entries)
+	}
+
+	if err := Save(path, nil); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Stat() error = %v, want the file to be removed without entries", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), fileName)
+	if _, err := Add(path, "first", "second", "third"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		ref  string
+		want []string
+	}{
+		{ref: "2", want: []string{"first", "third"}},
+		{ref: "third", want: []string{"first", "second"}},
+		{ref: "4", want: []string{"first", "second", "third"}},
+		{ref: "fourth", want: []string{"first", "second", "third"}},
+	}
+
+	for _, tt := range tests {
+		remaining, ok := Remove(entries, tt.ref)
+		if ok != (len(remaining) < len(entries)) || len(remaining) != len(tt.want) {
+			t.Fatalf("Remove(%q) = %+v, %v, want %v", tt.ref, remaining, ok, tt.want)
+		}
+
+		for i, media := range tt.want {
+			if remaining[i].Media != media {
+				t.Errorf("Remove(%q)[%d] = %s, want %s", tt.ref, i, remaining[i].Media, media)
+			}
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), fileName)
+	if _, err := Add(path, "first", "second"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	running, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// Added by another instance while the queue runs
+	if _, err := Add(path, "third"); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if err := Complete(path, running[:1]); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].Media != "second" || entries[1].Media != "third" {
+		t.Errorf("Load() after Complete() = %+v, want second and third", entries)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+
+	if runtime.GOOS != "windows" && info.Mode().Perm() != filePermissions {
+		t.Errorf("queue file mode = %v, want %v", info.Mode().Perm(), os.FileMode(filePermissions))
+	}
+}
@@ -0,0 +1,244 @@
+// Package schedule parses cron expressions and computes when they are due.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldCount is the number of fields of a cron expression.
+const fieldCount = 5
+
+// maxSearch limits how far ahead Next looks for a matching time, so that
+// expressions that never match, e.g. February 30, don't loop forever.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+var (
+	errInvalidExpression = errors.New(
+		"invalid cron expression, expected 5 fields: minute hour day month weekday",
+	)
+	errInvalidField = errors.New("invalid cron field")
+)
+
+// macros are the shorthands for common expressions.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field describes the range and names of the values of a cron field.
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59, names: nil}
+	hourField   = field{name: "hour", min: 0, max: 23, names: nil}
+	dayField    = field{name: "day", min: 1, max: 31, names: nil}
+	monthField  = field{name: "month", min: 1, max: 12, names: []string{
+		"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec",
+	}}
+	// Sunday is both 0 and 7.
+	weekdayField = field{name: "weekday", min: 0, max: 7, names: []string{
+		"sun", "mon", "tue", "wed", "thu", "fri", "sat",
+	}}
+)
+
+// Schedule is a parsed cron expression. Each field is a bit set of the
+// values it matches.
+type Schedule struct {
+	expression string
+
+	minutes, hours, days, months, weekdays uint64
+
+	// anyDay and anyWeekday are set if the field is "*". As in cron, a time
+	// matches if either the day of the month or the weekday matches unless
+	// one of them is "*".
+	anyDay, anyWeekday bool
+}
+
+// Parse parses a standard cron expression with the fields minute, hour, day
+// of the month, month and day of the week, e.g. "0 3 * * *" for every day at
+// 3:00. Fields are lists of values, ranges ("1-5") and steps ("*/15").
+// Months and weekdays may be given by their English abbreviations, and the
+// macros @hourly, @daily, @weekly, @monthly and @yearly are supported.
+func Parse(expression string) (*Schedule, error) {
+	expanded := strings.TrimSpace(expression)
+	if macro, ok := macros[strings.ToLower(expanded)]; ok {
+		expanded = macro
+	}
+
+	fields := strings.Fields(expanded)
+	if len(fields) != fieldCount {
+		return nil, fmt.Errorf("%w: %q", errInvalidExpression, expression)
+	}
+
+	schedule := &Schedule{
+		expression: expression,
+		minutes:    0,
+		hours:      0,
+		days:       0,
+		months:     0,
+		weekdays:   0,
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}
+
+	for i, target := range []struct {
+		field field
+		bits  *uint64
+	}{
+		{field: minuteField, bits: &schedule.minutes},
+		{field: hourField, bits: &schedule.hours},
+		{field: dayField, bits: &schedule.days},
+		{field: monthField, bits: &schedule.months},
+		{field: weekdayField, bits: &schedule.weekdays},
+	} {
+		bits, err := target.field.parse(fields[i])
+		if err != nil {
+			return nil, err
+		}
+
+		*target.bits = bits
+	}
+
+	// Sunday given as 7 matches Sunday
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1
+	}
+
+	return schedule, nil
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *Schedule) String() string {
+	return s.expression
+}
+
+// Next returns the first time after t the schedule is due, in the location
+// of t, or the zero time if there is none within the next years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := next.Add(maxSearch)
+
+	for next.Before(limit) {
+		switch {
+		case !has(s.months, int(next.Month())):
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !s.matchesDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case !has(s.hours, next.Hour()):
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0,
+				next.Location())
+		case !has(s.minutes, next.Minute()):
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchesDay reports whether the day of t matches the day of the month and
+// weekday fields.
+func (s *Schedule) matchesDay(t time.Time) bool {
+	day := has(s.days, t.Day())
+	weekday := has(s.weekdays, int(t.Weekday()))
+
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+
+	return day || weekday
+}
+
+// has reports whether value is in the bit set bits.
+func has(bits uint64, value int) bool {
+	return bits&(1<<value) != 0
+}
+
+// parse returns the bit set of the values the comma-separated list expr
+// matches.
+func (f field) parse(expr string) (uint64, error) {
+	var bits uint64
+
+	for item := range strings.SplitSeq(expr, ",") {
+		first, last, step, err := f.parseItem(item)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s: %q", errInvalidField, f.name, item)
+		}
+
+		for value := first; value <= last; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, nil
+}
+
+// parseItem returns the range and step of a single value, range or step of
+// a list.
+func (f field) parseItem(item string) (int, int, int, error) {
+	rangeExpr, stepExpr, hasStep := strings.Cut(item, "/")
+
+	step := 1
+
+	if hasStep {
+		var err error
+		if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+			return 0, 0, 0, errInvalidField
+		}
+	}
+
+	if rangeExpr == "*" {
+		return f.min, f.max, step, nil
+	}
+
+	firstExpr, lastExpr, isRange := strings.Cut(rangeExpr, "-")
+
+	first, err := f.value(firstExpr)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	last := first
+
+	switch {
+	case isRange:
+		if last, err = f.value(lastExpr); err != nil || last < first {
+			return 0, 0, 0, errInvalidField
+		}
+	case hasStep:
+		// "5/15" starts at 5 and continues to the end of the range
+		last = f.max
+	}
+
+	return first, last, step, nil
+}
+
+// value parses a single number or name of the field.
+func (f field) value(expr string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(expr, name) {
+			return f.min + i, nil
+		}
+	}
+
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < f.min || value > f.max {
+		return 0, errInvalidField
+	}
+
+	return value, nil
+}
@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// A Monday
+	now := time.Date(2026, 5, 4, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		want       time.Time
+	}{
+		{expression: "0 3 * * *", want: time.Date(2026, 5, 5, 3, 0, 0, 0, time.UTC)},
+		{expression: "*/15 * * * *", want: time.Date(2026, 5, 4, 10, 45, 0, 0, time.UTC)},
+		{expression: "31 10 * * *", want: time.Date(2026, 5, 4, 10, 31, 0, 0, time.UTC)},
+		{expression: "30 10 * * *", want: time.Date(2026, 5, 5, 10, 30, 0, 0, time.UTC)},
+		{expression: "0 8-18/2 * * mon-fri", want: time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)},
+		{expression: "0 0 * * sat,7", want: time.Date(2026, 5, 9, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 1 * mon", want: time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC)},
+		{expression: "0 0 29 feb *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{expression: "@monthly", want: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{expression: "@HOURLY", want: time.Date(2026, 5, 4, 11, 0, 0, 0, time.UTC)},
+		{expression: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		schedule, err := Parse(tt.expression)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.expression, err)
+		}
+
+		if got := schedule.Next(now); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next() = %v, want %v", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestNextHalfHourZone(t *testing.T) {
+	zone := time.FixedZone("IST", 5*60*60+30*60)
+	now := time.Date(2026, 5, 4, 10, 45, 0, 0, zone)
+
+	schedule, err := Parse("0 * * * *")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got, want := schedule.Next(now), time.Date(2026, 5, 4, 11, 0, 0, 0, zone); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        error
+	}{
+		{expression: "", err: errInvalidExpression},
+		{expression: "0 3 * *", err: errInvalidExpression},
+		{expression: "@often", err: errInvalidExpression},
+		{expression: "60 * * * *", err: errInvalidField},
+		{expression: "* 24 * * *", err: errInvalidField},
+		{expression: "* * 0 * *", err: errInvalidField},
+		{expression: "* * * 13 *", err: errInvalidField},
+		{expression: "* * * * 8", err: errInvalidField},
+		{expression: "*/0 * * * *", err: errInvalidField},
+		{expression: "5-1 * * * *", err: errInvalidField},
+		{expression: "* * * foo *", err: errInvalidField},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse(tt.expression); !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.expression, err, tt.err)
+		}
+	}
+}
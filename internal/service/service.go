@@ -0,0 +1,234 @@
+// Package service installs systemd user units that run the downloader
+// unattended.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultCalendar is the systemd calendar expression timers run on if
+	// none is given.
+	DefaultCalendar = "daily"
+
+	// randomizedDelay spreads the runs of timers on the same calendar, e.g.
+	// of several channels synced daily, so that they don't all start at once.
+	// With FixedRandomDelay, every timer keeps its own offset.
+	randomizedDelay = "1h"
+
+	// File and directory permissions of the units. The service may hold the
+	// access token in its environment and is only readable by the user.
+	dirPermissions     = 0o755
+	filePermissions    = 0o644
+	servicePermissions = 0o600
+)
+
+var (
+	errFailedToEnable      = errors.New("failed to enable timer")
+	errFailedToGetUnitDir  = errors.New("failed to get systemd user unit directory")
+	errFailedToWriteUnit   = errors.New("failed to write systemd unit")
+	errInvalidCalendar     = errors.New("invalid calendar expression")
+	errSystemdNotSupported = errors.New("systemd services are only supported on Linux")
+)
+
+// lookPath finds the systemctl executable, which tests replace.
+var lookPath = exec.LookPath
+
+// Unit describes a service run by a timer.
+type Unit struct {
+	// Name is the name of the units without the .service and .timer suffix.
+	Name string
+
+	// Description is shown by systemctl.
+	Description string
+
+	// Command is the executable and arguments the service runs.
+	Command []string
+
+	// WorkingDirectory is the directory the command runs in, so that relative
+	// paths in its arguments keep working.
+	WorkingDirectory string
+
+	// Calendar is the systemd calendar expression of the timer, e.g. daily
+	// or "Mon..Fri 18:00".
+	Calendar string
+
+	// Environment are the environment variables of the command in the form
+	// "NAME=value", e.g. the SWITCHTUBE_* variables it was installed with.
+	Environment []string
+}
+
+// SyncName returns the name of the units syncing the channel with the given
+// id. Characters systemd doesn't allow in unit names are replaced.
+func SyncName(id string) string {
+	return "switchtube-sync-" + strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-') {
+			return '_'
+		}
+
+		return r
+	}, id)
+}
+
+// Dir returns the directory systemd loads the units of the current user
+// from, e.g. ~/.config/systemd/user.
+func Dir() (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", errSystemdNotSupported
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToGetUnitDir, err)
+	}
+
+	return filepath.Join(dir, "systemd", "user"), nil
+}
+
+// Service returns the contents of the service unit.
+func (u Unit) Service() string {
+	args := make([]string, len(u.Command))
+	for i, arg := range u.Command {
+		args[i] = quote(arg)
+	}
+
+	var environment strings.Builder
+	for _, variable := range u.Environment {
+		environment.WriteString("Environment=" + quoteEnvironment(variable) + "\n")
+	}
+
+	return fmt.Sprintf(`[Unit]
+Description=%s
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=oneshot
+WorkingDirectory=%s
+%sExecStart=%s
+`, escapeValue(u.Description), escapeValue(u.WorkingDirectory), environment.String(),
+		strings.Join(args, " "))
+}
+
+// Timer returns the contents of the timer unit. Missed runs, e.g. while the
+// computer was off, are caught up on.
+func (u Unit) Timer() string {
+	return fmt.Sprintf(`[Unit]
+Description=Run %s.service on a schedule
+
+[Timer]
+OnCalendar=%s
+Persistent=true
+RandomizedDelaySec=%s
+FixedRandomDelay=true
+
+[Install]
+WantedBy=timers.target
+`, u.Name, u.Calendar, randomizedDelay)
+}
+
+// Write writes the service and timer unit to dir and returns the path of the
+// timer.
+func (u Unit) Write(dir string) (string, error) {
+	if u.Calendar == "" || strings.ContainsAny(u.Calendar, "\n\r") {
+		return "", fmt.Errorf("%w: %q", errInvalidCalendar, u.Calendar)
+	}
+
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToWriteUnit, err)
+	}
+
+	timer := filepath.Join(dir, u.Name+".timer")
+
+	for _, file := range []struct {
+		path        string
+		content     string
+		permissions os.FileMode
+	}{
+		{
+			path:        filepath.Join(dir, u.Name+".service"),
+			content:     u.Service(),
+			permissions: servicePermissions,
+		},
+		{path: timer, content: u.Timer(), permissions: filePermissions},
+	} {
+		if err := writeFile(file.path, file.content, file.permissions); err != nil {
+			return "", fmt.Errorf("%w: %w", errFailedToWriteUnit, err)
+		}
+	}
+
+	return timer, nil
+}
+
+// Enable reloads systemd and starts the timer of the unit, returning false if
+// systemctl isn't installed so that the user can do it later.
+func (u Unit) Enable() (bool, error) {
+	systemctl, err := lookPath("systemctl")
+	if err != nil {
+		return false, nil
+	}
+
+	for _, args := range [][]string{
+		{"--user", "daemon-reload"},
+		{"--user", "enable", "--now", u.Name + ".timer"},
+	} {
+		slog.Info("running systemctl", "args", args)
+
+		cmd := exec.Command(systemctl, args...)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return false, fmt.Errorf("%w: %w", errFailedToEnable, err)
+		}
+	}
+
+	return true, nil
+}
+
+// writeFile writes content to path with the given permissions, also if the
+// file already exists with others.
+func writeFile(path, content string, permissions os.FileMode) error {
+	if err := os.WriteFile(path, []byte(content), permissions); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if err := os.Chmod(path, permissions); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// escapeValue escapes the specifiers systemd would expand in a setting and
+// replaces line breaks, which would end it.
+func escapeValue(value string) string {
+	return strings.NewReplacer("%", "%%", "\n", " ", "\r", " ").Replace(value)
+}
+
+// quoteEnvironment quotes the "NAME=value" variable for an Environment=
+// setting, which expands specifiers and C escapes but not variables.
+func quoteEnvironment(variable string) string {
+	return `"` + strings.NewReplacer(
+		"%", "%%", `\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`,
+	).Replace(variable) + `"`
+}
+
+// quote quotes arg for ExecStart if necessary and escapes the specifiers and
+// variables systemd would expand.
+func quote(arg string) string {
+	arg = strings.NewReplacer("%", "%%", "$", "$$").Replace(arg)
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
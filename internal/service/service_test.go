@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{arg: "sync", want: "sync"},
+		{arg: "--output=/home/me/Videos", want: "--output=/home/me/Videos"},
+		{arg: "--output=/home/me/My Videos", want: `"--output=/home/me/My Videos"`},
+		{arg: `say "hi"`, want: `"say \"hi\""`},
+		{arg: "100%", want: "100%%"},
+		{arg: "$HOME", want: "$$HOME"},
+		{arg: "", want: `""`},
+	}
+
+	for _, tt := range tests {
+		if got := quote(tt.arg); got != tt.want {
+			t.Errorf("quote(%q) = %s, want %s", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestSyncName(t *testing.T) {
+	if got, want := SyncName("a1B2-c3/é"), "switchtube-sync-a1B2-c3__"; got != want {
+		t.Errorf("SyncName() = %s, want %s", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "systemd", "user")
+	unit := Unit{
+		Name:        SyncName("abc123"),
+		Description: "Sync 100% of abc123\nExecStart=evil",
+		Command: []string{
+			"/usr/bin/switchtube-downloader", "sync", "abc123", "--output=My Videos",
+		},
+		WorkingDirectory: "/srv/100% courses",
+		Calendar:         "Mon..Fri 18:00",
+		Environment:      []string{"SWITCHTUBE_TOKEN=se\"cr%t", "SWITCHTUBE_OUTPUT=$HOME"},
+	}
+
+	timer, err := unit.Write(dir)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if timer != filepath.Join(dir, "switchtube-sync-abc123.timer") {
+		t.Errorf("Write() = %s, want the timer in %s", timer, dir)
+	}
+
+	for _, tt := range []struct {
+		file string
+		want string
+	}{
+		{
+			file: "switchtube-sync-abc123.service",
+			want: `ExecStart=/usr/bin/switchtube-downloader sync abc123 "--output=My Videos"`,
+		},
+		{file: "switchtube-sync-abc123.service", want: "WorkingDirectory=/srv/100%% courses"},
+		{file: "switchtube-sync-abc123.service", want: "Description=Sync 100%% of abc123 ExecStart=evil"},
+		{file: "switchtube-sync-abc123.service", want: `Environment="SWITCHTUBE_TOKEN=se\"cr%%t"`},
+		{file: "switchtube-sync-abc123.service", want: `Environment="SWITCHTUBE_OUTPUT=$HOME"`},
+		{file: "switchtube-sync-abc123.timer", want: "OnCalendar=Mon..Fri 18:00"},
+		{file: "switchtube-sync-abc123.timer", want: "FixedRandomDelay=true"},
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", tt.file, err)
+		}
+
+		if !strings.Contains(string(data), tt.want+"\n") {
+			t.Errorf("%s = %q, want it to contain %q", tt.file, data, tt.want)
+		}
+	}
+
+	// The service holds the access token
+	if info, err := os.Stat(filepath.Join(dir, "switchtube-sync-abc123.service")); err != nil ||
+		info.Mode().Perm() != servicePermissions {
+		t.Errorf("Stat(service) = %v, %v, want permissions %o", info, err, servicePermissions)
+	}
+
+	unit.Calendar = "daily\nExecStart=evil"
+	if _, err := unit.Write(dir); !errors.Is(err, errInvalidCalendar) {
+		t.Errorf("Write() error = %v, want %v", err, errInvalidCalendar)
+	}
+}
+
+func TestEnableWithoutSystemctl(t *testing.T) {
+	original := lookPath
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+
+	defer func() { lookPath = original }()
+
+	unit := Unit{
+		Name:             "test",
+		Description:      "",
+		Command:          nil,
+		WorkingDirectory: "",
+		Calendar:         DefaultCalendar,
+	}
+
+	enabled, err := unit.Enable()
+	if enabled || err != nil {
+		t.Errorf("Enable() = %v, %v, want false without an error", enabled, err)
+	}
+}
@@ -0,0 +1,355 @@
+package token
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"switchtube-downloader/internal/helper/ui"
+)
+
+const (
+	// PassphraseEnvVar is the environment variable providing the passphrase
+	// of an encrypted token file, e.g. for unattended runs.
+	PassphraseEnvVar = "SWITCHTUBE_TOKEN_PASSPHRASE"
+
+	// encryptedPrefix marks tokens in the token file that are encrypted.
+	encryptedPrefix = "encrypted:"
+
+	// Parameters of the key derivation from the passphrase, following the
+	// OWASP recommendation for PBKDF2-HMAC-SHA256.
+	pbkdf2Iterations = 600_000
+	saltSize         = 16
+	keySize          = 32
+
+	// sessionDirName is the name of the directory holding the session cache
+	// of decrypted tokens.
+	sessionDirName = "switchtube-dl"
+)
+
+var (
+	errFailedToDecrypt    = errors.New("failed to decrypt token, wrong passphrase?")
+	errFailedToEncrypt    = errors.New("failed to encrypt token")
+	errPassphraseEmpty    = errors.New("passphrase cannot be empty")
+	errCorruptToken       = errors.New("encrypted token is corrupt")
+	errPassphraseMismatch = errors.New("passphrases don't match")
+	errInsecureCacheDir   = errors.New("session cache directory isn't private")
+)
+
+// FileOptions configure how the token file stores the token.
+type FileOptions struct {
+	// Encrypt encrypts the token with a passphrase that is asked for
+	// whenever the token is used. Encrypted tokens are decrypted regardless.
+	Encrypt bool
+
+	// CacheFor keeps the decrypted token in a private directory of the user
+	// for this long, so that the passphrase is only asked for once per
+	// session. It isn't cached if zero.
+	CacheFor time.Duration
+}
+
+// encryptedStore encrypts the tokens of another store, usually the token
+// file, with a passphrase. Tokens that aren't encrypted are passed through.
+type encryptedStore struct {
+	next     store
+	options  FileOptions
+	cacheDir string
+
+	// passphrase returns the passphrase, asking for it twice if confirm is
+	// set.
+	passphrase func(confirm bool) (string, error)
+}
+
+// newEncryptedStore creates an encryptedStore for next that asks for the
+// passphrase on the terminal.
+func newEncryptedStore(next store, options FileOptions) encryptedStore {
+	return encryptedStore{
+		next:       next,
+		options:    options,
+		cacheDir:   sessionDir(),
+		passphrase: askPassphrase,
+	}
+}
+
+func (es encryptedStore) get(user string) (string, error) {
+	value, err := es.next.get(user)
+	if err != nil || !strings.HasPrefix(value, encryptedPrefix) {
+		return value, err
+	}
+
+	if token, ok := es.cached(user); ok {
+		return token, nil
+	}
+
+	passphrase, err := es.passphrase(false)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := decrypt(value, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	es.cache(user, token)
+
+	return token, nil
+}
+
+func (es encryptedStore) set(user, token string) error {
+	es.uncache(user)
+
+	if !es.options.Encrypt {
+		return es.next.set(user, token)
+	}
+
+	passphrase, err := es.passphrase(true)
+	if err != nil {
+		return err
+	}
+
+	value, err := encrypt(token, passphrase)
+	if err != nil {
+		return err
+	}
+
+	return es.next.set(user, value)
+}
+
+func (es encryptedStore) delete(user string) error {
+	es.uncache(user)
+
+	return es.next.delete(user)
+}
+
+// encrypt encrypts token with AES-GCM using a key derived from passphrase
+// and a random salt, which are stored together with the nonce.
+func encrypt(token, passphrase string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToEncrypt, err)
+	}
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToEncrypt, err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToEncrypt, err)
+	}
+
+	sealed := aead.Seal(append(salt, nonce...), nonce, []byte(token), nil)
+
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt reverses encrypt.
+func decrypt(value, passphrase string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil || len(sealed) < saltSize {
+		return "", errCorruptToken
+	}
+
+	salt, sealed := sealed[:saltSize], sealed[saltSize:]
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToDecrypt, err)
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return "", errCorruptToken
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	token, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errFailedToDecrypt
+	}
+
+	return string(token), nil
+}
+
+// newAEAD creates the AES-GCM cipher with the key derived from passphrase and
+// salt.
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return aead, nil
+}
+
+// askPassphrase returns the passphrase from the environment or asks for it
+// without echoing it, twice if confirm is set.
+func askPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(PassphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+
+	if !ui.InputEnabled() {
+		return "", fmt.Errorf("%w: set %s", ui.ErrInputDisabled, PassphraseEnvVar)
+	}
+
+	passphrase := ui.Password("Passphrase of the token file: ")
+	if passphrase == "" {
+		return "", errPassphraseEmpty
+	}
+
+	if confirm && ui.Password("Repeat the passphrase: ") != passphrase {
+		return "", errPassphraseMismatch
+	}
+
+	return passphrase, nil
+}
+
+// cachedToken is the content of the session cache of a decrypted token.
+type cachedToken struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+// sessionDir returns the directory the decrypted token is cached in, which
+// is a private directory inside the runtime directory of the user that is
+// cleared on logout, or inside the cache directory of the user if there is
+// none. It is empty if neither is known, so the token isn't cached.
+func sessionDir() string {
+	base := os.Getenv("XDG_RUNTIME_DIR")
+	if base == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+
+		base = dir
+	}
+
+	return filepath.Join(base, sessionDirName)
+}
+
+// cachePath returns the session cache file of the token of user.
+func (es encryptedStore) cachePath(user string) string {
+	// Domain users contain a backslash on Windows
+	name := strings.NewReplacer(`\`, "_", "/", "_").Replace(user)
+
+	return filepath.Join(es.cacheDir, name+".token")
+}
+
+// cached returns the decrypted token of user from the session cache if it
+// hasn't expired.
+func (es encryptedStore) cached(user string) (string, bool) {
+	if es.options.CacheFor <= 0 || es.cacheDir == "" {
+		return "", false
+	}
+
+	data, err := os.ReadFile(es.cachePath(user))
+	if err != nil {
+		return "", false
+	}
+
+	var cached cachedToken
+	if err := json.Unmarshal(data, &cached); err != nil || time.Now().After(cached.Expires) {
+		return "", false
+	}
+
+	return cached.Token, cached.Token != ""
+}
+
+// cache stores the decrypted token of user in the session cache. Failures
+// are only logged, since the passphrase is asked for again next time.
+func (es encryptedStore) cache(user, token string) {
+	if es.options.CacheFor <= 0 || es.cacheDir == "" {
+		return
+	}
+
+	data, err := json.Marshal(cachedToken{Token: token, Expires: time.Now().Add(es.options.CacheFor)})
+	if err == nil {
+		err = es.writeCache(es.cachePath(user), data)
+	}
+
+	if err != nil {
+		slog.Warn("failed to cache decrypted token", "error", err)
+	}
+}
+
+// writeCache writes data to the session cache file at path. The cache
+// directory is created private to the user, and the file is written to a new
+// temporary file that replaces it, so that a file or link planted at path is
+// never written through.
+func (es encryptedStore) writeCache(path string, data []byte) error {
+	if err := os.MkdirAll(es.cacheDir, tokenDirPermissions); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	info, err := os.Lstat(es.cacheDir)
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if !info.IsDir() || (runtime.GOOS != "windows" && info.Mode().Perm()&^tokenDirPermissions != 0) {
+		return fmt.Errorf("%w: %s", errInsecureCacheDir, es.cacheDir)
+	}
+
+	// CreateTemp opens the file with O_EXCL and permissions 0600
+	file, err := os.CreateTemp(es.cacheDir, ".token-*")
+	if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(file.Name())
+
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// uncache removes the decrypted token of user from the session cache. Unlike
+// cached and cache, it doesn't depend on CacheFor, so that a token cached by
+// an earlier run is removed as well.
+func (es encryptedStore) uncache(user string) {
+	if es.cacheDir == "" {
+		return
+	}
+
+	err := os.Remove(es.cachePath(user))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("failed to remove cached token", "error", err)
+	}
+}
@@ -0,0 +1,208 @@
+package token
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	value, err := encrypt("secret-token", "correct horse")
+	if err != nil {
+		t.Fatalf("encrypt() error = %v", err)
+	}
+
+	if !strings.HasPrefix(value, encryptedPrefix) || strings.Contains(value, "secret-token") {
+		t.Errorf("encrypt() = %q, want an encrypted token", value)
+	}
+
+	if token, err := decrypt(value, "correct horse"); err != nil || token != "secret-token" {
+		t.Errorf("decrypt() = %q, %v, want secret-token", token, err)
+	}
+
+	if _, err := decrypt(value, "wrong horse"); !errors.Is(err, errFailedToDecrypt) {
+		t.Errorf("decrypt() with wrong passphrase error = %v, want %v", err, errFailedToDecrypt)
+	}
+
+	for _, corrupt := range []string{encryptedPrefix + "not base64", encryptedPrefix + "c2FsdA=="} {
+		if _, err := decrypt(corrupt, "correct horse"); !errors.Is(err, errCorruptToken) {
+			t.Errorf("decrypt(%q) error = %v, want %v", corrupt, err, errCorruptToken)
+		}
+	}
+}
+
+// newTestEncryptedStore creates an encryptedStore with a token file and
+// session cache in a temporary directory, counting the passphrase prompts.
+func newTestEncryptedStore(t *testing.T, options FileOptions, prompts *int) encryptedStore {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	return encryptedStore{
+		next:     fileStore{path: filepath.Join(dir, tokenFileName)},
+		options:  options,
+		cacheDir: filepath.Join(dir, sessionDirName),
+		passphrase: func(bool) (string, error) {
+			*prompts++
+
+			return "correct horse", nil
+		},
+	}
+}
+
+func TestEncryptedStore(t *testing.T) {
+	var prompts int
+
+	es := newTestEncryptedStore(t, FileOptions{Encrypt: true}, &prompts)
+
+	if err := es.set("alice", "token-a"); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	stored, err := es.next.get("alice")
+	if err != nil || !strings.HasPrefix(stored, encryptedPrefix) {
+		t.Errorf("token file contains %q, %v, want an encrypted token", stored, err)
+	}
+
+	for range 2 {
+		if token, err := es.get("alice"); err != nil || token != "token-a" {
+			t.Errorf("get() = %q, %v, want token-a", token, err)
+		}
+	}
+
+	// Without a session cache, every use asks for the passphrase
+	if prompts != 3 {
+		t.Errorf("passphrase asked for %d times, want 3", prompts)
+	}
+}
+
+func TestEncryptedStorePlain(t *testing.T) {
+	var prompts int
+
+	es := newTestEncryptedStore(t, FileOptions{}, &prompts)
+
+	if err := es.set("alice", "token-a"); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	if stored, _ := es.next.get("alice"); stored != "token-a" {
+		t.Errorf("token file contains %q, want the plain token", stored)
+	}
+
+	if token, err := es.get("alice"); err != nil || token != "token-a" {
+		t.Errorf("get() = %q, %v, want token-a", token, err)
+	}
+
+	if prompts != 0 {
+		t.Errorf("passphrase asked for %d times, want 0", prompts)
+	}
+}
+
+func TestEncryptedStoreSessionCache(t *testing.T) {
+	var prompts int
+
+	es := newTestEncryptedStore(t, FileOptions{Encrypt: true, CacheFor: time.Hour}, &prompts)
+
+	if err := es.set("alice", "token-a"); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	for range 3 {
+		if token, err := es.get("alice"); err != nil || token != "token-a" {
+			t.Errorf("get() = %q, %v, want token-a", token, err)
+		}
+	}
+
+	if prompts != 2 {
+		t.Errorf("passphrase asked for %d times, want 2 (set and first get)", prompts)
+	}
+
+	// An expired cache asks again
+	expired := `{"token":"token-a","expires":"2000-01-01T00:00:00Z"}`
+	if err := os.WriteFile(es.cachePath("alice"), []byte(expired), tokenFilePermissions); err != nil {
+		t.Fatalf("Failed to write cache: %v", err)
+	}
+
+	if _, err := es.get("alice"); err != nil || prompts != 3 {
+		t.Errorf("get() with expired cache error = %v, prompts = %d, want 3", err, prompts)
+	}
+
+	if err := es.delete("alice"); err != nil {
+		t.Fatalf("delete() error = %v", err)
+	}
+
+	if _, ok := es.cached("alice"); ok {
+		t.Error("cached() after delete found the token")
+	}
+}
+
+func TestUncacheWithoutSessionDir(t *testing.T) {
+	var prompts int
+
+	// Without a session directory, the cache path is relative to the working
+	// directory, whose files aren't touched
+	es := newTestEncryptedStore(t, FileOptions{Encrypt: true, CacheFor: time.Hour}, &prompts)
+	es.cacheDir = ""
+
+	t.Chdir(t.TempDir())
+
+	if err := os.WriteFile("alice.token", []byte("unrelated"), tokenFilePermissions); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	es.uncache("alice")
+
+	if _, err := os.Stat("alice.token"); err != nil {
+		t.Errorf("uncache() removed a file of the working directory: %v", err)
+	}
+}
+
+func TestSessionCacheWrite(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("links and permissions differ on Windows")
+	}
+
+	var prompts int
+
+	es := newTestEncryptedStore(t, FileOptions{Encrypt: true, CacheFor: time.Hour}, &prompts)
+	// A link planted in place of the cache file isn't written through
+	if err := os.MkdirAll(es.cacheDir, tokenDirPermissions); err != nil {
+		t.Fatalf("Failed to create cache directory: %v", err)
+	}
+
+	target := filepath.Join(t.TempDir(), "target")
+	if err := os.WriteFile(target, []byte("unchanged"), tokenFilePermissions); err != nil {
+		t.Fatalf("Failed to write target: %v", err)
+	}
+
+	if err := os.Symlink(target, es.cachePath("alice")); err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+
+	es.cache("alice", "token-a")
+
+	if data, _ := os.ReadFile(target); string(data) != "unchanged" {
+		t.Errorf("link target contains %q, want it unchanged", data)
+	}
+
+	if token, ok := es.cached("alice"); !ok || token != "token-a" {
+		t.Errorf("cached() = %q, %v, want token-a", token, ok)
+	}
+
+	if info, err := os.Lstat(es.cachePath("alice")); err != nil || info.Mode() != tokenFilePermissions {
+		t.Errorf("cache file mode = %v, %v, want %v", info.Mode(), err, os.FileMode(tokenFilePermissions))
+	}
+
+	// A cache directory others may access isn't used
+	if err := os.Chmod(es.cacheDir, 0o777); err != nil {
+		t.Fatalf("Failed to change permissions: %v", err)
+	}
+
+	if err := es.writeCache(es.cachePath("bob"), []byte("{}")); !errors.Is(err, errInsecureCacheDir) {
+		t.Errorf("writeCache() error = %v, want %v", err, errInsecureCacheDir)
+	}
+}
@@ -0,0 +1,225 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"switchtube-downloader/internal/config"
+)
+
+const (
+	// infoFileName is the name of the file in the config directory holding
+	// the details of the stored tokens. Unlike the tokens themselves, they
+	// aren't secret and are kept apart from the token stores.
+	infoFileName = "token-info.json"
+
+	// ExpiryWarningPeriod is how long before a token expires runs start to
+	// warn about it.
+	ExpiryWarningPeriod = 7 * 24 * time.Hour
+)
+
+// Info holds the details of a stored token. Times that aren't known are
+// zero, e.g. the expiry if SwitchTube doesn't report it.
+type Info struct {
+	Created time.Time `json:"created,omitzero"`
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// ExpiryWarning returns a warning if the token expires within
+// ExpiryWarningPeriod or has already expired at now, and an empty string
+// otherwise.
+func (i Info) ExpiryWarning(now time.Time) string {
+	if i.Expires.IsZero() {
+		return ""
+	}
+
+	left := i.Expires.Sub(now)
+
+	switch days := int(left.Hours() / 24); {
+	case left > ExpiryWarningPeriod:
+		return ""
+	case left <= 0:
+		return "token has expired"
+	case days == 0:
+		return "token expires today"
+	case days == 1:
+		return "token expires in 1 day"
+	default:
+		return fmt.Sprintf("token expires in %d days", days)
+	}
+}
+
+// Info returns the details of the access token Get returns. They are empty
+// if the token was stored before they were recorded, or if it is given
+// explicitly or by the environment.
+func (tm *Manager) Info() (Info, error) {
+	var info Info
+
+	if tm.overridden() {
+		return info, nil
+	}
+
+	key, err := tm.key()
+	if err != nil {
+		return info, err
+	}
+
+	infos, err := tm.info.load()
+	if err != nil {
+		return info, err
+	}
+
+	return infos[key], nil
+}
+
+// SetExpiry records when the access token Get returns expires, as reported
+// by SwitchTube. It does nothing for tokens that aren't stored.
+func (tm *Manager) SetExpiry(expires time.Time) error {
+	if tm.overridden() {
+		return nil
+	}
+
+	key, err := tm.key()
+	if err != nil {
+		return err
+	}
+
+	infos, err := tm.info.load()
+	if err != nil {
+		return err
+	}
+
+	info := infos[key]
+	info.Expires = expires
+	infos[key] = info
+
+	return tm.info.save(infos)
+}
+
+// overridden reports whether Get returns a token given explicitly or by the
+// environment instead of the stored one.
+func (tm *Manager) overridden() bool {
+	if _, ok := tm.store.(staticStore); ok {
+		return true
+	}
+
+	return strings.TrimSpace(os.Getenv(EnvVar)) != ""
+}
+
+// recordCreated records that the token stored under key was created now,
+// forgetting the expiry of the token it replaces. Failures are only logged,
+// since the details are informational.
+func (tm *Manager) recordCreated(key string) {
+	infos, err := tm.info.load()
+	if err == nil {
+		infos[key] = Info{Created: time.Now().UTC().Truncate(time.Second), Expires: time.Time{}}
+		err = tm.info.save(infos)
+	}
+
+	if err != nil {
+		slog.Warn("failed to record the creation date of the token", "error", err)
+	}
+}
+
+// forget removes the details of the token stored under key. Failures are
+// only logged, like in recordCreated.
+func (tm *Manager) forget(key string) {
+	infos, err := tm.info.load()
+	if err != nil {
+		slog.Warn("failed to remove the details of the token", "error", err)
+
+		return
+	}
+
+	if _, ok := infos[key]; !ok {
+		return
+	}
+
+	delete(infos, key)
+
+	if err := tm.info.save(infos); err != nil {
+		slog.Warn("failed to remove the details of the token", "error", err)
+	}
+}
+
+// infoFile stores the details of the tokens in a JSON file, keyed like the
+// tokens in the token stores.
+type infoFile struct {
+	// path overrides the default location inside the config directory.
+	path string
+}
+
+// filePath returns the location of the info file.
+func (f infoFile) filePath() (string, error) {
+	if f.path != "" {
+		return f.path, nil
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToReadFile, err)
+	}
+
+	return filepath.Join(dir, infoFileName), nil
+}
+
+// load reads the details of all tokens. A missing file contains none.
+func (f infoFile) load() (map[string]Info, error) {
+	path, err := f.filePath()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make(map[string]Info)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return infos, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToReadFile, err)
+	}
+
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToDecodeFile, err)
+	}
+
+	return infos, nil
+}
+
+// save writes the details of all tokens, removing the file if there are
+// none.
+func (f infoFile) save(infos map[string]Info) error {
+	path, err := f.filePath()
+	if err != nil {
+		return err
+	}
+
+	if len(infos) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+		}
+
+		return nil
+	}
+
+	data, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToEncodeFile, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), tokenDirPermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	if err := os.WriteFile(path, data, tokenFilePermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestExpiryWarning(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		expires time.Time
+		want    string
+	}{
+		{name: "unknown expiry", expires: time.Time{}, want: ""},
+		{name: "far away", expires: now.Add(30 * 24 * time.Hour), want: ""},
+		{name: "within a week", expires: now.Add(3*24*time.Hour + time.Hour), want: "token expires in 3 days"},
+		{name: "tomorrow", expires: now.Add(30 * time.Hour), want: "token expires in 1 day"},
+		{name: "today", expires: now.Add(time.Hour), want: "token expires today"},
+		{name: "expired", expires: now.Add(-time.Hour), want: "token has expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := Info{Created: time.Time{}, Expires: tt.expires}
+			if got := info.ExpiryWarning(now); got != tt.want {
+				t.Errorf("ExpiryWarning() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInfo(t *testing.T) {
+	keyring.MockInit()
+
+	tokenMgr := NewTokenManager().WithAccount("info")
+
+	before := time.Now().Add(-time.Second)
+	if err := tokenMgr.Import("token"); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	info, err := tokenMgr.Info()
+	if err != nil || info.Created.Before(before) || !info.Expires.IsZero() {
+		t.Fatalf("Info() after Import() = %+v, %v, want created now", info, err)
+	}
+
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := tokenMgr.SetExpiry(expires); err != nil {
+		t.Fatalf("SetExpiry() error = %v", err)
+	}
+
+	if got, _ := tokenMgr.Info(); !got.Expires.Equal(expires) || !got.Created.Equal(info.Created) {
+		t.Errorf("Info() after SetExpiry() = %+v, want expiry %v", got, expires)
+	}
+
+	if got, _ := NewTokenManager().Info(); !got.Created.IsZero() {
+		t.Errorf("Info() of another account = %+v, want empty", got)
+	}
+
+	t.Run("overridden by the environment", func(t *testing.T) {
+		t.Setenv(EnvVar, "env-token")
+
+		if got, _ := tokenMgr.Info(); !got.Created.IsZero() {
+			t.Errorf("Info() = %+v, want empty", got)
+		}
+	})
+
+	if err := tokenMgr.Delete(); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if got, _ := tokenMgr.Info(); !got.Created.IsZero() || !got.Expires.IsZero() {
+		t.Errorf("Info() after Delete() = %+v, want empty", got)
+	}
+}
@@ -0,0 +1,211 @@
+package token
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// passEntryPrefix is the folder of the password store the tokens are
+	// stored in, one entry per user.
+	passEntryPrefix = "switchtube/"
+
+	// gopassExitNotFound is the exit status of gopass for missing entries.
+	// pass exits with 1 on every failure instead.
+	gopassExitNotFound = 11
+)
+
+var (
+	errPassNotFound = errors.New("neither pass nor gopass found on PATH")
+	errPassFailed   = errors.New("pass failed")
+)
+
+// lookPath finds the executable of pass, which tests replace.
+var lookPath = exec.LookPath
+
+// passStore stores tokens in the password store of pass, or of gopass, which
+// understands the same commands. This suits CLI-centric setups that already
+// keep their secrets there, encrypted with their GPG key.
+type passStore struct {
+	// commands are the executables tried in order.
+	commands []string
+}
+
+// newPassStore creates a passStore using pass, or gopass if pass isn't
+// installed.
+func newPassStore() passStore {
+	return passStore{commands: []string{"pass", "gopass"}}
+}
+
+func (ps passStore) get(user string) (string, error) {
+	output, err := ps.run("", "show", passEntryPrefix+user)
+	if err != nil {
+		return "", err
+	}
+
+	// Like browserpass and others, the secret is the first line of the entry
+	token, _, _ := strings.Cut(output, "\n")
+
+	return strings.TrimSpace(token), nil
+}
+
+func (ps passStore) set(user, token string) error {
+	_, err := ps.run(token+"\n", "insert", "--multiline", "--force", passEntryPrefix+user)
+
+	return err
+}
+
+func (ps passStore) delete(user string) error {
+	_, err := ps.run("", "rm", "--force", passEntryPrefix+user)
+
+	return err
+}
+
+// run runs pass with args, the last of which is the entry, and input on its
+// standard input, and returns its standard output. A missing entry results in
+// errNotFound.
+func (ps passStore) run(input string, args ...string) (string, error) {
+	path, err := ps.command()
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if missingEntry(path, args[len(args)-1], err) {
+			return "", errNotFound
+		}
+
+		return "", fmt.Errorf("%w: %w: %s", errPassFailed, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// missingEntry reports whether the command at path failed with err because
+// entry doesn't exist. The message isn't checked, since it is translated.
+func missingEntry(path, entry string, err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+
+	if strings.HasPrefix(filepath.Base(path), "gopass") {
+		return exitErr.ExitCode() == gopassExitNotFound
+	}
+
+	// pass keeps every entry in a file of the store directory
+	dir, err := passStoreDir()
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(filepath.Join(dir, filepath.FromSlash(entry)+".gpg"))
+
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// passStoreDir returns the directory pass keeps its entries in.
+func passStoreDir() (string, error) {
+	if dir := os.Getenv("PASSWORD_STORE_DIR"); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	return filepath.Join(home, ".password-store"), nil
+}
+
+// list returns the users with a token in the password store. gopass lists
+// them itself, while the entries of pass are the files of its directory.
+func (ps passStore) list() ([]string, error) {
+	path, err := ps.command()
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(filepath.Base(path), "gopass") {
+		return listPassDir()
+	}
+
+	output, err := ps.run("", "ls", "--flat", strings.TrimSuffix(passEntryPrefix, "/"))
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var users []string
+
+	for line := range strings.Lines(output) {
+		if user, ok := strings.CutPrefix(strings.TrimSpace(line), passEntryPrefix); ok {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+// listPassDir returns the users with a token in the directory of pass.
+func listPassDir() ([]string, error) {
+	dir, err := passStoreDir()
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(dir, filepath.FromSlash(passEntryPrefix))
+
+	var users []string
+
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if user, ok := strings.CutSuffix(path, ".gpg"); ok && !entry.IsDir() {
+			rel, err := filepath.Rel(root, user)
+			if err != nil {
+				return fmt.Errorf("%w", err)
+			}
+
+			users = append(users, filepath.ToSlash(rel))
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", errPassFailed, err)
+	}
+
+	slices.Sort(users)
+
+	return users, nil
+}
+
+// command returns the path of the first executable of the store that is
+// installed.
+func (ps passStore) command() (string, error) {
+	for _, name := range ps.commands {
+		if path, err := lookPath(name); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", errPassNotFound
+}
@@ -0,0 +1,135 @@
+package token
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// fakePass is a shell script implementing the pass commands used by
+// passStore with one file per entry in $PASSWORD_STORE_DIR, like pass, and
+// lists entries flat, like gopass. It fails with a translated message and the
+// exit status $MISSING for missing entries.
+const fakePass = `#!/bin/sh
+for entry; do :; done
+entry="$PASSWORD_STORE_DIR/$entry.gpg"
+case "$1" in
+show)
+	[ -f "$entry" ] || { echo "Fehler: nicht im Passwortspeicher." >&2; exit "$MISSING"; }
+	cat "$entry"
+	printf 'login: alice\n'
+	;;
+insert)
+	mkdir -p "$(dirname "$entry")"
+	cat > "$entry"
+	;;
+ls)
+	cd "$PASSWORD_STORE_DIR" 2>/dev/null || exit 0
+	find switchtube -name '*.gpg' | sed 's/\.gpg$//' | sort
+	;;
+rm)
+	[ -f "$entry" ] || { echo "Fehler: nicht im Passwortspeicher." >&2; exit "$MISSING"; }
+	rm "$entry"
+	;;
+*)
+	echo "unknown command $1" >&2
+	exit 2
+	;;
+esac
+`
+
+func TestPassStore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake pass is a shell script")
+	}
+
+	tests := []struct {
+		command string
+		missing string
+	}{
+		{command: "pass", missing: "1"},
+		{command: "gopass", missing: "11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			dir := t.TempDir()
+			script := filepath.Join(dir, tt.command)
+
+			if err := os.WriteFile(script, []byte(fakePass), 0o700); err != nil {
+				t.Fatalf("Failed to write fake pass: %v", err)
+			}
+
+			t.Setenv("PASSWORD_STORE_DIR", filepath.Join(dir, "store"))
+			t.Setenv("MISSING", tt.missing)
+
+			// Only this command is installed
+			oldLookPath := lookPath
+			lookPath = func(name string) (string, error) {
+				if name == tt.command {
+					return script, nil
+				}
+
+				return "", exec.ErrNotFound
+			}
+			defer func() { lookPath = oldLookPath }()
+
+			testPassStore(t, newPassStore())
+		})
+	}
+}
+
+// testPassStore checks storing, reading and deleting a token with ps.
+func testPassStore(t *testing.T, ps passStore) {
+	t.Helper()
+
+	if _, err := ps.get("alice"); !errors.Is(err, errNotFound) {
+		t.Errorf("get() of missing entry error = %v, want %v", err, errNotFound)
+	}
+
+	if err := ps.set("alice", "token-a"); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	if token, err := ps.get("alice"); err != nil || token != "token-a" {
+		t.Errorf("get() = %q, %v, want token-a", token, err)
+	}
+
+	if err := ps.set("alice/work", "token-b"); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	if users, err := ps.list(); err != nil || !slices.Equal(users, []string{"alice", "alice/work"}) {
+		t.Errorf("list() = %v, %v, want [alice alice/work]", users, err)
+	}
+
+	if err := ps.delete("alice"); err != nil {
+		t.Fatalf("delete() error = %v", err)
+	}
+
+	if err := ps.delete("alice"); !errors.Is(err, errNotFound) {
+		t.Errorf("delete() twice error = %v, want %v", err, errNotFound)
+	}
+
+	if err := ps.delete("alice/work"); err != nil {
+		t.Fatalf("delete() error = %v", err)
+	}
+
+	if users, err := ps.list(); err != nil || len(users) != 0 {
+		t.Errorf("list() of empty store = %v, %v, want none", users, err)
+	}
+}
+
+func TestPassStoreNotInstalled(t *testing.T) {
+	oldLookPath := lookPath
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+	defer func() { lookPath = oldLookPath }()
+
+	if _, err := newPassStore().get("alice"); !errors.Is(err, errPassNotFound) {
+		t.Errorf("get() error = %v, want %v", err, errPassNotFound)
+	}
+}
@@ -0,0 +1,145 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+var errFailedToPurge = errors.New("failed to purge tokens")
+
+// Purge deletes the tokens of the current user from every token store,
+// regardless of the store tm uses, as well as the whole token file, the
+// details of the tokens and the session caches of decrypted tokens. Besides
+// the default account and the given accounts, the tokens of all accounts
+// found in the token file, the details and pass are deleted, e.g. of accounts
+// that were removed from the config file. It returns the number of tokens
+// deleted. Stores that aren't available, such as a missing keyring or pass,
+// are skipped.
+func (tm *Manager) Purge(accounts []string) (int, error) {
+	if _, ok := tm.store.(staticStore); ok {
+		return 0, errStaticToken
+	}
+
+	keys, err := tm.storedKeys(accounts)
+	if err != nil {
+		return 0, err
+	}
+
+	encrypted := newEncryptedStore(fileStore{path: ""}, FileOptions{Encrypt: false, CacheFor: 0})
+	for _, key := range keys {
+		encrypted.uncache(key)
+	}
+
+	deleted := 0
+
+	// Without D-Bus or a keychain, deleting from the keyring always fails
+	for _, key := range keys {
+		err := keyringStore{service: serviceName}.delete(key)
+		if err == nil {
+			deleted++
+		} else if !errors.Is(err, errNotFound) {
+			slog.Warn("failed to delete token from keyring", "error", err)
+
+			break
+		}
+	}
+
+	var errs []error
+
+	for _, key := range keys {
+		err := newPassStore().delete(key)
+		if err == nil {
+			deleted++
+		} else if errors.Is(err, errPassNotFound) {
+			break
+		} else if !errors.Is(err, errNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	// The token file may hold tokens of accounts that no longer exist
+	removed, err := fileStore{path: ""}.purge()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := tm.info.save(nil); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return deleted + removed, fmt.Errorf("%w: %w", errFailedToPurge, err)
+	}
+
+	return deleted + removed, nil
+}
+
+// storedKeys returns the keys of the tokens of the current user: those of the
+// default account and of accounts, and the ones found in the token file, the
+// details of the tokens and pass. The keyring can't be enumerated.
+func (tm *Manager) storedKeys(accounts []string) ([]string, error) {
+	user, err := tm.WithAccount("").key()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]bool{user: true}
+
+	for _, account := range accounts {
+		key, err := tm.WithAccount(account).key()
+		if err != nil {
+			return nil, err
+		}
+
+		keys[key] = true
+	}
+
+	// Accounts are keyed below the user, like the ones key returns
+	for _, key := range tm.foundKeys() {
+		if strings.HasPrefix(key, user+"/") {
+			keys[key] = true
+		}
+	}
+
+	return slices.Sorted(maps.Keys(keys)), nil
+}
+
+// foundKeys returns the keys of all tokens in the token file, the details of
+// the tokens and pass. Stores that can't be read are skipped.
+func (tm *Manager) foundKeys() []string {
+	tokens, _ := fileStore{path: ""}.load()
+
+	infos, err := tm.info.load()
+	if err != nil {
+		slog.Warn("failed to read the details of the tokens", "error", err)
+	}
+
+	users, err := newPassStore().list()
+	if err != nil && !errors.Is(err, errPassNotFound) {
+		slog.Warn("failed to list the tokens in pass", "error", err)
+	}
+
+	return slices.Concat(slices.Collect(maps.Keys(tokens)), slices.Collect(maps.Keys(infos)), users)
+}
+
+// purge removes the token file and returns the number of tokens it held. A
+// file that can't be decoded is removed as well.
+func (fs fileStore) purge() (int, error) {
+	tokens, _ := fs.load()
+
+	path, err := fs.filePath()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	return len(tokens), nil
+}
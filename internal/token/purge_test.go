@@ -0,0 +1,59 @@
+package token
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestPurge(t *testing.T) {
+	keyring.MockInit()
+
+	oldLookPath := lookPath
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+	defer func() { lookPath = oldLookPath }()
+
+	file, err := NewTokenManagerWithStore(StoreFile)
+	if err != nil {
+		t.Fatalf("NewTokenManagerWithStore() error = %v", err)
+	}
+
+	keyringMgr, err := NewTokenManagerWithStore(StoreKeyring)
+	if err != nil {
+		t.Fatalf("NewTokenManagerWithStore() error = %v", err)
+	}
+
+	// The token of the removed account is only found through its details
+	removed := keyringMgr.WithAccount("removed-account")
+
+	for _, tokenMgr := range []*Manager{
+		keyringMgr, keyringMgr.WithAccount("work"), removed, file, file.WithAccount("removed-account"),
+	} {
+		if err := tokenMgr.Import("token"); err != nil {
+			t.Fatalf("Import() error = %v", err)
+		}
+	}
+
+	deleted, err := keyringMgr.Purge([]string{"work", "unused"})
+	if err != nil || deleted != 5 {
+		t.Errorf("Purge() = %d, %v, want 5", deleted, err)
+	}
+
+	for _, tokenMgr := range []*Manager{
+		keyringMgr, keyringMgr.WithAccount("work"), removed, file.WithAccount("removed-account"),
+	} {
+		if _, err := tokenMgr.Export(); !errors.Is(err, ErrNoTokenFound) {
+			t.Errorf("Export() after Purge() error = %v, want %v", err, ErrNoTokenFound)
+		}
+	}
+
+	if deleted, err := keyringMgr.Purge(nil); err != nil || deleted != 0 {
+		t.Errorf("Purge() twice = %d, %v, want 0", deleted, err)
+	}
+
+	if _, err := NewTokenManagerWithToken("token").Purge(nil); !errors.Is(err, errStaticToken) {
+		t.Errorf("Purge() of static token error = %v, want %v", err, errStaticToken)
+	}
+}
@@ -0,0 +1,317 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"switchtube-downloader/internal/config"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Names of the token stores accepted by NewTokenManagerWithStore. StoreKeyring
+// is the keyring of the operating system, which can also be requested by the
+// name of its backend: StoreSecretService on Linux and BSD, StoreKeychain on
+// macOS and StoreWincred on Windows.
+const (
+	StoreAuto          = "auto"
+	StoreKeyring       = "keyring"
+	StoreSecretService = "secret-service"
+	StoreKeychain      = "keychain"
+	StoreWincred       = "wincred"
+	StoreFile          = "file"
+	StorePass          = "pass"
+)
+
+// Stores lists the names of all token stores.
+var Stores = []string{
+	StoreAuto, StoreKeyring, StoreSecretService, StoreKeychain, StoreWincred, StoreFile, StorePass,
+}
+
+// keyringBackends maps the keyring backends to the operating systems they
+// are available on.
+var keyringBackends = map[string][]string{
+	StoreSecretService: {"linux", "freebsd", "openbsd", "netbsd", "dragonfly"},
+	StoreKeychain:      {"darwin"},
+	StoreWincred:       {"windows"},
+}
+
+const (
+	// tokenFileName is the name of the token file in the config directory.
+	tokenFileName = "tokens.json"
+
+	// File and directory permissions of the token file.
+	tokenDirPermissions  = 0o700
+	tokenFilePermissions = 0o600
+)
+
+var (
+	errFailedToDecodeFile = errors.New("failed to decode token file")
+	errFailedToEncodeFile = errors.New("failed to encode token file")
+	errFailedToReadFile   = errors.New("failed to read token file")
+	errFailedToWriteFile  = errors.New("failed to write token file")
+	errNotFound           = errors.New("token not found")
+	errStaticToken        = errors.New("token was given explicitly and can't be changed")
+	errUnknownStore       = errors.New("unknown token store")
+	errUnsupportedStore   = errors.New("token store not available on this system")
+)
+
+// store is a storage backend for access tokens, keyed by user name. All
+// implementations return errNotFound if there is no token for a user.
+type store interface {
+	get(user string) (string, error)
+	set(user, token string) error
+	delete(user string) error
+}
+
+// newStore returns the store with the given name. The token file is
+// configured by options.
+func newStore(name string, options FileOptions) (store, error) {
+	file := newEncryptedStore(fileStore{path: ""}, options)
+
+	switch name {
+	case StoreAuto, "":
+		return newAutoStore(file), nil
+	case StoreKeyring:
+		return keyringStore{service: serviceName}, nil
+	case StoreSecretService, StoreKeychain, StoreWincred:
+		if !slices.Contains(keyringBackends[name], runtime.GOOS) {
+			return nil, fmt.Errorf("%w: %s (use %s for the keyring of %s)",
+				errUnsupportedStore, name, StoreKeyring, runtime.GOOS)
+		}
+
+		return keyringStore{service: serviceName}, nil
+	case StoreFile:
+		return file, nil
+	case StorePass:
+		return newPassStore(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s (must be %s)",
+			errUnknownStore, name, strings.Join(Stores, ", "))
+	}
+}
+
+// staticStore holds a single token that was given explicitly, e.g. by a
+// program using the library.
+type staticStore struct {
+	token string
+}
+
+func (ss staticStore) get(string) (string, error) {
+	return ss.token, nil
+}
+
+func (ss staticStore) set(string, string) error {
+	return errStaticToken
+}
+
+func (ss staticStore) delete(string) error {
+	return errStaticToken
+}
+
+// keyringStore stores tokens in the system keyring.
+type keyringStore struct {
+	service string
+}
+
+func (ks keyringStore) get(user string) (string, error) {
+	token, err := keyring.Get(ks.service, user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", errNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("%w", err)
+	}
+
+	return token, nil
+}
+
+func (ks keyringStore) set(user, token string) error {
+	if err := keyring.Set(ks.service, user, token); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+func (ks keyringStore) delete(user string) error {
+	err := keyring.Delete(ks.service, user)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return errNotFound
+	} else if err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+// fileStore stores tokens in a JSON file only readable by the current user.
+// This is meant for headless systems without a keyring.
+type fileStore struct {
+	// path overrides the default location inside the config directory.
+	path string
+}
+
+func (fs fileStore) get(user string) (string, error) {
+	tokens, err := fs.load()
+	if err != nil {
+		return "", err
+	}
+
+	token, ok := tokens[user]
+	if !ok {
+		return "", errNotFound
+	}
+
+	return token, nil
+}
+
+func (fs fileStore) set(user, token string) error {
+	tokens, err := fs.load()
+	if err != nil {
+		return err
+	}
+
+	tokens[user] = token
+
+	return fs.save(tokens)
+}
+
+func (fs fileStore) delete(user string) error {
+	tokens, err := fs.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := tokens[user]; !ok {
+		return errNotFound
+	}
+
+	delete(tokens, user)
+
+	return fs.save(tokens)
+}
+
+// filePath returns the location of the token file.
+func (fs fileStore) filePath() (string, error) {
+	if fs.path != "" {
+		return fs.path, nil
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errFailedToReadFile, err)
+	}
+
+	return filepath.Join(dir, tokenFileName), nil
+}
+
+// load reads all tokens from the token file. A missing file contains no
+// tokens.
+func (fs fileStore) load() (map[string]string, error) {
+	path, err := fs.filePath()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(map[string]string)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToReadFile, err)
+	}
+
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("%w: %w", errFailedToDecodeFile, err)
+	}
+
+	return tokens, nil
+}
+
+// save writes all tokens to the token file.
+func (fs fileStore) save(tokens map[string]string) error {
+	path, err := fs.filePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", errFailedToEncodeFile, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), tokenDirPermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	if err := os.WriteFile(path, data, tokenFilePermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	// WriteFile keeps the permissions of an existing file
+	if err := os.Chmod(path, tokenFilePermissions); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToWriteFile, err)
+	}
+
+	return nil
+}
+
+// autoStore uses the keyring and falls back to the token file if the
+// keyring is not available, e.g. on servers without D-Bus.
+type autoStore struct {
+	primary  store
+	fallback store
+}
+
+// newAutoStore creates an autoStore using the keyring and the token file.
+func newAutoStore(file store) autoStore {
+	return autoStore{
+		primary:  keyringStore{service: serviceName},
+		fallback: file,
+	}
+}
+
+func (as autoStore) get(user string) (string, error) {
+	token, err := as.primary.get(user)
+	if err == nil {
+		return token, nil
+	}
+
+	// The token may have been stored in the file while the keyring was unavailable
+	return as.fallback.get(user)
+}
+
+func (as autoStore) set(user, token string) error {
+	err := as.primary.set(user, token)
+	if err == nil {
+		return nil
+	}
+
+	slog.Warn("keyring not available, storing token in file instead", "error", err)
+
+	return as.fallback.set(user, token)
+}
+
+func (as autoStore) delete(user string) error {
+	primaryErr := as.primary.delete(user)
+	fallbackErr := as.fallback.delete(user)
+
+	switch {
+	case primaryErr == nil || fallbackErr == nil:
+		return nil
+	case errors.Is(primaryErr, errNotFound) && errors.Is(fallbackErr, errNotFound):
+		return errNotFound
+	case !errors.Is(primaryErr, errNotFound):
+		return primaryErr
+	default:
+		return fallbackErr
+	}
+}
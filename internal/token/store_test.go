@@ -0,0 +1,116 @@
+package token
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+var errStoreUnavailable = errors.New("store unavailable")
+
+// brokenStore is a store whose operations always fail, like a keyring on a
+// system without D-Bus.
+type brokenStore struct{}
+
+func (brokenStore) get(string) (string, error) { return "", errStoreUnavailable }
+func (brokenStore) set(string, string) error   { return errStoreUnavailable }
+func (brokenStore) delete(string) error        { return errStoreUnavailable }
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", tokenFileName)
+	fs := fileStore{path: path}
+
+	if _, err := fs.get("alice"); !errors.Is(err, errNotFound) {
+		t.Errorf("get() on missing file error = %v, want %v", err, errNotFound)
+	}
+
+	if err := fs.set("alice", "token-a"); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	if err := fs.set("bob", "token-b"); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	if token, err := fs.get("alice"); err != nil || token != "token-a" {
+		t.Errorf("get() = %q, %v, want token-a", token, err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat token file: %v", err)
+		}
+
+		if perm := info.Mode().Perm(); perm != tokenFilePermissions {
+			t.Errorf("token file permissions = %o, want %o", perm, tokenFilePermissions)
+		}
+	}
+
+	if err := fs.delete("alice"); err != nil {
+		t.Fatalf("delete() error = %v", err)
+	}
+
+	if err := fs.delete("alice"); !errors.Is(err, errNotFound) {
+		t.Errorf("delete() twice error = %v, want %v", err, errNotFound)
+	}
+
+	if token, err := fs.get("bob"); err != nil || token != "token-b" {
+		t.Errorf("get() after deleting other user = %q, %v, want token-b", token, err)
+	}
+}
+
+func TestAutoStoreFallback(t *testing.T) {
+	// Hide the warning printed when falling back
+	oldStdout := os.Stdout
+	os.Stdout, _ = os.Open(os.DevNull)
+
+	defer func() { os.Stdout = oldStdout }()
+
+	fallback := fileStore{path: filepath.Join(t.TempDir(), tokenFileName)}
+	as := autoStore{primary: brokenStore{}, fallback: fallback}
+
+	if err := as.set("alice", "token-a"); err != nil {
+		t.Fatalf("set() error = %v", err)
+	}
+
+	if token, err := fallback.get("alice"); err != nil || token != "token-a" {
+		t.Errorf("fallback get() = %q, %v, want token-a", token, err)
+	}
+
+	if token, err := as.get("alice"); err != nil || token != "token-a" {
+		t.Errorf("get() = %q, %v, want token-a", token, err)
+	}
+
+	if err := as.delete("alice"); err != nil {
+		t.Errorf("delete() error = %v", err)
+	}
+
+	if _, err := as.get("alice"); err == nil {
+		t.Error("get() after delete returned no error")
+	}
+}
+
+func TestNewTokenManagerWithStore(t *testing.T) {
+	for _, name := range []string{"", StoreAuto, StoreKeyring, StoreFile, StorePass} {
+		if _, err := NewTokenManagerWithStore(name); err != nil {
+			t.Errorf("NewTokenManagerWithStore(%q) error = %v", name, err)
+		}
+	}
+
+	if _, err := NewTokenManagerWithStore("vault"); !errors.Is(err, errUnknownStore) {
+		t.Errorf("NewTokenManagerWithStore(vault) error = %v, want %v", err, errUnknownStore)
+	}
+
+	for backend, systems := range keyringBackends {
+		_, err := NewTokenManagerWithStore(backend)
+		if slices.Contains(systems, runtime.GOOS) && err != nil {
+			t.Errorf("NewTokenManagerWithStore(%q) error = %v", backend, err)
+		} else if !slices.Contains(systems, runtime.GOOS) && !errors.Is(err, errUnsupportedStore) {
+			t.Errorf("NewTokenManagerWithStore(%q) error = %v, want %v", backend, err, errUnsupportedStore)
+		}
+	}
+}
@@ -4,56 +4,136 @@ package token
 import (
 	"errors"
 	"fmt"
+	"os"
 	"os/user"
+	"strings"
 
 	"switchtube-downloader/internal/helper/ui"
-
-	"github.com/zalando/go-keyring"
 )
 
 const (
 	serviceName          = "SwitchTube"
 	createAccessTokenURL = "https://tube.switch.ch/access_tokens"
+
+	// EnvVar is the environment variable that overrides the stored token.
+	EnvVar = "SWITCHTUBE_TOKEN"
 )
 
 var (
 	// ErrTokenAlreadyExists is returned when trying to set a token that already
-	// exists in the keyring.
-	ErrTokenAlreadyExists = errors.New("token already exists in keyring")
-
-	errFailedToDelete     = errors.New("failed to delete token from keyring")
-	errFailedToGetUser    = errors.New("failed to get current user")
-	errFailedToRetrieve   = errors.New("failed to retrieve token from keyring")
-	errFailedToStore      = errors.New("failed to store token in keyring")
-	errNoTokenFoundDelete = errors.New("no token found in keyring")
-	errNoTokenFound       = errors.New("no token found in keyring - run 'token set' first")
-	errTokenEmpty         = errors.New("token cannot be empty")
-	errUnableToCreate     = errors.New("unable to create access token")
+	// exists in the token store.
+	ErrTokenAlreadyExists = errors.New("token already exists")
+
+	// ErrNoTokenFound is returned when no token is stored and none is given by
+	// the environment.
+	ErrNoTokenFound = errors.New("no token found - run 'token set' first")
+
+	// ErrNoTokenStored is returned when deleting a token that isn't stored.
+	ErrNoTokenStored = errors.New("no token found")
+
+	errFailedToDelete   = errors.New("failed to delete token")
+	errFailedToGetUser  = errors.New("failed to get current user")
+	errFailedToRetrieve = errors.New("failed to retrieve token")
+	errFailedToStore    = errors.New("failed to store token")
+	errTokenEmpty       = errors.New("token cannot be empty")
+	errUnableToCreate   = errors.New("unable to create access token")
 )
 
 // Manager encapsulates token management logic.
 type Manager struct {
-	keyringService string
+	store store
+
+	// account is the name of the account the token belongs to, which is
+	// empty for the default account.
+	account string
+
+	// info holds the details of the stored tokens.
+	info infoFile
 }
 
-// NewTokenManager creates a new instance of tokenManager.
+// NewTokenManager creates a new instance of tokenManager using the keyring
+// with a fallback to the token file.
 func NewTokenManager() *Manager {
 	return &Manager{
-		keyringService: serviceName,
+		store:   newAutoStore(newEncryptedStore(fileStore{path: ""}, FileOptions{Encrypt: false, CacheFor: 0})),
+		account: "",
+		info:    infoFile{path: ""},
 	}
 }
 
-// Get retrieves the access token from the system keyring.
-func (tm *Manager) Get() (string, error) {
+// NewTokenManagerWithStore creates a new instance of tokenManager using the
+// token store with the given name, one of Stores.
+func NewTokenManagerWithStore(name string) (*Manager, error) {
+	return NewTokenManagerWithFile(name, FileOptions{Encrypt: false, CacheFor: 0})
+}
+
+// NewTokenManagerWithFile creates a new instance of tokenManager like
+// NewTokenManagerWithStore, with the token file configured by options.
+func NewTokenManagerWithFile(name string, options FileOptions) (*Manager, error) {
+	backend, err := newStore(name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{store: backend, account: "", info: infoFile{path: ""}}, nil
+}
+
+// NewTokenManagerWithToken creates a new instance of tokenManager that
+// always returns token, ignoring the environment and the token stores.
+func NewTokenManagerWithToken(token string) *Manager {
+	return &Manager{store: staticStore{token: token}, account: "", info: infoFile{path: ""}}
+}
+
+// WithAccount returns a copy of tm managing the token of the account with the
+// given name instead, or of the default account if name is empty.
+func (tm *Manager) WithAccount(name string) *Manager {
+	copied := *tm
+	copied.account = name
+
+	return &copied
+}
+
+// key returns the key of the token in the store, which is the name of the
+// current user, followed by the account, if any.
+func (tm *Manager) key() (string, error) {
 	userName, err := user.Current()
 	if err != nil {
 		return "", fmt.Errorf("%w: %w", errFailedToGetUser, err)
 	}
 
-	token, err := keyring.Get(tm.keyringService, userName.Username)
+	if tm.account == "" {
+		return userName.Username, nil
+	}
+
+	return userName.Username + "/" + tm.account, nil
+}
+
+// Get retrieves the access token from the SWITCHTUBE_TOKEN environment
+// variable or, if it is not set, from the token store. A token given to
+// NewTokenManagerWithToken takes precedence over the environment.
+func (tm *Manager) Get() (string, error) {
+	if static, ok := tm.store.(staticStore); ok {
+		return static.token, nil
+	}
+
+	if token := strings.TrimSpace(os.Getenv(EnvVar)); token != "" {
+		return token, nil
+	}
+
+	return tm.getStored()
+}
+
+// getStored retrieves the access token from the token store.
+func (tm *Manager) getStored() (string, error) {
+	key, err := tm.key()
 	if err != nil {
-		if errors.Is(err, keyring.ErrNotFound) {
-			return "", errNoTokenFound
+		return "", err
+	}
+
+	token, err := tm.store.get(key)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return "", ErrNoTokenFound
 		}
 
 		return "", fmt.Errorf("%w: %w", errFailedToRetrieve, err)
@@ -62,18 +142,18 @@ func (tm *Manager) Get() (string, error) {
 	return token, nil
 }
 
-// Set creates and stores a new access token in the system keyring.
+// Set creates and stores a new access token in the token store.
 func (tm *Manager) Set() error {
-	existingToken, err := tm.Get()
-	if err != nil && !errors.Is(err, errNoTokenFound) {
+	existingToken, err := tm.getStored()
+	if err != nil && !errors.Is(err, ErrNoTokenFound) {
 		return fmt.Errorf("%w: %w", errFailedToRetrieve, err)
 	}
 
 	if existingToken != "" {
-		fmt.Println("Token already exists in keyring")
+		fmt.Fprintln(os.Stderr, "Token already exists")
 
 		if !ui.Confirm("Do you want to replace it?") {
-			fmt.Println("Operation cancelled")
+			fmt.Fprintln(os.Stderr, "Operation cancelled")
 
 			return fmt.Errorf("%w", ErrTokenAlreadyExists)
 		}
@@ -84,40 +164,76 @@ func (tm *Manager) Set() error {
 		return fmt.Errorf("%w: %w", errUnableToCreate, err)
 	}
 
-	userName, err := user.Current()
+	key, err := tm.key()
+	if err != nil {
+		return err
+	}
+
+	if err = tm.store.set(key, token); err != nil {
+		return fmt.Errorf("%w: %w", errFailedToStore, err)
+	}
+
+	tm.recordCreated(key)
+
+	return nil
+}
+
+// Import stores the given access token, replacing an existing one without
+// prompting.
+func (tm *Manager) Import(token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return errTokenEmpty
+	}
+
+	key, err := tm.key()
 	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToGetUser, err)
+		return err
 	}
 
-	if err = keyring.Set(tm.keyringService, userName.Username, token); err != nil {
+	if err = tm.store.set(key, token); err != nil {
 		return fmt.Errorf("%w: %w", errFailedToStore, err)
 	}
 
+	tm.recordCreated(key)
+
 	return nil
 }
 
-// Delete removes the access token from the system keyring.
+// Export returns the access token from the token store. Unlike Get, it
+// ignores the SWITCHTUBE_TOKEN environment variable.
+func (tm *Manager) Export() (string, error) {
+	return tm.getStored()
+}
+
+// Delete removes the access token from the token store.
 func (tm *Manager) Delete() error {
-	userName, err := user.Current()
+	key, err := tm.key()
 	if err != nil {
-		return fmt.Errorf("%w: %w", errFailedToGetUser, err)
+		return err
 	}
 
-	if err = keyring.Delete(tm.keyringService, userName.Username); err != nil {
-		if errors.Is(err, keyring.ErrNotFound) {
-			return fmt.Errorf("%w for %s", errNoTokenFoundDelete, tm.keyringService)
+	if err = tm.store.delete(key); err != nil {
+		if errors.Is(err, errNotFound) {
+			return fmt.Errorf("%w", ErrNoTokenStored)
 		}
 
 		return fmt.Errorf("%w: %w", errFailedToDelete, err)
 	}
 
+	tm.forget(key)
+
 	return nil
 }
 
 // create prompts the user to visit the access-token-creation URL and enter a new token.
 func (tm *Manager) create() (string, error) {
-	fmt.Printf("Please visit: %s\n", createAccessTokenURL)
-	fmt.Printf("Create a new access token and paste it below\n\n")
+	if !ui.InputEnabled() {
+		return "", fmt.Errorf("%w: use 'token import' instead", ui.ErrInputDisabled)
+	}
+
+	fmt.Fprintf(os.Stderr, "Please visit: %s\n", createAccessTokenURL)
+	fmt.Fprintf(os.Stderr, "Create a new access token and paste it below\n\n")
 
 	token := ui.Input("Enter your access token: ")
 	if token == "" {
@@ -9,6 +9,23 @@ import (
 	"github.com/zalando/go-keyring"
 )
 
+func TestMain(m *testing.M) {
+	// Keep the token file fallback away from the real config directory
+	configHome, err := os.MkdirTemp("", "token-test")
+	if err != nil {
+		panic(err)
+	}
+
+	os.Setenv("XDG_CONFIG_HOME", configHome)
+	os.Setenv("HOME", configHome)
+	os.Setenv("AppData", configHome)
+
+	code := m.Run()
+
+	os.RemoveAll(configHome)
+	os.Exit(code)
+}
+
 func TestGet(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -26,7 +43,7 @@ func TestGet(t *testing.T) {
 		{
 			name:        "token not found",
 			setupToken:  false,
-			wantErrType: errNoTokenFound,
+			wantErrType: ErrNoTokenFound,
 		},
 	}
 
@@ -64,6 +81,75 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGetFromEnv(t *testing.T) {
+	tests := []struct {
+		name         string
+		envValue     string
+		keyringToken string
+		wantToken    string
+	}{
+		{
+			name:         "environment overrides keyring",
+			envValue:     "env-token",
+			keyringToken: "keyring-token",
+			wantToken:    "env-token",
+		},
+		{
+			name:         "environment without keyring",
+			envValue:     "  env-token  ",
+			keyringToken: "",
+			wantToken:    "env-token",
+		},
+		{
+			name:         "empty environment falls back to keyring",
+			envValue:     "",
+			keyringToken: "keyring-token",
+			wantToken:    "keyring-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring.MockInit()
+			t.Setenv(EnvVar, tt.envValue)
+
+			if tt.keyringToken != "" {
+				currentUser, userErr := user.Current()
+				if userErr != nil {
+					t.Fatalf("Failed to get current user: %v", userErr)
+				}
+
+				keyring.Set(serviceName, currentUser.Username, tt.keyringToken)
+			}
+
+			token, err := NewTokenManager().Get()
+			if err != nil {
+				t.Fatalf("Get() error = %v, want nil", err)
+			}
+
+			if token != tt.wantToken {
+				t.Errorf("Get() token = %v, want %v", token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestStaticToken(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv(EnvVar, "env-token")
+
+	tm := NewTokenManagerWithToken("static-token")
+
+	token, err := tm.Get()
+	if err != nil || token != "static-token" {
+		t.Errorf("Get() = %q, %v, want static-token", token, err)
+	}
+
+	if err := tm.Delete(); err == nil {
+		t.Error("Delete() error = nil, want an error for a static token")
+	}
+}
+
 func TestSet(t *testing.T) {
 	// Capture stdout to hide prompts
 	oldStdout := os.Stdout
@@ -140,6 +226,74 @@ func TestSet(t *testing.T) {
 	}
 }
 
+func TestImportExport(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    string
+		input       string
+		wantToken   string
+		wantErrType error
+	}{
+		{
+			name:      "import new token",
+			input:     "imported-token\n",
+			wantToken: "imported-token",
+		},
+		{
+			name:      "import replaces existing token",
+			existing:  "old-token",
+			input:     "new-token",
+			wantToken: "new-token",
+		},
+		{
+			name:        "import empty token",
+			input:       "  \n",
+			wantErrType: errTokenEmpty,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring.MockInit()
+			t.Setenv(EnvVar, "env-token")
+
+			tokenMgr := NewTokenManager()
+
+			if tt.existing != "" {
+				currentUser, userErr := user.Current()
+				if userErr != nil {
+					t.Fatalf("Failed to get current user: %v", userErr)
+				}
+
+				keyring.Set(serviceName, currentUser.Username, tt.existing)
+			}
+
+			err := tokenMgr.Import(tt.input)
+			if tt.wantErrType != nil {
+				if !errors.Is(err, tt.wantErrType) {
+					t.Errorf("Import() error = %v, want error type %v", err, tt.wantErrType)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Import() error = %v, want nil", err)
+			}
+
+			// Export must ignore the environment variable
+			token, err := tokenMgr.Export()
+			if err != nil {
+				t.Fatalf("Export() error = %v, want nil", err)
+			}
+
+			if token != tt.wantToken {
+				t.Errorf("Export() token = %v, want %v", token, tt.wantToken)
+			}
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -153,7 +307,7 @@ func TestDelete(t *testing.T) {
 		{
 			name:        "token not found",
 			setupToken:  false,
-			wantErrType: errNoTokenFoundDelete,
+			wantErrType: ErrNoTokenStored,
 		},
 	}
 
@@ -267,3 +421,28 @@ func TestCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestWithAccount(t *testing.T) {
+	keyring.MockInit()
+
+	tokenMgr := NewTokenManager()
+	work := tokenMgr.WithAccount("work")
+
+	if err := tokenMgr.Import("default-token"); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if _, err := work.Export(); !errors.Is(err, ErrNoTokenFound) {
+		t.Errorf("Export() of account without token error = %v, want %v", err, ErrNoTokenFound)
+	}
+
+	if err := work.Import("work-token"); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	for manager, want := range map[*Manager]string{tokenMgr: "default-token", work: "work-token"} {
+		if token, err := manager.Export(); err != nil || token != want {
+			t.Errorf("Export() of account %q = %q, %v, want %q", manager.account, token, err, want)
+		}
+	}
+}
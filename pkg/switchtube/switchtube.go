@@ -0,0 +1,205 @@
+// Package switchtube lets Go programs download videos from SwitchTube without
+// shelling out to the switchtube-downloader command.
+//
+// A media argument is the ID or URL of a video, channel or profile, just like
+// on the command line:
+//
+//	client, err := switchtube.NewClient("", switchtube.ClientOptions{})
+//	if err != nil {
+//		return err
+//	}
+//
+//	summaries, err := client.Download(ctx, "https://tube.switch.ch/channels/a1B2c3",
+//		switchtube.DownloadOptions{
+//			Output: "videos",
+//			Progress: switchtube.ProgressFunc(func(event switchtube.ProgressEvent) {
+//				fmt.Println(event.File, event.Bytes, event.Total)
+//			}),
+//		})
+package switchtube
+
+import (
+	"context"
+	"fmt"
+
+	"switchtube-downloader/internal/download"
+	"switchtube-downloader/internal/models"
+	"switchtube-downloader/internal/token"
+)
+
+type (
+	// API is the part of the SwitchTube API downloads use, which
+	// Client.WithAPI replaces.
+	API = download.API
+
+	// ClientOptions configure the HTTP client of a Client.
+	ClientOptions = models.ClientConfig
+
+	// Video is a video of a channel.
+	Video = models.Video
+
+	// VideoDetails describes a video including its downloadable variants.
+	VideoDetails = models.VideoDetails
+
+	// Variant is a downloadable variant of a video.
+	Variant = models.Variant
+
+	// Channel is a channel found by a search.
+	Channel = models.Channel
+
+	// ChannelListing describes the videos of a channel.
+	ChannelListing = models.ChannelListing
+
+	// SearchResult holds the videos and channels matching a search query.
+	SearchResult = models.SearchResult
+
+	// ProgressEvent reports the progress of downloading a single video.
+	ProgressEvent = models.ProgressEvent
+
+	// ProgressReporter receives progress events while videos are
+	// downloaded.
+	ProgressReporter = models.ProgressReporter
+
+	// DownloadSummary is the result of downloading the videos of a channel.
+	DownloadSummary = models.DownloadSummary
+
+	// VideoResult is the result of downloading a single video of a channel.
+	VideoResult = models.VideoResult
+)
+
+// Statuses of videos in download summaries.
+const (
+	StatusDownloaded = models.StatusDownloaded
+	StatusSkipped    = models.StatusSkipped
+	StatusFailed     = models.StatusFailed
+)
+
+// States of a download reported in progress events.
+const (
+	ProgressStarted     = models.ProgressStarted
+	ProgressDownloading = models.ProgressDownloading
+	ProgressFinished    = models.ProgressFinished
+	ProgressFailed      = models.ProgressFailed
+)
+
+// ProgressFunc is a ProgressReporter calling the function for every event.
+type ProgressFunc func(event ProgressEvent)
+
+// Report calls f with event.
+func (f ProgressFunc) Report(event ProgressEvent) {
+	f(event)
+}
+
+// DownloadOptions configure Client.Download.
+type DownloadOptions struct {
+	// Output is the directory videos are downloaded to. Channels get their
+	// own folder inside it. It defaults to the working directory if empty.
+	Output string
+
+	// UseEpisode prefixes file names with the episode number.
+	UseEpisode bool
+
+	// Overwrite replaces existing files, which are skipped otherwise.
+	Overwrite bool
+
+	// Progress receives the progress of every video. No progress is shown if
+	// it is nil.
+	Progress ProgressReporter
+}
+
+// Client talks to SwitchTube with an access token.
+type Client struct {
+	client *download.Client
+}
+
+// NewClient creates a Client using accessToken. If accessToken is empty, the
+// token is taken from the SWITCHTUBE_TOKEN environment variable or the token
+// store of the switchtube-downloader command.
+func NewClient(accessToken string, options ClientOptions) (*Client, error) {
+	tokenManager := token.NewTokenManager()
+	if accessToken != "" {
+		tokenManager = token.NewTokenManagerWithToken(accessToken)
+	}
+
+	client, err := download.NewClient(tokenManager, options)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return &Client{client: client}, nil
+}
+
+// WithAPI returns a copy of c whose downloads make the requests of API with
+// api, e.g. a mock in tests or a different transport.
+func (c *Client) WithAPI(api API) *Client {
+	return &Client{client: c.client.WithAPI(api)}
+}
+
+// Video returns the metadata and variants of the video media.
+func (c *Client) Video(ctx context.Context, media string) (*VideoDetails, error) {
+	details, err := download.VideoInfo(ctx, c.client, media)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return details, nil
+}
+
+// Channel returns the videos of the channel media together with the size of
+// the variant that would be downloaded.
+func (c *Client) Channel(ctx context.Context, media string) (*ChannelListing, error) {
+	listing, err := download.ListChannel(ctx, c.client, media)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return listing, nil
+}
+
+// Search returns the videos and channels matching query.
+func (c *Client) Search(ctx context.Context, query string) (*SearchResult, error) {
+	result, err := download.Search(ctx, c.client, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+
+	return result, nil
+}
+
+// Download downloads the video, all videos of the channel or all channels of
+// the profile media and returns the summary of every downloaded channel,
+// which is empty for a single video. It never prompts and prints nothing to
+// standard output; the progress goes to the Progress reporter of options. The
+// download is aborted once ctx is done. If some videos failed, the summaries
+// are returned together with the error.
+func (c *Client) Download(
+	ctx context.Context,
+	media string,
+	options DownloadOptions,
+) ([]DownloadSummary, error) {
+	var config models.DownloadConfig
+
+	config.Media = media
+	config.Output = options.Output
+	config.UseEpisode = options.UseEpisode
+	config.All = true
+	config.Force = options.Overwrite
+	config.Skip = !options.Overwrite
+
+	config.Reporter = options.Progress
+	if config.Reporter == nil {
+		config.Reporter = ProgressFunc(func(ProgressEvent) {})
+	}
+
+	var summaries []DownloadSummary
+
+	config.OnSummary = func(summary DownloadSummary) {
+		summaries = append(summaries, summary)
+	}
+
+	if err := download.Download(ctx, c.client, config); err != nil {
+		return summaries, fmt.Errorf("%w", err)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,151 @@
+package switchtube
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProgressFunc(t *testing.T) {
+	var events []ProgressEvent
+
+	var reporter ProgressReporter = ProgressFunc(func(event ProgressEvent) {
+		events = append(events, event)
+	})
+
+	reporter.Report(ProgressEvent{VideoID: "abc", State: ProgressFinished})
+
+	if len(events) != 1 || events[0].VideoID != "abc" || events[0].State != ProgressFinished {
+		t.Errorf("events = %+v, want the reported event", events)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	client, err := NewClient("secret", ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, err := client.Video(context.Background(), ""); err == nil {
+		t.Error("Video(\"\") error = nil, want an error for empty media")
+	}
+}
+
+// newTestServer serves a channel with a single video like SwitchTube.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	responses := map[string]string{
+		"/api/v1/browse/channels/ch1":             `{"id":"ch1","name":"Course"}`,
+		"/api/v1/browse/channels/ch1/videos":      `[{"id":"v1","title":"Intro","episode":"01"}]`,
+		"/api/v1/browse/videos/v1/video_variants": `[{"path":"/media/v1.mp4","mediaType":"video/mp4"}]`,
+		"/api/v1/browse/videos/v1":                `{"id":"v1","title":"Intro","episode":"01"}`,
+		"/media/v1.mp4":                           "video data",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestDownload(t *testing.T) {
+	server := newTestServer(t)
+
+	client, err := NewClient("secret", ClientOptions{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	// Nothing may be printed to stdout, which belongs to the program
+	stdout := captureStdout(t)
+
+	var states []string
+
+	output := t.TempDir()
+	summaries, err := client.Download(context.Background(), server.URL+"/channels/ch1", DownloadOptions{
+		Output: output,
+		Progress: ProgressFunc(func(event ProgressEvent) {
+			states = append(states, event.State)
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+
+	if len(summaries) != 1 || summaries[0].Channel != "Course" || summaries[0].Downloaded != 1 ||
+		len(summaries[0].Videos) != 1 || summaries[0].Videos[0].Status != StatusDownloaded {
+		t.Errorf("Download() = %+v, want one downloaded video of Course", summaries)
+	}
+
+	if len(states) == 0 || states[len(states)-1] != ProgressFinished {
+		t.Errorf("progress states = %v, want them to end with %s", states, ProgressFinished)
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "Course", "Intro.mp4"))
+	if err != nil || string(data) != "video data" {
+		t.Errorf("downloaded video = %q, %v, want the media", data, err)
+	}
+
+	if printed := stdout(); printed != "" {
+		t.Errorf("stdout = %q, want nothing", printed)
+	}
+}
+
+func TestDownloadCancelled(t *testing.T) {
+	server := newTestServer(t)
+
+	client, err := NewClient("secret", ClientOptions{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = client.Download(ctx, server.URL+"/channels/ch1", DownloadOptions{Output: t.TempDir()})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Download() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+// captureStdout redirects stdout to a file until the returned function is
+// called, which returns what was written.
+func captureStdout(t *testing.T) func() string {
+	t.Helper()
+
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := os.Stdout
+	os.Stdout = file
+
+	t.Cleanup(func() { os.Stdout = original })
+
+	return func() string {
+		os.Stdout = original
+
+		data, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(data)
+	}
+}